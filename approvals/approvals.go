@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/alwinius/bow/extension/notification"
 	"github.com/alwinius/bow/pkg/store"
 	"github.com/alwinius/bow/types"
 	"github.com/google/uuid"
@@ -33,6 +35,9 @@ type Manager interface {
 
 	// Increases Approval votes by 1
 	Approve(identifier, voter string) (*types.Approval, error)
+	// ApproveOnce - same as Approve but fails with ErrAlreadyVoted when the
+	// voter already voted, used by one-time approve links
+	ApproveOnce(identifier, voter string) (*types.Approval, error)
 	// Rejects Approval
 	Reject(identifier string) (*types.Approval, error)
 
@@ -42,11 +47,17 @@ type Manager interface {
 	Archive(identifier string) error
 
 	StartExpiryService(ctx context.Context) error
+	StartReminderService(ctx context.Context) error
 }
 
 // Approvals related errors
 var (
 	ErrApprovalAlreadyExists = errors.New("approval already exists")
+	// ErrVoterRequired - votes without voter identity are not accepted
+	// for approvals that limit who can vote
+	ErrVoterRequired = errors.New("voter identity is required")
+	// ErrAlreadyVoted - returned by ApproveOnce when the voter already voted
+	ErrAlreadyVoted = errors.New("voter already voted")
 )
 
 // Approvals cache prefix
@@ -62,6 +73,15 @@ type DefaultManager struct {
 
 	store store.Store
 
+	// sender is used to remind approvers about approaching deadlines
+	sender           notification.Sender
+	reminderInterval time.Duration
+
+	// approve links are added to approval requested notifications
+	// when both are set
+	approveLinkURL string
+	signingSecret  []byte
+
 	// subscriber channels
 	channels map[uint32]chan *types.Approval
 	index    uint32
@@ -76,18 +96,39 @@ type DefaultManager struct {
 type Opts struct {
 	Store store.Store
 	// Cache cache.Cache
+
+	// Sender - optional, enables deadline reminders
+	Sender notification.Sender
+	// ReminderInterval - how often pending approvals are checked,
+	// defaults to DefaultReminderInterval
+	ReminderInterval time.Duration
+
+	// ApproveLinkURL, SigningSecret - optional, new approvals are announced
+	// through the sender with one-time approve link signed with the secret,
+	// ApproveLinkURL is the address bow is reachable at
+	ApproveLinkURL string
+	SigningSecret  []byte
 }
 
 // New create new instance of default manager
 func New(opts *Opts) *DefaultManager {
+	reminderInterval := opts.ReminderInterval
+	if reminderInterval <= 0 {
+		reminderInterval = DefaultReminderInterval
+	}
+
 	man := &DefaultManager{
 		// cache:      opts.Cache,
-		store:      opts.Store,
-		channels:   make(map[uint32]chan *types.Approval),
-		approvedCh: make(map[uint32]chan *types.Approval),
-		index:      0,
-		mu:         &sync.Mutex{},
-		subMu:      &sync.RWMutex{},
+		store:            opts.Store,
+		sender:           opts.Sender,
+		reminderInterval: reminderInterval,
+		approveLinkURL:   opts.ApproveLinkURL,
+		signingSecret:    opts.SigningSecret,
+		channels:         make(map[uint32]chan *types.Approval),
+		approvedCh:       make(map[uint32]chan *types.Approval),
+		index:            0,
+		mu:               &sync.Mutex{},
+		subMu:            &sync.RWMutex{},
 	}
 
 	return man
@@ -237,8 +278,24 @@ func (m *DefaultManager) Update(r *types.Approval) error {
 	return m.store.UpdateApproval(r)
 }
 
-// Approve - increase VotesReceived by 1 and returns updated version
+// Approve - records vote and returns updated version, VotesReceived is the number
+// of distinct voters so repeated votes from the same voter are not counted. Votes of
+// voters that aren't allowed to vote are recorded but not counted
 func (m *DefaultManager) Approve(identifier, voter string) (*types.Approval, error) {
+	return m.approve(identifier, voter, false)
+}
+
+// ApproveOnce - records vote, fails with ErrAlreadyVoted if the voter already voted.
+// The check is done under the same lock as the vote so concurrent requests
+// can't both succeed
+func (m *DefaultManager) ApproveOnce(identifier, voter string) (*types.Approval, error) {
+	return m.approve(identifier, voter, true)
+}
+
+// anonymousVoterPrefix - prefix of voter IDs generated for votes without voter
+const anonymousVoterPrefix = "anonymous-"
+
+func (m *DefaultManager) approve(identifier, voter string, once bool) (*types.Approval, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -251,15 +308,35 @@ func (m *DefaultManager) Approve(identifier, voter string) (*types.Approval, err
 		return nil, err
 	}
 
-	for _, v := range existing.GetVoters() {
-		if v == voter {
-			// nothing to do, same voter
-			return existing, nil
+	if voter == "" {
+		if len(existing.AllowedVoters) > 0 {
+			return nil, ErrVoterRequired
+		}
+		// anonymous votes, ie: API calls without voter, are counted
+		// as votes of distinct voters
+		voter = anonymousVoterPrefix + uuid.New().String()
+	}
+
+	if existing.HasVoter(voter) {
+		log.WithFields(log.Fields{
+			"identifier": identifier,
+			"voter":      voter,
+		}).Info("approvals.manager: voter already voted, vote not counted")
+		if once {
+			return existing, ErrAlreadyVoted
 		}
+		return existing, nil
+	}
+
+	if !existing.CanVote(voter) {
+		log.WithFields(log.Fields{
+			"identifier": identifier,
+			"voter":      voter,
+		}).Info("approvals.manager: voter is not in approval voters, vote recorded but not counted")
 	}
 
 	existing.AddVoter(voter)
-	existing.VotesReceived++
+	existing.VotesReceived = existing.CountedVotes()
 
 	err = m.Update(existing)
 	if err != nil {
@@ -399,9 +476,46 @@ func (m *DefaultManager) Create(r *types.Approval) error {
 		return fmt.Errorf("failed to create approval: %s", err)
 	}
 
+	m.notifyRequested(r)
+
 	return m.publishRequest(created)
 }
 
+// notifyRequested - announces new approval with one-time approve link, each
+// allowed voter gets own link so the vote counts. Links expire together with the approval
+func (m *DefaultManager) notifyRequested(approval *types.Approval) {
+	if m.sender == nil || m.approveLinkURL == "" || len(m.signingSecret) == 0 {
+		return
+	}
+
+	var links string
+	if len(approval.AllowedVoters) == 0 {
+		links = "Approve: " + ApproveLink(m.approveLinkURL, m.signingSecret, approval.Identifier, "", approval.Deadline)
+	} else {
+		voterLinks := make([]string, len(approval.AllowedVoters))
+		for i, voter := range approval.AllowedVoters {
+			voterLinks[i] = voter + ": " + ApproveLink(m.approveLinkURL, m.signingSecret, approval.Identifier, voter, approval.Deadline)
+		}
+		links = "Approve as " + strings.Join(voterLinks, ", ")
+	}
+
+	err := m.sender.Send(types.EventNotification{
+		Name:         "approval requested",
+		Message:      fmt.Sprintf("%s, votes required: %d. %s", approval.Message, approval.VotesRequired, links),
+		CreatedAt:    approval.CreatedAt,
+		Type:         types.NotificationApprovalRequested,
+		Level:        types.LevelInfo,
+		ResourceKind: approval.Provider.String(),
+		Identifier:   approval.Identifier,
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":      err,
+			"identifier": approval.Identifier,
+		}).Error("approvals.manager: failed to send approval requested notification")
+	}
+}
+
 func getKey(identifier string) string {
 	return ApprovalsPrefix + "/" + identifier
 }
@@ -322,6 +322,44 @@ func TestApproveTwiceSameVoter(t *testing.T) {
 	}
 }
 
+func TestApproveOnce(t *testing.T) {
+	store, teardown := NewTestingUtils()
+	defer teardown()
+
+	am := New(&Opts{
+		Store: store,
+	})
+	err := am.Create(&types.Approval{
+		Provider:       types.ProviderTypeKubernetes,
+		Identifier:     "xxx/app-1:1.2.5",
+		CurrentVersion: "1.2.3",
+		NewVersion:     "1.2.5",
+		Deadline:       time.Now().Add(5 * time.Minute),
+		VotesRequired:  2,
+	})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+
+	_, err = am.ApproveOnce("xxx/app-1:1.2.5", "approve-link:abc")
+	if err != nil {
+		t.Fatalf("failed to approve: %s", err)
+	}
+
+	_, err = am.ApproveOnce("xxx/app-1:1.2.5", "approve-link:abc")
+	if err != ErrAlreadyVoted {
+		t.Errorf("expected already voted error, got: %v", err)
+	}
+
+	stored, err := am.Get("xxx/app-1:1.2.5")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if stored.VotesReceived != 1 {
+		t.Errorf("unexpected number of received votes: %d", stored.VotesReceived)
+	}
+}
+
 func TestApproveTwoVoters(t *testing.T) {
 	store, teardown := NewTestingUtils()
 	defer teardown()
@@ -358,6 +396,49 @@ func TestApproveTwoVoters(t *testing.T) {
 	}
 }
 
+func TestApproveDistinctVoters(t *testing.T) {
+	store, teardown := NewTestingUtils()
+	defer teardown()
+
+	am := New(&Opts{
+		Store: store,
+	})
+
+	err := am.Create(&types.Approval{
+		Provider:       types.ProviderTypeKubernetes,
+		Identifier:     "xxx/app-1:1.2.5",
+		CurrentVersion: "1.2.3",
+		NewVersion:     "1.2.5",
+		Deadline:       time.Now().Add(5 * time.Minute),
+		VotesRequired:  2,
+	})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+
+	am.Approve("xxx/app-1:1.2.5", "warda")
+	approval, err := am.Approve("xxx/app-1:1.2.5", "warda")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if approval.Status() != types.ApprovalStatusPending {
+		t.Errorf("expected same voter twice to leave approval pending, got: %s", approval.Status())
+	}
+
+	approval, err = am.Approve("xxx/app-1:1.2.5", "karolis")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if approval.Status() != types.ApprovalStatusApproved {
+		t.Errorf("expected two distinct voters to approve, got: %s", approval.Status())
+	}
+
+	voters := approval.GetVoters()
+	if len(voters) != 2 || voters[0] != "karolis" || voters[1] != "warda" {
+		t.Errorf("unexpected voters: %v", voters)
+	}
+}
+
 func TestReject(t *testing.T) {
 	store, teardown := NewTestingUtils()
 	defer teardown()
@@ -469,3 +550,106 @@ func TestGetArchived(t *testing.T) {
 		t.Errorf("didn't expect approval to be archived")
 	}
 }
+
+func TestApproveAllowedVoters(t *testing.T) {
+	store, teardown := NewTestingUtils()
+	defer teardown()
+
+	am := New(&Opts{
+		Store: store,
+	})
+	err := am.Create(&types.Approval{
+		Provider:       types.ProviderTypeKubernetes,
+		Identifier:     "xxx/app-1:1.2.5",
+		CurrentVersion: "1.2.3",
+		NewVersion:     "1.2.5",
+		Deadline:       time.Now().Add(5 * time.Minute),
+		VotesRequired:  2,
+		AllowedVoters:  types.StringList{"alice", "bob"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+
+	for _, voter := range []string{"mallory", "alice", "eve"} {
+		_, err = am.Approve("xxx/app-1:1.2.5", voter)
+		if err != nil {
+			t.Fatalf("failed to approve: %s", err)
+		}
+	}
+
+	stored, err := am.Get("xxx/app-1:1.2.5")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if stored.VotesReceived != 1 {
+		t.Errorf("expected only allowed voter to be counted, got: %d", stored.VotesReceived)
+	}
+	if len(stored.Voters) != 3 {
+		t.Errorf("expected all votes to be recorded, got: %d", len(stored.Voters))
+	}
+	if stored.Status() != types.ApprovalStatusPending {
+		t.Errorf("unexpected status: %s", stored.Status())
+	}
+
+	_, err = am.Approve("xxx/app-1:1.2.5", "bob")
+	if err != nil {
+		t.Fatalf("failed to approve: %s", err)
+	}
+	stored, err = am.Get("xxx/app-1:1.2.5")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if stored.Status() != types.ApprovalStatusApproved {
+		t.Errorf("expected approval to be approved, got: %s", stored.Status())
+	}
+}
+
+func TestApproveAnonymous(t *testing.T) {
+	store, teardown := NewTestingUtils()
+	defer teardown()
+
+	am := New(&Opts{
+		Store: store,
+	})
+
+	err := am.Create(&types.Approval{
+		Provider:       types.ProviderTypeKubernetes,
+		Identifier:     "xxx/app-1:1.2.5",
+		CurrentVersion: "1.2.3",
+		NewVersion:     "1.2.5",
+		Deadline:       time.Now().Add(5 * time.Minute),
+		VotesRequired:  2,
+	})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+
+	am.Approve("xxx/app-1:1.2.5", "")
+	approval, err := am.Approve("xxx/app-1:1.2.5", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if approval.Status() != types.ApprovalStatusApproved {
+		t.Errorf("expected anonymous votes to be counted, got: %s (%d votes)", approval.Status(), approval.VotesReceived)
+	}
+
+	// approvals limiting who can vote need voter identity
+	err = am.Create(&types.Approval{
+		Provider:       types.ProviderTypeKubernetes,
+		Identifier:     "xxx/app-2:1.2.5",
+		CurrentVersion: "1.2.3",
+		NewVersion:     "1.2.5",
+		Deadline:       time.Now().Add(5 * time.Minute),
+		VotesRequired:  1,
+		AllowedVoters:  []string{"warda"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+
+	_, err = am.Approve("xxx/app-2:1.2.5", "")
+	if err != ErrVoterRequired {
+		t.Errorf("expected voter to be required, got: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package approvals
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ApproveLinkPath - path of one-time approve links, parameters are passed
+// in the query as identifiers contain slashes
+const ApproveLinkPath = "/v1/approve-link"
+
+// approveLinkVoterPrefix - links without intended voter vote as a
+// separate voter each so visiting the same link again doesn't count
+const approveLinkVoterPrefix = "approve-link:"
+
+// Approve link errors
+var (
+	ErrInvalidApproveLink = errors.New("invalid approve link signature")
+	ErrApproveLinkExpired = errors.New("approve link expired")
+)
+
+// ApproveLink - creates signed approve link that records a single vote until
+// it expires, baseURL is the address bow is reachable at. Link votes as the given
+// voter, same identity as in bow/approval-voters, empty voter creates anonymous
+// link that only counts when approval voters aren't limited
+func ApproveLink(baseURL string, secret []byte, identifier, voter string, expires time.Time) string {
+	nonce := uuid.New().String()
+	exp := strconv.FormatInt(expires.Unix(), 10)
+
+	query := url.Values{}
+	query.Set("identifier", identifier)
+	if voter != "" {
+		query.Set("voter", voter)
+	}
+	query.Set("nonce", nonce)
+	query.Set("expires", exp)
+	query.Set("signature", signApproveLink(secret, identifier, voter, nonce, exp))
+
+	return strings.TrimSuffix(baseURL, "/") + ApproveLinkPath + "?" + query.Encode()
+}
+
+// VerifyApproveLink - checks approve link query signature and expiry,
+// returns approval identifier and the voter link votes as
+func VerifyApproveLink(secret []byte, query url.Values, now time.Time) (identifier, voter string, err error) {
+	identifier = query.Get("identifier")
+	voter = query.Get("voter")
+	nonce := query.Get("nonce")
+	exp := query.Get("expires")
+
+	expected := signApproveLink(secret, identifier, voter, nonce, exp)
+	if identifier == "" || nonce == "" || !hmac.Equal([]byte(query.Get("signature")), []byte(expected)) {
+		return "", "", ErrInvalidApproveLink
+	}
+
+	expires, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return "", "", ErrInvalidApproveLink
+	}
+	if now.After(time.Unix(expires, 0)) {
+		return "", "", ErrApproveLinkExpired
+	}
+
+	if voter == "" {
+		voter = approveLinkVoterPrefix + nonce
+	}
+	return identifier, voter, nil
+}
+
+func signApproveLink(secret []byte, identifier, voter, nonce, expires string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(identifier + "\n" + voter + "\n" + nonce + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,188 @@
+package approvals
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/types"
+)
+
+func TestVerifyApproveLink(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Now()
+
+	link := ApproveLink("https://bow.example.com", secret, "deployment/default/wd:2.0.0", "", now.Add(time.Hour))
+	if !strings.HasPrefix(link, "https://bow.example.com"+ApproveLinkPath+"?") {
+		t.Fatalf("unexpected link: %s", link)
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("failed to parse link: %s", err)
+	}
+
+	identifier, voter, err := VerifyApproveLink(secret, u.Query(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if identifier != "deployment/default/wd:2.0.0" {
+		t.Errorf("unexpected identifier: %s", identifier)
+	}
+	if !strings.HasPrefix(voter, approveLinkVoterPrefix) {
+		t.Errorf("unexpected voter: %s", voter)
+	}
+
+	other, _ := url.Parse(ApproveLink("https://bow.example.com", secret, "deployment/default/wd:2.0.0", "", now.Add(time.Hour)))
+	_, otherVoter, _ := VerifyApproveLink(secret, other.Query(), now)
+	if otherVoter == voter {
+		t.Errorf("expected each link to vote as a different voter")
+	}
+
+	_, _, err = VerifyApproveLink(secret, u.Query(), now.Add(2*time.Hour))
+	if err != ErrApproveLinkExpired {
+		t.Errorf("expected expired link error, got: %v", err)
+	}
+
+	_, _, err = VerifyApproveLink([]byte("other"), u.Query(), now)
+	if err != ErrInvalidApproveLink {
+		t.Errorf("expected invalid link error, got: %v", err)
+	}
+
+	tampered := u.Query()
+	tampered.Set("identifier", "deployment/default/wd:3.0.0")
+	_, _, err = VerifyApproveLink(secret, tampered, now)
+	if err != ErrInvalidApproveLink {
+		t.Errorf("expected invalid link error for tampered identifier, got: %v", err)
+	}
+
+	extended := u.Query()
+	extended.Set("expires", "9999999999")
+	_, _, err = VerifyApproveLink(secret, extended, now)
+	if err != ErrInvalidApproveLink {
+		t.Errorf("expected invalid link error for tampered expiry, got: %v", err)
+	}
+}
+
+func TestVerifyVoterApproveLink(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Now()
+
+	u, err := url.Parse(ApproveLink("https://bow.example.com", secret, "deployment/default/wd:2.0.0", "alice", now.Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("failed to parse link: %s", err)
+	}
+
+	_, voter, err := VerifyApproveLink(secret, u.Query(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if voter != "alice" {
+		t.Errorf("expected link to vote as alice, got: %s", voter)
+	}
+
+	tampered := u.Query()
+	tampered.Set("voter", "bob")
+	_, _, err = VerifyApproveLink(secret, tampered, now)
+	if err != ErrInvalidApproveLink {
+		t.Errorf("expected invalid link error for tampered voter, got: %v", err)
+	}
+
+	anonymous := u.Query()
+	anonymous.Del("voter")
+	_, _, err = VerifyApproveLink(secret, anonymous, now)
+	if err != ErrInvalidApproveLink {
+		t.Errorf("expected invalid link error for removed voter, got: %v", err)
+	}
+}
+
+func TestCreateSendsApproveLink(t *testing.T) {
+	store, teardown := NewTestingUtils()
+	defer teardown()
+
+	sender := &fakeSender{}
+	am := New(&Opts{
+		Store:          store,
+		Sender:         sender,
+		ApproveLinkURL: "https://bow.example.com",
+		SigningSecret:  []byte("secret"),
+	})
+
+	err := am.Create(&types.Approval{
+		Identifier:     "xxx/app-1:1.2.3",
+		Message:        "New image is available for resource xxx/app-1",
+		CurrentVersion: "1.2.0",
+		NewVersion:     "1.2.3",
+		VotesRequired:  1,
+		Deadline:       time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected approval requested notification, got: %d", len(sender.sent))
+	}
+	sent := sender.sent[0]
+	if sent.Type != types.NotificationApprovalRequested {
+		t.Errorf("unexpected notification type: %s", sent.Type)
+	}
+	if !strings.Contains(sent.Message, "Approve: https://bow.example.com"+ApproveLinkPath+"?") {
+		t.Errorf("expected approve link in message: %s", sent.Message)
+	}
+}
+
+func TestCreateSendsVoterApproveLinks(t *testing.T) {
+	store, teardown := NewTestingUtils()
+	defer teardown()
+
+	sender := &fakeSender{}
+	am := New(&Opts{
+		Store:          store,
+		Sender:         sender,
+		ApproveLinkURL: "https://bow.example.com",
+		SigningSecret:  []byte("secret"),
+	})
+
+	err := am.Create(&types.Approval{
+		Identifier:    "xxx/app-1:1.2.3",
+		VotesRequired: 1,
+		Deadline:      time.Now().Add(time.Hour),
+		AllowedVoters: types.StringList{"alice", "bob"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected approval requested notification, got: %d", len(sender.sent))
+	}
+	msg := sender.sent[0].Message
+	if !strings.Contains(msg, "Approve as alice: https://bow.example.com"+ApproveLinkPath+"?") || !strings.Contains(msg, ", bob: https://bow.example.com"+ApproveLinkPath+"?") {
+		t.Errorf("expected approve link for each voter in message: %s", msg)
+	}
+}
+
+func TestCreateWithoutApproveLinkURL(t *testing.T) {
+	store, teardown := NewTestingUtils()
+	defer teardown()
+
+	sender := &fakeSender{}
+	am := New(&Opts{
+		Store:         store,
+		Sender:        sender,
+		SigningSecret: []byte("secret"),
+	})
+
+	err := am.Create(&types.Approval{
+		Identifier:    "xxx/app-1:1.2.3",
+		VotesRequired: 1,
+		Deadline:      time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Errorf("expected no notifications, got: %d", len(sender.sent))
+	}
+}
@@ -0,0 +1,96 @@
+package approvals
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/timeutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultReminderInterval - how often pending approvals are checked
+// for approaching deadlines
+const DefaultReminderInterval = 10 * time.Minute
+
+// StartReminderService - starts service which reminds approvers about pending
+// approvals that have less than half of their approval deadline left
+func (m *DefaultManager) StartReminderService(ctx context.Context) error {
+	if m.sender == nil {
+		return nil
+	}
+
+	ticker := time.NewTicker(m.reminderInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			err := m.remindEntries()
+			if err != nil {
+				log.WithFields(log.Fields{
+					"error": err,
+				}).Error("approvals.StartReminderService: got error while checking approval deadlines")
+			}
+		}
+	}
+}
+
+func (m *DefaultManager) remindEntries() error {
+	approvals, err := m.store.ListApprovals(&types.GetApprovalQuery{
+		Archived: false,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := timeutil.Now()
+	for _, approval := range approvals {
+		if !approval.ReminderDue(now) {
+			continue
+		}
+
+		err = m.sender.Send(types.EventNotification{
+			Name:         "approval reminder",
+			Message:      reminderMessage(approval, now),
+			CreatedAt:    now,
+			Type:         types.NotificationApprovalReminder,
+			Level:        types.LevelWarn,
+			ResourceKind: approval.Provider.String(),
+			Identifier:   approval.Identifier,
+		})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":      err,
+				"identifier": approval.Identifier,
+			}).Error("approvals.remindEntries: failed to send reminder")
+			continue
+		}
+
+		approval.Reminded = true
+		err = m.store.UpdateApproval(approval)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":      err,
+				"identifier": approval.Identifier,
+			}).Error("approvals.remindEntries: failed to mark approval as reminded")
+		}
+	}
+
+	return nil
+}
+
+func reminderMessage(approval *types.Approval, now time.Time) string {
+	return fmt.Sprintf("Approval for %s update %s -> %s is still pending, votes: %d/%d, expires in %s",
+		approval.Identifier,
+		approval.CurrentVersion,
+		approval.NewVersion,
+		approval.VotesReceived,
+		approval.VotesRequired,
+		approval.Deadline.Sub(now).Round(time.Minute),
+	)
+}
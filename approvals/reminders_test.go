@@ -0,0 +1,108 @@
+package approvals
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/extension/notification"
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/timeutil"
+)
+
+type fakeSender struct {
+	sent []types.EventNotification
+}
+
+func (s *fakeSender) Configure(*notification.Config) (bool, error) {
+	return true, nil
+}
+
+func (s *fakeSender) Send(event types.EventNotification) error {
+	s.sent = append(s.sent, event)
+	return nil
+}
+
+func TestRemindEntries(t *testing.T) {
+	store, teardown := NewTestingUtils()
+	defer teardown()
+
+	sender := &fakeSender{}
+	am := New(&Opts{
+		Store:  store,
+		Sender: sender,
+	})
+
+	now := time.Now()
+	timeutil.Now = func() time.Time { return now }
+	defer func() { timeutil.Now = time.Now }()
+
+	for _, approval := range []*types.Approval{
+		{
+			Identifier:     "xxx/app-1:1.2.3",
+			CurrentVersion: "1.2.0",
+			NewVersion:     "1.2.3",
+			VotesRequired:  2,
+			Deadline:       now.Add(2 * time.Hour),
+		},
+		{
+			Identifier:     "xxx/app-2:1.2.3",
+			CurrentVersion: "1.2.0",
+			NewVersion:     "1.2.3",
+			VotesRequired:  1,
+			Deadline:       now.Add(10 * time.Hour),
+		},
+	} {
+		err := am.Create(approval)
+		if err != nil {
+			t.Fatalf("failed to create approval: %s", err)
+		}
+	}
+
+	err := am.remindEntries()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected no reminders yet, got: %d", len(sender.sent))
+	}
+
+	// first approval has less than half of its deadline left
+	now = now.Add(90 * time.Minute)
+
+	err = am.remindEntries()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected 1 reminder, got: %d", len(sender.sent))
+	}
+
+	reminder := sender.sent[0]
+	if reminder.Type != types.NotificationApprovalReminder {
+		t.Errorf("unexpected notification type: %s", reminder.Type)
+	}
+	if reminder.Identifier != "xxx/app-1:1.2.3" {
+		t.Errorf("unexpected identifier: %s", reminder.Identifier)
+	}
+	expected := "Approval for xxx/app-1:1.2.3 update 1.2.0 -> 1.2.3 is still pending, votes: 0/2, expires in 30m0s"
+	if reminder.Message != expected {
+		t.Errorf("unexpected message: %s", reminder.Message)
+	}
+
+	stored, err := am.Get("xxx/app-1:1.2.3")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if !stored.Reminded {
+		t.Errorf("expected approval to be marked as reminded")
+	}
+
+	// reminders are sent once
+	err = am.remindEntries()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Errorf("expected reminder to be sent once, got: %d", len(sender.sent))
+	}
+}
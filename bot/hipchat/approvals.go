@@ -2,14 +2,24 @@ package hipchat
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/alwinius/bow/types"
 )
 
+// formatVoters - comma separated voters
+func formatVoters(approval *types.Approval) string {
+	voters := approval.GetVoters()
+	if len(voters) == 0 {
+		return "-"
+	}
+	return strings.Join(voters, ", ")
+}
+
 func (b *Bot) RequestApproval(req *types.Approval) error {
 	msg := fmt.Sprintf(ApprovalRequiredTempl,
 		req.Message, req.Identifier, req.Identifier,
-		req.VotesReceived, req.VotesRequired, req.Delta(), req.Identifier,
+		req.VotesReceived, req.VotesRequired, formatVoters(req), req.Delta(), req.Identifier,
 		req.Provider.String())
 	return b.postMessage(formatAsSnippet(msg))
 }
@@ -18,16 +28,16 @@ func (b *Bot) ReplyToApproval(approval *types.Approval) error {
 	switch approval.Status() {
 	case types.ApprovalStatusPending:
 		msg := fmt.Sprintf(VoteReceivedTempl,
-			approval.VotesReceived, approval.VotesRequired, approval.Delta(), approval.Identifier)
+			approval.VotesReceived, approval.VotesRequired, formatVoters(approval), approval.Delta(), approval.Identifier)
 		b.postMessage(formatAsSnippet(msg))
 	case types.ApprovalStatusRejected:
 		msg := fmt.Sprintf(ChangeRejectedTempl,
 			approval.Status().String(), approval.VotesReceived, approval.VotesRequired,
-			approval.Delta(), approval.Identifier)
+			formatVoters(approval), approval.Delta(), approval.Identifier)
 		b.postMessage(formatAsSnippet(msg))
 	case types.ApprovalStatusApproved:
 		msg := fmt.Sprintf(UpdateApprovedTempl,
-			approval.VotesReceived, approval.VotesRequired, approval.Delta(), approval.Identifier)
+			approval.VotesReceived, approval.VotesRequired, formatVoters(approval), approval.Delta(), approval.Identifier)
 		b.postMessage(formatAsSnippet(msg))
 	}
 	return nil
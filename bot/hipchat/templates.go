@@ -5,6 +5,7 @@ var ApprovalRequiredTempl = `Approval required!
   To vote for change send 'approve %s' to me
   To reject it: 'reject %s'
     Votes: %d/%d
+    Voters: %s
     Delta: %s
     Identifier: %s
     Provider: %s`
@@ -12,6 +13,7 @@ var ApprovalRequiredTempl = `Approval required!
 var VoteReceivedTempl = `Vote received
   Waiting for remaining votes!
     Votes: %d/%d
+    Voters: %s
     Delta: %s
     Identifier: %s`
 
@@ -19,11 +21,13 @@ var ChangeRejectedTempl = `Change rejected
   Change was rejected.
     Status: %s
     Votes: %d/%d
+    Voters: %s
     Delta: %s
     Identifier: %s`
 
 var UpdateApprovedTempl = `Update approved!
   All approvals received, thanks for voting!
     Votes: %d/%d
+    Voters: %s
     Delta: %s
     Identifier: %s`
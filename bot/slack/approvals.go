@@ -2,13 +2,53 @@ package slack
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/alwinius/bow/types"
 	"github.com/nlopes/slack"
 )
 
+// formatVoters - voters mentioned in the message, voters are Slack user IDs
+func formatVoters(approval *types.Approval) string {
+	voters := approval.GetVoters()
+	if len(voters) == 0 {
+		return "-"
+	}
+	mentions := make([]string, 0, len(voters))
+	for _, v := range voters {
+		mentions = append(mentions, fmt.Sprintf("<@%s>", v))
+	}
+	return strings.Join(mentions, ", ")
+}
+
+// approvalActions - approve/reject buttons, handled by the Slack
+// interactions endpoint
+func approvalActions(identifier string) []slack.AttachmentAction {
+	return []slack.AttachmentAction{
+		{
+			Name:  "approve",
+			Text:  "Approve",
+			Type:  "button",
+			Style: "primary",
+			Value: identifier,
+		},
+		{
+			Name:  "reject",
+			Text:  "Reject",
+			Type:  "button",
+			Style: "danger",
+			Value: identifier,
+		},
+	}
+}
+
 // Request - request approval
 func (b *Bot) RequestApproval(req *types.Approval) error {
+	var actions []slack.AttachmentAction
+	if b.interactive {
+		actions = approvalActions(req.Identifier)
+	}
+
 	return b.postMessage(
 		"Approval required",
 		req.Message,
@@ -24,6 +64,11 @@ func (b *Bot) RequestApproval(req *types.Approval) error {
 				Value: fmt.Sprintf("%d/%d", req.VotesReceived, req.VotesRequired),
 				Short: true,
 			},
+			slack.AttachmentField{
+				Title: "Voters",
+				Value: formatVoters(req),
+				Short: true,
+			},
 			slack.AttachmentField{
 				Title: "Delta",
 				Value: req.Delta(),
@@ -39,7 +84,7 @@ func (b *Bot) RequestApproval(req *types.Approval) error {
 				Value: req.Provider.String(),
 				Short: true,
 			},
-		})
+		}, actions...)
 }
 
 func (b *Bot) ReplyToApproval(approval *types.Approval) error {
@@ -60,6 +105,11 @@ func (b *Bot) ReplyToApproval(approval *types.Approval) error {
 					Value: fmt.Sprintf("%d/%d", approval.VotesReceived, approval.VotesRequired),
 					Short: true,
 				},
+				slack.AttachmentField{
+					Title: "Voters",
+					Value: formatVoters(approval),
+					Short: true,
+				},
 				slack.AttachmentField{
 					Title: "Delta",
 					Value: approval.Delta(),
@@ -92,6 +142,11 @@ func (b *Bot) ReplyToApproval(approval *types.Approval) error {
 					Value: fmt.Sprintf("%d/%d", approval.VotesReceived, approval.VotesRequired),
 					Short: true,
 				},
+				slack.AttachmentField{
+					Title: "Voters",
+					Value: formatVoters(approval),
+					Short: true,
+				},
 				slack.AttachmentField{
 					Title: "Delta",
 					Value: approval.Delta(),
@@ -119,6 +174,11 @@ func (b *Bot) ReplyToApproval(approval *types.Approval) error {
 					Value: fmt.Sprintf("%d/%d", approval.VotesReceived, approval.VotesRequired),
 					Short: true,
 				},
+				slack.AttachmentField{
+					Title: "Voters",
+					Value: formatVoters(approval),
+					Short: true,
+				},
 				slack.AttachmentField{
 					Title: "Delta",
 					Value: approval.Delta(),
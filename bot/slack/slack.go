@@ -41,6 +41,10 @@ type Bot struct {
 
 	approvalsChannel string // slack approvals channel name
 
+	// interactive - approval requests get approve/reject buttons,
+	// enabled when Slack app signing secret is set
+	interactive bool
+
 	ctx                context.Context
 	botMessagesChannel chan *bot.BotMessage
 	approvalsRespCh    chan *bot.ApprovalResponse
@@ -66,6 +70,8 @@ func (b *Bot) Configure(approvalsRespCh chan *bot.ApprovalResponse, botMessagesC
 			b.approvalsChannel = strings.TrimPrefix(channel, "#")
 		}
 
+		b.interactive = os.Getenv(constants.EnvSlackSigningSecret) != ""
+
 		b.slackClient = client
 		b.slackHTTPClient = client
 		b.approvalsRespCh = approvalsRespCh
@@ -90,6 +96,8 @@ func (b *Bot) Start(ctx context.Context) error {
 	b.users = map[string]string{}
 
 	for _, user := range users {
+		// votes are recorded with usernames, same as in bow/approval-voters
+		b.users[user.ID] = user.Name
 		switch user.Name {
 		case b.name:
 			if user.IsBot {
@@ -144,7 +152,7 @@ func (b *Bot) startInternal() error {
 	}
 }
 
-func (b *Bot) postMessage(title, message, color string, fields []slack.AttachmentField) error {
+func (b *Bot) postMessage(title, message, color string, fields []slack.AttachmentField, actions ...slack.AttachmentAction) error {
 	params := slack.NewPostMessageParameters()
 	params.Username = b.name
 
@@ -158,6 +166,11 @@ func (b *Bot) postMessage(title, message, color string, fields []slack.Attachmen
 		},
 	}
 
+	if len(actions) > 0 {
+		attachements[0].CallbackID = constants.SlackApprovalCallbackID
+		attachements[0].Actions = actions
+	}
+
 	var mgsOpts []slack.MsgOption
 
 	mgsOpts = append(mgsOpts, slack.MsgOptionPostMessageParameters(params))
@@ -219,7 +232,7 @@ func (b *Bot) handleMessage(event *slack.MessageEvent) {
 
 	eventText = b.trimBot(eventText)
 
-	approval, ok := bot.IsApproval(event.User, eventText)
+	approval, ok := bot.IsApproval(b.userName(event.User), eventText)
 	// only accepting approvals from approvals channel
 	if ok && b.isApprovalsChannel(event) {
 		b.approvalsRespCh <- approval
@@ -241,6 +254,14 @@ func (b *Bot) handleMessage(event *slack.MessageEvent) {
 	}
 }
 
+// userName - username of the Slack user ID, ID is returned for unknown users
+func (b *Bot) userName(id string) string {
+	if name, ok := b.users[id]; ok && name != "" {
+		return name
+	}
+	return id
+}
+
 func (b *Bot) Respond(text string, channel string) {
 
 	// if message is short, replying directly via slack RTM
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"fmt"
 	"github.com/alwinius/bow/extension/credentialshelper"
+	"github.com/alwinius/bow/internal/argocd"
 	"github.com/alwinius/bow/internal/gitrepo"
 	"github.com/alwinius/bow/secrets"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"context"
@@ -20,13 +25,16 @@ import (
 
 	// "github.com/alwinius/bow/cache/memory"
 	"github.com/alwinius/bow/pkg/auth"
+	"github.com/alwinius/bow/pkg/config"
 	"github.com/alwinius/bow/pkg/http"
+	"github.com/alwinius/bow/pkg/rpc"
 	"github.com/alwinius/bow/pkg/store"
 	"github.com/alwinius/bow/pkg/store/sql"
 
 	"github.com/alwinius/bow/constants"
 	"github.com/alwinius/bow/extension/notification"
 	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/internal/policy"
 	"github.com/alwinius/bow/internal/workgroup"
 	"github.com/alwinius/bow/provider"
 	"github.com/alwinius/bow/provider/helm"
@@ -34,18 +42,31 @@ import (
 	"github.com/alwinius/bow/registry"
 	"github.com/alwinius/bow/trigger/poll"
 	"github.com/alwinius/bow/trigger/pubsub"
+	"github.com/alwinius/bow/trigger/sqs"
 	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/circuit"
+	"github.com/alwinius/bow/util/deadletter"
 	"github.com/alwinius/bow/version"
 
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	k8sclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
 	// notification extensions
 	"github.com/alwinius/bow/extension/notification/auditor"
 	_ "github.com/alwinius/bow/extension/notification/hipchat"
 	_ "github.com/alwinius/bow/extension/notification/mattermost"
 	_ "github.com/alwinius/bow/extension/notification/slack"
+	"github.com/alwinius/bow/extension/notification/stream"
+	_ "github.com/alwinius/bow/extension/notification/teams"
+	_ "github.com/alwinius/bow/extension/notification/telegram"
 	_ "github.com/alwinius/bow/extension/notification/webhook"
 
 	// credentials helpers
 	_ "github.com/alwinius/bow/extension/credentialshelper/aws"
+	_ "github.com/alwinius/bow/extension/credentialshelper/gcr"
 	secretsCredentialsHelper "github.com/alwinius/bow/extension/credentialshelper/secrets"
 
 	// bots
@@ -57,37 +78,227 @@ import (
 
 // gcloud pubsub related config
 const (
-	EnvTriggerPubSub     = "PUBSUB" // set to 1 or something to enable pub/sub trigger
-	EnvTriggerPoll       = "POLL"   // set to 0 to disable poll trigger
-	EnvProjectID         = "PROJECT_ID"
-	EnvClusterName       = "CLUSTER_NAME"
-	EnvDataDir           = "XDG_DATA_HOME"
-	EnvHelmProvider      = "HELM_PROVIDER"  // helm provider
-	EnvHelmTillerAddress = "TILLER_ADDRESS" // helm provider
-	EnvUIDir             = "UI_DIR"
-	EnvRepoURL           = "REPO_URL"
-	EnvRepoUser          = "REPO_USERNAME"   // optional
-	EnvRepoPassword      = "REPO_PASSWORD"   // optional
-	EnvRepoChartPath     = "REPO_CHART_PATH" // optional
-	EnvRepoBranch        = "REPO_BRANCH"     // optional
+	EnvTriggerPubSub          = "PUBSUB" // set to 1 or something to enable pub/sub trigger
+	EnvTriggerPoll            = "POLL"   // set to 0 to disable poll trigger
+	EnvProjectID              = "PROJECT_ID"
+	EnvClusterName            = "CLUSTER_NAME"
+	EnvDataDir                = "XDG_DATA_HOME"
+	EnvHelmProvider           = "HELM_PROVIDER"             // helm provider
+	EnvHelmTillerAddress      = "TILLER_ADDRESS"            // helm provider
+	EnvHelmChartRepository    = "HELM_CHART_REPOSITORY"     // helm provider, optional chart repository url
+	EnvHelmVersion            = "HELM_VERSION"              // helm provider, set to 3 to read releases from secrets instead of tiller
+	EnvHelmNamespaces         = "HELM_NAMESPACES"           // helm provider, optional comma separated namespaces to track
+	EnvHelmExcludedNamespaces = "HELM_EXCLUDED_NAMESPACES"  // helm provider, optional comma separated namespaces to ignore
+	EnvHelmMaxParallelUpdates = "HELM_MAX_PARALLEL_UPDATES" // helm provider, optional, how many releases are upgraded at once (default 1)
+	EnvUIDir                  = "UI_DIR"
+	EnvRepoURL                = "REPO_URL"
+	EnvRepoUser               = "REPO_USERNAME"   // optional
+	EnvRepoPassword           = "REPO_PASSWORD"   // optional
+	EnvRepoChartPath          = "REPO_CHART_PATH" // optional
+	EnvRepoBranch             = "REPO_BRANCH"     // optional
+
+	// provider circuit breaker, events for an image are suppressed for the
+	// backoff duration after threshold consecutive failures
+	EnvCircuitBreakerThreshold = "CIRCUIT_BREAKER_THRESHOLD" // optional, defaults to 5
+	EnvCircuitBreakerBackoff   = "CIRCUIT_BREAKER_BACKOFF"   // optional, defaults to 5m
+
+	// EnvDeadLetterQueueSize - optional, how many events that failed until their
+	// circuit opened are kept for /v1/dead-letter, defaults to 100
+	EnvDeadLetterQueueSize = "DEAD_LETTER_QUEUE_SIZE"
+
+	// EnvUpdateTimeAnnotation - optional, spec template annotation holding the
+	// update time, defaults to bow/update-time
+	EnvUpdateTimeAnnotation = "BOW_UPDATE_TIME_ANNOTATION"
+	// EnvUpdateTimeAnnotationMode - optional, when the update time annotation is
+	// set: always (default), same-image (only when the image string doesn't change), never
+	EnvUpdateTimeAnnotationMode = "BOW_UPDATE_TIME_ANNOTATION_MODE"
+	// EnvImageHashRollout - optional, set to true to trigger rollouts with short
+	// image digest in bow/image-hash instead of update time
+	EnvImageHashRollout = "BOW_IMAGE_HASH_ROLLOUT"
+
+	// EnvStartupSummaryRepositories - optional, how many tracked repositories are listed
+	// in the notification sent on startup, defaults to 10, 0 only sends image counts
+	EnvStartupSummaryRepositories = "STARTUP_SUMMARY_REPOSITORIES"
+
+	// EnvArgoCDServer - optional, ArgoCD API server url used to sync applications
+	// of updated resources (bow/argocd-app annotation)
+	EnvArgoCDServer = "ARGOCD_SERVER"
+	// EnvArgoCDToken - ArgoCD API token of an account allowed to sync applications
+	EnvArgoCDToken = "ARGOCD_TOKEN"
+
+	// EnvRequiredClusterLabel - optional, key=value label that must be set on the
+	// kube-system namespace, bow refuses to process updates without it
+	EnvRequiredClusterLabel = "REQUIRED_CLUSTER_LABEL"
+
+	// EnvDisruptionBudgetBackoff - optional, how long updates are deferred while
+	// pod disruption budgets don't allow disruptions, defaults to 60s
+	EnvDisruptionBudgetBackoff = "DISRUPTION_BUDGET_BACKOFF"
+
+	// EnvShutdownTimeout - optional, how long providers wait for updates that are
+	// in progress when bow is stopped, defaults to 20s so shutdown fits into
+	// the default pod termination grace period
+	EnvShutdownTimeout = "BOW_SHUTDOWN_TIMEOUT"
+
+	// EnvEventDedupWindow - optional, events for the same repository and tag received
+	// within the window are processed once, defaults to 5s, 0 disables deduplication
+	EnvEventDedupWindow = "BOW_EVENT_DEDUP_WINDOW"
+
+	// EnvLabelSelector - optional, only resources matching the selector are
+	// tracked, ie: "team=payments,tier!=batch"
+	EnvLabelSelector = "BOW_LABEL_SELECTOR"
+
+	// EnvECRSQSQueueURL - optional, enables trigger receiving ECR push
+	// events from the SQS queue
+	EnvECRSQSQueueURL = "BOW_ECR_SQS_QUEUE_URL"
+
+	// EnvUseWorkloadIdentity - optional, set to true to authenticate to Artifact
+	// Registry and GCR with the GKE Workload Identity service account
+	EnvUseWorkloadIdentity = "BOW_USE_WORKLOAD_IDENTITY"
+
+	// EnvFluxCompat - optional, set to true to track resources that only have Flux
+	// image policy annotation (app.kubernetes.io/image-policy)
+	EnvFluxCompat = "BOW_FLUX_COMPAT"
 
 	// EnvDefaultDockerRegistryCfg - default registry configuration that can be passed into
 	// bow for polling trigger
 	EnvDefaultDockerRegistryCfg = "DOCKER_REGISTRY_CFG"
+
+	// EnvGRPCPort - optional, starts gRPC server on this port next to the HTTP server
+	EnvGRPCPort = "BOW_GRPC_PORT"
+	// EnvGRPCTLSCert, EnvGRPCTLSKey - optional, paths to gRPC server TLS certificate and key
+	EnvGRPCTLSCert = "BOW_GRPC_TLS_CERT"
+	EnvGRPCTLSKey  = "BOW_GRPC_TLS_KEY"
 )
 
 // EnvDebug - set to 1 or anything else to enable debug logging
 const EnvDebug = "DEBUG"
+
+// configurableEnv - environment variables that can also be set through
+// the config file, config keys are their snake_case names (slack_token)
+var configurableEnv = []string{
+	EnvTriggerPubSub,
+	EnvTriggerPoll,
+	EnvProjectID,
+	EnvClusterName,
+	EnvDataDir,
+	EnvHelmProvider,
+	EnvHelmTillerAddress,
+	EnvHelmChartRepository,
+	EnvHelmVersion,
+	EnvHelmNamespaces,
+	EnvHelmExcludedNamespaces,
+	EnvHelmMaxParallelUpdates,
+	EnvUIDir,
+	EnvRepoURL,
+	EnvRepoUser,
+	EnvRepoPassword,
+	EnvRepoChartPath,
+	EnvRepoBranch,
+	EnvCircuitBreakerThreshold,
+	EnvCircuitBreakerBackoff,
+	EnvDeadLetterQueueSize,
+	EnvDisruptionBudgetBackoff,
+	EnvShutdownTimeout,
+	EnvEventDedupWindow,
+	EnvRequiredClusterLabel,
+	EnvArgoCDServer,
+	EnvArgoCDToken,
+	EnvStartupSummaryRepositories,
+	EnvUpdateTimeAnnotation,
+	EnvUpdateTimeAnnotationMode,
+	EnvImageHashRollout,
+	EnvLabelSelector,
+	EnvFluxCompat,
+	EnvUseWorkloadIdentity,
+	EnvDefaultDockerRegistryCfg,
+	EnvGRPCPort,
+	EnvGRPCTLSCert,
+	EnvGRPCTLSKey,
+	EnvDebug,
+	registry.EnvInsecure,
+	constants.WebhookEndpointEnv,
+	constants.EnvSlackToken,
+	constants.EnvSlackBotName,
+	constants.EnvSlackChannels,
+	constants.EnvSlackApprovalsChannel,
+	constants.EnvSlackSigningSecret,
+	constants.EnvHipchatToken,
+	constants.EnvHipchatBotName,
+	constants.EnvHipchatChannels,
+	constants.EnvHipchatApprovalsChannel,
+	constants.EnvHipchatApprovalsUserName,
+	constants.EnvHipchatApprovalsBotName,
+	constants.EnvHipchatApprovalsPasswort,
+	constants.EnvHipchatConnectionAttempts,
+	"HIPCHAT_SERVER",
+	constants.EnvMattermostEndpoint,
+	constants.EnvMattermostName,
+	constants.EnvTeamsWebhookURL,
+	constants.EnvTeamsChannels,
+	constants.EnvTelegramBotToken,
+	constants.EnvTelegramChatID,
+	constants.EnvNotificationLevel,
+	constants.EnvNotificationFormatter,
+	constants.EnvNotificationCooldown,
+	constants.EnvNotificationLevelKubernetes,
+	constants.EnvNotificationLevelHelm,
+	constants.EnvBasicAuthUser,
+	constants.EnvBasicAuthPassword,
+	constants.EnvAuthenticatedWebhooks,
+	constants.EnvTokenSecret,
+	constants.EnvApprovalsSigningSecret,
+	constants.EnvApprovalsLinkURL,
+	constants.EnvApprovalsReminderInterval,
+	constants.EnvQuayWebhookSecret,
+	constants.EnvGitlabWebhookSecret,
+	constants.EnvStreamBufferSize,
+	"AWS_ACCESS_KEY_ID",
+	"AWS_SECRET_ACCESS_KEY",
+	"AWS_REGION",
+	EnvECRSQSQueueURL,
+}
+
 const repoPath = "/home/alwin/projects/bow-tmp/"
 
 func main() {
 	ver := version.GetbowVersion()
 
-	uiDir := kingpin.Flag("ui-dir", "path to web UI static files").Default("www").Envar(EnvUIDir).String()
+	uiDir := kingpin.Flag("ui-dir", "path to web UI static files").String()
+	configFile := kingpin.Flag("config", "path to YAML config file, environment variables take precedence over its values").Envar(config.EnvConfig).String()
+
+	kingpin.Command("run", "Start bow").Default()
+	versionCmd := kingpin.Command("version", "Print version information")
+	versionOutput := versionCmd.Flag("output", "output format: text, json or yaml").Short('o').Default(versionOutputText).Enum(versionOutputText, versionOutputJSON, versionOutputYAML)
 
 	kingpin.UsageTemplate(kingpin.CompactUsageTemplate).Version(ver.Version)
 	kingpin.CommandLine.Help = "Automated Kubernetes deployment updates. Learn more on https://bow.sh."
-	kingpin.Parse()
+	if kingpin.Parse() == versionCmd.FullCommand() {
+		err := printVersion(os.Stdout, *versionOutput)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Fatal("main: failed to print version")
+		}
+		return
+	}
+
+	if *configFile != "" {
+		err := config.Load(*configFile, configurableEnv)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+				"path":  *configFile,
+			}).Fatal("main: failed to load config file")
+		}
+	}
+
+	// resolved after loading the config file so that it can provide the default
+	if *uiDir == "" {
+		*uiDir = os.Getenv(EnvUIDir)
+	}
+	if *uiDir == "" {
+		*uiDir = "www"
+	}
 
 	log.WithFields(log.Fields{
 		"os":         ver.OS,
@@ -142,9 +353,29 @@ func main() {
 		}
 	}
 
+	notificationFormatter, err := notification.ParseFormatter(os.Getenv(constants.EnvNotificationFormatter))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Errorf("main: got error while parsing notification formatter, defaulting to: %s", notificationFormatter)
+	}
+
+	var notificationCooldown time.Duration
+	if os.Getenv(constants.EnvNotificationCooldown) != "" {
+		notificationCooldown, err = time.ParseDuration(os.Getenv(constants.EnvNotificationCooldown))
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Error("main: got error while parsing notification cooldown, repeated notifications won't be suppressed")
+			notificationCooldown = 0
+		}
+	}
+
 	notifCfg := &notification.Config{
-		Attempts: 10,
-		Level:    notificationLevel,
+		Attempts:  10,
+		Level:     notificationLevel,
+		Formatter: notificationFormatter,
+		Cooldown:  notificationCooldown,
 	}
 	sender := notification.New(ctx)
 
@@ -157,8 +388,16 @@ func main() {
 
 	var g workgroup.Group
 
+	selector, err := labelSelector(os.Getenv(EnvLabelSelector))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Fatalf("main: invalid %s", EnvLabelSelector)
+	}
+
 	t := &k8s.Translator{
 		FieldLogger: log.WithField("context", "translator"),
+		Selector:    selector,
 	}
 
 	buf := k8s.NewBuffer(&g, t, log.StandardLogger(), 128)
@@ -180,18 +419,34 @@ func main() {
 	// approvalsCache := memory.NewMemoryCache()
 	approvalsManager := approvals.New(&approvals.Opts{
 		// Cache: approvalsCache,
-		Store: sqlStore,
+		Store:            sqlStore,
+		Sender:           sender,
+		ReminderInterval: approvalsReminderInterval(),
+		ApproveLinkURL:   os.Getenv(constants.EnvApprovalsLinkURL),
+		SigningSecret:    []byte(os.Getenv(constants.EnvApprovalsSigningSecret)),
 	})
 
 	go approvalsManager.StartExpiryService(ctx)
+	go approvalsManager.StartReminderService(ctx)
+
+	configureCircuitBreaker()
+	configureDisruptionBudgetBackoff()
+	shutdownTimeout := configureShutdownTimeout()
+	configureEventDedupWindow()
+	configureUpdateTimeAnnotation()
+	policy.FluxCompat = os.Getenv(EnvFluxCompat) == "true"
+	checkRequiredClusterLabel()
 
 	// setting up providers
+	deadLetters := deadLetterQueue()
+
 	providers := setupProviders(&ProviderOpts{
 		sender:           sender,
 		approvalsManager: approvalsManager,
 		grc:              &t.GenericResourceCache,
 		store:            sqlStore,
 		repo:             repo,
+		deadLetters:      deadLetters,
 	})
 
 	// registering secrets based credentials helper
@@ -218,20 +473,25 @@ func main() {
 		grc:              &t.GenericResourceCache,
 		store:            sqlStore,
 		uiDir:            *uiDir,
+		deadLetters:      deadLetters,
 	})
 
 	bot.Run(approvalsManager) // the bot handles communication via Slack
 
+	if sp, ok := providers.(startupNotifier); ok {
+		go sp.NotifyStartup(sender, startupSummaryRepositories())
+	}
+
 	signalChan := make(chan os.Signal, 1)
 	cleanupDone := make(chan bool)
-	signal.Notify(signalChan, os.Interrupt)
+	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
 	g.Add(func(stop <-chan struct{}) {
 		go func() {
 			for range signalChan {
 				log.Info("received an interrupt, shutting down...")
 				go func() {
 					select {
-					case <-time.After(10 * time.Second):
+					case <-time.After(shutdownTimeout + 5*time.Second):
 						log.Info("connection shutdown took too long, exiting... ")
 						close(cleanupDone)
 						return
@@ -257,6 +517,251 @@ type ProviderOpts struct {
 	grc              *k8s.GenericResourceCache
 	store            store.Store
 	repo             gitrepo.Repo
+	deadLetters      *deadletter.Queue
+}
+
+// configureCircuitBreaker - overrides provider circuit breaker defaults
+func configureCircuitBreaker() {
+	if threshold := os.Getenv(EnvCircuitBreakerThreshold); threshold != "" {
+		n, err := strconv.Atoi(threshold)
+		if err != nil || n < 1 {
+			log.WithFields(log.Fields{
+				"threshold": threshold,
+			}).Fatal("main: invalid circuit breaker threshold, expected positive number")
+		}
+		circuit.DefaultOpts.Threshold = n
+	}
+
+	if backoff := os.Getenv(EnvCircuitBreakerBackoff); backoff != "" {
+		d, err := time.ParseDuration(backoff)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":   err,
+				"backoff": backoff,
+			}).Fatal("main: invalid circuit breaker backoff")
+		}
+		circuit.DefaultOpts.Backoff = d
+	}
+}
+
+// configureShutdownTimeout - overrides how long providers wait for in-flight
+// updates on shutdown
+func configureShutdownTimeout() time.Duration {
+	timeout := os.Getenv(EnvShutdownTimeout)
+	if timeout == "" {
+		return kubernetes.StopTimeout
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil || d <= 0 {
+		log.WithFields(log.Fields{
+			"error":   err,
+			"timeout": timeout,
+		}).Fatal("main: invalid shutdown timeout, expected positive duration")
+	}
+	kubernetes.StopTimeout = d
+	helm.StopTimeout = d
+	return d
+}
+
+// configureEventDedupWindow - overrides how long duplicate events are skipped
+func configureEventDedupWindow() {
+	window := os.Getenv(EnvEventDedupWindow)
+	if window == "" {
+		return
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil || d < 0 {
+		log.WithFields(log.Fields{
+			"error":  err,
+			"window": window,
+		}).Fatal("main: invalid event deduplication window, expected duration")
+	}
+	kubernetes.EventDedupWindow = d
+	helm.EventDedupWindow = d
+}
+
+// configureDisruptionBudgetBackoff - overrides how long updates blocked by pod
+// disruption budgets are deferred
+func configureDisruptionBudgetBackoff() {
+	backoff := os.Getenv(EnvDisruptionBudgetBackoff)
+	if backoff == "" {
+		return
+	}
+	d, err := time.ParseDuration(backoff)
+	if err != nil || d <= 0 {
+		log.WithFields(log.Fields{
+			"error":   err,
+			"backoff": backoff,
+		}).Fatal("main: invalid disruption budget backoff, expected positive duration")
+	}
+	kubernetes.DisruptionBudgetBackoff = d
+}
+
+// configureUpdateTimeAnnotation - overrides update time annotation key, when the
+// annotation is set and whether image hash replaces it
+func configureUpdateTimeAnnotation() {
+	if key := os.Getenv(EnvUpdateTimeAnnotation); key != "" {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			log.WithFields(log.Fields{
+				"annotation": key,
+				"errors":     strings.Join(errs, ", "),
+			}).Fatal("main: invalid update time annotation key")
+		}
+		kubernetes.UpdateTimeAnnotation = key
+	}
+
+	mode, err := kubernetes.ParseUpdateTimeMode(os.Getenv(EnvUpdateTimeAnnotationMode))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Fatal("main: invalid update time annotation mode")
+	}
+	kubernetes.UpdateTimeAnnotationMode = mode
+	kubernetes.ImageHashRollout = os.Getenv(EnvImageHashRollout) == "true"
+}
+
+// requiredClusterLabelNamespace - namespace that carries the required cluster label,
+// it exists in every cluster
+const requiredClusterLabelNamespace = "kube-system"
+
+// checkRequiredClusterLabel - refuses to start when the cluster wasn't opted into
+// automated updates with the required label on the kube-system namespace
+func checkRequiredClusterLabel() {
+	required := os.Getenv(EnvRequiredClusterLabel)
+	if required == "" {
+		return
+	}
+
+	parts := strings.SplitN(required, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		log.WithFields(log.Fields{
+			"label": required,
+		}).Fatal("main: invalid required cluster label, expected key=value")
+	}
+	key, value := parts[0], parts[1]
+
+	clientSet := inClusterClient()
+	if clientSet == nil {
+		log.WithFields(log.Fields{
+			"label": required,
+		}).Fatal("main: required cluster label can only be checked through kubernetes API, refusing to process updates")
+	}
+
+	ns, err := clientSet.CoreV1().Namespaces().Get(requiredClusterLabelNamespace, meta_v1.GetOptions{})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":     err,
+			"namespace": requiredClusterLabelNamespace,
+		}).Fatal("main: failed to get namespace to check required cluster label, refusing to process updates")
+	}
+
+	if current, ok := ns.Labels[key]; !ok || current != value {
+		log.WithFields(log.Fields{
+			"label":     required,
+			"namespace": requiredClusterLabelNamespace,
+		}).Fatal("main: cluster is not opted into automated updates, required label is missing on namespace, refusing to process updates")
+	}
+
+	log.WithFields(log.Fields{
+		"label":     required,
+		"namespace": requiredClusterLabelNamespace,
+	}).Info("main: required cluster label found, cluster is opted into automated updates")
+}
+
+// startupNotifier - providers sending a summary of tracked images once they are ready
+type startupNotifier interface {
+	NotifyStartup(sender notification.Sender, maxRepositories int)
+}
+
+// startupSummaryRepositories - how many tracked repositories are listed in the
+// startup notification
+func startupSummaryRepositories() int {
+	n := os.Getenv(EnvStartupSummaryRepositories)
+	if n == "" {
+		return provider.DefaultStartupSummaryRepositories
+	}
+	max, err := strconv.Atoi(n)
+	if err != nil || max < 0 {
+		log.WithFields(log.Fields{
+			"repositories": n,
+		}).Fatal("main: invalid startup summary repositories, expected non-negative number")
+	}
+	return max
+}
+
+// helmMaxParallelUpdates - how many helm releases are upgraded at the same time
+func helmMaxParallelUpdates() int {
+	parallel := os.Getenv(EnvHelmMaxParallelUpdates)
+	if parallel == "" {
+		return 1
+	}
+	n, err := strconv.Atoi(parallel)
+	if err != nil || n < 1 {
+		log.WithFields(log.Fields{
+			"max_parallel_updates": parallel,
+		}).Fatal("main: invalid helm max parallel updates, expected positive number")
+	}
+	return n
+}
+
+// providerSender - drops provider notifications below the level from env,
+// sender is used as is when the level isn't set
+func providerSender(sender notification.Sender, env string) notification.Sender {
+	if os.Getenv(env) == "" {
+		return sender
+	}
+	level, err := types.ParseLevel(os.Getenv(env))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+			"env":   env,
+		}).Error("main: got error while parsing provider notification level, using global level")
+		return sender
+	}
+	return notification.NewLevelFilter(sender, level)
+}
+
+// deadLetterQueue - queue for events that failed until their circuit opened
+func deadLetterQueue() *deadletter.Queue {
+	size := os.Getenv(EnvDeadLetterQueueSize)
+	if size == "" {
+		return deadletter.New(deadletter.DefaultSize)
+	}
+	n, err := strconv.Atoi(size)
+	if err != nil || n < 1 {
+		log.WithFields(log.Fields{
+			"size": size,
+		}).Fatal("main: invalid dead letter queue size, expected positive number")
+	}
+	return deadletter.New(n)
+}
+
+// approvalsReminderInterval - optional interval for approval deadline reminders
+func approvalsReminderInterval() time.Duration {
+	interval := os.Getenv(constants.EnvApprovalsReminderInterval)
+	if interval == "" {
+		return approvals.DefaultReminderInterval
+	}
+
+	d, err := time.ParseDuration(interval)
+	if err != nil || d <= 0 {
+		log.WithFields(log.Fields{
+			"interval": interval,
+		}).Fatal("main: invalid approvals reminder interval, expected positive duration")
+	}
+	return d
+}
+
+// labelSelector - parses optional label selector, empty selector matches everything
+func labelSelector(s string) (labels.Selector, error) {
+	if s == "" {
+		return labels.Everything(), nil
+	}
+	selector, err := labels.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse label selector '%s': %s", s, err)
+	}
+	return selector, nil
 }
 
 // setupProviders - setting up available providers. New providers should be initialised here and added to
@@ -264,12 +769,25 @@ type ProviderOpts struct {
 func setupProviders(opts *ProviderOpts) (providers provider.Providers) {
 	var enabledProviders []provider.Provider
 
-	k8sProvider, err := kubernetes.NewProvider(opts.sender, opts.approvalsManager, opts.grc, opts.repo)
+	k8sProvider, err := kubernetes.NewProvider(&kubernetes.Opts{
+		Sender:              providerSender(opts.sender, constants.EnvNotificationLevelKubernetes),
+		ApprovalManager:     opts.approvalsManager,
+		Cache:               opts.grc,
+		Repo:                &opts.repo,
+		Recorder:            eventRecorder(),
+		RegistryClient:      registry.New(),
+		Deployments:         deploymentGetter(),
+		DisruptionBudgets:   disruptionBudgetLister(),
+		SecretResolver:      imagePullSecretResolver(),
+		ArgoCD:              argoCDSyncer(),
+		UseWorkloadIdentity: os.Getenv(EnvUseWorkloadIdentity) == "true",
+	})
 	if err != nil {
 		log.WithFields(log.Fields{
 			"error": err,
 		}).Fatal("main.setupProviders: failed to create kubernetes provider")
 	}
+	k8sProvider.SetDeadLetterQueue(opts.deadLetters)
 	go func() {
 		err := k8sProvider.Start()
 		if err != nil {
@@ -282,9 +800,10 @@ func setupProviders(opts *ProviderOpts) (providers provider.Providers) {
 	enabledProviders = append(enabledProviders, k8sProvider)
 
 	if os.Getenv(EnvHelmProvider) == "1" {
-		tillerAddr := os.Getenv(EnvHelmTillerAddress)
-		helmImplementer := helm.NewHelmImplementer(tillerAddr)
-		helmProvider := helm.NewProvider(helmImplementer, opts.sender, opts.approvalsManager)
+		helmProvider := helm.NewProvider(helmImplementer(), providerSender(opts.sender, constants.EnvNotificationLevelHelm), opts.approvalsManager, helmSecretResolver(), registry.New(), helmChartRepository(), helm.NewNamespaceFilter(os.Getenv(EnvHelmNamespaces), os.Getenv(EnvHelmExcludedNamespaces)))
+		helmProvider.MaxParallelUpdates = helmMaxParallelUpdates()
+		helmProvider.OCICharts = helm.NewOCIChartRepository(registry.New())
+		helmProvider.SetDeadLetterQueue(opts.deadLetters)
 
 		go func() {
 			err := helmProvider.Start()
@@ -303,12 +822,140 @@ func setupProviders(opts *ProviderOpts) (providers provider.Providers) {
 	return providers
 }
 
+// inClusterClient - kubernetes client for the cluster bow is running in,
+// returns nil when bow is running outside of the cluster
+func inClusterClient() *k8sclient.Clientset {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Warn("main.inClusterClient: not running in cluster")
+		return nil
+	}
+
+	clientSet, err := k8sclient.NewForConfig(config)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Error("main.inClusterClient: failed to create kubernetes client")
+		return nil
+	}
+
+	return clientSet
+}
+
+// eventRecorder - records kubernetes events on updated resources, nil
+// when kubernetes API is not available
+func eventRecorder() k8s.EventRecorder {
+	clientSet := inClusterClient()
+	if clientSet == nil {
+		log.Warn("main.eventRecorder: kubernetes events won't be recorded")
+		return nil
+	}
+
+	return k8s.NewEventRecorder(clientSet.CoreV1(), "bow")
+}
+
+// deploymentGetter - gets deployment status for rollbacks of failed updates,
+// nil when kubernetes API is not available
+func deploymentGetter() k8s.DeploymentGetter {
+	clientSet := inClusterClient()
+	if clientSet == nil {
+		log.Warn("main.deploymentGetter: failed updates won't be rolled back")
+		return nil
+	}
+
+	return k8s.NewDeploymentGetter(clientSet.AppsV1())
+}
+
+// disruptionBudgetLister - lists pod disruption budgets through the in-cluster
+// API, nil when kubernetes API is not available
+func disruptionBudgetLister() k8s.DisruptionBudgetLister {
+	clientSet := inClusterClient()
+	if clientSet == nil {
+		log.Warn("main.disruptionBudgetLister: pod disruption budgets won't be checked before updates")
+		return nil
+	}
+
+	return k8s.NewDisruptionBudgetLister(clientSet.PolicyV1beta1())
+}
+
+// imagePullSecretResolver - resolves image pull secrets of tracked resources
+// through the in-cluster API, nil when kubernetes API is not available
+func imagePullSecretResolver() kubernetes.SecretResolver {
+	clientSet := inClusterClient()
+	if clientSet == nil {
+		log.Warn("main.imagePullSecretResolver: image pull secrets of tracked resources won't be resolved")
+		return nil
+	}
+
+	// resolves the same dockerconfigjson secrets as helm image pull secrets
+	return helm.NewKubernetesSecretResolver(clientSet.CoreV1())
+}
+
+// argoCDSyncer - syncs ArgoCD applications of updated resources, nil when
+// ArgoCD server isn't configured
+func argoCDSyncer() kubernetes.ArgoCDSyncer {
+	server := os.Getenv(EnvArgoCDServer)
+	if server == "" {
+		return nil
+	}
+	if os.Getenv(EnvArgoCDToken) == "" {
+		log.Fatalf("main.argoCDSyncer: %s is required to sync ArgoCD applications", EnvArgoCDToken)
+	}
+	log.WithFields(log.Fields{
+		"server": server,
+	}).Info("main.argoCDSyncer: ArgoCD applications of updated resources are synced")
+	return argocd.NewClient(server, os.Getenv(EnvArgoCDToken))
+}
+
+// helmImplementer - tiller client or, with HELM_VERSION=3, Helm 3 releases
+// read from secrets through the in-cluster API
+func helmImplementer() helm.Implementer {
+	if os.Getenv(EnvHelmVersion) != "3" {
+		return helm.NewHelmImplementer(os.Getenv(EnvHelmTillerAddress))
+	}
+
+	clientSet := inClusterClient()
+	if clientSet == nil {
+		log.Fatal("main.helmImplementer: helm 3 releases can only be read through kubernetes API")
+	}
+	log.Warn("main.helmImplementer: helm 3 releases are tracked, upgrading them is not supported yet")
+	return helm.NewHelm3Implementer(clientSet.CoreV1(), "")
+}
+
+// helmChartRepository - chart repository used by releases that update chart
+// versions, nil when it isn't configured
+func helmChartRepository() helm.ChartRepository {
+	url := os.Getenv(EnvHelmChartRepository)
+	if url == "" {
+		return nil
+	}
+	log.WithFields(log.Fields{
+		"url": url,
+	}).Info("main.helmChartRepository: chart versions are fetched from the repository")
+	return helm.NewHTTPChartRepository(url)
+}
+
+// helmSecretResolver - resolves image pull secrets through the in-cluster API,
+// nil when kubernetes API is not available
+func helmSecretResolver() helm.SecretResolver {
+	clientSet := inClusterClient()
+	if clientSet == nil {
+		log.Warn("main.helmSecretResolver: image pull secrets won't be resolved")
+		return nil
+	}
+
+	return helm.NewKubernetesSecretResolver(clientSet.CoreV1())
+}
+
 type TriggerOpts struct {
 	providers        provider.Providers
 	approvalsManager approvals.Manager
 	grc              *k8s.GenericResourceCache
 	store            store.Store
 	uiDir            string
+	deadLetters      *deadletter.Queue
 }
 
 // setupTriggers - setting up triggers. New triggers should be added to this function. Each trigger
@@ -332,6 +979,14 @@ func setupTriggers(ctx context.Context, opts *TriggerOpts) (teardown func()) {
 		Authenticator:         authenticator,
 		UIDir:                 opts.uiDir,
 		AuthenticatedWebhooks: os.Getenv(constants.EnvAuthenticatedWebhooks) == "true",
+
+		ApprovalsSigningSecret: []byte(os.Getenv(constants.EnvApprovalsSigningSecret)),
+		SlackSigningSecret:     os.Getenv(constants.EnvSlackSigningSecret),
+		QuayWebhookSecret:      os.Getenv(constants.EnvQuayWebhookSecret),
+		GitlabWebhookSecret:    os.Getenv(constants.EnvGitlabWebhookSecret),
+
+		Stream:      stream.Default,
+		DeadLetters: opts.deadLetters,
 	})
 
 	go func() {
@@ -344,6 +999,38 @@ func setupTriggers(ctx context.Context, opts *TriggerOpts) (teardown func()) {
 		}
 	}()
 
+	// optional gRPC server, same operations as the HTTP API
+	var grpcServer *rpc.Server
+	if port := os.Getenv(EnvGRPCPort); port != "" {
+		grpcPort, err := strconv.Atoi(port)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+				"port":  port,
+			}).Fatal("main.setupTriggers: invalid gRPC port")
+			return
+		}
+
+		grpcServer = rpc.New(&rpc.Opts{
+			Port:            grpcPort,
+			Providers:       opts.providers,
+			ApprovalManager: opts.approvalsManager,
+			Authenticator:   authenticator,
+			CertFile:        os.Getenv(EnvGRPCTLSCert),
+			KeyFile:         os.Getenv(EnvGRPCTLSKey),
+		})
+
+		go func() {
+			err := grpcServer.Start()
+			if err != nil {
+				log.WithFields(log.Fields{
+					"error": err,
+					"port":  grpcPort,
+				}).Fatal("grpc server stopped")
+			}
+		}()
+	}
+
 	// checking whether pubsub (GCR) trigger is enabled
 	if os.Getenv(EnvTriggerPubSub) != "" {
 		projectID := os.Getenv(EnvProjectID)
@@ -367,6 +1054,22 @@ func setupTriggers(ctx context.Context, opts *TriggerOpts) (teardown func()) {
 		go subManager.Start(ctx)
 	}
 
+	// checking whether SQS (ECR) trigger is enabled
+	if queueURL := os.Getenv(EnvECRSQSQueueURL); queueURL != "" {
+		subscriber, err := sqs.New(&sqs.Opts{
+			QueueURL:  queueURL,
+			Providers: opts.providers,
+		})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Fatal("main.setupTriggers: failed to create SQS subscriber")
+			return
+		}
+
+		go subscriber.Start(ctx)
+	}
+
 	if os.Getenv(EnvTriggerPoll) != "0" {
 
 		registryClient := registry.New()
@@ -380,6 +1083,9 @@ func setupTriggers(ctx context.Context, opts *TriggerOpts) (teardown func()) {
 
 	teardown = func() {
 		whs.Stop()
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
 	}
 
 	return teardown
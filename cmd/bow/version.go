@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/alwinius/bow/version"
+
+	"github.com/ghodss/yaml"
+)
+
+// version output formats
+const (
+	versionOutputText = "text"
+	versionOutputJSON = "json"
+	versionOutputYAML = "yaml"
+)
+
+// printVersion - writes version info in the given format, json and yaml
+// are meant for scripts, ie: version checks in CI pipelines
+func printVersion(w io.Writer, output string) error {
+	ver := version.GetbowVersion()
+
+	switch output {
+	case versionOutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(ver)
+	case versionOutputYAML:
+		b, err := yaml.Marshal(ver)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case versionOutputText, "":
+		_, err := fmt.Fprintf(w, "%s version %s (revision %s, built %s)\napi version: %s\ngo version: %s\nos/arch: %s/%s\n",
+			ver.Name, ver.Version, ver.Revision, ver.BuildDate, ver.APIVersion, ver.GoVersion, ver.OS, ver.Arch)
+		return err
+	}
+
+	return fmt.Errorf("unknown output format '%s', expected one of: text, json, yaml", output)
+}
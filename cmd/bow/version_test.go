@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/version"
+)
+
+func TestPrintVersion(t *testing.T) {
+	defer func(v string) { version.Version = v }(version.Version)
+	version.Version = "1.2.3"
+
+	var buf bytes.Buffer
+	if err := printVersion(&buf, versionOutputJSON); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var info types.VersionInfo
+	if err := json.Unmarshal(buf.Bytes(), &info); err != nil {
+		t.Fatalf("failed to decode json output: %s", err)
+	}
+	if info.Name != "bow" || info.Version != "1.2.3" {
+		t.Errorf("unexpected version info: %+v", info)
+	}
+
+	buf.Reset()
+	if err := printVersion(&buf, versionOutputYAML); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.Contains(buf.String(), "version: 1.2.3") {
+		t.Errorf("unexpected yaml output: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := printVersion(&buf, versionOutputText); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.HasPrefix(buf.String(), "bow version 1.2.3") {
+		t.Errorf("unexpected text output: %s", buf.String())
+	}
+
+	if err := printVersion(&buf, "xml"); err == nil {
+		t.Errorf("expected error for unknown output format")
+	}
+}
@@ -15,6 +15,9 @@ const (
 	EnvSlackBotName          = "SLACK_BOT_NAME"
 	EnvSlackChannels         = "SLACK_CHANNELS"
 	EnvSlackApprovalsChannel = "SLACK_APPROVALS_CHANNEL"
+	// signing secret of the Slack app, enables approve/reject buttons
+	// on approval requests
+	EnvSlackSigningSecret = "SLACK_SIGNING_SECRET"
 
 	EnvHipchatToken    = "HIPCHAT_TOKEN"
 	EnvHipchatBotName  = "HIPCHAT_BOT_NAME"
@@ -30,16 +33,74 @@ const (
 	// for documentation on setting it up
 	EnvMattermostEndpoint = "MATTERMOST_ENDPOINT"
 	EnvMattermostName     = "MATTERMOST_USERNAME"
+
+	// Microsoft Teams incoming webhook, see https://docs.microsoft.com/en-us/microsoftteams/platform/webhooks-and-connectors/how-to/add-incoming-webhook
+	// for documentation on setting it up. Named webhooks (name=url,name2=url2) can be
+	// referenced in notification channels
+	EnvTeamsWebhookURL = "TEAMS_WEBHOOK_URL"
+	EnvTeamsChannels   = "TEAMS_CHANNELS"
+)
+
+// Telegram bot configuration, notifications are sent to comma separated
+// default chats. Chat IDs (-1001234567890, @channelusername) can also be
+// referenced in notification channels
+const (
+	EnvTelegramBotToken = "TELEGRAM_BOT_TOKEN"
+	EnvTelegramChatID   = "TELEGRAM_CHAT_ID"
 )
 
+// SlackApprovalCallbackID - callback ID of the approval request attachments,
+// button names are approve/reject and values are approval identifiers
+const SlackApprovalCallbackID = "bow_approval"
+
 // EnvNotificationLevel - minimum level for notifications, defaults to info
 const EnvNotificationLevel = "NOTIFICATION_LEVEL"
 
+// EnvNotificationFormatter - optional rich message format for senders that support it,
+// for example "blockkit" for Slack, defaults to plain text
+const EnvNotificationFormatter = "NOTIFICATION_FORMATTER"
+
+// EnvNotificationLevelKubernetes, EnvNotificationLevelHelm - optional minimum level for
+// notifications of the provider, ie: success drops debug pre-update notifications.
+// Notifications still have to pass the global level
+const (
+	EnvNotificationLevelKubernetes = "NOTIFICATION_LEVEL_KUBERNETES"
+	EnvNotificationLevelHelm       = "NOTIFICATION_LEVEL_HELM"
+)
+
+// EnvNotificationCooldown - optional duration, ie: 10m, repeated notifications with the
+// same type, identifier and level are suppressed within it, disabled by default
+const EnvNotificationCooldown = "NOTIFICATION_COOLDOWN"
+
 // Basic Auth - User / Password
 const EnvBasicAuthUser = "BASIC_AUTH_USER"
 const EnvBasicAuthPassword = "BASIC_AUTH_PASSWORD"
 const EnvAuthenticatedWebhooks = "AUTHENTICATED_WEBHOOKS"
 const EnvTokenSecret = "TOKEN_SECRET"
 
+// EnvApprovalsSigningSecret - shared secret for signed approval votes, enables
+// /v1/approvals/{identifier}/approve and /v1/approvals/{identifier}/reject
+const EnvApprovalsSigningSecret = "APPROVALS_SIGNING_SECRET"
+
+// EnvApprovalsLinkURL - address bow is reachable at, ie: https://bow.example.com.
+// Together with the signing secret new approvals are announced with one-time approve links
+const EnvApprovalsLinkURL = "APPROVALS_LINK_URL"
+
+// EnvApprovalsReminderInterval - how often pending approvals are checked for
+// approaching deadlines, ie: "10m"
+const EnvApprovalsReminderInterval = "APPROVALS_REMINDER_INTERVAL"
+
+// EnvQuayWebhookSecret - optional token Quay notifications have to send
+// as "Authorization: Bearer <token>" to /v1/webhooks/quay
+const EnvQuayWebhookSecret = "QUAY_WEBHOOK_SECRET"
+
+// EnvGitlabWebhookSecret - optional secret token GitLab hooks have to send
+// in X-Gitlab-Token header to /v1/webhooks/gitlab
+const EnvGitlabWebhookSecret = "GITLAB_WEBHOOK_SECRET"
+
+// EnvStreamBufferSize - how many notifications are buffered for each /v1/stream
+// client, notifications to clients with full buffers are dropped
+const EnvStreamBufferSize = "STREAM_BUFFER_SIZE"
+
 // BowLogoURL - is a logo URL for bot icon
 const BowLogoURL = "https://bow.sh/images/logo.png"
@@ -25,6 +25,10 @@ import (
 // more info here: https://docs.aws.amazon.com/AmazonECR/latest/userguide/service_limits.html
 const AWSCredentialsExpiry = 2 * time.Hour
 
+// AWSTokenRefreshMargin - ECR tokens are refreshed this long before they expire
+// so polls never use a token that expires mid-request
+const AWSTokenRefreshMargin = 15 * time.Minute
+
 var registryRegxp *regexp.Regexp
 
 func init() {
@@ -126,7 +130,11 @@ func (h *CredentialsHelper) GetCredentials(image *types.TrackedImage) (*types.Cr
 				Password: password,
 			}
 
-			h.cache.Put(registry, creds)
+			if ad.ExpiresAt != nil {
+				h.cache.PutWithExpiry(registry, creds, ad.ExpiresAt.Add(-AWSTokenRefreshMargin))
+			} else {
+				h.cache.Put(registry, creds)
+			}
 
 			return creds, nil
 		}
@@ -11,6 +11,11 @@ import (
 type item struct {
 	credentials *types.Credentials
 	created     time.Time
+	expires     time.Time
+}
+
+func (i *item) expired(t time.Time) bool {
+	return !t.Before(i.expires)
 }
 
 // Cache - internal cache for aws
@@ -49,7 +54,7 @@ func (c *Cache) expire() {
 	defer c.mu.Unlock()
 	t := time.Now()
 	for k, v := range c.creds {
-		if t.Sub(v.created) > c.ttl {
+		if v.expired(t) {
 			delete(c.creds, k)
 		}
 	}
@@ -59,7 +64,20 @@ func (c *Cache) expire() {
 func (c *Cache) Put(registry string, creds *types.Credentials) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.creds[registry] = &item{credentials: creds, created: time.Now()}
+	now := time.Now()
+	c.creds[registry] = &item{credentials: creds, created: now, expires: now.Add(c.ttl)}
+}
+
+// PutWithExpiry - saves new creds that must not be used after given time,
+// cache ttl still applies if it's shorter
+func (c *Cache) PutWithExpiry(registry string, creds *types.Credentials, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if ttlExpiry := now.Add(c.ttl); ttlExpiry.Before(expires) {
+		expires = ttlExpiry
+	}
+	c.creds[registry] = &item{credentials: creds, created: now, expires: expires}
 }
 
 // Get - retrieves creds
@@ -68,7 +86,7 @@ func (c *Cache) Get(registry string) (*types.Credentials, error) {
 	defer c.mu.RUnlock()
 
 	item, ok := c.creds[registry]
-	if !ok {
+	if !ok || item.expired(time.Now()) {
 		return nil, fmt.Errorf("not found")
 	}
 
@@ -57,3 +57,34 @@ func TestExpiry(t *testing.T) {
 	}
 
 }
+
+func TestPutWithExpiry(t *testing.T) {
+	c := NewCache(time.Hour)
+
+	creds := &types.Credentials{
+		Username: "AWS",
+		Password: "token",
+	}
+
+	// token expiring before cache ttl
+	c.PutWithExpiry("reg1", creds, time.Now().Add(200*time.Millisecond))
+	// token expiring after cache ttl
+	c.PutWithExpiry("reg2", creds, time.Now().Add(12*time.Hour))
+
+	if _, err := c.Get("reg1"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+
+	if _, err := c.Get("reg1"); err == nil {
+		t.Errorf("expected expired token to be refreshed")
+	}
+
+	c.mu.RLock()
+	expires := c.creds["reg2"].expires
+	c.mu.RUnlock()
+	if expires.After(time.Now().Add(time.Hour)) {
+		t.Errorf("expected cache ttl to limit expiry, got: %s", expires)
+	}
+}
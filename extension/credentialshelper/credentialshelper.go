@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/alwinius/bow/types"
+	imageutil "github.com/alwinius/bow/util/image"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -25,6 +26,9 @@ var (
 var (
 	credHelpersM sync.RWMutex
 	credHelpers  = make(map[string]CredentialsHelper)
+	// helpers are consulted in registration order, registry specific helpers
+	// are registered first so static credentials act as a fallback
+	credHelperNames []string
 )
 
 // RegisterCredentialsHelper - registering new credentials helper
@@ -49,6 +53,7 @@ func RegisterCredentialsHelper(name string, ch CredentialsHelper) {
 	}).Info("extension.credentialshelper: helper registered")
 
 	credHelpers[name] = ch
+	credHelperNames = append(credHelperNames, name)
 }
 
 // UnregisterCredentialsHelper - unregister existing credentials helper, used for testing
@@ -61,6 +66,12 @@ func UnregisterCredentialsHelper(name string) {
 	defer credHelpersM.Unlock()
 
 	delete(credHelpers, name)
+	for i, n := range credHelperNames {
+		if n == name {
+			credHelperNames = append(credHelperNames[:i], credHelperNames[i+1:]...)
+			break
+		}
+	}
 }
 
 // GetCredentials - generic function for getting credentials
@@ -71,7 +82,23 @@ func GetCredentials(image *types.TrackedImage) (creds *types.Credentials) {
 
 	creds = &types.Credentials{}
 
-	for name, credHelper := range credHelpers {
+	// Google registry images using workload identity ignore static credentials
+	if image.Credentials != nil && !image.WorkloadIdentity() {
+		*creds = *image.Credentials
+		return creds
+	}
+
+	// ECR public gallery is polled anonymously, cloud helpers and image pull
+	// secrets only have credentials for private registries
+	if imageutil.IsECRPublic(image.Image.Registry()) {
+		log.WithFields(log.Fields{
+			"tracked_image": image,
+		}).Debug("extension.credentialshelper: using anonymous access for ECR public registry")
+		return creds
+	}
+
+	for _, name := range credHelperNames {
+		credHelper := credHelpers[name]
 		if credHelper.IsEnabled() {
 			creds, err := credHelper.GetCredentials(image)
 			if err != nil {
@@ -0,0 +1,74 @@
+package credentialshelper
+
+import (
+	"testing"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/image"
+)
+
+type fakeHelper struct {
+	calls int
+}
+
+func (h *fakeHelper) IsEnabled() bool { return true }
+
+func (h *fakeHelper) GetCredentials(image *types.TrackedImage) (*types.Credentials, error) {
+	h.calls++
+	return &types.Credentials{Username: "user", Password: "pass"}, nil
+}
+
+func TestGetCredentialsECRPublic(t *testing.T) {
+	helper := &fakeHelper{}
+	RegisterCredentialsHelper("fake", helper)
+	defer UnregisterCredentialsHelper("fake")
+
+	ref, err := image.Parse("public.ecr.aws/nginx/nginx:1.19")
+	if err != nil {
+		t.Fatalf("failed to parse image: %s", err)
+	}
+
+	creds := GetCredentials(&types.TrackedImage{Image: ref})
+	if creds.Username != "" || creds.Password != "" {
+		t.Errorf("expected anonymous credentials, got: %s", creds.Username)
+	}
+	if helper.calls != 0 {
+		t.Errorf("expected helpers to be skipped, got %d calls", helper.calls)
+	}
+
+	ref, err = image.Parse("registry.corp/team/app:1.0")
+	if err != nil {
+		t.Fatalf("failed to parse image: %s", err)
+	}
+	creds = GetCredentials(&types.TrackedImage{Image: ref})
+	if creds.Username != "user" {
+		t.Errorf("expected helper credentials, got: %s", creds.Username)
+	}
+}
+
+func TestGetCredentialsWorkloadIdentity(t *testing.T) {
+	helper := &fakeHelper{}
+	RegisterCredentialsHelper("fake", helper)
+	defer UnregisterCredentialsHelper("fake")
+
+	static := &types.Credentials{Username: "pull-secret", Password: "secret"}
+
+	// pull secret credentials are kept for registries without workload identity
+	ref, err := image.Parse("registry.corp/team/app:1.0")
+	if err != nil {
+		t.Fatalf("failed to parse image: %s", err)
+	}
+	creds := GetCredentials(&types.TrackedImage{Image: ref, Credentials: static, UseWorkloadIdentity: true})
+	if creds.Username != "pull-secret" {
+		t.Errorf("expected pull secret credentials, got: %s", creds.Username)
+	}
+
+	ref, err = image.Parse("gcr.io/project/app:1.0")
+	if err != nil {
+		t.Fatalf("failed to parse image: %s", err)
+	}
+	creds = GetCredentials(&types.TrackedImage{Image: ref, Credentials: static, UseWorkloadIdentity: true})
+	if creds.Username != "user" {
+		t.Errorf("expected helper credentials for Google registry, got: %s", creds.Username)
+	}
+}
@@ -0,0 +1,136 @@
+package gcr
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+
+	"github.com/alwinius/bow/extension/credentialshelper"
+	"github.com/alwinius/bow/types"
+	imageutil "github.com/alwinius/bow/util/image"
+	"github.com/alwinius/bow/util/timeutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// cloudPlatformScope - scope required to pull from GCR and Artifact Registry
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// tokenUsername - username registries expect when authenticating with an OAuth access token
+const tokenUsername = "oauth2accesstoken"
+
+// unavailableBackoff - how long to wait before looking for credentials again after
+// they couldn't be found, ie: when bow is not running on GCP
+const unavailableBackoff = 5 * time.Minute
+
+func init() {
+	credentialshelper.RegisterCredentialsHelper("gcr", New())
+}
+
+// GoogleTokenSource - oauth2.TokenSource backed by Google application default credentials
+// (GOOGLE_APPLICATION_CREDENTIALS key file, gcloud credentials or metadata server).
+// Credentials are looked up on first use, tokens are reused while valid and refreshed
+// once they expire so long running pollers don't end up with stale tokens
+type GoogleTokenSource struct {
+	ctx    context.Context
+	scopes []string
+
+	mu     sync.Mutex
+	source oauth2.TokenSource
+}
+
+// NewGoogleTokenSource - creates new token source for given scopes
+func NewGoogleTokenSource(ctx context.Context, scopes ...string) *GoogleTokenSource {
+	return &GoogleTokenSource{
+		ctx:    ctx,
+		scopes: scopes,
+	}
+}
+
+// Token - returns valid access token, refreshing it if needed
+func (s *GoogleTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.source == nil {
+		creds, err := google.FindDefaultCredentials(s.ctx, s.scopes...)
+		if err != nil {
+			return nil, err
+		}
+		// default credentials already reuse tokens until they expire
+		s.source = creds.TokenSource
+	}
+
+	return s.source.Token()
+}
+
+// CredentialsHelper provides authorization to Google Container Registry and Artifact
+// Registry, access token is taken from the token source on each poll so it's always valid.
+// With GKE Workload Identity default credentials are the pod's Google service account
+type CredentialsHelper struct {
+	tokenSource oauth2.TokenSource
+
+	mu          sync.Mutex
+	unavailable time.Time
+}
+
+// New creates a new instance of gcr credentials helper
+func New() *CredentialsHelper {
+	return &CredentialsHelper{
+		tokenSource: NewGoogleTokenSource(context.Background(), cloudPlatformScope),
+	}
+}
+
+// IsEnabled returns a bool whether this credentials helper is initialised or not
+func (h *CredentialsHelper) IsEnabled() bool {
+	return true
+}
+
+// GetCredentials - returns access token for Google registries
+func (h *CredentialsHelper) GetCredentials(image *types.TrackedImage) (*types.Credentials, error) {
+	if !imageutil.IsGoogleRegistry(image.Image.Registry()) {
+		return nil, credentialshelper.ErrUnsupportedRegistry
+	}
+
+	if image.WorkloadIdentity() {
+		return h.workloadIdentityCredentials(image)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := timeutil.Now()
+	if now.Before(h.unavailable) {
+		return nil, credentialshelper.ErrCredentialsNotAvailable
+	}
+
+	token, err := h.tokenSource.Token()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":    err,
+			"registry": image.Image.Registry(),
+		}).Debug("credentialshelper.gcr: failed to get access token")
+		h.unavailable = now.Add(unavailableBackoff)
+		return nil, credentialshelper.ErrCredentialsNotAvailable
+	}
+
+	return &types.Credentials{Username: tokenUsername, Password: token.AccessToken}, nil
+}
+
+// workloadIdentityCredentials - access token of the Workload Identity service account,
+// failures are not backed off as images explicitly asked for these credentials
+func (h *CredentialsHelper) workloadIdentityCredentials(image *types.TrackedImage) (*types.Credentials, error) {
+	token, err := h.tokenSource.Token()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":    err,
+			"registry": image.Image.Registry(),
+		}).Warn("credentialshelper.gcr: failed to get workload identity access token")
+		return nil, credentialshelper.ErrCredentialsNotAvailable
+	}
+
+	return &types.Credentials{Username: tokenUsername, Password: token.AccessToken}, nil
+}
@@ -0,0 +1,158 @@
+package gcr
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/alwinius/bow/extension/credentialshelper"
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/image"
+)
+
+func trackedImage(img string) *types.TrackedImage {
+	ref, err := image.Parse(img)
+	if err != nil {
+		panic(err)
+	}
+	return &types.TrackedImage{Image: ref}
+}
+
+type fakeTokenSource struct {
+	tokens []string
+	err    error
+	calls  int
+}
+
+func (s *fakeTokenSource) Token() (*oauth2.Token, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &oauth2.Token{AccessToken: s.tokens[s.calls-1], Expiry: time.Now().Add(time.Hour)}, nil
+}
+
+func TestGetCredentials(t *testing.T) {
+	ts := &fakeTokenSource{tokens: []string{"ya29.first", "ya29.second", "ya29.third"}}
+	h := &CredentialsHelper{tokenSource: ts}
+
+	for i, img := range []string{"gcr.io/v2-namespace/hello-world:1.1.1", "eu.gcr.io/project/app:1.0.0", "europe-west1-docker.pkg.dev/project/repo/app:1.0.0"} {
+		creds, err := h.GetCredentials(trackedImage(img))
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", img, err)
+		}
+		// every poll asks token source so refreshed tokens are picked up
+		if creds.Username != "oauth2accesstoken" || creds.Password != ts.tokens[i] {
+			t.Errorf("unexpected credentials for %s: %v", img, creds)
+		}
+	}
+}
+
+func TestGetCredentialsUnsupportedRegistry(t *testing.T) {
+	h := &CredentialsHelper{tokenSource: &fakeTokenSource{}}
+	_, err := h.GetCredentials(trackedImage("karolisr/bow:0.1.0"))
+	if err != credentialshelper.ErrUnsupportedRegistry {
+		t.Errorf("expected unsupported registry error, got: %v", err)
+	}
+}
+
+func TestGetCredentialsUnavailable(t *testing.T) {
+	ts := &fakeTokenSource{err: errors.New("google: could not find default credentials")}
+	h := &CredentialsHelper{tokenSource: ts}
+
+	for i := 0; i < 3; i++ {
+		_, err := h.GetCredentials(trackedImage("gcr.io/v2-namespace/hello-world:1.1.1"))
+		if err != credentialshelper.ErrCredentialsNotAvailable {
+			t.Errorf("expected credentials not available error, got: %v", err)
+		}
+	}
+
+	if ts.calls != 1 {
+		t.Errorf("expected token source to be backed off, called %d times", ts.calls)
+	}
+}
+
+func TestGoogleTokenSourceRefresh(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Metadata-Flavor") != "Google" {
+			resp.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if !strings.HasSuffix(req.URL.Path, "/instance/service-accounts/default/token") {
+			resp.WriteHeader(http.StatusNotFound)
+			return
+		}
+		requests++
+		// expiring within oauth2 expiry delta so every call has to refresh
+		resp.Write([]byte(`{"access_token": "ya29.token", "expires_in": 1, "token_type": "Bearer"}`))
+	}))
+	defer srv.Close()
+
+	home, err := ioutil.TempDir("", "bow-gcr")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(home)
+
+	for key, value := range map[string]string{
+		"GCE_METADATA_HOST":              strings.TrimPrefix(srv.URL, "http://"),
+		"GOOGLE_APPLICATION_CREDENTIALS": "",
+		"HOME":                           home,
+	} {
+		defer os.Setenv(key, os.Getenv(key))
+		os.Setenv(key, value)
+	}
+
+	ts := NewGoogleTokenSource(context.Background(), cloudPlatformScope)
+	for i := 0; i < 2; i++ {
+		token, err := ts.Token()
+		if err != nil {
+			t.Fatalf("failed to get token: %s", err)
+		}
+		if token.AccessToken != "ya29.token" {
+			t.Errorf("unexpected token: %s", token.AccessToken)
+		}
+	}
+
+	if requests != 2 {
+		t.Errorf("expected expired token to be refreshed, metadata server called %d times", requests)
+	}
+}
+
+func TestGetCredentialsWorkloadIdentity(t *testing.T) {
+	ts := &fakeTokenSource{err: errors.New("metadata server unavailable")}
+	h := &CredentialsHelper{tokenSource: ts}
+
+	img := trackedImage("europe-west1-docker.pkg.dev/project/repo/app:1.0.0")
+	img.UseWorkloadIdentity = true
+	for i := 0; i < 2; i++ {
+		_, err := h.GetCredentials(img)
+		if err != credentialshelper.ErrCredentialsNotAvailable {
+			t.Errorf("expected credentials not available error, got: %v", err)
+		}
+	}
+
+	// images that asked for workload identity aren't backed off
+	if ts.calls != 2 {
+		t.Errorf("expected token source to be called for each poll, called %d times", ts.calls)
+	}
+
+	ts.err = nil
+	ts.tokens = []string{"", "", "ya29.workload"}
+	creds, err := h.GetCredentials(img)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if creds.Username != "oauth2accesstoken" || creds.Password != "ya29.workload" {
+		t.Errorf("unexpected credentials: %v", creds)
+	}
+}
@@ -0,0 +1,34 @@
+package notification
+
+import (
+	"github.com/alwinius/bow/types"
+)
+
+// LevelFilter - drops notifications below the level before they reach the
+// wrapped sender, allows providers to have higher notification level than
+// the global one
+type LevelFilter struct {
+	sender Sender
+	level  types.Level
+}
+
+// NewLevelFilter - wraps sender, notifications below the level are dropped
+func NewLevelFilter(sender Sender, level types.Level) *LevelFilter {
+	return &LevelFilter{
+		sender: sender,
+		level:  level,
+	}
+}
+
+// Configure - configures wrapped sender
+func (f *LevelFilter) Configure(config *Config) (bool, error) {
+	return f.sender.Configure(config)
+}
+
+// Send - sends notification unless it's below the level
+func (f *LevelFilter) Send(event types.EventNotification) error {
+	if event.Level < f.level {
+		return nil
+	}
+	return f.sender.Send(event)
+}
@@ -3,6 +3,7 @@ package notification
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -33,9 +34,45 @@ var (
 type Config struct {
 	Attempts int
 	Level    types.Level
+	// Formatter is an optional message format, senders that support rich
+	// formatting use it instead of plain text messages
+	Formatter Formatter
+	// Cooldown - optional, notifications with the same type, identifier and level
+	// are suppressed for this long after one was sent
+	Cooldown time.Duration
 	Params   map[string]interface{} `yaml:",inline"`
 }
 
+// Formatter - message format used by senders
+type Formatter int
+
+// Available formatters
+const (
+	FormatterPlain Formatter = iota
+	FormatterBlockKit
+)
+
+func (f Formatter) String() string {
+	switch f {
+	case FormatterBlockKit:
+		return "blockkit"
+	default:
+		return "plain"
+	}
+}
+
+// ParseFormatter - parses formatter name, empty name defaults to plain text
+func ParseFormatter(formatter string) (Formatter, error) {
+	switch strings.ToLower(formatter) {
+	case "", "plain":
+		return FormatterPlain, nil
+	case "blockkit":
+		return FormatterBlockKit, nil
+	}
+
+	return FormatterPlain, fmt.Errorf("not a valid notification formatter: %q", formatter)
+}
+
 // Sender represents anything that can transmit notifications.
 type Sender interface {
 	// Configure attempts to initialize the notifier with the provided configuration.
@@ -78,6 +115,7 @@ type DefaultNotificationSender struct {
 	config  *Config
 	stopper *stopper.Stopper
 	level   types.Level
+	limiter *rateLimiter
 }
 
 // New - create new sender
@@ -90,6 +128,10 @@ func New(ctx context.Context) *DefaultNotificationSender {
 // Configure - configure is used to register multiple notification senders
 func (m *DefaultNotificationSender) Configure(config *Config) (bool, error) {
 	m.config = config
+	m.limiter = nil
+	if config.Cooldown > 0 {
+		m.limiter = newRateLimiter(config.Cooldown)
+	}
 	// Configure registered notifiers.
 	for senderName, sender := range m.Senders() {
 		if configured, err := sender.Configure(config); configured {
@@ -124,6 +166,19 @@ func (m *DefaultNotificationSender) Send(event types.EventNotification) error {
 		return nil
 	}
 
+	if m.limiter != nil {
+		var ok bool
+		event, ok = m.limiter.allow(event, timeutil.Now())
+		if !ok {
+			log.WithFields(log.Fields{
+				logNotiName:  event.Name,
+				"identifier": event.Identifier,
+				"cooldown":   m.config.Cooldown,
+			}).Debug("extension.notification: repeated notification suppressed")
+			return nil
+		}
+	}
+
 	sendersM.RLock()
 	defer sendersM.RUnlock()
 
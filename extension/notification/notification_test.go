@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/timeutil"
 )
 
 type fakeSender struct {
@@ -162,3 +164,93 @@ func TestSendLevelNotificationC(t *testing.T) {
 		t.Errorf("unexpected level: %s", fs.sent.Level)
 	}
 }
+
+func TestSendCooldown(t *testing.T) {
+	defer func(now func() time.Time) { timeutil.Now = now }(timeutil.Now)
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeutil.Now = func() time.Time { return now }
+
+	sndr := New(context.Background())
+
+	sndr.Configure(&Config{
+		Level:    types.LevelDebug,
+		Attempts: 1,
+		Cooldown: time.Minute,
+	})
+
+	fs := &fakeSender{
+		shouldConfigure: true,
+	}
+
+	RegisterSender("fakeSender", fs)
+	defer sndr.UnregisterSender("fakeSender")
+
+	send := func(identifier, message string) string {
+		fs.sent = nil
+		err := sndr.Send(types.EventNotification{
+			Level:      types.LevelInfo,
+			Type:       types.NotificationPreDeploymentUpdate,
+			Identifier: identifier,
+			Message:    message,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if fs.sent == nil {
+			return ""
+		}
+		return fs.sent.Message
+	}
+
+	if got := send("deployment/default/foo", "first"); got != "first" {
+		t.Errorf("unexpected notification message: %s", got)
+	}
+	now = now.Add(10 * time.Second)
+	if got := send("deployment/default/foo", "second"); got != "" {
+		t.Errorf("expected notification to be suppressed, got: %s", got)
+	}
+	if got := send("deployment/default/foo", "third"); got != "" {
+		t.Errorf("expected notification to be suppressed, got: %s", got)
+	}
+	if got := send("deployment/default/bar", "other"); got != "other" {
+		t.Errorf("unexpected notification message for other identifier: %s", got)
+	}
+
+	now = now.Add(time.Minute)
+	if got := send("deployment/default/foo", "fourth"); got != "fourth (2 more suppressed)" {
+		t.Errorf("unexpected notification message after cooldown: %s", got)
+	}
+	now = now.Add(time.Minute)
+	if got := send("deployment/default/foo", "fifth"); got != "fifth" {
+		t.Errorf("unexpected notification message: %s", got)
+	}
+}
+
+func TestLevelFilter(t *testing.T) {
+	fs := &fakeSender{}
+	filter := NewLevelFilter(fs, types.LevelSuccess)
+
+	err := filter.Send(types.EventNotification{
+		Level:   types.LevelDebug,
+		Type:    types.NotificationPreReleaseUpdate,
+		Message: "preparing update",
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if fs.sent != nil {
+		t.Errorf("expected debug notification to be dropped, got: %s", fs.sent.Message)
+	}
+
+	err = filter.Send(types.EventNotification{
+		Level:   types.LevelSuccess,
+		Type:    types.NotificationReleaseUpdate,
+		Message: "updated",
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if fs.sent == nil || fs.sent.Message != "updated" {
+		t.Errorf("expected success notification to be sent")
+	}
+}
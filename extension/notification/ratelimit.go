@@ -0,0 +1,71 @@
+package notification
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alwinius/bow/types"
+)
+
+// rateLimiterPruneSize - expired entries are pruned once the limiter
+// tracks more notification keys than this
+const rateLimiterPruneSize = 1000
+
+// rateLimiter - suppresses repeated notifications with the same type, identifier
+// and level within the cooldown, the next notification that gets through reports
+// how many were suppressed
+type rateLimiter struct {
+	cooldown time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*rateLimitEntry
+}
+
+type rateLimitEntry struct {
+	sentAt     time.Time
+	suppressed int
+}
+
+func newRateLimiter(cooldown time.Duration) *rateLimiter {
+	return &rateLimiter{
+		cooldown: cooldown,
+		entries:  make(map[string]*rateLimitEntry),
+	}
+}
+
+// allow - whether the notification should be sent, returned notification
+// message mentions notifications suppressed since the last one
+func (l *rateLimiter) allow(event types.EventNotification, now time.Time) (types.EventNotification, bool) {
+	key := fmt.Sprintf("%s/%s/%s", event.Type, event.Identifier, event.Level)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[key]
+	if ok && now.Sub(e.sentAt) < l.cooldown {
+		e.suppressed++
+		return event, false
+	}
+
+	if ok && e.suppressed > 0 {
+		event.Message = fmt.Sprintf("%s (%d more suppressed)", event.Message, e.suppressed)
+	}
+	l.entries[key] = &rateLimitEntry{sentAt: now}
+
+	if len(l.entries) > rateLimiterPruneSize {
+		l.prune(now)
+	}
+
+	return event, true
+}
+
+// prune - forgets entries whose cooldown passed, suppressed counts of
+// long gone notifications aren't worth reporting
+func (l *rateLimiter) prune(now time.Time) {
+	for key, e := range l.entries {
+		if now.Sub(e.sentAt) >= l.cooldown {
+			delete(l.entries, key)
+		}
+	}
+}
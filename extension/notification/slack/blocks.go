@@ -0,0 +1,137 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/alwinius/bow/types"
+)
+
+// postMessageURL - Slack Web API endpoint, vendored slack client doesn't support
+// blocks yet so Block Kit messages are posted directly
+var postMessageURL = "https://slack.com/api/chat.postMessage"
+
+// headerTextLimit - Slack rejects header blocks with longer text
+const headerTextLimit = 150
+
+type blockText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type buttonElement struct {
+	Type string     `json:"type"`
+	Text *blockText `json:"text"`
+	URL  string     `json:"url"`
+}
+
+type block struct {
+	Type     string        `json:"type"`
+	Text     *blockText    `json:"text,omitempty"`
+	Elements []interface{} `json:"elements,omitempty"`
+}
+
+type blockKitMessage struct {
+	Channel  string  `json:"channel"`
+	Text     string  `json:"text"`
+	Username string  `json:"username,omitempty"`
+	IconURL  string  `json:"icon_url,omitempty"`
+	Blocks   []block `json:"blocks"`
+}
+
+type blockKitResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// buildBlocks - builds Block Kit layout: header, message, version delta and
+// a changelog button if release notes are available
+func buildBlocks(event types.EventNotification) []block {
+	header := event.Type.String()
+	if event.Name != "" {
+		header = event.Name
+	}
+	if utf8.RuneCountInString(header) > headerTextLimit {
+		header = string([]rune(header)[:headerTextLimit])
+	}
+
+	blocks := []block{
+		{
+			Type: "header",
+			Text: &blockText{Type: "plain_text", Text: header},
+		},
+		{
+			Type: "section",
+			Text: &blockText{Type: "mrkdwn", Text: event.Message},
+		},
+	}
+
+	current := event.Metadata["current_version"]
+	newVersion := event.Metadata["new_version"]
+	if current != "" || newVersion != "" {
+		blocks = append(blocks, block{
+			Type: "context",
+			Elements: []interface{}{
+				&blockText{Type: "mrkdwn", Text: fmt.Sprintf("*%s* → *%s*", current, newVersion)},
+			},
+		})
+	}
+
+	// helm plans can carry several release notes, button links to the first one
+	releaseNotes := types.DecodeReleaseNotes(event.Metadata[types.ReleaseNotesMetadata])
+	if len(releaseNotes) > 0 {
+		blocks = append(blocks, block{
+			Type: "actions",
+			Elements: []interface{}{
+				&buttonElement{
+					Type: "button",
+					Text: &blockText{Type: "plain_text", Text: "Release notes"},
+					URL:  releaseNotes[0],
+				},
+			},
+		})
+	}
+
+	return blocks
+}
+
+func (s *sender) postBlocks(channel string, event types.EventNotification) error {
+	bts, err := json.Marshal(&blockKitMessage{
+		Channel:  channel,
+		Text:     event.Message,
+		Username: s.botName,
+		IconURL:  s.iconURL,
+		Blocks:   buildBlocks(event),
+	})
+	if err != nil {
+		return fmt.Errorf("could not marshal: %s", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, postMessageURL, bytes.NewBuffer(bts))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var r blockKitResponse
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	if err != nil {
+		return fmt.Errorf("failed to decode slack response: %s", err)
+	}
+
+	if !r.OK {
+		return fmt.Errorf("slack returned error: %s", r.Error)
+	}
+
+	return nil
+}
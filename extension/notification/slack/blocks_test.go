@@ -0,0 +1,130 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alwinius/bow/extension/notification"
+	"github.com/alwinius/bow/types"
+)
+
+func TestBuildBlocks(t *testing.T) {
+	blocks := buildBlocks(types.EventNotification{
+		Name:    "update release",
+		Message: "Successfully updated release default/foo 1.0.0->1.1.0",
+		Type:    types.NotificationReleaseUpdate,
+		Level:   types.LevelSuccess,
+		Metadata: map[string]string{
+			"current_version":          "1.0.0",
+			"new_version":              "1.1.0",
+			types.ReleaseNotesMetadata: types.EncodeReleaseNotes([]string{"https://example.com/changelog?a=1,2", "https://example.com/other"}),
+		},
+	})
+
+	if len(blocks) != 4 {
+		t.Fatalf("expected 4 blocks, got: %d", len(blocks))
+	}
+
+	expectedTypes := []string{"header", "section", "context", "actions"}
+	for idx, b := range blocks {
+		if b.Type != expectedTypes[idx] {
+			t.Errorf("unexpected block %d type: %s", idx, b.Type)
+		}
+	}
+
+	if blocks[0].Text.Text != "update release" {
+		t.Errorf("unexpected header: %s", blocks[0].Text.Text)
+	}
+
+	ctx := blocks[2].Elements[0].(*blockText)
+	if ctx.Text != "*1.0.0* → *1.1.0*" {
+		t.Errorf("unexpected context text: %s", ctx.Text)
+	}
+
+	button := blocks[3].Elements[0].(*buttonElement)
+	if button.URL != "https://example.com/changelog?a=1,2" {
+		t.Errorf("unexpected button URL: %s", button.URL)
+	}
+}
+
+func TestBuildBlocksHeaderLimit(t *testing.T) {
+	blocks := buildBlocks(types.EventNotification{
+		Name:    strings.Repeat("ü", 200),
+		Message: "Successfully updated release default/foo 1.0.0->1.1.0",
+		Type:    types.NotificationReleaseUpdate,
+	})
+
+	header := blocks[0].Text.Text
+	if !utf8.ValidString(header) || utf8.RuneCountInString(header) != headerTextLimit {
+		t.Errorf("expected header to be cut to %d characters, got: %q", headerTextLimit, header)
+	}
+}
+
+func TestBuildBlocksWithoutReleaseNotes(t *testing.T) {
+	blocks := buildBlocks(types.EventNotification{
+		Message: "bow has started",
+		Type:    types.NotificationSystemEvent,
+	})
+
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got: %d", len(blocks))
+	}
+
+	if blocks[0].Text.Text != "system event" {
+		t.Errorf("unexpected header: %s", blocks[0].Text.Text)
+	}
+}
+
+func TestSendBlockKit(t *testing.T) {
+	var received blockKitMessage
+	var authHeader string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+		err := json.NewDecoder(r.Body).Decode(&received)
+		if err != nil {
+			t.Errorf("failed to decode request: %s", err)
+		}
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer ts.Close()
+
+	postMessageURL = ts.URL
+
+	s := &sender{
+		channels:  []string{"general"},
+		botName:   "bow",
+		formatter: notification.FormatterBlockKit,
+		token:     "xoxb-token",
+		client:    ts.Client(),
+	}
+
+	err := s.Send(types.EventNotification{
+		Name:      "update resource",
+		Message:   "Successfully updated deployment default/foo 1.0.0->1.1.0",
+		CreatedAt: time.Now(),
+		Type:      types.NotificationDeploymentUpdate,
+		Level:     types.LevelSuccess,
+		Channels:  []string{"deployments"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if authHeader != "Bearer xoxb-token" {
+		t.Errorf("unexpected auth header: %s", authHeader)
+	}
+
+	if received.Channel != "deployments" {
+		t.Errorf("unexpected channel: %s", received.Channel)
+	}
+
+	if len(received.Blocks) != 2 {
+		t.Errorf("expected 2 blocks, got: %d", len(received.Blocks))
+	}
+}
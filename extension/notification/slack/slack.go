@@ -3,6 +3,7 @@ package slack
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
@@ -24,6 +25,12 @@ type sender struct {
 	slackClient *slack.Client
 	channels    []string
 	botName     string
+	iconURL     string
+
+	// Block Kit messages are posted directly to the Web API
+	formatter notification.Formatter
+	token     string
+	client    *http.Client
 }
 
 func init() {
@@ -52,10 +59,18 @@ func (s *sender) Configure(config *notification.Config) (bool, error) {
 	}
 
 	s.slackClient = slack.New(token)
+	s.token = token
+	s.iconURL = constants.BowLogoURL
+	s.formatter = config.Formatter
+	s.client = &http.Client{
+		Transport: http.DefaultTransport,
+		Timeout:   timeout,
+	}
 
 	log.WithFields(log.Fields{
-		"name":     "slack",
-		"channels": s.channels,
+		"name":      "slack",
+		"channels":  s.channels,
+		"formatter": s.formatter.String(),
 	}).Info("extension.notification.slack: sender configured")
 
 	var msg string
@@ -84,6 +99,24 @@ func (s *sender) Configure(config *notification.Config) (bool, error) {
 }
 
 func (s *sender) Send(event types.EventNotification) error {
+	chans := s.channels
+	if len(event.Channels) > 0 {
+		chans = event.Channels
+	}
+
+	if s.formatter == notification.FormatterBlockKit {
+		for _, channel := range chans {
+			err := s.postBlocks(channel, event)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"error":   err,
+					"channel": channel,
+				}).Error("extension.notification.slack: failed to send block kit notification")
+			}
+		}
+		return nil
+	}
+
 	params := slack.NewPostMessageParameters()
 	params.Username = s.botName
 	params.IconURL = constants.BowLogoURL
@@ -104,11 +137,6 @@ func (s *sender) Send(event types.EventNotification) error {
 		},
 	}
 
-	chans := s.channels
-	if len(event.Channels) > 0 {
-		chans = event.Channels
-	}
-
 	var mgsOpts []slack.MsgOption
 
 	mgsOpts = append(mgsOpts, slack.MsgOptionPostMessageParameters(params))
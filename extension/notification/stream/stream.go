@@ -0,0 +1,132 @@
+package stream
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/alwinius/bow/constants"
+	"github.com/alwinius/bow/extension/notification"
+	"github.com/alwinius/bow/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultBufferSize - notifications buffered for each subscriber
+const DefaultBufferSize = 100
+
+// Default - broadcaster registered as notification sender
+var Default = New(DefaultBufferSize)
+
+func init() {
+	notification.RegisterSender("stream", Default)
+}
+
+// Filter - limits subscription to notifications of the provider
+// and namespace, empty fields match everything
+type Filter struct {
+	Provider  string
+	Namespace string
+}
+
+func (f Filter) matches(event types.EventNotification) bool {
+	if f.Provider != "" && event.Metadata["provider"] != f.Provider {
+		return false
+	}
+	if f.Namespace != "" && event.Metadata["namespace"] != f.Namespace {
+		return false
+	}
+	return true
+}
+
+// Subscription - notifications for a single client
+type Subscription struct {
+	filter  Filter
+	events  chan types.EventNotification
+	dropped uint64
+}
+
+// Events - notifications matching subscription filter
+func (s *Subscription) Events() <-chan types.EventNotification {
+	return s.events
+}
+
+// Dropped - how many notifications were dropped because client
+// didn't keep up
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Broadcaster - notification sender that passes notifications to subscribers,
+// slow subscribers lose notifications instead of blocking senders
+type Broadcaster struct {
+	mu          sync.RWMutex
+	bufferSize  int
+	subscribers map[*Subscription]bool
+}
+
+// New - create new broadcaster
+func New(bufferSize int) *Broadcaster {
+	return &Broadcaster{
+		bufferSize:  bufferSize,
+		subscribers: make(map[*Subscription]bool),
+	}
+}
+
+// Configure - sets buffer size, broadcaster is always enabled
+func (b *Broadcaster) Configure(config *notification.Config) (bool, error) {
+	if size := os.Getenv(constants.EnvStreamBufferSize); size != "" {
+		parsed, err := strconv.Atoi(size)
+		if err != nil || parsed < 1 {
+			log.WithFields(log.Fields{
+				"buffer_size": size,
+				"default":     DefaultBufferSize,
+			}).Error("extension.notification.stream: invalid buffer size, using default")
+			parsed = DefaultBufferSize
+		}
+		b.mu.Lock()
+		b.bufferSize = parsed
+		b.mu.Unlock()
+	}
+	return true, nil
+}
+
+// Send - passes notification to matching subscribers
+func (b *Broadcaster) Send(event types.EventNotification) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers {
+		if !sub.filter.matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			atomic.AddUint64(&sub.dropped, 1)
+		}
+	}
+	return nil
+}
+
+// Subscribe - creates new subscription, it has to be removed with Unsubscribe
+func (b *Broadcaster) Subscribe(filter Filter) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &Subscription{
+		filter: filter,
+		events: make(chan types.EventNotification, b.bufferSize),
+	}
+	b.subscribers[sub] = true
+	return sub
+}
+
+// Unsubscribe - stops passing notifications to the subscription
+func (b *Broadcaster) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscribers, sub)
+}
@@ -0,0 +1,60 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/alwinius/bow/types"
+)
+
+func notificationFor(provider, namespace string) types.EventNotification {
+	return types.EventNotification{
+		Name: "update resource",
+		Metadata: map[string]string{
+			"provider":  provider,
+			"namespace": namespace,
+		},
+	}
+}
+
+func TestBroadcasterFilters(t *testing.T) {
+	b := New(10)
+
+	all := b.Subscribe(Filter{})
+	helm := b.Subscribe(Filter{Provider: "helm"})
+	prod := b.Subscribe(Filter{Namespace: "prod"})
+
+	b.Send(notificationFor("helm", "staging"))
+	b.Send(notificationFor("kubernetes", "prod"))
+
+	if len(all.Events()) != 2 {
+		t.Errorf("expected 2 notifications, got: %d", len(all.Events()))
+	}
+	if len(helm.Events()) != 1 || (<-helm.Events()).Metadata["provider"] != "helm" {
+		t.Errorf("expected helm notification only")
+	}
+	if len(prod.Events()) != 1 || (<-prod.Events()).Metadata["namespace"] != "prod" {
+		t.Errorf("expected prod notification only")
+	}
+
+	b.Unsubscribe(all)
+	b.Send(notificationFor("helm", "prod"))
+	if len(all.Events()) != 2 {
+		t.Errorf("unsubscribed client shouldn't get notifications")
+	}
+}
+
+func TestBroadcasterDropsForSlowSubscribers(t *testing.T) {
+	b := New(2)
+	sub := b.Subscribe(Filter{})
+
+	for i := 0; i < 5; i++ {
+		b.Send(notificationFor("helm", "prod"))
+	}
+
+	if len(sub.Events()) != 2 {
+		t.Errorf("expected buffer to be full, got: %d", len(sub.Events()))
+	}
+	if sub.Dropped() != 3 {
+		t.Errorf("expected 3 dropped notifications, got: %d", sub.Dropped())
+	}
+}
@@ -0,0 +1,234 @@
+package teams
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alwinius/bow/constants"
+	"github.com/alwinius/bow/extension/notification"
+	"github.com/alwinius/bow/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const timeout = 5 * time.Second
+
+// TeamsNotifier - sends notifications to Microsoft Teams Incoming Webhooks
+// as Adaptive Cards
+type TeamsNotifier struct {
+	// default webhook, used when notification doesn't
+	// reference any named webhook
+	endpoint string
+	// named webhooks, map[name]endpoint, names can be used in
+	// notification channel overrides
+	channels map[string]string
+	client   *http.Client
+}
+
+func init() {
+	notification.RegisterSender("teams", &TeamsNotifier{})
+}
+
+// Configure - configures notifier from environment variables
+func (s *TeamsNotifier) Configure(config *notification.Config) (bool, error) {
+	s.endpoint = os.Getenv(constants.EnvTeamsWebhookURL)
+
+	channels, err := parseChannels(os.Getenv(constants.EnvTeamsChannels))
+	if err != nil {
+		return false, err
+	}
+	s.channels = channels
+
+	if s.endpoint == "" && len(s.channels) == 0 {
+		return false, nil
+	}
+
+	if s.endpoint != "" {
+		if _, err := url.ParseRequestURI(s.endpoint); err != nil {
+			return false, fmt.Errorf("could not parse endpoint URL: %s", err)
+		}
+	}
+
+	s.client = &http.Client{
+		Transport: http.DefaultTransport,
+		Timeout:   timeout,
+	}
+
+	var names []string
+	for name := range s.channels {
+		names = append(names, name)
+	}
+
+	log.WithFields(log.Fields{
+		"name":     "teams",
+		"channels": names,
+	}).Info("extension.notification.teams: sender configured")
+
+	return true, nil
+}
+
+// parseChannels - parses named webhooks, format: name=https://...,other=https://...
+func parseChannels(channels string) (map[string]string, error) {
+	parsed := make(map[string]string)
+	if channels == "" {
+		return parsed, nil
+	}
+
+	for _, c := range strings.Split(channels, ",") {
+		parts := strings.SplitN(strings.TrimSpace(c), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid teams channel '%s', expected name=webhook URL", c)
+		}
+		if _, err := url.ParseRequestURI(parts[1]); err != nil {
+			return nil, fmt.Errorf("could not parse endpoint URL for teams channel '%s': %s", parts[0], err)
+		}
+		parsed[parts[0]] = parts[1]
+	}
+
+	return parsed, nil
+}
+
+// endpoints - named webhooks referenced in notification channels, falling back
+// to default webhook
+func (s *TeamsNotifier) endpoints(event types.EventNotification) []string {
+	var endpoints []string
+	for _, c := range event.Channels {
+		if endpoint, ok := s.channels[c]; ok {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	if len(endpoints) == 0 && s.endpoint != "" {
+		endpoints = append(endpoints, s.endpoint)
+	}
+
+	return endpoints
+}
+
+type cardText struct {
+	Type   string `json:"type"`
+	Text   string `json:"text"`
+	Weight string `json:"weight,omitempty"`
+	Size   string `json:"size,omitempty"`
+	Color  string `json:"color,omitempty"`
+	Wrap   bool   `json:"wrap"`
+}
+
+type cardFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type cardFactSet struct {
+	Type  string     `json:"type"`
+	Facts []cardFact `json:"facts"`
+}
+
+type adaptiveCard struct {
+	Schema  string        `json:"$schema"`
+	Type    string        `json:"type"`
+	Version string        `json:"version"`
+	Body    []interface{} `json:"body"`
+}
+
+type attachment struct {
+	ContentType string        `json:"contentType"`
+	Content     *adaptiveCard `json:"content"`
+}
+
+type message struct {
+	Type        string       `json:"type"`
+	Attachments []attachment `json:"attachments"`
+}
+
+func levelColor(level types.Level) string {
+	switch level {
+	case types.LevelSuccess:
+		return "good"
+	case types.LevelWarn:
+		return "warning"
+	case types.LevelError, types.LevelFatal:
+		return "attention"
+	default:
+		return "default"
+	}
+}
+
+func newMessage(event types.EventNotification) *message {
+	title := event.Name
+	if title == "" {
+		title = event.Type.String()
+	}
+
+	var facts []cardFact
+	if event.ResourceKind != "" {
+		facts = append(facts, cardFact{Title: "Kind", Value: event.ResourceKind})
+	}
+	for _, f := range []struct{ title, key string }{
+		{"Namespace", "namespace"},
+		{"Name", "name"},
+		{"Old version", "current_version"},
+		{"New version", "new_version"},
+		{"Policy", "policy"},
+	} {
+		if v := event.Metadata[f.key]; v != "" {
+			facts = append(facts, cardFact{Title: f.title, Value: v})
+		}
+	}
+
+	body := []interface{}{
+		&cardText{Type: "TextBlock", Text: title, Weight: "bolder", Size: "medium", Color: levelColor(event.Level), Wrap: true},
+		&cardText{Type: "TextBlock", Text: event.Message, Wrap: true},
+	}
+	if len(facts) > 0 {
+		body = append(body, &cardFactSet{Type: "FactSet", Facts: facts})
+	}
+
+	return &message{
+		Type: "message",
+		Attachments: []attachment{
+			{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				Content: &adaptiveCard{
+					Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+					Type:    "AdaptiveCard",
+					Version: "1.2",
+					Body:    body,
+				},
+			},
+		},
+	}
+}
+
+// Send - sends notification as an Adaptive Card
+func (s *TeamsNotifier) Send(event types.EventNotification) error {
+	bts, err := json.Marshal(newMessage(event))
+	if err != nil {
+		return fmt.Errorf("could not marshal: %s", err)
+	}
+
+	for _, endpoint := range s.endpoints(event) {
+		resp, err := s.client.Post(endpoint, "application/json", bytes.NewBuffer(bts))
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Error("extension.notification.teams: failed to send notification")
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+			log.WithFields(log.Fields{
+				"status": resp.StatusCode,
+			}).Error("extension.notification.teams: unexpected response status")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package teams
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/types"
+)
+
+func TestTeamsRequest(t *testing.T) {
+	var received *message
+	handler := func(resp http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Errorf("failed to parse body: %s", err)
+		}
+
+		received = &message{}
+		if err := json.Unmarshal(body, received); err != nil {
+			t.Errorf("failed to unmarshal body: %s", err)
+		}
+
+		t.Log(string(body))
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	s := &TeamsNotifier{
+		endpoint: ts.URL,
+		client:   &http.Client{},
+	}
+
+	s.Send(types.EventNotification{
+		ResourceKind: "deployment",
+		Name:         "update resource",
+		Message:      "message here",
+		CreatedAt:    time.Now(),
+		Type:         types.NotificationDeploymentUpdate,
+		Level:        types.LevelSuccess,
+		Metadata: map[string]string{
+			"namespace":       "default",
+			"name":            "wd",
+			"current_version": "1.0.0",
+			"new_version":     "1.1.0",
+			"policy":          "minor",
+		},
+	})
+
+	if received == nil {
+		t.Fatalf("notification was not received")
+	}
+
+	if len(received.Attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got: %d", len(received.Attachments))
+	}
+
+	card := received.Attachments[0]
+	if card.ContentType != "application/vnd.microsoft.card.adaptive" {
+		t.Errorf("unexpected content type: %s", card.ContentType)
+	}
+
+	if len(card.Content.Body) != 3 {
+		t.Fatalf("expected 3 card elements, got: %d", len(card.Content.Body))
+	}
+
+	factSet, ok := card.Content.Body[2].(map[string]interface{})
+	if !ok {
+		t.Fatalf("unexpected fact set: %v", card.Content.Body[2])
+	}
+
+	facts := map[string]string{}
+	for _, f := range factSet["facts"].([]interface{}) {
+		fact := f.(map[string]interface{})
+		facts[fact["title"].(string)] = fact["value"].(string)
+	}
+
+	expected := map[string]string{
+		"Kind":        "deployment",
+		"Namespace":   "default",
+		"Name":        "wd",
+		"Old version": "1.0.0",
+		"New version": "1.1.0",
+		"Policy":      "minor",
+	}
+	for k, v := range expected {
+		if facts[k] != v {
+			t.Errorf("expected fact %s to be %s, got: %s", k, v, facts[k])
+		}
+	}
+}
+
+func TestTeamsNamedChannels(t *testing.T) {
+	var defaultHits, opsHits int
+
+	defaultServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		defaultHits++
+	}))
+	defer defaultServer.Close()
+
+	opsServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		opsHits++
+	}))
+	defer opsServer.Close()
+
+	channels, err := parseChannels("ops=" + opsServer.URL)
+	if err != nil {
+		t.Fatalf("failed to parse channels: %s", err)
+	}
+
+	s := &TeamsNotifier{
+		endpoint: defaultServer.URL,
+		channels: channels,
+		client:   &http.Client{},
+	}
+
+	s.Send(types.EventNotification{
+		Name:     "update resource",
+		Message:  "message here",
+		Channels: []string{"ops"},
+	})
+	s.Send(types.EventNotification{
+		Name:     "update resource",
+		Message:  "message here",
+		Channels: []string{"unknown"},
+	})
+
+	if opsHits != 1 {
+		t.Errorf("expected 1 notification on named webhook, got: %d", opsHits)
+	}
+	if defaultHits != 1 {
+		t.Errorf("expected 1 notification on default webhook, got: %d", defaultHits)
+	}
+}
+
+func TestParseChannels(t *testing.T) {
+	_, err := parseChannels("ops")
+	if err == nil {
+		t.Errorf("expected error for channel without webhook URL")
+	}
+
+	channels, err := parseChannels("ops=https://example.com/a, dev=https://example.com/b")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if channels["dev"] != "https://example.com/b" {
+		t.Errorf("unexpected dev channel: %s", channels["dev"])
+	}
+}
@@ -0,0 +1,186 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/alwinius/bow/constants"
+	"github.com/alwinius/bow/extension/notification"
+	"github.com/alwinius/bow/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const timeout = 5 * time.Second
+
+// defaultAPIURL - Telegram Bot API, bot token is part of the method URL
+const defaultAPIURL = "https://api.telegram.org"
+
+// chatIDPattern - numeric chat IDs (groups are negative) and @channelusername,
+// only notification channels that look like chat IDs are used by this sender
+var chatIDPattern = regexp.MustCompile(`^(-?[0-9]+|@[A-Za-z0-9_]+)$`)
+
+// TelegramNotifier - sends notifications to Telegram chats through a bot
+type TelegramNotifier struct {
+	apiURL string
+	token  string
+	// default chats, used when notification doesn't reference any chat
+	chatIDs []string
+	client  *http.Client
+}
+
+func init() {
+	notification.RegisterSender("telegram", &TelegramNotifier{})
+}
+
+// Configure - configures notifier from environment variables
+func (s *TelegramNotifier) Configure(config *notification.Config) (bool, error) {
+	s.token = os.Getenv(constants.EnvTelegramBotToken)
+	if s.token == "" {
+		return false, nil
+	}
+
+	s.chatIDs = nil
+	for _, id := range strings.Split(os.Getenv(constants.EnvTelegramChatID), ",") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if !chatIDPattern.MatchString(id) {
+			return false, fmt.Errorf("invalid telegram chat ID '%s', expected numeric ID or @channelusername", id)
+		}
+		s.chatIDs = append(s.chatIDs, id)
+	}
+
+	s.apiURL = defaultAPIURL
+	s.client = &http.Client{
+		Transport: http.DefaultTransport,
+		Timeout:   timeout,
+	}
+
+	log.WithFields(log.Fields{
+		"name":  "telegram",
+		"chats": s.chatIDs,
+	}).Info("extension.notification.telegram: sender configured")
+
+	return true, nil
+}
+
+// chats - chat IDs referenced in notification channels, falling back
+// to default chats
+func (s *TelegramNotifier) chats(event types.EventNotification) []string {
+	var chats []string
+	for _, c := range event.Channels {
+		if chatIDPattern.MatchString(c) {
+			chats = append(chats, c)
+		}
+	}
+
+	if len(chats) == 0 {
+		chats = s.chatIDs
+	}
+
+	return chats
+}
+
+type sendMessageRequest struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+type sendMessageResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// markdownEscaper - characters with a meaning in Telegram (legacy) Markdown
+var markdownEscaper = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+
+// codeEscaper - code entities only end on backticks
+var codeEscaper = strings.NewReplacer("`", "'")
+
+// newText - level and title in bold, resource and versions as code
+func newText(event types.EventNotification) string {
+	title := event.Name
+	if title == "" {
+		title = event.Type.String()
+	}
+
+	lines := []string{
+		fmt.Sprintf("*%s* %s", markdownEscaper.Replace(strings.ToUpper(event.Level.String())), markdownEscaper.Replace(title)),
+		markdownEscaper.Replace(event.Message),
+	}
+
+	resource := event.Metadata["name"]
+	if ns := event.Metadata["namespace"]; ns != "" && resource != "" {
+		resource = ns + "/" + resource
+	}
+	if event.ResourceKind != "" && resource != "" {
+		resource = event.ResourceKind + " " + resource
+	}
+	if resource != "" {
+		lines = append(lines, "`"+codeEscaper.Replace(resource)+"`")
+	}
+
+	current, new := event.Metadata["current_version"], event.Metadata["new_version"]
+	if current != "" || new != "" {
+		lines = append(lines, "```\n"+codeEscaper.Replace(current)+" -> "+codeEscaper.Replace(new)+"\n```")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Send - sends notification to every chat
+func (s *TelegramNotifier) Send(event types.EventNotification) error {
+	text := newText(event)
+
+	for _, chatID := range s.chats(event) {
+		err := s.sendMessage(chatID, text)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":   err,
+				"chat_id": chatID,
+			}).Error("extension.notification.telegram: failed to send notification")
+		}
+	}
+
+	return nil
+}
+
+func (s *TelegramNotifier) sendMessage(chatID, text string) error {
+	bts, err := json.Marshal(&sendMessageRequest{
+		ChatID:                chatID,
+		Text:                  text,
+		ParseMode:             "Markdown",
+		DisableWebPagePreview: true,
+	})
+	if err != nil {
+		return fmt.Errorf("could not marshal: %s", err)
+	}
+
+	resp, err := s.client.Post(s.apiURL+"/bot"+s.token+"/sendMessage", "application/json", bytes.NewBuffer(bts))
+	if err != nil {
+		// error contains the URL with bot token
+		return fmt.Errorf("request failed: %s", strings.Replace(err.Error(), s.token, "<token>", -1))
+	}
+	defer resp.Body.Close()
+
+	var result sendMessageResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return fmt.Errorf("unexpected response, status %d: %s", resp.StatusCode, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram API error: %s", result.Description)
+	}
+
+	return nil
+}
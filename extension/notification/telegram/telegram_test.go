@@ -0,0 +1,117 @@
+package telegram
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/types"
+)
+
+func TestTelegramRequest(t *testing.T) {
+	var received []sendMessageRequest
+	handler := func(resp http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/botsecret-token/sendMessage" {
+			t.Errorf("unexpected path: %s", req.URL.Path)
+		}
+
+		var msg sendMessageRequest
+		if err := json.NewDecoder(req.Body).Decode(&msg); err != nil {
+			t.Errorf("failed to decode body: %s", err)
+		}
+		received = append(received, msg)
+
+		resp.Write([]byte(`{"ok": true}`))
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	s := &TelegramNotifier{
+		apiURL:  ts.URL,
+		token:   "secret-token",
+		chatIDs: []string{"-1001234567890"},
+		client:  &http.Client{},
+	}
+
+	s.Send(types.EventNotification{
+		ResourceKind: "deployment",
+		Name:         "update resource",
+		Message:      "Successfully updated deployment default/my_app 1.0.0->1.1.0 (gcr.io/v2-namespace/my_app:1.1.0)",
+		CreatedAt:    time.Now(),
+		Type:         types.NotificationDeploymentUpdate,
+		Level:        types.LevelSuccess,
+		Metadata: map[string]string{
+			"namespace":       "default",
+			"name":            "my_app",
+			"current_version": "1.0.0",
+			"new_version":     "1.1.0",
+		},
+	})
+
+	if len(received) != 1 {
+		t.Fatalf("expected 1 message, got: %d", len(received))
+	}
+
+	msg := received[0]
+	if msg.ChatID != "-1001234567890" {
+		t.Errorf("unexpected chat: %s", msg.ChatID)
+	}
+	if msg.ParseMode != "Markdown" {
+		t.Errorf("unexpected parse mode: %s", msg.ParseMode)
+	}
+
+	expected := "*SUCCESS* update resource\n" +
+		"Successfully updated deployment default/my\\_app 1.0.0->1.1.0 (gcr.io/v2-namespace/my\\_app:1.1.0)\n" +
+		"`deployment default/my_app`\n" +
+		"```\n1.0.0 -> 1.1.0\n```"
+	if msg.Text != expected {
+		t.Errorf("unexpected text:\n%s\nexpected:\n%s", msg.Text, expected)
+	}
+}
+
+func TestTelegramChannels(t *testing.T) {
+	var chats []string
+	ts := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		var msg sendMessageRequest
+		json.NewDecoder(req.Body).Decode(&msg)
+		chats = append(chats, msg.ChatID)
+		resp.Write([]byte(`{"ok": true}`))
+	}))
+	defer ts.Close()
+
+	s := &TelegramNotifier{
+		apiURL:  ts.URL,
+		token:   "secret-token",
+		chatIDs: []string{"-1001234567890"},
+		client:  &http.Client{},
+	}
+
+	// slack channel names are ignored, chat IDs override default chat
+	s.Send(types.EventNotification{
+		Name:     "update resource",
+		Channels: []string{"general", "@bow_releases", "123456"},
+	})
+
+	if strings.Join(chats, ",") != "@bow_releases,123456" {
+		t.Errorf("unexpected chats: %v", chats)
+	}
+}
+
+func TestTelegramAPIError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		resp.WriteHeader(http.StatusBadRequest)
+		resp.Write([]byte(`{"ok": false, "description": "Bad Request: chat not found"}`))
+	}))
+	defer ts.Close()
+
+	s := &TelegramNotifier{apiURL: ts.URL, token: "secret-token", client: &http.Client{}}
+
+	err := s.sendMessage("1", "text")
+	if err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Errorf("expected chat not found error, got: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package argocd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client - ArgoCD REST API client, authenticates with an API token of an
+// account allowed to sync applications
+type Client struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewClient - create new ArgoCD client for the API server url, ie: https://argocd.example.com
+func NewClient(serverURL, token string) *Client {
+	return &Client{
+		url:    strings.TrimSuffix(serverURL, "/"),
+		token:  token,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type syncRequest struct {
+	Strategy syncStrategy `json:"strategy"`
+}
+
+type syncStrategy struct {
+	Apply applyStrategy `json:"apply"`
+}
+
+type applyStrategy struct {
+	Force bool `json:"force"`
+}
+
+// Sync - syncs application with force (same as argocd app sync --force), apps
+// in other namespaces than the ArgoCD one are referenced as namespace/name
+func (c *Client) Sync(app string) error {
+	name, query := app, ""
+	if idx := strings.Index(app, "/"); idx >= 0 {
+		name, query = app[idx+1:], "?appNamespace="+url.QueryEscape(app[:idx])
+	}
+
+	body, err := json.Marshal(syncRequest{Strategy: syncStrategy{Apply: applyStrategy{Force: true}}})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/v1/applications/%s/sync%s", c.url, url.PathEscape(name), query), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("failed to sync application %s, status code %d: %s", app, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package argocd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSync(t *testing.T) {
+	var (
+		path, query, auth string
+		req               syncRequest
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, query, auth = r.URL.Path, r.URL.RawQuery, r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path == "/api/v1/applications/missing/sync" {
+			http.Error(w, `{"error":"application not found"}`, http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL+"/", "secret-token")
+
+	err := client.Sync("guestbook")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if path != "/api/v1/applications/guestbook/sync" || query != "" {
+		t.Errorf("unexpected request: %s?%s", path, query)
+	}
+	if auth != "Bearer secret-token" {
+		t.Errorf("unexpected authorization header: %s", auth)
+	}
+	if !req.Strategy.Apply.Force {
+		t.Errorf("expected forced sync")
+	}
+
+	err = client.Sync("team-a/guestbook")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if path != "/api/v1/applications/guestbook/sync" || query != "appNamespace=team-a" {
+		t.Errorf("unexpected request: %s?%s", path, query)
+	}
+
+	if err := client.Sync("missing"); err == nil {
+		t.Errorf("expected error for missing application")
+	}
+}
@@ -0,0 +1,71 @@
+package gitrepo
+
+import (
+	"regexp"
+	"strings"
+)
+
+// kustomizationFiles - file names kustomize looks for
+var kustomizationFiles = map[string]bool{
+	"kustomization.yaml": true,
+	"kustomization.yml":  true,
+	"Kustomization":      true,
+}
+
+var kustomizeImageField = regexp.MustCompile(`^\s*(?:-\s+)?(name|newName|newTag):\s*["']?([^"'\s]+)["']?\s*$`)
+
+type kustomizeImage struct {
+	name    string
+	newName string
+	tag     string
+	tagLine int
+}
+
+// replaceKustomizeTag - updates newTag of kustomization images entries that point to the
+// repository, ie: "- name: app\n  newName: gcr.io/team/app\n  newTag: 1.0.0". Entries
+// without newName point to the repository in their name
+func replaceKustomizeTag(content, repository, oldTag, newTag string) string {
+	lines := strings.Split(content, "\n")
+
+	var (
+		images  []*kustomizeImage
+		current *kustomizeImage
+	)
+	for i, line := range lines {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "- ") {
+			current = &kustomizeImage{tagLine: -1}
+			images = append(images, current)
+		}
+		if current == nil {
+			continue
+		}
+		m := kustomizeImageField.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		switch m[1] {
+		case "name":
+			current.name = m[2]
+		case "newName":
+			current.newName = m[2]
+		case "newTag":
+			current.tag = m[2]
+			current.tagLine = i
+		}
+	}
+
+	for _, img := range images {
+		target := img.newName
+		if target == "" {
+			target = img.name
+		}
+		if target != repository || img.tagLine < 0 || img.tag != oldTag {
+			continue
+		}
+		line := lines[img.tagLine]
+		idx := strings.Index(line, ":")
+		lines[img.tagLine] = line[:idx+1] + strings.Replace(line[idx+1:], oldTag, newTag, 1)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,49 @@
+package gitrepo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReplaceKustomizeTag(t *testing.T) {
+	content := `resources:
+- deployment.yaml
+images:
+- name: app
+  newName: gcr.io/team/app
+  newTag: 1.0.0
+- name: gcr.io/team/worker
+  newTag: "1.0.0"
+- name: other
+  newName: gcr.io/team/other
+  newTag: 1.0.0
+`
+
+	got := replaceKustomizeTag(content, "gcr.io/team/app", "1.0.0", "1.1.0")
+	want := `resources:
+- deployment.yaml
+images:
+- name: app
+  newName: gcr.io/team/app
+  newTag: 1.1.0
+- name: gcr.io/team/worker
+  newTag: "1.0.0"
+- name: other
+  newName: gcr.io/team/other
+  newTag: 1.0.0
+`
+	if got != want {
+		t.Errorf("unexpected kustomization:\n%s", got)
+	}
+
+	got = replaceKustomizeTag(content, "gcr.io/team/worker", "1.0.0", "1.1.0")
+	if !strings.Contains(got, `newTag: "1.1.0"`) {
+		t.Errorf("expected quoted tag of entry without newName to be updated:\n%s", got)
+	}
+
+	// tag doesn't match, kustomization already points elsewhere
+	got = replaceKustomizeTag(content, "gcr.io/team/app", "0.9.0", "1.1.0")
+	if got != content {
+		t.Errorf("expected kustomization to stay unchanged:\n%s", got)
+	}
+}
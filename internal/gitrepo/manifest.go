@@ -0,0 +1,308 @@
+package gitrepo
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// manifests are edited line by line instead of being decoded and encoded
+// again, chart templates aren't valid yaml until they are rendered and
+// users' formatting and comments are kept this way
+
+// manifestExtensions - files that are searched for resource manifests
+var manifestExtensions = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+}
+
+func isManifest(path string) bool {
+	return manifestExtensions[filepath.Ext(path)]
+}
+
+var yamlField = regexp.MustCompile(`^(-\s+)?["']?([^"':\s][^"':]*?)["']?:(?:\s+(.*?))?\s*$`)
+
+// field - key and value of the yaml mapping line, item is true for
+// lines that start a list item, ie: "- name: app"
+func field(line string) (key, value string, item, ok bool) {
+	m := yamlField.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return "", "", false, false
+	}
+	return m[2], m[3], m[1] != "", true
+}
+
+// skipped - lines that don't hold fields, template directives included
+func skipped(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "{{")
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func unquote(value string) string {
+	if s, err := strconv.Unquote(value); err == nil {
+		return s
+	}
+	return strings.Trim(value, `'"`)
+}
+
+// blockEnd - end of the lines nested under the field at line i, list
+// items on the same indentation belong to the field too
+func blockEnd(lines []string, i int) int {
+	indent := indentOf(lines[i])
+	for j := i + 1; j < len(lines); j++ {
+		if skipped(lines[j]) {
+			continue
+		}
+		ind := indentOf(lines[j])
+		if ind > indent {
+			continue
+		}
+		if _, _, item, _ := field(lines[j]); ind == indent && item && !strings.HasPrefix(strings.TrimSpace(lines[i]), "- ") {
+			continue
+		}
+		return j
+	}
+	return len(lines)
+}
+
+// lastLine - last line in [start, end) that holds a field, start - 1 if there is none
+func lastLine(lines []string, start, end int) int {
+	for j := end - 1; j >= start; j-- {
+		if !skipped(lines[j]) {
+			return j
+		}
+	}
+	return start - 1
+}
+
+// childIndent - indentation of the fields nested under the field at line i
+func childIndent(lines []string, i int) int {
+	end := blockEnd(lines, i)
+	for j := i + 1; j < end; j++ {
+		if !skipped(lines[j]) {
+			return indentOf(lines[j])
+		}
+	}
+	return indentOf(lines[i]) + 2
+}
+
+// child - line of the key among mapping fields nested directly under the
+// field at line parent, parent -1 searches top level fields of the document
+func child(lines []string, parent int, key string) int {
+	start, end, indent := 0, len(lines), 0
+	if parent >= 0 {
+		start, end, indent = parent+1, blockEnd(lines, parent), childIndent(lines, parent)
+	}
+	for j := start; j < end; j++ {
+		if skipped(lines[j]) || indentOf(lines[j]) != indent {
+			continue
+		}
+		if k, _, item, ok := field(lines[j]); ok && !item && k == key {
+			return j
+		}
+	}
+	return -1
+}
+
+// fieldPath - line of the last key, -1 when any of the keys is missing
+func fieldPath(lines []string, keys ...string) int {
+	line := -1
+	for _, key := range keys {
+		line = child(lines, line, key)
+		if line < 0 {
+			return -1
+		}
+	}
+	return line
+}
+
+// ensureChild - line of the key nested under the field at line parent, the key
+// is added as the first nested field when it's missing. Lines are returned as
+// they might have changed, line is -1 when the parent holds an inline value
+func ensureChild(lines []string, parent int, key string) ([]string, int) {
+	if line := child(lines, parent, key); line >= 0 {
+		return lines, line
+	}
+	k, value, _, _ := field(lines[parent])
+	switch value {
+	case "":
+	case "{}":
+		lines[parent] = lines[parent][:strings.Index(lines[parent], k)] + k + ":"
+	default:
+		return lines, -1
+	}
+
+	added := strings.Repeat(" ", childIndent(lines, parent)) + key + ":"
+	lines = insertLine(lines, parent+1, added)
+	return lines, parent + 1
+}
+
+func insertLine(lines []string, i int, line string) []string {
+	lines = append(lines, "")
+	copy(lines[i+1:], lines[i:])
+	lines[i] = line
+	return lines
+}
+
+func removeLine(lines []string, i int) []string {
+	return append(lines[:i], lines[i+1:]...)
+}
+
+// documents - yaml documents of the manifest
+func documents(content string) [][]string {
+	var docs [][]string
+	var doc []string
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "---") {
+			docs = append(docs, doc)
+			doc = []string{line}
+			continue
+		}
+		doc = append(doc, line)
+	}
+	return append(docs, doc)
+}
+
+func joinDocuments(docs [][]string) string {
+	var lines []string
+	for _, doc := range docs {
+		lines = append(lines, doc...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isResource - whether the document holds the resource, kind is compared case
+// insensitively as resource kinds are lower case, ie: deployment
+func isResource(doc []string, kind, name string) bool {
+	kindLine := fieldPath(doc, "kind")
+	nameLine := fieldPath(doc, "metadata", "name")
+	if kindLine < 0 || nameLine < 0 {
+		return false
+	}
+	_, k, _, _ := field(doc[kindLine])
+	_, n, _, _ := field(doc[nameLine])
+	return strings.EqualFold(unquote(k), kind) && unquote(n) == name
+}
+
+// specTemplate - keys leading to the template that holds spec annotations
+// of the resource kind, same as GenericResource.GetSpecAnnotations
+func specTemplate(kind string) []string {
+	if strings.EqualFold(kind, "cronjob") {
+		return []string{"spec", "jobTemplate"}
+	}
+	return []string{"spec", "template"}
+}
+
+// editResources - applies edit to the documents of the resource in the manifest
+func editResources(content, kind, name string, edit func(doc []string) []string) string {
+	docs := documents(content)
+	for i, doc := range docs {
+		if isResource(doc, kind, name) {
+			docs[i] = edit(doc)
+		}
+	}
+	return joinDocuments(docs)
+}
+
+// setSpecAnnotations - sets spec template annotations of the resource in the manifest,
+// annotations with empty values are removed
+func setSpecAnnotations(content, kind, name string, annotations map[string]string) string {
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return editResources(content, kind, name, func(doc []string) []string {
+		template := fieldPath(doc, specTemplate(kind)...)
+		if template < 0 {
+			return doc
+		}
+		doc, metadata := ensureChild(doc, template, "metadata")
+		if metadata < 0 {
+			return doc
+		}
+		doc, annotationsLine := ensureChild(doc, metadata, "annotations")
+		if annotationsLine < 0 {
+			return doc
+		}
+
+		for _, key := range keys {
+			value := annotations[key]
+			line := child(doc, annotationsLine, key)
+			switch {
+			case line >= 0 && value == "":
+				doc = removeLine(doc, line)
+			case line >= 0:
+				doc[line] = strings.Repeat(" ", indentOf(doc[line])) + key + ": " + strconv.Quote(value)
+			case value != "":
+				indent := strings.Repeat(" ", childIndent(doc, annotationsLine))
+				last := lastLine(doc, annotationsLine+1, blockEnd(doc, annotationsLine))
+				doc = insertLine(doc, last+1, indent+key+": "+strconv.Quote(value))
+			}
+		}
+		return doc
+	})
+}
+
+// setImagePullPolicy - sets image pull policy of the resource containers running
+// the image in the manifest
+func setImagePullPolicy(content, kind, name, image, pullPolicy string) string {
+	return editResources(content, kind, name, func(doc []string) []string {
+		for i := 0; i < len(doc); i++ {
+			key, value, item, ok := field(doc[i])
+			if !ok || key != "image" || unquote(value) != image {
+				continue
+			}
+			// container fields are indented like the image field
+			indent := strings.Index(doc[i], key)
+			if line := containerField(doc, i, indent, item, "imagePullPolicy"); line >= 0 {
+				doc[line] = doc[line][:strings.Index(doc[line], "imagePullPolicy")] + "imagePullPolicy: " + pullPolicy
+				continue
+			}
+			doc = insertLine(doc, i+1, strings.Repeat(" ", indent)+"imagePullPolicy: "+pullPolicy)
+		}
+		return doc
+	})
+}
+
+// containerField - line of the key among fields of the container list item that
+// holds the field at line i, fields of the item are indented by indent
+func containerField(doc []string, i, indent int, item bool, key string) int {
+	matches := func(j int) bool {
+		k, _, _, ok := field(doc[j])
+		return ok && k == key && strings.Index(doc[j], k) == indent
+	}
+	for j := i + 1; j < len(doc); j++ {
+		if skipped(doc[j]) || indentOf(doc[j]) > indent {
+			continue
+		}
+		if _, _, itemStart, _ := field(doc[j]); indentOf(doc[j]) < indent || itemStart {
+			break
+		}
+		if matches(j) {
+			return j
+		}
+	}
+	if item {
+		return -1
+	}
+	for j := i - 1; j >= 0; j-- {
+		if skipped(doc[j]) || indentOf(doc[j]) > indent {
+			continue
+		}
+		if matches(j) {
+			return j
+		}
+		if indentOf(doc[j]) < indent {
+			break
+		}
+	}
+	return -1
+}
@@ -0,0 +1,196 @@
+package gitrepo
+
+import (
+	"testing"
+
+	"github.com/alwinius/bow/internal/k8s"
+)
+
+func specAnnotations(t *testing.T, manifest string) map[string]string {
+	obj, err := yamlToGenericResource(manifest)
+	if err != nil {
+		t.Fatalf("failed to read edited manifest: %s\n%s", err, manifest)
+	}
+	gr, err := k8s.NewGenericResource(obj)
+	if err != nil {
+		t.Fatalf("failed to create generic resource: %s", err)
+	}
+	return gr.GetSpecAnnotations()
+}
+
+func TestSetSpecAnnotations(t *testing.T) {
+	got := setSpecAnnotations(deployment, "deployment", "wd", map[string]string{"bow/resolved-digest": "sha256:abc"})
+	want := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: wd
+  namespace: default
+spec:
+  template:
+    metadata:
+      annotations:
+        bow/resolved-digest: "sha256:abc"
+    spec:
+      containers:
+      - name: wd
+        image: gcr.io/v2-namespace/wd:1.1.0
+`
+	if got != want {
+		t.Errorf("unexpected manifest:\n%s\nwant:\n%s", got, want)
+	}
+	if digest := specAnnotations(t, got)["bow/resolved-digest"]; digest != "sha256:abc" {
+		t.Errorf("unexpected resolved digest: %s", digest)
+	}
+
+	// digest is replaced and removed
+	got = setSpecAnnotations(got, "deployment", "wd", map[string]string{"bow/resolved-digest": "sha256:def"})
+	if digest := specAnnotations(t, got)["bow/resolved-digest"]; digest != "sha256:def" {
+		t.Errorf("unexpected replaced digest: %s", digest)
+	}
+	got = setSpecAnnotations(got, "deployment", "wd", map[string]string{"bow/resolved-digest": ""})
+	if _, ok := specAnnotations(t, got)["bow/resolved-digest"]; ok {
+		t.Errorf("expected resolved digest to be removed:\n%s", got)
+	}
+}
+
+func TestSetSpecAnnotationsCronJob(t *testing.T) {
+	got := setSpecAnnotations(cronJobV1beta1, "cronjob", "cleanup", map[string]string{"bow/update-time": "now"})
+	if updated := specAnnotations(t, got)["bow/update-time"]; updated != "now" {
+		t.Errorf("unexpected update time: %s\n%s", updated, got)
+	}
+}
+
+func TestSetSpecAnnotationsTemplate(t *testing.T) {
+	content := `apiVersion: v1
+kind: Service
+metadata:
+  name: wd
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: wd
+spec:
+  template:
+    metadata:
+      annotations:
+        # set by users
+        prometheus.io/scrape: "true"
+        {{- with .Values.podAnnotations }}
+        {{- toYaml . | nindent 8 }}
+        {{- end }}
+        bow/update-time: 'yesterday'
+    spec:
+      containers:
+      - name: wd
+        image: {{ .Values.image }}
+`
+
+	got := setSpecAnnotations(content, "deployment", "wd", map[string]string{
+		"bow/update-time":     "today",
+		"bow/resolved-digest": "sha256:abc",
+	})
+	want := `apiVersion: v1
+kind: Service
+metadata:
+  name: wd
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: wd
+spec:
+  template:
+    metadata:
+      annotations:
+        # set by users
+        prometheus.io/scrape: "true"
+        {{- with .Values.podAnnotations }}
+        {{- toYaml . | nindent 8 }}
+        {{- end }}
+        bow/update-time: "today"
+        bow/resolved-digest: "sha256:abc"
+    spec:
+      containers:
+      - name: wd
+        image: {{ .Values.image }}
+`
+	if got != want {
+		t.Errorf("unexpected manifest:\n%s\nwant:\n%s", got, want)
+	}
+
+	if unchanged := setSpecAnnotations(content, "deployment", "other", map[string]string{"bow/update-time": "today"}); unchanged != content {
+		t.Errorf("expected manifests of other resources to be left as they are:\n%s", unchanged)
+	}
+}
+
+func TestSetImagePullPolicy(t *testing.T) {
+	content := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: wd
+spec:
+  template:
+    spec:
+      initContainers:
+      - image: gcr.io/v2-namespace/wd:1.2.0
+        name: migrate
+      containers:
+      - name: wd
+        imagePullPolicy: IfNotPresent
+        image: "gcr.io/v2-namespace/wd:1.2.0"
+        ports:
+        - containerPort: 80
+      - name: sidecar
+        image: gcr.io/v2-namespace/sidecar:1.0.0
+`
+
+	got := setImagePullPolicy(content, "deployment", "wd", "gcr.io/v2-namespace/wd:1.2.0", "Always")
+	want := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: wd
+spec:
+  template:
+    spec:
+      initContainers:
+      - image: gcr.io/v2-namespace/wd:1.2.0
+        imagePullPolicy: Always
+        name: migrate
+      containers:
+      - name: wd
+        imagePullPolicy: Always
+        image: "gcr.io/v2-namespace/wd:1.2.0"
+        ports:
+        - containerPort: 80
+      - name: sidecar
+        image: gcr.io/v2-namespace/sidecar:1.0.0
+`
+	if got != want {
+		t.Errorf("unexpected manifest:\n%s\nwant:\n%s", got, want)
+	}
+
+	obj, err := yamlToGenericResource(got)
+	if err != nil {
+		t.Fatalf("failed to read edited manifest: %s", err)
+	}
+	gr, err := k8s.NewGenericResource(obj)
+	if err != nil {
+		t.Fatalf("failed to create generic resource: %s", err)
+	}
+	for _, c := range append(gr.Containers(), gr.InitContainers()...) {
+		want := "Always"
+		if c.Name == "sidecar" {
+			want = ""
+		}
+		if string(c.ImagePullPolicy) != want {
+			t.Errorf("unexpected image pull policy of %s: '%s'", c.Name, c.ImagePullPolicy)
+		}
+	}
+}
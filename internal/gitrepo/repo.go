@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/alwinius/bow/provider/helm"
 	"github.com/alwinius/bow/util/image"
+	"github.com/ghodss/yaml"
 	"github.com/sirupsen/logrus"
 	cryptossh "golang.org/x/crypto/ssh"
 	"gopkg.in/src-d/go-git.v4"
@@ -231,13 +232,25 @@ func (r *Repo) newClone() (*git.Repository, error) {
 	return c, err
 }
 
+// ReplacedImage - image that GrepAndReplace writes in place of the old image
+func ReplacedImage(oldImage string, newTag string) string {
+	ref, err := image.Parse(oldImage)
+	if err != nil {
+		return oldImage
+	}
+	if ref.Registry() == image.DefaultRegistryHostname {
+		return fmt.Sprintf("%s:%s", ref.ShortName(), newTag)
+	}
+	return fmt.Sprintf("%s:%s", ref.Repository(), newTag)
+}
+
 func (r *Repo) GrepAndReplace(oldImage string, newTag string) {
 	r.init()
 	r.fileAccessLock.Lock()
 	defer r.fileAccessLock.Unlock()
-	ref, err := image.Parse(oldImage)
+	newImage := ReplacedImage(oldImage, newTag)
 
-	err = filepath.Walk(r.LocalPath,
+	err := filepath.Walk(r.LocalPath,
 		func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
@@ -249,12 +262,12 @@ func (r *Repo) GrepAndReplace(oldImage string, newTag string) {
 				}
 				defer reader.Close()
 
-				var changed string
 				b, err := ioutil.ReadAll(reader)
-				if ref.Registry() == image.DefaultRegistryHostname {
-					changed = strings.ReplaceAll(string(b), oldImage, fmt.Sprintf("%s:%s", ref.ShortName(), newTag))
-				} else {
-					changed = strings.ReplaceAll(string(b), oldImage, fmt.Sprintf("%s:%s", ref.Repository(), newTag))
+				changed := strings.ReplaceAll(string(b), oldImage, newImage)
+				if kustomizationFiles[info.Name()] && !strings.Contains(newTag, "@") {
+					// kustomize keeps name and tag of images separately
+					repository, oldTag := image.SplitTag(oldImage)
+					changed = replaceKustomizeTag(changed, repository, oldTag, newTag)
 				}
 
 				if changed != string(b) {
@@ -274,3 +287,108 @@ func (r *Repo) GrepAndReplace(oldImage string, newTag string) {
 		logrus.Error(err)
 	}
 }
+
+// SetSpecAnnotations - sets spec template annotations of the resource in the chart
+// manifests, annotations with empty values are removed
+func (r *Repo) SetSpecAnnotations(kind, name string, annotations map[string]string) {
+	r.editManifests(func(content string) string {
+		return setSpecAnnotations(content, kind, name, annotations)
+	})
+}
+
+// SetImagePullPolicy - sets image pull policy of the resource containers running
+// the image in the chart manifests
+func (r *Repo) SetImagePullPolicy(kind, name, image, pullPolicy string) {
+	r.editManifests(func(content string) string {
+		return setImagePullPolicy(content, kind, name, image, pullPolicy)
+	})
+}
+
+// SaveConfigMap - writes config map manifest to the chart templates, existing
+// manifest of the config map is replaced
+func (r *Repo) SaveConfigMap(namespace, name string, data map[string]string) {
+	r.init()
+	r.fileAccessLock.Lock()
+	defer r.fileAccessLock.Unlock()
+
+	content, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]string{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"data": data,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+			"name":  name,
+		}).Error("repo.SaveConfigMap: failed to encode config map")
+		return
+	}
+
+	dir := filepath.Join(r.LocalPath, r.ChartPath)
+	if info, err := os.Stat(filepath.Join(dir, "templates")); err == nil && info.IsDir() {
+		dir = filepath.Join(dir, "templates")
+	}
+	path := filepath.Join(dir, name+".yaml")
+	err = ioutil.WriteFile(path, content, 0644)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+			"path":  path,
+		}).Error("repo.SaveConfigMap: failed to write config map")
+		return
+	}
+
+	// new files aren't committed unless they are added to the worktree
+	w, err := r.repository.Worktree()
+	if err == nil {
+		var rel string
+		rel, err = filepath.Rel(r.LocalPath, path)
+		if err == nil {
+			_, err = w.Add(filepath.ToSlash(rel))
+		}
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+			"path":  path,
+		}).Error("repo.SaveConfigMap: failed to add config map to the worktree")
+	}
+}
+
+// editManifests - applies edit to the manifest files of the chart, changed
+// files are written back
+func (r *Repo) editManifests(edit func(content string) string) {
+	r.init()
+	r.fileAccessLock.Lock()
+	defer r.fileAccessLock.Unlock()
+
+	err := filepath.Walk(filepath.Join(r.LocalPath, r.ChartPath),
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !isManifest(path) {
+				return nil
+			}
+
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			changed := edit(string(b))
+			if changed == string(b) {
+				return nil
+			}
+			return ioutil.WriteFile(path, []byte(changed), info.Mode())
+		})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+			"path":  r.ChartPath,
+		}).Error("repo.editManifests: failed to edit manifests")
+	}
+}
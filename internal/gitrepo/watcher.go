@@ -1,8 +1,12 @@
 package gitrepo
 
 import (
+	"github.com/alwinius/bow/internal/k8s"
 	"github.com/alwinius/bow/internal/workgroup"
+	"github.com/ghodss/yaml"
 	"github.com/sirupsen/logrus"
+	v1beta1 "k8s.io/api/batch/v1beta1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/cache"
@@ -117,17 +121,47 @@ func (b *buffer) send(ev interface{}) {
 	}
 }
 
+// acceptedK8sTypes - kinds of resources that can be tracked and updated
+var acceptedK8sTypes = regexp.MustCompile(`^(Deployment|StatefulSet|DaemonSet|CronJob)$`)
+
 func yamlToGenericResource(r string) (runtime.Object, error) {
-	acceptedK8sTypes := regexp.MustCompile(`(Deployment|StatefulSet|Cronjob)`) // TODO: fill properly or remove
 	decode := scheme.Codecs.UniversalDeserializer().Decode
 	obj, groupVersionKind, err := decode([]byte(r), nil, nil)
 	if err != nil {
+		// batch/v1 CronJobs are not known to the client scheme, their spec is the
+		// same as batch/v1beta1 so they can be read as such
+		var typeMeta meta_v1.TypeMeta
+		if yaml.Unmarshal([]byte(r), &typeMeta) == nil {
+			switch {
+			case typeMeta.APIVersion == "batch/v1" && typeMeta.Kind == "CronJob":
+				return yamlToCronJob(r)
+			// Argo Rollouts are custom resources
+			case typeMeta.APIVersion == k8s.RolloutAPIVersion && typeMeta.Kind == k8s.RolloutKind:
+				return yamlToRollout(r)
+			}
+		}
 		return nil, err
 	}
 	if !acceptedK8sTypes.MatchString(groupVersionKind.Kind) {
 		return nil, nil
-	} else {
-		return obj, nil
 	}
+	return obj, nil
+}
+
+func yamlToCronJob(r string) (runtime.Object, error) {
+	var cronJob v1beta1.CronJob
+	err := yaml.Unmarshal([]byte(r), &cronJob)
+	if err != nil {
+		return nil, err
+	}
+	return &cronJob, nil
+}
 
+func yamlToRollout(r string) (runtime.Object, error) {
+	var rollout k8s.Rollout
+	err := yaml.Unmarshal([]byte(r), &rollout)
+	if err != nil {
+		return nil, err
+	}
+	return &rollout, nil
 }
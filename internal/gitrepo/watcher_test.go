@@ -0,0 +1,151 @@
+package gitrepo
+
+import (
+	"testing"
+
+	"github.com/alwinius/bow/internal/k8s"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	v1beta1 "k8s.io/api/batch/v1beta1"
+)
+
+const cronJobV1 = `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: cleanup
+  namespace: jobs
+  labels:
+    bow/policy: minor
+spec:
+  schedule: "*/5 * * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: cleanup
+            image: gcr.io/v2-namespace/cleanup:1.1.0
+          restartPolicy: OnFailure
+`
+
+const cronJobV1beta1 = `apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: cleanup
+  namespace: jobs
+spec:
+  schedule: "*/5 * * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: cleanup
+            image: gcr.io/v2-namespace/cleanup:1.1.0
+          restartPolicy: OnFailure
+`
+
+const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: wd
+  namespace: default
+spec:
+  template:
+    spec:
+      containers:
+      - name: wd
+        image: gcr.io/v2-namespace/wd:1.1.0
+`
+
+const service = `apiVersion: v1
+kind: Service
+metadata:
+  name: wd
+spec:
+  ports:
+  - port: 80
+`
+
+const rollout = `apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: canary
+  namespace: apps
+  labels:
+    bow/policy: minor
+spec:
+  replicas: 5
+  strategy:
+    canary:
+      steps:
+      - setWeight: 20
+      - pause: {}
+  template:
+    spec:
+      containers:
+      - name: app
+        image: gcr.io/v2-namespace/hello-world:1.1.0
+`
+
+func TestYamlToGenericResourceRollout(t *testing.T) {
+	obj, err := yamlToGenericResource(rollout)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	r, ok := obj.(*k8s.Rollout)
+	if !ok {
+		t.Fatalf("expected rollout, got: %T", obj)
+	}
+
+	if r.Name != "canary" || r.Namespace != "apps" || r.Labels["bow/policy"] != "minor" {
+		t.Errorf("unexpected rollout: %s/%s %v", r.Namespace, r.Name, r.Labels)
+	}
+
+	containers := r.Spec.Template.Spec.Containers
+	if len(containers) != 1 || containers[0].Image != "gcr.io/v2-namespace/hello-world:1.1.0" {
+		t.Errorf("unexpected containers: %v", containers)
+	}
+}
+
+func TestYamlToGenericResourceCronJob(t *testing.T) {
+	for _, manifest := range []string{cronJobV1, cronJobV1beta1} {
+		obj, err := yamlToGenericResource(manifest)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		cronJob, ok := obj.(*v1beta1.CronJob)
+		if !ok {
+			t.Fatalf("expected cronjob, got: %T", obj)
+		}
+
+		if cronJob.Name != "cleanup" || cronJob.Namespace != "jobs" {
+			t.Errorf("unexpected cronjob: %s/%s", cronJob.Namespace, cronJob.Name)
+		}
+
+		containers := cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers
+		if len(containers) != 1 || containers[0].Image != "gcr.io/v2-namespace/cleanup:1.1.0" {
+			t.Errorf("unexpected containers: %v", containers)
+		}
+	}
+}
+
+func TestYamlToGenericResource(t *testing.T) {
+	obj, err := yamlToGenericResource(deployment)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := obj.(*apps_v1.Deployment); !ok {
+		t.Errorf("expected deployment, got: %T", obj)
+	}
+
+	obj, err = yamlToGenericResource(service)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if obj != nil {
+		t.Errorf("expected service to be ignored, got: %T", obj)
+	}
+}
@@ -15,6 +15,15 @@ func getContainerImages(containers []core_v1.Container) []string {
 	return images
 }
 
+func containsImage(images []string, image string) bool {
+	for _, img := range images {
+		if img == image {
+			return true
+		}
+	}
+	return false
+}
+
 func getImagePullSecrets(imagePullSecrets []core_v1.LocalObjectReference) []string {
 	var secrets []string
 	for _, s := range imagePullSecrets {
@@ -61,3 +70,13 @@ func getCronJobIdentifier(s *v1beta1.CronJob) string {
 func updateCronJobContainer(s *v1beta1.CronJob, index int, image string) {
 	s.Spec.JobTemplate.Spec.Template.Spec.Containers[index].Image = image
 }
+
+// argo rollouts
+
+func getRolloutIdentifier(r *Rollout) string {
+	return "rollout/" + r.Namespace + "/" + r.Name
+}
+
+func updateRolloutContainer(r *Rollout, index int, image string) {
+	r.Spec.Template.Spec.Containers[index].Image = image
+}
@@ -0,0 +1,27 @@
+package k8s
+
+import (
+	apps_v1 "k8s.io/api/apps/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typed_apps_v1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+)
+
+// DeploymentGetter - gets current state of deployments running in the cluster
+type DeploymentGetter interface {
+	GetDeployment(namespace, name string) (*apps_v1.Deployment, error)
+}
+
+// ClientDeploymentGetter - gets deployments through the apps API
+type ClientDeploymentGetter struct {
+	client typed_apps_v1.DeploymentsGetter
+}
+
+// NewDeploymentGetter - create new deployment getter
+func NewDeploymentGetter(client typed_apps_v1.DeploymentsGetter) *ClientDeploymentGetter {
+	return &ClientDeploymentGetter{client: client}
+}
+
+// GetDeployment - get deployment together with its status
+func (g *ClientDeploymentGetter) GetDeployment(namespace, name string) (*apps_v1.Deployment, error) {
+	return g.client.Deployments(namespace).Get(name, meta_v1.GetOptions{})
+}
@@ -0,0 +1,31 @@
+package k8s
+
+import (
+	policy_v1beta1 "k8s.io/api/policy/v1beta1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typed_policy_v1beta1 "k8s.io/client-go/kubernetes/typed/policy/v1beta1"
+)
+
+// DisruptionBudgetLister - lists pod disruption budgets running in the cluster
+type DisruptionBudgetLister interface {
+	ListDisruptionBudgets(namespace string) ([]policy_v1beta1.PodDisruptionBudget, error)
+}
+
+// ClientDisruptionBudgetLister - lists pod disruption budgets through the policy API
+type ClientDisruptionBudgetLister struct {
+	client typed_policy_v1beta1.PodDisruptionBudgetsGetter
+}
+
+// NewDisruptionBudgetLister - create new pod disruption budget lister
+func NewDisruptionBudgetLister(client typed_policy_v1beta1.PodDisruptionBudgetsGetter) *ClientDisruptionBudgetLister {
+	return &ClientDisruptionBudgetLister{client: client}
+}
+
+// ListDisruptionBudgets - list pod disruption budgets together with their status
+func (l *ClientDisruptionBudgetLister) ListDisruptionBudgets(namespace string) ([]policy_v1beta1.PodDisruptionBudget, error) {
+	list, err := l.client.PodDisruptionBudgets(namespace).List(meta_v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
@@ -0,0 +1,111 @@
+package k8s
+
+import (
+	"fmt"
+	"time"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	"k8s.io/api/batch/v1beta1"
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	typed_core_v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// EventRecorder - records events about resources, has the same signature as
+// record.EventRecorder from client-go so either can be used
+type EventRecorder interface {
+	Event(object runtime.Object, eventtype, reason, message string)
+}
+
+// ClientEventRecorder - creates events through the core API
+type ClientEventRecorder struct {
+	client    typed_core_v1.EventsGetter
+	component string
+}
+
+// NewEventRecorder - create new event recorder, component is reported as event source
+func NewEventRecorder(client typed_core_v1.EventsGetter, component string) *ClientEventRecorder {
+	return &ClientEventRecorder{
+		client:    client,
+		component: component,
+	}
+}
+
+// Event - creates event for the object, failures are only logged as events
+// are informational
+func (r *ClientEventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	ref, err := objectReference(object)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":  err,
+			"reason": reason,
+		}).Error("k8s.ClientEventRecorder: failed to get object reference")
+		return
+	}
+
+	now := meta_v1.NewTime(time.Now())
+	event := &core_v1.Event{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", ref.Name, now.UnixNano()),
+			Namespace: ref.Namespace,
+		},
+		InvolvedObject: *ref,
+		Reason:         reason,
+		Message:        message,
+		Type:           eventtype,
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Source:         core_v1.EventSource{Component: r.component},
+	}
+
+	_, err = r.client.Events(ref.Namespace).Create(event)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":     err,
+			"reason":    reason,
+			"name":      ref.Name,
+			"namespace": ref.Namespace,
+		}).Error("k8s.ClientEventRecorder: failed to create event")
+	}
+}
+
+func objectReference(object runtime.Object) (*core_v1.ObjectReference, error) {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return nil, err
+	}
+
+	// typed objects usually come without type meta
+	gvk := object.GetObjectKind().GroupVersionKind()
+	apiVersion, kind := gvk.GroupVersion().String(), gvk.Kind
+	if kind == "" {
+		switch object.(type) {
+		case *apps_v1.Deployment:
+			apiVersion, kind = "apps/v1", "Deployment"
+		case *apps_v1.StatefulSet:
+			apiVersion, kind = "apps/v1", "StatefulSet"
+		case *apps_v1.DaemonSet:
+			apiVersion, kind = "apps/v1", "DaemonSet"
+		case *v1beta1.CronJob:
+			apiVersion, kind = "batch/v1beta1", "CronJob"
+		case *Rollout:
+			apiVersion, kind = RolloutAPIVersion, RolloutKind
+		default:
+			return nil, fmt.Errorf("unknown kind of object %T", object)
+		}
+	}
+
+	return &core_v1.ObjectReference{
+		APIVersion:      apiVersion,
+		Kind:            kind,
+		Name:            accessor.GetName(),
+		Namespace:       accessor.GetNamespace(),
+		UID:             accessor.GetUID(),
+		ResourceVersion: accessor.GetResourceVersion(),
+	}, nil
+}
@@ -0,0 +1,60 @@
+package k8s
+
+import (
+	"testing"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typed_core_v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+type fakeEvents struct {
+	typed_core_v1.EventInterface
+	created []*core_v1.Event
+}
+
+func (e *fakeEvents) Create(event *core_v1.Event) (*core_v1.Event, error) {
+	e.created = append(e.created, event)
+	return event, nil
+}
+
+type fakeEventsGetter struct {
+	namespaces map[string]*fakeEvents
+}
+
+func (g *fakeEventsGetter) Events(namespace string) typed_core_v1.EventInterface {
+	if _, ok := g.namespaces[namespace]; !ok {
+		g.namespaces[namespace] = &fakeEvents{}
+	}
+	return g.namespaces[namespace]
+}
+
+func TestEventRecorder(t *testing.T) {
+	getter := &fakeEventsGetter{namespaces: make(map[string]*fakeEvents)}
+	recorder := NewEventRecorder(getter, "bow")
+
+	recorder.Event(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "dep-1",
+			Namespace: "xxxx",
+			UID:       "1234",
+		},
+	}, core_v1.EventTypeNormal, "ImageUpdated", "Image updated gcr.io/v2-namespace/hello-world:1.1.1 -> gcr.io/v2-namespace/hello-world:1.1.2")
+
+	events := getter.namespaces["xxxx"]
+	if events == nil || len(events.created) != 1 {
+		t.Fatalf("expected 1 event to be created")
+	}
+
+	event := events.created[0]
+	if event.InvolvedObject.Kind != "Deployment" || event.InvolvedObject.Name != "dep-1" || event.InvolvedObject.UID != "1234" {
+		t.Errorf("unexpected involved object: %v", event.InvolvedObject)
+	}
+	if event.Type != core_v1.EventTypeNormal || event.Reason != "ImageUpdated" {
+		t.Errorf("unexpected event type or reason: %s %s", event.Type, event.Reason)
+	}
+	if event.Source.Component != "bow" {
+		t.Errorf("unexpected event source: %s", event.Source.Component)
+	}
+}
@@ -43,6 +43,8 @@ func NewGenericResource(obj interface{}) (*GenericResource, error) {
 		// ok
 	case *v1beta1.CronJob:
 		// ok
+	case *Rollout:
+		// ok
 	default:
 		return nil, fmt.Errorf("unsupported resource type: %v", reflect.TypeOf(obj).Kind())
 	}
@@ -81,6 +83,8 @@ func (r *GenericResource) DeepCopy() *GenericResource {
 		gr.obj = obj.DeepCopy()
 	case *v1beta1.CronJob:
 		gr.obj = obj.DeepCopy()
+	case *Rollout:
+		gr.obj = obj.DeepCopy()
 	}
 
 	return gr
@@ -97,6 +101,8 @@ func (r *GenericResource) GetIdentifier() string {
 		return getDaemonsetSetIdentifier(obj)
 	case *v1beta1.CronJob:
 		return getCronJobIdentifier(obj)
+	case *Rollout:
+		return getRolloutIdentifier(obj)
 	}
 	return ""
 }
@@ -112,6 +118,8 @@ func (r *GenericResource) GetName() string {
 		return obj.GetName()
 	case *v1beta1.CronJob:
 		return obj.GetName()
+	case *Rollout:
+		return obj.GetName()
 	}
 	return ""
 }
@@ -127,6 +135,8 @@ func (r *GenericResource) GetNamespace() string {
 		return obj.GetNamespace()
 	case *v1beta1.CronJob:
 		return obj.GetNamespace()
+	case *Rollout:
+		return obj.GetNamespace()
 	}
 	return ""
 }
@@ -142,6 +152,8 @@ func (r *GenericResource) Kind() string {
 		return "daemonset"
 	case *v1beta1.CronJob:
 		return "cronjob"
+	case *Rollout:
+		return "rollout"
 	}
 	return ""
 }
@@ -162,6 +174,8 @@ func (r *GenericResource) GetLabels() (labels map[string]string) {
 		return getOrInitialise(obj.GetLabels())
 	case *v1beta1.CronJob:
 		return getOrInitialise(obj.GetLabels())
+	case *Rollout:
+		return getOrInitialise(obj.GetLabels())
 	}
 	return
 }
@@ -177,6 +191,8 @@ func (r *GenericResource) SetLabels(labels map[string]string) {
 		obj.SetLabels(labels)
 	case *v1beta1.CronJob:
 		obj.SetLabels(labels)
+	case *Rollout:
+		obj.SetLabels(labels)
 	}
 }
 
@@ -191,6 +207,8 @@ func (r *GenericResource) GetSpecAnnotations() (annotations map[string]string) {
 		return getOrInitialise(obj.Spec.Template.GetAnnotations())
 	case *v1beta1.CronJob:
 		return getOrInitialise(obj.Spec.JobTemplate.GetAnnotations())
+	case *Rollout:
+		return getOrInitialise(obj.Spec.Template.GetAnnotations())
 	}
 	return
 }
@@ -206,9 +224,29 @@ func (r *GenericResource) SetSpecAnnotations(annotations map[string]string) {
 		obj.Spec.Template.SetAnnotations(annotations)
 	case *v1beta1.CronJob:
 		obj.Spec.JobTemplate.SetAnnotations(annotations)
+	case *Rollout:
+		obj.Spec.Template.SetAnnotations(annotations)
 	}
 }
 
+// GetSpecLabels - get resource spec template labels, these are the labels
+// of pods created from the template
+func (r *GenericResource) GetSpecLabels() (labels map[string]string) {
+	switch obj := r.obj.(type) {
+	case *apps_v1.Deployment:
+		return getOrInitialise(obj.Spec.Template.GetLabels())
+	case *apps_v1.StatefulSet:
+		return getOrInitialise(obj.Spec.Template.GetLabels())
+	case *apps_v1.DaemonSet:
+		return getOrInitialise(obj.Spec.Template.GetLabels())
+	case *v1beta1.CronJob:
+		return getOrInitialise(obj.Spec.JobTemplate.Spec.Template.GetLabels())
+	case *Rollout:
+		return getOrInitialise(obj.Spec.Template.GetLabels())
+	}
+	return
+}
+
 func getOrInitialise(a map[string]string) map[string]string {
 	if a == nil {
 		return make(map[string]string)
@@ -227,6 +265,8 @@ func (r *GenericResource) GetAnnotations() (annotations map[string]string) {
 		return getOrInitialise(obj.GetAnnotations())
 	case *v1beta1.CronJob:
 		return getOrInitialise(obj.GetAnnotations())
+	case *Rollout:
+		return getOrInitialise(obj.GetAnnotations())
 	}
 	return
 }
@@ -242,6 +282,8 @@ func (r *GenericResource) SetAnnotations(annotations map[string]string) {
 		obj.SetAnnotations(annotations)
 	case *v1beta1.CronJob:
 		obj.SetAnnotations(annotations)
+	case *Rollout:
+		obj.SetAnnotations(annotations)
 	}
 }
 
@@ -256,21 +298,20 @@ func (r *GenericResource) GetImagePullSecrets() (secrets []string) {
 		return getImagePullSecrets(obj.Spec.Template.Spec.ImagePullSecrets)
 	case *v1beta1.CronJob:
 		return getImagePullSecrets(obj.Spec.JobTemplate.Spec.Template.Spec.ImagePullSecrets)
+	case *Rollout:
+		return getImagePullSecrets(obj.Spec.Template.Spec.ImagePullSecrets)
 	}
 	return
 }
 
-// GetImages - returns images used by this resource
+// GetImages - returns images used by this resource, init container images
+// follow container images unless a container already uses them
 func (r *GenericResource) GetImages() (images []string) {
-	switch obj := r.obj.(type) {
-	case *apps_v1.Deployment:
-		return getContainerImages(obj.Spec.Template.Spec.Containers)
-	case *apps_v1.StatefulSet:
-		return getContainerImages(obj.Spec.Template.Spec.Containers)
-	case *apps_v1.DaemonSet:
-		return getContainerImages(obj.Spec.Template.Spec.Containers)
-	case *v1beta1.CronJob:
-		return getContainerImages(obj.Spec.JobTemplate.Spec.Template.Spec.Containers)
+	images = getContainerImages(r.Containers())
+	for _, img := range getContainerImages(r.InitContainers()) {
+		if !containsImage(images, img) {
+			images = append(images, img)
+		}
 	}
 	return
 }
@@ -286,22 +327,67 @@ func (r *GenericResource) Containers() (containers []core_v1.Container) {
 		return obj.Spec.Template.Spec.Containers
 	case *v1beta1.CronJob:
 		return obj.Spec.JobTemplate.Spec.Template.Spec.Containers
+	case *Rollout:
+		return obj.Spec.Template.Spec.Containers
 	}
 	return
 }
 
+// InitContainers - returns init containers managed by this resource
+func (r *GenericResource) InitContainers() (containers []core_v1.Container) {
+	switch obj := r.obj.(type) {
+	case *apps_v1.Deployment:
+		return obj.Spec.Template.Spec.InitContainers
+	case *apps_v1.StatefulSet:
+		return obj.Spec.Template.Spec.InitContainers
+	case *apps_v1.DaemonSet:
+		return obj.Spec.Template.Spec.InitContainers
+	case *v1beta1.CronJob:
+		return obj.Spec.JobTemplate.Spec.Template.Spec.InitContainers
+	case *Rollout:
+		return obj.Spec.Template.Spec.InitContainers
+	}
+	return
+}
+
+// UpdateInitContainer - updates init container image
+func (r *GenericResource) UpdateInitContainer(index int, image string) {
+	containers := r.InitContainers()
+	if index < len(containers) {
+		containers[index].Image = image
+	}
+}
+
+// SetInitContainerImagePullPolicy - sets image pull policy of the init container
+func (r *GenericResource) SetInitContainerImagePullPolicy(index int, policy core_v1.PullPolicy) {
+	containers := r.InitContainers()
+	if index < len(containers) {
+		containers[index].ImagePullPolicy = policy
+	}
+}
+
 // UpdateContainer - updates container image
 func (r *GenericResource) UpdateContainer(index int, image string) {
-	//switch obj := r.obj.(type) {
-	//case *apps_v1.Deployment:
-	//	updateDeploymentContainer(obj, index, image)
-	//case *apps_v1.StatefulSet:
-	//	updateStatefulSetContainer(obj, index, image)
-	//case *apps_v1.DaemonSet:
-	//	updateDaemonsetSetContainer(obj, index, image)
-	//case *v1beta1.CronJob:
-	//	updateCronJobContainer(obj, index, image)
-	//}
+	switch obj := r.obj.(type) {
+	case *apps_v1.Deployment:
+		updateDeploymentContainer(obj, index, image)
+	case *apps_v1.StatefulSet:
+		updateStatefulSetContainer(obj, index, image)
+	case *apps_v1.DaemonSet:
+		updateDaemonsetSetContainer(obj, index, image)
+	case *v1beta1.CronJob:
+		updateCronJobContainer(obj, index, image)
+	case *Rollout:
+		updateRolloutContainer(obj, index, image)
+	}
+}
+
+// SetImagePullPolicy - sets image pull policy of the container
+func (r *GenericResource) SetImagePullPolicy(index int, policy core_v1.PullPolicy) {
+	containers := r.Containers()
+	if index < len(containers) {
+		containers[index].ImagePullPolicy = policy
+	}
 }
 
 type Status struct {
@@ -362,6 +448,14 @@ func (r *GenericResource) GetStatus() Status {
 			AvailableReplicas:   0,
 			UnavailableReplicas: 0,
 		}
+	case *Rollout:
+		return Status{
+			Replicas:            obj.Status.Replicas,
+			UpdatedReplicas:     obj.Status.UpdatedReplicas,
+			ReadyReplicas:       obj.Status.ReadyReplicas,
+			AvailableReplicas:   obj.Status.AvailableReplicas,
+			UnavailableReplicas: obj.Status.Replicas - obj.Status.AvailableReplicas,
+		}
 	}
 	return Status{}
 }
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	apps_v1 "k8s.io/api/apps/v1"
+	batch_v1 "k8s.io/api/batch/v1"
+	v1beta1 "k8s.io/api/batch/v1beta1"
 	core_v1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -185,3 +187,132 @@ func TestDaemonsetlSetMultipleContainers(t *testing.T) {
 		t.Errorf("unexpected image: %s", updated.Spec.Template.Spec.Containers[0].Image)
 	}
 }
+
+func TestCronJob(t *testing.T) {
+	c := &v1beta1.CronJob{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "cron-1",
+			Namespace:   "xxxx",
+			Annotations: map[string]string{},
+			Labels:      map[string]string{"bow/policy": "minor"},
+		},
+		Spec: v1beta1.CronJobSpec{
+			JobTemplate: v1beta1.JobTemplateSpec{
+				Spec: batch_v1.JobSpec{
+					Template: core_v1.PodTemplateSpec{
+						Spec: core_v1.PodSpec{
+							Containers: []core_v1.Container{
+								{
+									Image: "gcr.io/v2-namespace/hello-world:1.1.1",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	gr, err := NewGenericResource(c)
+	if err != nil {
+		t.Fatalf("failed to create generic resource: %s", err)
+	}
+
+	if gr.Kind() != "cronjob" {
+		t.Errorf("unexpected kind: %s", gr.Kind())
+	}
+	if gr.Identifier != "cronjob/xxxx/cron-1" {
+		t.Errorf("unexpected identifier: %s", gr.Identifier)
+	}
+	if gr.GetLabels()["bow/policy"] != "minor" {
+		t.Errorf("unexpected labels: %v", gr.GetLabels())
+	}
+
+	images := gr.GetImages()
+	if len(images) != 1 || images[0] != "gcr.io/v2-namespace/hello-world:1.1.1" {
+		t.Errorf("unexpected images: %v", images)
+	}
+
+	gr.SetSpecAnnotations(map[string]string{"bow/update-time": "now"})
+	if gr.GetSpecAnnotations()["bow/update-time"] != "now" {
+		t.Errorf("unexpected spec annotations: %v", gr.GetSpecAnnotations())
+	}
+
+	c.Spec.JobTemplate.Spec.Template.Spec.InitContainers = []core_v1.Container{
+		{Image: "gcr.io/v2-namespace/hello-world:1.1.1"},
+		{Image: "gcr.io/v2-namespace/migrations:1.1.1"},
+	}
+	images = gr.GetImages()
+	if len(images) != 2 || images[1] != "gcr.io/v2-namespace/migrations:1.1.1" {
+		t.Errorf("unexpected images with init containers: %v", images)
+	}
+	gr.UpdateInitContainer(1, "gcr.io/v2-namespace/migrations:1.1.2")
+	gr.SetInitContainerImagePullPolicy(1, core_v1.PullAlways)
+	if init := gr.InitContainers()[1]; init.Image != "gcr.io/v2-namespace/migrations:1.1.2" || init.ImagePullPolicy != core_v1.PullAlways {
+		t.Errorf("unexpected init container: %s %s", init.Image, init.ImagePullPolicy)
+	}
+
+	gr.SetImagePullPolicy(0, core_v1.PullAlways)
+	gr.SetImagePullPolicy(1, core_v1.PullNever)
+	if policy := c.Spec.JobTemplate.Spec.Template.Spec.Containers[0].ImagePullPolicy; policy != core_v1.PullAlways {
+		t.Errorf("unexpected image pull policy: %s", policy)
+	}
+}
+
+func TestRollout(t *testing.T) {
+	r := &Rollout{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "canary",
+			Namespace:   "xxxx",
+			Annotations: map[string]string{},
+			Labels:      map[string]string{"bow/policy": "minor"},
+		},
+		Spec: RolloutSpec{
+			Template: core_v1.PodTemplateSpec{
+				Spec: core_v1.PodSpec{
+					Containers: []core_v1.Container{
+						{
+							Image: "gcr.io/v2-namespace/hello-world:1.1.1",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	gr, err := NewGenericResource(r)
+	if err != nil {
+		t.Fatalf("failed to create generic resource: %s", err)
+	}
+
+	if gr.Kind() != "rollout" {
+		t.Errorf("unexpected kind: %s", gr.Kind())
+	}
+	if gr.Identifier != "rollout/xxxx/canary" {
+		t.Errorf("unexpected identifier: %s", gr.Identifier)
+	}
+	if gr.GetLabels()["bow/policy"] != "minor" {
+		t.Errorf("unexpected labels: %v", gr.GetLabels())
+	}
+
+	images := gr.GetImages()
+	if len(images) != 1 || images[0] != "gcr.io/v2-namespace/hello-world:1.1.1" {
+		t.Errorf("unexpected images: %v", images)
+	}
+
+	gr.UpdateContainer(0, "gcr.io/v2-namespace/hello-world:1.2.0")
+	if image := r.Spec.Template.Spec.Containers[0].Image; image != "gcr.io/v2-namespace/hello-world:1.2.0" {
+		t.Errorf("unexpected image: %s", image)
+	}
+
+	gr.SetSpecAnnotations(map[string]string{"bow/update-time": "now"})
+	if gr.GetSpecAnnotations()["bow/update-time"] != "now" {
+		t.Errorf("unexpected spec annotations: %v", gr.GetSpecAnnotations())
+	}
+
+	copied := gr.DeepCopy()
+	copied.SetSpecAnnotations(map[string]string{"bow/update-time": "later"})
+	if gr.GetSpecAnnotations()["bow/update-time"] != "now" {
+		t.Errorf("expected copy to be independent of the original")
+	}
+}
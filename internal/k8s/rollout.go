@@ -0,0 +1,59 @@
+package k8s
+
+import (
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Argo Rollouts API version and kind
+const (
+	RolloutAPIVersion = "argoproj.io/v1alpha1"
+	RolloutKind       = "Rollout"
+)
+
+// Rollout - Argo Rollout (argoproj.io/v1alpha1), only fields bow reads
+// are kept, rollout strategy is left to Argo
+type Rollout struct {
+	meta_v1.TypeMeta   `json:",inline"`
+	meta_v1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RolloutSpec   `json:"spec,omitempty"`
+	Status RolloutStatus `json:"status,omitempty"`
+}
+
+// RolloutSpec - rollout spec
+type RolloutSpec struct {
+	Replicas *int32                  `json:"replicas,omitempty"`
+	Template core_v1.PodTemplateSpec `json:"template"`
+}
+
+// RolloutStatus - rollout status
+type RolloutStatus struct {
+	Replicas          int32 `json:"replicas,omitempty"`
+	UpdatedReplicas   int32 `json:"updatedReplicas,omitempty"`
+	ReadyReplicas     int32 `json:"readyReplicas,omitempty"`
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+}
+
+// DeepCopy - copies the rollout
+func (r *Rollout) DeepCopy() *Rollout {
+	if r == nil {
+		return nil
+	}
+	out := new(Rollout)
+	out.TypeMeta = r.TypeMeta
+	r.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if r.Spec.Replicas != nil {
+		replicas := *r.Spec.Replicas
+		out.Spec.Replicas = &replicas
+	}
+	r.Spec.Template.DeepCopyInto(&out.Spec.Template)
+	out.Status = r.Status
+	return out
+}
+
+// DeepCopyObject - implements runtime.Object
+func (r *Rollout) DeepCopyObject() runtime.Object {
+	return r.DeepCopy()
+}
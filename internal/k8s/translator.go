@@ -2,6 +2,8 @@ package k8s
 
 import (
 	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 type Translator struct {
@@ -9,7 +11,12 @@ type Translator struct {
 
 	GenericResourceCache
 
-	bowSelector string
+	// Selector - optional, only resources with matching labels are cached
+	Selector labels.Selector
+}
+
+func (t *Translator) selected(gr *GenericResource) bool {
+	return t.Selector == nil || t.Selector.Matches(labels.Set(gr.GetLabels()))
 }
 
 func (t *Translator) OnAdd(obj interface{}) {
@@ -18,6 +25,10 @@ func (t *Translator) OnAdd(obj interface{}) {
 		t.Errorf("OnAdd failed to add resource %T: %#v", obj, obj)
 		return
 	}
+	if !t.selected(gr) {
+		t.Debugf("skipping %s %s, labels don't match selector", gr.Kind(), gr.Name)
+		return
+	}
 	t.Debugf("added %s %s", gr.Kind(), gr.Name)
 	t.GenericResourceCache.Add(gr)
 }
@@ -28,6 +39,12 @@ func (t *Translator) OnUpdate(oldObj, newObj interface{}) {
 		t.Errorf("OnUpdate failed to update resource %T: %#v", newObj, newObj)
 		return
 	}
+	if !t.selected(gr) {
+		// labels might have changed since the resource was added
+		t.Debugf("removing %s %s, labels don't match selector", gr.Kind(), gr.Name)
+		t.GenericResourceCache.Remove(gr.GetIdentifier())
+		return
+	}
 	t.Debugf("updated %s %s", gr.Kind(), gr.Name)
 	t.GenericResourceCache.Add(gr)
 }
@@ -0,0 +1,70 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func labeledDeployment(name string, lbls map[string]string) *apps_v1.Deployment {
+	return &apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      name,
+			Namespace: "xxxx",
+			Labels:    lbls,
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: core_v1.PodTemplateSpec{
+				Spec: core_v1.PodSpec{
+					Containers: []core_v1.Container{
+						{
+							Image: "gcr.io/v2-namespace/hello-world:1.1.1",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestTranslatorSelector(t *testing.T) {
+	selector, err := labels.Parse("team=payments")
+	if err != nil {
+		t.Fatalf("failed to parse selector: %s", err)
+	}
+
+	tr := &Translator{
+		FieldLogger: logrus.New(),
+		Selector:    selector,
+	}
+
+	tr.OnAdd(labeledDeployment("dep-1", map[string]string{"team": "payments"}))
+	tr.OnAdd(labeledDeployment("dep-2", map[string]string{"team": "search"}))
+
+	values := tr.GenericResourceCache.Values()
+	if len(values) != 1 || values[0].Name != "dep-1" {
+		t.Fatalf("expected only selected resource to be cached, got: %v", values)
+	}
+
+	// labels changed, resource no longer selected
+	tr.OnUpdate(nil, labeledDeployment("dep-1", map[string]string{"team": "search"}))
+	if len(tr.GenericResourceCache.Values()) != 0 {
+		t.Errorf("expected deselected resource to be removed")
+	}
+}
+
+func TestTranslatorNoSelector(t *testing.T) {
+	tr := &Translator{
+		FieldLogger: logrus.New(),
+	}
+
+	tr.OnAdd(labeledDeployment("dep-1", nil))
+	if len(tr.GenericResourceCache.Values()) != 1 {
+		t.Errorf("expected resource to be cached without selector")
+	}
+}
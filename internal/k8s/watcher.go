@@ -10,34 +10,37 @@ import (
 	v1beta1 "k8s.io/api/batch/v1beta1"
 	"k8s.io/api/core/v1"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	k8swatch "k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 )
 
 // WatchDeployments creates a SharedInformer for apps/v1.Deployments and registers it with g.
-func WatchDeployments(g *workgroup.Group, client *kubernetes.Clientset, log logrus.FieldLogger, rs ...cache.ResourceEventHandler) {
-	watch(g, client.AppsV1().RESTClient(), log, "deployments", new(apps_v1.Deployment), rs...)
+func WatchDeployments(g *workgroup.Group, client *kubernetes.Clientset, log logrus.FieldLogger, selector labels.Selector, rs ...cache.ResourceEventHandler) {
+	watch(g, client.AppsV1().RESTClient(), log, "deployments", new(apps_v1.Deployment), selector, rs...)
 }
 
 // WatchStatefulSets creates a SharedInformer for apps/v1.StatefulSet and registers it with g.
-func WatchStatefulSets(g *workgroup.Group, client *kubernetes.Clientset, log logrus.FieldLogger, rs ...cache.ResourceEventHandler) {
-	watch(g, client.AppsV1().RESTClient(), log, "statefulsets", new(apps_v1.StatefulSet), rs...)
+func WatchStatefulSets(g *workgroup.Group, client *kubernetes.Clientset, log logrus.FieldLogger, selector labels.Selector, rs ...cache.ResourceEventHandler) {
+	watch(g, client.AppsV1().RESTClient(), log, "statefulsets", new(apps_v1.StatefulSet), selector, rs...)
 }
 
 // WatchDaemonSets creates a SharedInformer for apps/v1.DaemonSet and registers it with g.
-func WatchDaemonSets(g *workgroup.Group, client *kubernetes.Clientset, log logrus.FieldLogger, rs ...cache.ResourceEventHandler) {
-	watch(g, client.AppsV1().RESTClient(), log, "daemonsets", new(apps_v1.DaemonSet), rs...)
+func WatchDaemonSets(g *workgroup.Group, client *kubernetes.Clientset, log logrus.FieldLogger, selector labels.Selector, rs ...cache.ResourceEventHandler) {
+	watch(g, client.AppsV1().RESTClient(), log, "daemonsets", new(apps_v1.DaemonSet), selector, rs...)
 }
 
 // WatchCronJobs creates a SharedInformer for v1beta1.CronJob and registers it with g.
-func WatchCronJobs(g *workgroup.Group, client *kubernetes.Clientset, log logrus.FieldLogger, rs ...cache.ResourceEventHandler) {
-	watch(g, client.BatchV1beta1().RESTClient(), log, "cronjobs", new(v1beta1.CronJob), rs...)
+func WatchCronJobs(g *workgroup.Group, client *kubernetes.Clientset, log logrus.FieldLogger, selector labels.Selector, rs ...cache.ResourceEventHandler) {
+	watch(g, client.BatchV1beta1().RESTClient(), log, "cronjobs", new(v1beta1.CronJob), selector, rs...)
 }
 
-func watch(g *workgroup.Group, c cache.Getter, log logrus.FieldLogger, resource string, objType runtime.Object, rs ...cache.ResourceEventHandler) {
-	lw := cache.NewListWatchFromClient(c, resource, v1.NamespaceAll, fields.Everything())
+func watch(g *workgroup.Group, c cache.Getter, log logrus.FieldLogger, resource string, objType runtime.Object, selector labels.Selector, rs ...cache.ResourceEventHandler) {
+	lw := newListWatch(c, resource, selector)
 	sw := cache.NewSharedInformer(lw, objType, 30*time.Minute)
 	for _, r := range rs {
 		sw.AddEventHandler(r)
@@ -50,6 +53,38 @@ func watch(g *workgroup.Group, c cache.Getter, log logrus.FieldLogger, resource
 	})
 }
 
+// newListWatch - same as cache.NewListWatchFromClient for all namespaces but with label
+// selector added to list options so API server only returns selected resources
+func newListWatch(c cache.Getter, resource string, selector labels.Selector) *cache.ListWatch {
+	if selector == nil {
+		selector = labels.Everything()
+	}
+	optionsModifier := func(options *metav1.ListOptions) {
+		options.FieldSelector = fields.Everything().String()
+		options.LabelSelector = selector.String()
+	}
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			optionsModifier(&options)
+			return c.Get().
+				Namespace(v1.NamespaceAll).
+				Resource(resource).
+				VersionedParams(&options, metav1.ParameterCodec).
+				Do().
+				Get()
+		},
+		WatchFunc: func(options metav1.ListOptions) (k8swatch.Interface, error) {
+			options.Watch = true
+			optionsModifier(&options)
+			return c.Get().
+				Namespace(v1.NamespaceAll).
+				Resource(resource).
+				VersionedParams(&options, metav1.ParameterCodec).
+				Watch()
+		},
+	}
+}
+
 type buffer struct {
 	ev chan interface{}
 	logrus.StdLogger
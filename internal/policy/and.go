@@ -0,0 +1,71 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// andSeparator - separates policies that all have to agree on the update,
+// ie: minor && glob:1.3*
+const andSeparator = "&&"
+
+// AndPolicy - combines policies, updates are only allowed when every
+// policy allows them
+type AndPolicy struct {
+	policies []Policy
+}
+
+// NewAndPolicy - create new policy requiring all policies to agree
+func NewAndPolicy(policies ...Policy) *AndPolicy {
+	return &AndPolicy{
+		policies: policies,
+	}
+}
+
+// ShouldUpdate - checks policies in order, stops at the first one refusing
+// the update or returning an error
+func (ap *AndPolicy) ShouldUpdate(current, new string) (bool, error) {
+	if len(ap.policies) == 0 {
+		return false, nil
+	}
+	for _, p := range ap.policies {
+		should, err := p.ShouldUpdate(current, new)
+		if err != nil || !should {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Name - names of combined policies, ie: minor && glob:1.3*
+func (ap *AndPolicy) Name() string {
+	names := make([]string, len(ap.policies))
+	for i, p := range ap.policies {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, " "+andSeparator+" ")
+}
+
+// Type - and policy type
+func (ap *AndPolicy) Type() PolicyType { return PolicyTypeAnd }
+
+// Policies - combined policies
+func (ap *AndPolicy) Policies() []Policy { return ap.policies }
+
+// parseAndPolicy - parses every policy of the combined policy string, unset
+// and "never" policies can't be combined
+func parseAndPolicy(policyName string, options *Options) (Policy, error) {
+	var policies []Policy
+	for _, name := range strings.Split(policyName, andSeparator) {
+		name = strings.TrimSpace(name)
+		p, err := getPolicy(name, options)
+		if err != nil {
+			return &NilPolicy{}, err
+		}
+		if p.Type() == PolicyTypeNone {
+			return &NilPolicy{}, fmt.Errorf("policy '%s' can't be combined in '%s'", name, policyName)
+		}
+		policies = append(policies, p)
+	}
+	return NewAndPolicy(policies...), nil
+}
@@ -0,0 +1,72 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/alwinius/bow/types"
+)
+
+func TestAndPolicySemverGlob(t *testing.T) {
+	plc, err := GetPolicyFromLabelsOrAnnotations(map[string]string{}, map[string]string{
+		types.BowPolicyLabel: "minor && glob:1.3*",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ap, ok := plc.(*AndPolicy)
+	if !ok {
+		t.Fatalf("expected and policy, got: %T", plc)
+	}
+	if ap.Type() != PolicyTypeAnd || ap.Name() != "minor && glob:1.3*" {
+		t.Errorf("unexpected policy type and name: %d %s", ap.Type(), ap.Name())
+	}
+	if len(ap.Policies()) != 2 || ap.Policies()[0].Type() != PolicyTypeSemver || ap.Policies()[1].Type() != PolicyTypeGlob {
+		t.Errorf("unexpected combined policies: %v", ap.Policies())
+	}
+
+	tests := []struct {
+		current string
+		new     string
+		want    bool
+	}{
+		{"1.2.0", "1.3.0", true},
+		{"1.2.0", "1.3.5", true},
+		{"1.2.0", "1.4.0", false}, // refused by glob
+		{"1.2.0", "1.2.9", false}, // refused by glob
+		{"1.3.1", "1.3.0", false}, // refused by semver
+		{"1.2.0", "2.0.0", false}, // refused by both
+	}
+	for _, tt := range tests {
+		t.Run(tt.current+"->"+tt.new, func(t *testing.T) {
+			got, err := plc.ShouldUpdate(tt.current, tt.new)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("ShouldUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAndPolicyErrors(t *testing.T) {
+	plc, err := GetPolicy("minor && glob:*", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = plc.ShouldUpdate("1.2.0", "master")
+	if err == nil {
+		t.Errorf("expected semver error to be returned")
+	}
+
+	for _, name := range []string{"minor &&", "minor && never", "minor && foo", "minor && regexp:["} {
+		plc, err := GetPolicy(name, &Options{})
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+		if plc.Type() != PolicyTypeNone {
+			t.Errorf("%s: expected nil policy, got: %T", name, plc)
+		}
+	}
+}
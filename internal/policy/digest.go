@@ -0,0 +1,29 @@
+package policy
+
+// DigestPolicy - updates the same tag when its digest changes, ie: immutable
+// 1.2.3 tag rebuilt with a patched base image. Providers compare the new digest
+// with the running one so events without a new digest don't update anything
+type DigestPolicy struct{}
+
+// NewDigestPolicy - create new digest policy
+func NewDigestPolicy() *DigestPolicy {
+	return &DigestPolicy{}
+}
+
+// ShouldUpdate - only the same tag is updated
+func (dp *DigestPolicy) ShouldUpdate(current, new string) (bool, error) {
+	return current == new, nil
+}
+
+func (dp *DigestPolicy) Name() string     { return "digest" }
+func (dp *DigestPolicy) Type() PolicyType { return PolicyTypeDigest }
+
+// MatchDigest - digest policy always compares digests
+func (dp *DigestPolicy) MatchDigest() bool { return true }
+
+// MatchDigest - whether policy (also when wrapped by maintenance window policy)
+// only updates when digest of the same tag changed
+func MatchDigest(plc Policy) bool {
+	dm, ok := Unwrap(plc).(interface{ MatchDigest() bool })
+	return ok && dm.MatchDigest()
+}
@@ -0,0 +1,46 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/alwinius/bow/types"
+)
+
+func TestGetPolicyDigest(t *testing.T) {
+	plc, err := GetPolicyFromLabelsOrAnnotations(map[string]string{}, map[string]string{
+		types.BowPolicyLabel:            "digest",
+		types.BowUpdateWindowAnnotation: "* 2-5 * * 6,0",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if plc.Type() != PolicyTypeDigest || plc.Name() != "digest" {
+		t.Errorf("unexpected policy type and name: %d %s", plc.Type(), plc.Name())
+	}
+	if !MatchDigest(plc) {
+		t.Errorf("expected digest policy wrapped in update window to match digest")
+	}
+
+	for _, tt := range []struct {
+		current string
+		new     string
+		want    bool
+	}{
+		{"1.2.3", "1.2.3", true},
+		{"1.2.3", "1.2.4", false},
+		{"latest", "latest", true},
+		{"latest", "master", false},
+	} {
+		got, err := NewDigestPolicy().ShouldUpdate(tt.current, tt.new)
+		if err != nil || got != tt.want {
+			t.Errorf("ShouldUpdate(%s, %s) = %v, %v, want %v", tt.current, tt.new, got, err, tt.want)
+		}
+	}
+
+	if MatchDigest(NewForcePolicy(true)) {
+		t.Errorf("force policy without matchDigest shouldn't match digest")
+	}
+	if MatchDigest(NewSemverPolicy(SemverPolicyTypePatch)) {
+		t.Errorf("semver policy shouldn't match digest")
+	}
+}
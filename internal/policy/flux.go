@@ -0,0 +1,136 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FluxCompat - resources without bow policy are tracked with the Flux image policy
+// from app.kubernetes.io/image-policy annotation, translated into bow policy
+var FluxCompat = false
+
+var (
+	// >=1.0.0, >=1.0.0-0 includes pre-releases
+	fluxLowerBoundRegexp = regexp.MustCompile(`^>=\s*v?\d+(?:\.\d+){0,2}(-\S+)?$`)
+	// >=1.2.0 <2.0.0, >=1.2.0, <1.3.0
+	fluxBoundsRegexp = regexp.MustCompile(`^>=\s*v?(\d+)\.(\d+)\.(\d+)\s*,?\s*<\s*v?(\d+)\.(\d+)\.(\d+)$`)
+)
+
+// TranslateFluxPolicy - translates Flux image policy, ie: "semver:^1.2", "alphabetical:asc"
+// or "numerical:asc" into the nearest bow policy. Semver ranges become semver policies
+// relative to the running version, ie: ^1.2 -> minor, ~1.2 -> patch, pinned versions -> never
+func TranslateFluxPolicy(spec string) (string, error) {
+	parts := strings.SplitN(strings.TrimSpace(spec), ":", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid flux image policy '%s', expected ie: semver:^1.0", spec)
+	}
+	kind, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+
+	switch kind {
+	case "semver":
+		policy, err := fluxSemverPolicy(value)
+		if err != nil {
+			return "", fmt.Errorf("flux image policy '%s': %s", spec, err)
+		}
+		return policy, nil
+	case "alphabetical", "numerical":
+		if value != "" && value != "asc" {
+			return "", fmt.Errorf("flux image policy '%s': only ascending order is supported", spec)
+		}
+		// alphabetical ordering is used for sortable timestamps, numerical for build numbers
+		if kind == "numerical" {
+			return "timestamp:" + TimestampEpochLayout, nil
+		}
+		return "timestamp", nil
+	}
+
+	return "", fmt.Errorf("unsupported flux image policy '%s', ImagePolicy references can't be resolved, expected semver, alphabetical or numerical policy", spec)
+}
+
+// fluxSemverPolicy - nearest semver policy for the range
+func fluxSemverPolicy(rng string) (string, error) {
+	switch rng {
+	case "*", "x", "X":
+		return "major", nil
+	}
+
+	if m := fluxLowerBoundRegexp.FindStringSubmatch(rng); m != nil {
+		if m[1] != "" {
+			return "all", nil
+		}
+		return "major", nil
+	}
+
+	if m := fluxBoundsRegexp.FindStringSubmatch(rng); m != nil {
+		v := make([]int64, 6)
+		for i := range v {
+			v[i], _ = strconv.ParseInt(m[i+1], 10, 64)
+		}
+		switch {
+		case v[3] == v[0]+1 && v[4] == 0 && v[5] == 0:
+			return "minor", nil
+		case v[3] == v[0] && v[4] == v[1]+1 && v[5] == 0:
+			return "patch", nil
+		}
+		return "", fmt.Errorf("range '%s' has no bow equivalent", rng)
+	}
+
+	switch {
+	case strings.HasPrefix(rng, "^"):
+		parts, ok := fluxVersionParts(rng[1:])
+		if !ok {
+			break
+		}
+		// ^0.2 doesn't allow minor updates
+		if parts[0] == "0" && len(parts) > 1 {
+			return "patch", nil
+		}
+		return "minor", nil
+	case strings.HasPrefix(rng, "~"):
+		parts, ok := fluxVersionParts(rng[1:])
+		if !ok {
+			break
+		}
+		if len(parts) == 1 {
+			return "minor", nil
+		}
+		return "patch", nil
+	default:
+		parts, ok := fluxVersionParts(rng)
+		if !ok {
+			break
+		}
+		switch len(parts) {
+		case 1:
+			return "minor", nil
+		case 2:
+			return "patch", nil
+		}
+		return "never", nil
+	}
+
+	return "", fmt.Errorf("range '%s' has no bow equivalent", rng)
+}
+
+// fluxVersionParts - numeric parts of the version, wildcards (1.x, 1.2.*) are dropped
+func fluxVersionParts(version string) ([]string, bool) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	for len(parts) > 1 {
+		last := parts[len(parts)-1]
+		if last != "x" && last != "X" && last != "*" {
+			break
+		}
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) > 3 {
+		return nil, false
+	}
+	for _, part := range parts {
+		if _, err := strconv.ParseUint(part, 10, 64); err != nil {
+			return nil, false
+		}
+	}
+	return parts, true
+}
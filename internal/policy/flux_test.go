@@ -0,0 +1,78 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/alwinius/bow/types"
+)
+
+func TestTranslateFluxPolicy(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "semver:*", want: "major"},
+		{spec: "semver: >=1.0.0", want: "major"},
+		{spec: "semver:>=1.0.0-0", want: "all"},
+		{spec: "semver:^1.2", want: "minor"},
+		{spec: "semver:^0.2.1", want: "patch"},
+		{spec: "semver:1.x", want: "minor"},
+		{spec: "semver:~1.2.0", want: "patch"},
+		{spec: "semver:~1", want: "minor"},
+		{spec: "semver:1.2.*", want: "patch"},
+		{spec: "semver:>=1.2.0 <2.0.0", want: "minor"},
+		{spec: "semver:>=1.2.0, <1.3.0", want: "patch"},
+		{spec: "semver:1.2.3", want: "never"},
+		{spec: "semver:>=1.2.0 <3.0.0", wantErr: true},
+		{spec: "semver:!=1.2.0", wantErr: true},
+		{spec: "alphabetical:asc", want: "timestamp"},
+		{spec: "numerical:asc", want: "timestamp:epoch"},
+		{spec: "alphabetical:desc", wantErr: true},
+		{spec: "flux-system:podinfo", wantErr: true},
+		{spec: "semver", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := TranslateFluxPolicy(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TranslateFluxPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("TranslateFluxPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPolicyFromFluxAnnotation(t *testing.T) {
+	defer func(compat bool) { FluxCompat = compat }(FluxCompat)
+
+	annotations := map[string]string{types.FluxImagePolicyAnnotation: "semver:^1.0"}
+
+	FluxCompat = false
+	plc, err := GetPolicyFromLabelsOrAnnotations(nil, annotations)
+	if err != nil || plc.Type() != PolicyTypeNone {
+		t.Fatalf("flux annotation should be ignored unless compatibility is enabled, got: %s, %v", plc.Name(), err)
+	}
+
+	FluxCompat = true
+	plc, err = GetPolicyFromLabelsOrAnnotations(nil, annotations)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if plc.Name() != "minor" {
+		t.Errorf("expected minor policy, got: %s", plc.Name())
+	}
+
+	// bow policy takes precedence
+	plc, err = GetPolicyFromLabelsOrAnnotations(map[string]string{types.BowPolicyLabel: "patch"}, annotations)
+	if err != nil || plc.Name() != "patch" {
+		t.Errorf("expected bow policy, got: %s, %v", plc.Name(), err)
+	}
+
+	_, err = GetPolicyFromLabelsOrAnnotations(nil, map[string]string{types.FluxImagePolicyAnnotation: "flux-system:podinfo"})
+	if err == nil {
+		t.Errorf("expected error for ImagePolicy reference")
+	}
+}
@@ -1,7 +1,15 @@
 package policy
 
+import (
+	"github.com/Masterminds/semver"
+
+	log "github.com/sirupsen/logrus"
+)
+
 type ForcePolicy struct {
-	matchTag bool
+	matchTag    bool
+	matchDigest bool
+	noDowngrade bool
 }
 
 func NewForcePolicy(matchTag bool) *ForcePolicy {
@@ -10,8 +18,17 @@ func NewForcePolicy(matchTag bool) *ForcePolicy {
 	}
 }
 
+// ShouldUpdate - any new tag is accepted unless the tag has to match or, with
+// bow/noDowngrade, the new semver tag is lower than the current one
 func (fp *ForcePolicy) ShouldUpdate(current, new string) (bool, error) {
-	if fp.matchTag && current != new {
+	if (fp.matchTag || fp.matchDigest) && current != new {
+		return false, nil
+	}
+	if fp.noDowngrade && IsDowngrade(current, new) {
+		log.WithFields(log.Fields{
+			"current_tag": current,
+			"new_tag":     new,
+		}).Debug("policy.force: new tag is lower than current, refusing to downgrade")
 		return false, nil
 	}
 	return true, nil
@@ -22,3 +39,24 @@ func (fp *ForcePolicy) Name() string {
 }
 
 func (fp *ForcePolicy) Type() PolicyType { return PolicyTypeForce }
+
+// MatchDigest - whether updates to the same tag are only applied when its digest
+// changed, providers compare new image digest with the previously deployed one
+func (fp *ForcePolicy) MatchDigest() bool { return fp.matchDigest }
+
+// NoDowngrade - whether semver tags should never be replaced with lower semver tags
+func (fp *ForcePolicy) NoDowngrade() bool { return fp.noDowngrade }
+
+// IsDowngrade - true when both tags are semver and new one is lower than current,
+// tags that can't be parsed as semver are never considered a downgrade
+func IsDowngrade(current, new string) bool {
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return false
+	}
+	newVersion, err := semver.NewVersion(new)
+	if err != nil {
+		return false
+	}
+	return newVersion.LessThan(currentVersion)
+}
@@ -7,19 +7,45 @@ import (
 	"github.com/ryanuber/go-glob"
 )
 
+// globExcludeSeparator - separates exclude pattern in the policy string,
+// ie: glob:release-*!release-*-rc
+const globExcludeSeparator = "!"
+
 type GlobPolicy struct {
 	policy  string // original string
 	pattern string // without prefix
+	exclude string // optional, tags matching it are never updated to
+}
+
+// GlobOption - optional glob policy setting
+type GlobOption func(p *GlobPolicy)
+
+// ExcludeGlob - skips tags that match the pattern even if they match
+// the policy pattern, ie: release-*-rc
+func ExcludeGlob(pattern string) GlobOption {
+	return func(p *GlobPolicy) {
+		p.exclude = pattern
+	}
 }
 
-func NewGlobPolicy(policy string) (*GlobPolicy, error) {
+func NewGlobPolicy(policy string, opts ...GlobOption) (*GlobPolicy, error) {
 	if strings.Contains(policy, ":") {
 		parts := strings.Split(policy, ":")
 		if len(parts) == 2 {
-			return &GlobPolicy{
+			p := &GlobPolicy{
 				policy:  policy,
 				pattern: parts[1],
-			}, nil
+			}
+			if idx := strings.Index(p.pattern, globExcludeSeparator); idx >= 0 {
+				p.pattern, p.exclude = p.pattern[:idx], p.pattern[idx+1:]
+				if p.exclude == "" {
+					return nil, fmt.Errorf("empty exclude pattern in glob policy: %s", policy)
+				}
+			}
+			for _, opt := range opts {
+				opt(p)
+			}
+			return p, nil
 		}
 	}
 
@@ -27,6 +53,9 @@ func NewGlobPolicy(policy string) (*GlobPolicy, error) {
 }
 
 func (p *GlobPolicy) ShouldUpdate(current, new string) (bool, error) {
+	if p.exclude != "" && glob.Glob(p.exclude, new) {
+		return false, nil
+	}
 	return glob.Glob(p.pattern, new), nil
 }
 
@@ -57,3 +57,38 @@ func TestGlobPolicy_ShouldUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestGlobPolicyExclude(t *testing.T) {
+	fromLabel, err := NewGlobPolicy("glob:release-*!release-*-rc")
+	if err != nil {
+		t.Fatalf("failed to parse policy: %s", err)
+	}
+	fromOption, err := NewGlobPolicy("glob:release-*", ExcludeGlob("release-*-rc"))
+	if err != nil {
+		t.Fatalf("failed to parse policy: %s", err)
+	}
+
+	tests := []struct {
+		new  string
+		want bool
+	}{
+		{new: "release-1.0", want: true},
+		{new: "release-1.0-rc", want: false},
+		{new: "master", want: false},
+	}
+	for _, p := range []*GlobPolicy{fromLabel, fromOption} {
+		for _, tt := range tests {
+			got, err := p.ShouldUpdate("release-0.9", tt.new)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("%s: ShouldUpdate(%s) = %v, want %v", p.Name(), tt.new, got, tt.want)
+			}
+		}
+	}
+
+	if _, err := NewGlobPolicy("glob:release-*!"); err == nil {
+		t.Errorf("expected error for empty exclude pattern")
+	}
+}
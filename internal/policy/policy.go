@@ -1,11 +1,11 @@
 package policy
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/alwinius/bow/types"
-
-	log "github.com/sirupsen/logrus"
+	"github.com/alwinius/bow/util/timeutil"
 )
 
 type PolicyType int
@@ -16,6 +16,9 @@ const (
 	PolicyTypeForce
 	PolicyTypeGlob
 	PolicyTypeRegexp
+	PolicyTypeTimestamp
+	PolicyTypeAnd
+	PolicyTypeDigest
 )
 
 type Policy interface {
@@ -30,65 +33,76 @@ func (np *NilPolicy) ShouldUpdate(c, n string) (bool, error) { return false, nil
 func (np *NilPolicy) Name() string                           { return "nil policy" }
 func (np *NilPolicy) Type() PolicyType                       { return PolicyTypeNone }
 
-// GetPolicyFromLabelsOrAnnotations - gets policy from k8s labels or annotations
-func GetPolicyFromLabelsOrAnnotations(labels map[string]string, annotations map[string]string) Policy {
+// GetPolicyFromLabelsOrAnnotations - gets policy from k8s labels or annotations. Resources
+// without a policy get NilPolicy, invalid policies return an error
+func GetPolicyFromLabelsOrAnnotations(labels map[string]string, annotations map[string]string) (Policy, error) {
+
+	window, err := getUpdateWindow(annotations)
+	if err != nil {
+		return &NilPolicy{}, err
+	}
 
 	policyNameA, ok := getPolicyFromLabels(annotations)
 	if ok {
-		return GetPolicy(policyNameA, &Options{MatchTag: getMatchTag(annotations)})
+		return GetPolicy(policyNameA, &Options{MatchTag: getMatchTag(annotations), MatchDigest: getMatchDigest(annotations), NoDowngrade: getNoDowngrade(annotations), IncludeBuildMeta: getIncludeBuildMeta(annotations), PreReleaseChannel: annotations[types.BowPreReleaseChannelLabel], Window: window})
 	}
 
 	policyNameL, ok := getPolicyFromLabels(labels)
 	if !ok {
-		return &NilPolicy{}
+		return getFluxPolicy(annotations, window)
 	}
 
-	return GetPolicy(policyNameL, &Options{MatchTag: getMatchTag(labels)})
+	return GetPolicy(policyNameL, &Options{MatchTag: getMatchTag(labels), MatchDigest: getMatchDigest(labels), NoDowngrade: getNoDowngrade(labels), IncludeBuildMeta: getIncludeBuildMeta(labels), PreReleaseChannel: labels[types.BowPreReleaseChannelLabel], Window: window})
 }
 
 // Options - additional options when parsing policy
 type Options struct {
 	MatchTag bool
+	// MatchDigest - force policy only updates the same tag, providers skip the
+	// update when its digest didn't change
+	MatchDigest bool
+	// NoDowngrade - force policy won't replace semver tags with lower semver tags
+	NoDowngrade bool
+	// IncludeBuildMeta - all and patch semver policies treat higher numeric build
+	// metadata as an update when versions are otherwise equal, ie: 1.4.2+build.57 -> 1.4.2+build.58
+	IncludeBuildMeta bool
+	// PreReleaseChannel - semver policies only accept pre-releases of this channel,
+	// ie: "staging" tracks 1.1.2-staging while stable and -alpha tags are ignored
+	PreReleaseChannel string
+	// Window - optional update window, policy is wrapped so
+	// updates are only allowed inside it
+	Window *timeutil.UpdateWindow
 }
 
-// GetPolicy - policy getter used by Helm config
-func GetPolicy(policyName string, options *Options) Policy {
-
-	switch {
-	case strings.HasPrefix(policyName, "glob:"):
-		p, err := NewGlobPolicy(policyName)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"error":  err,
-				"policy": policyName,
-			}).Error("failed to parse glob policy, check your deployment configuration")
-			return &NilPolicy{}
-		}
-		return p
-	case strings.HasPrefix(policyName, "regexp:"):
-		p, err := NewRegexpPolicy(policyName)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"error":  err,
-				"policy": policyName,
-			}).Error("failed to parse regexp policy, check your deployment configuration")
-			return &NilPolicy{}
+// GetPolicy - policy getter used by Helm config and kubernetes provider. Unset ("")
+// and "never" policies return NilPolicy, policies that can't be parsed return
+// NilPolicy together with an error. Policies separated by "&&" are combined
+// into AndPolicy, ie: minor && glob:1.3*
+func GetPolicy(policyName string, options *Options) (Policy, error) {
+	p, err := getPolicy(policyName, options)
+	if err != nil || options == nil || options.Window == nil || p.Type() == PolicyTypeNone {
+		return p, err
+	}
+	return NewWindowPolicy(p, options.Window), nil
+}
+
+func getPolicy(policyName string, options *Options) (Policy, error) {
+	if strings.Contains(policyName, andSeparator) {
+		return parseAndPolicy(policyName, options)
+	}
+
+	for _, r := range registered {
+		if r.match(policyName) {
+			return r.new(policyName, options)
 		}
-		return p
 	}
 
 	switch policyName {
-	case "all", "major", "minor", "patch":
-		return ParseSemverPolicy(policyName)
-	case "force":
-		return NewForcePolicy(options.MatchTag)
 	case "", "never":
-		return &NilPolicy{}
+		return &NilPolicy{}, nil
 	}
 
-	log.Infof("policy.GetPolicy: unknown policy '%s', please check your configuration", policyName)
-
-	return &NilPolicy{}
+	return &NilPolicy{}, fmt.Errorf("unknown policy '%s'", policyName)
 }
 
 // ParseSemverPolicy - parse policy type
@@ -102,6 +116,8 @@ func ParseSemverPolicy(policy string) Policy {
 		return NewSemverPolicy(SemverPolicyTypeMinor)
 	case "patch":
 		return NewSemverPolicy(SemverPolicyTypePatch)
+	case "prerelease":
+		return NewSemverPolicy(SemverPolicyTypePreRelease)
 	// case "force":
 	// 	return PolicyTypeForce
 	default:
@@ -109,6 +125,21 @@ func ParseSemverPolicy(policy string) Policy {
 	}
 }
 
+// getFluxPolicy - policy translated from Flux image policy annotation, NilPolicy
+// unless Flux compatibility is enabled
+func getFluxPolicy(annotations map[string]string, window *timeutil.UpdateWindow) (Policy, error) {
+	spec, ok := annotations[types.FluxImagePolicyAnnotation]
+	if !FluxCompat || !ok {
+		return &NilPolicy{}, nil
+	}
+
+	policyName, err := TranslateFluxPolicy(spec)
+	if err != nil {
+		return &NilPolicy{}, err
+	}
+	return GetPolicy(policyName, &Options{MatchTag: getMatchTag(annotations), IncludeBuildMeta: getIncludeBuildMeta(annotations), PreReleaseChannel: annotations[types.BowPreReleaseChannelLabel], Window: window})
+}
+
 func getPolicyFromLabels(labels map[string]string) (string, bool) {
 	policy, ok := labels[types.BowPolicyLabel]
 	if ok {
@@ -130,3 +161,23 @@ func getMatchTag(labels map[string]string) bool {
 
 	return false
 }
+
+func getUpdateWindow(annotations map[string]string) (*timeutil.UpdateWindow, error) {
+	spec, ok := annotations[types.BowUpdateWindowAnnotation]
+	if !ok || strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	return timeutil.ParseUpdateWindow(spec)
+}
+
+func getMatchDigest(labels map[string]string) bool {
+	return labels[types.BowForceDigestMatchLabel] == "true"
+}
+
+func getNoDowngrade(labels map[string]string) bool {
+	return labels[types.BowForceNoDowngradeLabel] == "true"
+}
+
+func getIncludeBuildMeta(labels map[string]string) bool {
+	return labels[types.BowSemverBuildMetaLabel] == "true"
+}
@@ -63,15 +63,21 @@ func TestGetPolicy(t *testing.T) {
 		options    *Options
 	}
 	tests := []struct {
-		name string
-		args args
-		want Policy
+		name    string
+		args    args
+		want    Policy
+		wantErr bool
 	}{
 		{
 			name: "patch",
 			args: args{policyName: "patch", options: &Options{}},
 			want: NewSemverPolicy(SemverPolicyTypePatch),
 		},
+		{
+			name: "prerelease",
+			args: args{policyName: "prerelease", options: &Options{}},
+			want: NewSemverPolicy(SemverPolicyTypePreRelease),
+		},
 		{
 			name: "glob:foo-*",
 			args: args{policyName: "glob:foo-*", options: &Options{}},
@@ -82,10 +88,36 @@ func TestGetPolicy(t *testing.T) {
 			args: args{policyName: "force", options: &Options{MatchTag: true}},
 			want: NewForcePolicy(true),
 		},
+		{
+			name: "unset",
+			args: args{policyName: "", options: &Options{}},
+			want: &NilPolicy{},
+		},
+		{
+			name: "never",
+			args: args{policyName: "never", options: &Options{}},
+			want: &NilPolicy{},
+		},
+		{
+			name:    "typo",
+			args:    args{policyName: "mnior", options: &Options{}},
+			want:    &NilPolicy{},
+			wantErr: true,
+		},
+		{
+			name:    "invalid regexp",
+			args:    args{policyName: "regexp:(", options: &Options{}},
+			want:    &NilPolicy{},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := GetPolicy(tt.args.policyName, tt.args.options); !reflect.DeepEqual(got, tt.want) {
+			got, err := GetPolicy(tt.args.policyName, tt.args.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("GetPolicy() = %v, want %v", got, tt.want)
 			}
 		})
@@ -98,9 +130,10 @@ func TestGetPolicyFromLabelsOrAnnotations(t *testing.T) {
 		annotations map[string]string
 	}
 	tests := []struct {
-		name string
-		args args
-		want Policy
+		name    string
+		args    args
+		want    Policy
+		wantErr bool
 	}{
 		{
 			name: "annotations policy",
@@ -118,12 +151,137 @@ func TestGetPolicyFromLabelsOrAnnotations(t *testing.T) {
 			},
 			want: NewSemverPolicy(SemverPolicyTypeAll),
 		},
+		{
+			name: "no policy",
+			args: args{
+				labels:      map[string]string{"foo": "bar"},
+				annotations: map[string]string{},
+			},
+			want: &NilPolicy{},
+		},
+		{
+			name: "invalid label policy",
+			args: args{
+				labels:      map[string]string{types.BowPolicyLabel: "mnior"},
+				annotations: map[string]string{},
+			},
+			want:    &NilPolicy{},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := GetPolicyFromLabelsOrAnnotations(tt.args.labels, tt.args.annotations); !reflect.DeepEqual(got, tt.want) {
+			got, err := GetPolicyFromLabelsOrAnnotations(tt.args.labels, tt.args.annotations)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetPolicyFromLabelsOrAnnotations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("GetPolicyFromLabelsOrAnnotations() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestIsDowngrade(t *testing.T) {
+	tests := []struct {
+		current string
+		new     string
+		want    bool
+	}{
+		{current: "1.2.0", new: "1.1.9", want: true},
+		{current: "1.2.0", new: "1.2.1", want: false},
+		{current: "1.2.0", new: "1.2.0", want: false},
+		{current: "latest", new: "1.0.0", want: false},
+		{current: "1.2.0", new: "latest", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.current+"->"+tt.new, func(t *testing.T) {
+			if got := IsDowngrade(tt.current, tt.new); got != tt.want {
+				t.Errorf("IsDowngrade() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPolicyForceNoDowngrade(t *testing.T) {
+	plc, err := GetPolicyFromLabelsOrAnnotations(map[string]string{
+		types.BowPolicyLabel:           "force",
+		types.BowForceNoDowngradeLabel: "true",
+	}, map[string]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fp, ok := plc.(*ForcePolicy)
+	if !ok {
+		t.Fatalf("expected force policy, got: %s", plc.Name())
+	}
+	if !fp.NoDowngrade() {
+		t.Errorf("expected no downgrade to be set")
+	}
+	if should, _ := plc.ShouldUpdate("1.2.3", "1.2.2"); should {
+		t.Errorf("expected downgrade to be refused")
+	}
+	if should, _ := plc.ShouldUpdate("1.2.3", "latest"); !should {
+		t.Errorf("expected non semver tag to be updated")
+	}
+
+	// force combined with other policies refuses downgrades too
+	plc, err = GetPolicyFromLabelsOrAnnotations(map[string]string{
+		types.BowPolicyLabel:           "force && glob:1.*",
+		types.BowForceNoDowngradeLabel: "true",
+	}, map[string]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if should, _ := plc.ShouldUpdate("1.2.3", "1.2.2"); should {
+		t.Errorf("expected downgrade to be refused by combined policy")
+	}
+	if should, _ := plc.ShouldUpdate("1.2.3", "1.2.4"); !should {
+		t.Errorf("expected upgrade to be allowed by combined policy")
+	}
+}
+
+func TestGetPolicyForceMatchDigest(t *testing.T) {
+	plc, err := GetPolicyFromLabelsOrAnnotations(map[string]string{
+		types.BowPolicyLabel:           "force",
+		types.BowForceDigestMatchLabel: "true",
+	}, map[string]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fp, ok := plc.(*ForcePolicy)
+	if !ok {
+		t.Fatalf("expected force policy, got: %s", plc.Name())
+	}
+	if !fp.MatchDigest() {
+		t.Errorf("expected match digest to be set")
+	}
+
+	// digests are only compared for the same tag
+	if update, _ := fp.ShouldUpdate("latest", "latest"); !update {
+		t.Errorf("expected same tag to be updated")
+	}
+	if update, _ := fp.ShouldUpdate("latest", "master"); update {
+		t.Errorf("expected different tag to be ignored")
+	}
+}
+
+func TestGetPolicyIncludeBuildMeta(t *testing.T) {
+	plc, err := GetPolicyFromLabelsOrAnnotations(map[string]string{}, map[string]string{
+		types.BowPolicyLabel:          "patch",
+		types.BowSemverBuildMetaLabel: "true",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	update, err := plc.ShouldUpdate("1.4.2+build.57", "1.4.2+build.58")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !update {
+		t.Errorf("expected higher build metadata to be an update")
+	}
+}
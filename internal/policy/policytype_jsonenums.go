@@ -9,19 +9,25 @@ import (
 
 var (
 	_PolicyTypeNameToValue = map[string]PolicyType{
-		"PolicyTypeNone":   PolicyTypeNone,
-		"PolicyTypeSemver": PolicyTypeSemver,
-		"PolicyTypeForce":  PolicyTypeForce,
-		"PolicyTypeGlob":   PolicyTypeGlob,
-		"PolicyTypeRegexp": PolicyTypeRegexp,
+		"PolicyTypeNone":      PolicyTypeNone,
+		"PolicyTypeSemver":    PolicyTypeSemver,
+		"PolicyTypeForce":     PolicyTypeForce,
+		"PolicyTypeGlob":      PolicyTypeGlob,
+		"PolicyTypeRegexp":    PolicyTypeRegexp,
+		"PolicyTypeTimestamp": PolicyTypeTimestamp,
+		"PolicyTypeAnd":       PolicyTypeAnd,
+		"PolicyTypeDigest":    PolicyTypeDigest,
 	}
 
 	_PolicyTypeValueToName = map[PolicyType]string{
-		PolicyTypeNone:   "PolicyTypeNone",
-		PolicyTypeSemver: "PolicyTypeSemver",
-		PolicyTypeForce:  "PolicyTypeForce",
-		PolicyTypeGlob:   "PolicyTypeGlob",
-		PolicyTypeRegexp: "PolicyTypeRegexp",
+		PolicyTypeNone:      "PolicyTypeNone",
+		PolicyTypeSemver:    "PolicyTypeSemver",
+		PolicyTypeForce:     "PolicyTypeForce",
+		PolicyTypeGlob:      "PolicyTypeGlob",
+		PolicyTypeRegexp:    "PolicyTypeRegexp",
+		PolicyTypeTimestamp: "PolicyTypeTimestamp",
+		PolicyTypeAnd:       "PolicyTypeAnd",
+		PolicyTypeDigest:    "PolicyTypeDigest",
 	}
 )
 
@@ -29,11 +35,12 @@ func init() {
 	var v PolicyType
 	if _, ok := interface{}(v).(fmt.Stringer); ok {
 		_PolicyTypeNameToValue = map[string]PolicyType{
-			interface{}(PolicyTypeNone).(fmt.Stringer).String():   PolicyTypeNone,
-			interface{}(PolicyTypeSemver).(fmt.Stringer).String(): PolicyTypeSemver,
-			interface{}(PolicyTypeForce).(fmt.Stringer).String():  PolicyTypeForce,
-			interface{}(PolicyTypeGlob).(fmt.Stringer).String():   PolicyTypeGlob,
-			interface{}(PolicyTypeRegexp).(fmt.Stringer).String(): PolicyTypeRegexp,
+			interface{}(PolicyTypeNone).(fmt.Stringer).String():      PolicyTypeNone,
+			interface{}(PolicyTypeSemver).(fmt.Stringer).String():    PolicyTypeSemver,
+			interface{}(PolicyTypeForce).(fmt.Stringer).String():     PolicyTypeForce,
+			interface{}(PolicyTypeGlob).(fmt.Stringer).String():      PolicyTypeGlob,
+			interface{}(PolicyTypeRegexp).(fmt.Stringer).String():    PolicyTypeRegexp,
+			interface{}(PolicyTypeTimestamp).(fmt.Stringer).String(): PolicyTypeTimestamp,
 		}
 	}
 }
@@ -0,0 +1,186 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// registration - policy type that can be set in bow/policy, getPolicy picks the
+// first registration matching the policy name
+type registration struct {
+	name        string
+	format      string
+	description string
+	// example - policy value used to describe the policy, examples list
+	// current -> new tag pairs checked with the example policy
+	example  string
+	examples [][2]string
+
+	match func(policyName string) bool
+	new   func(policyName string, options *Options) (Policy, error)
+}
+
+func hasPrefix(prefix string) func(string) bool {
+	return func(policyName string) bool {
+		return strings.HasPrefix(policyName, prefix)
+	}
+}
+
+func equals(name string) func(string) bool {
+	return func(policyName string) bool {
+		return policyName == name
+	}
+}
+
+func semverRegistration(name, description string, examples [][2]string) *registration {
+	return &registration{
+		name:        name,
+		format:      name,
+		description: description,
+		example:     name,
+		examples:    examples,
+		match:       equals(name),
+		new: func(policyName string, options *Options) (Policy, error) {
+			p := ParseSemverPolicy(policyName)
+			if sp, ok := p.(*SemverPolicy); ok && options != nil {
+				sp.includeBuildMeta = options.IncludeBuildMeta
+				sp.preReleaseChannel = options.PreReleaseChannel
+			}
+			return p, nil
+		},
+	}
+}
+
+var registered = []*registration{
+	semverRegistration("all", "updates to any higher semver version, including pre-releases",
+		[][2]string{{"1.2.3", "2.0.0"}, {"1.2.3", "1.3.0-rc.1"}, {"1.2.3", "1.2.2"}}),
+	semverRegistration("major", "updates to higher major, minor and patch versions",
+		[][2]string{{"1.2.3", "2.0.0"}, {"1.2.3", "1.2.4"}, {"1.2.3", "1.2.2"}}),
+	semverRegistration("minor", "updates to higher minor and patch versions of the same major version",
+		[][2]string{{"1.2.3", "1.3.0"}, {"1.2.3", "1.2.4"}, {"1.2.3", "2.0.0"}}),
+	semverRegistration("patch", "updates to higher patch versions of the same major and minor version",
+		[][2]string{{"1.2.3", "1.2.4"}, {"1.2.3", "1.3.0"}, {"1.2.3", "2.0.0"}}),
+	semverRegistration("prerelease", "updates like all, pre-releases are only updated to pre-releases with the same name",
+		[][2]string{{"1.2.3-rc.1", "1.2.3-rc.2"}, {"1.2.3-rc.1", "1.2.3-beta.2"}, {"1.2.3-rc.1", "1.2.4"}}),
+	{
+		name:        "force",
+		format:      "force",
+		description: "updates to any new tag, with bow/matchTag only to new images of the same tag",
+		example:     "force",
+		examples:    [][2]string{{"latest", "latest"}, {"1.2.3", "1.2.2"}, {"master", "feature-x"}},
+		match:       equals("force"),
+		new: func(policyName string, options *Options) (Policy, error) {
+			fp := NewForcePolicy(options.MatchTag)
+			fp.matchDigest = options.MatchDigest
+			fp.noDowngrade = options.NoDowngrade
+			return fp, nil
+		},
+	},
+	{
+		name:        "digest",
+		format:      "digest",
+		description: "updates the same tag when the registry digest differs from the running one, ie: rebuilt immutable tags",
+		example:     "digest",
+		examples:    [][2]string{{"1.2.3", "1.2.3"}, {"1.2.3", "1.2.4"}, {"latest", "master"}},
+		match:       equals("digest"),
+		new: func(policyName string, options *Options) (Policy, error) {
+			return NewDigestPolicy(), nil
+		},
+	},
+	{
+		name:        "glob",
+		format:      "glob:<pattern>[!<exclude pattern>]",
+		description: "updates to tags matching the glob pattern unless they match the exclude pattern",
+		example:     "glob:release-*!release-*-rc",
+		examples:    [][2]string{{"release-1", "release-2"}, {"release-1", "release-2-rc"}, {"release-1", "master"}},
+		match:       hasPrefix("glob:"),
+		new: func(policyName string, options *Options) (Policy, error) {
+			p, err := NewGlobPolicy(policyName)
+			if err != nil {
+				return &NilPolicy{}, fmt.Errorf("failed to parse glob policy '%s': %s", policyName, err)
+			}
+			return p, nil
+		},
+	},
+	{
+		name:        "regexp",
+		format:      "regexp:<regular expression>",
+		description: "updates to tags matching the regular expression",
+		example:     "regexp:^v[0-9]+$",
+		examples:    [][2]string{{"v1", "v2"}, {"v1", "v2-rc"}, {"v1", "latest"}},
+		match:       hasPrefix("regexp:"),
+		new: func(policyName string, options *Options) (Policy, error) {
+			p, err := NewRegexpPolicy(policyName)
+			if err != nil {
+				return &NilPolicy{}, fmt.Errorf("failed to parse regexp policy '%s': %s", policyName, err)
+			}
+			return p, nil
+		},
+	},
+	{
+		name:        "timestamp",
+		format:      "timestamp[:<layout>[@<timezone>]], layout defaults to " + TimestampDefaultLayout + ", epoch for unix seconds",
+		description: "treats tags as timestamps and updates to later ones",
+		example:     "timestamp",
+		examples:    [][2]string{{"20240115120000", "20240116090000"}, {"20240115120000", "20240114120000"}, {"20240115120000", "latest"}},
+		match: func(policyName string) bool {
+			return policyName == "timestamp" || strings.HasPrefix(policyName, "timestamp:")
+		},
+		new: func(policyName string, options *Options) (Policy, error) {
+			p, err := NewTimestampPolicy(policyName)
+			if err != nil {
+				return &NilPolicy{}, fmt.Errorf("failed to parse timestamp policy '%s': %s", policyName, err)
+			}
+			return p, nil
+		},
+	},
+}
+
+// Description - policy type, its bow/policy value format and example
+// updates checked with the policy
+type Description struct {
+	Name        string    `json:"name"`
+	Format      string    `json:"format"`
+	Description string    `json:"description"`
+	Example     string    `json:"example"`
+	Examples    []Example `json:"examples"`
+}
+
+// Example - whether the example policy updates current tag to the new one
+type Example struct {
+	Current string `json:"current"`
+	New     string `json:"new"`
+	Update  bool   `json:"update"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Describe - describes registered policy types, example outcomes are
+// computed with the policies created by their constructors
+func Describe() []Description {
+	descriptions := make([]Description, 0, len(registered))
+	for _, r := range registered {
+		d := Description{
+			Name:        r.name,
+			Format:      r.format,
+			Description: r.description,
+			Example:     r.example,
+		}
+
+		p, err := r.new(r.example, &Options{})
+		for _, pair := range r.examples {
+			e := Example{Current: pair[0], New: pair[1]}
+			if err == nil {
+				var updateErr error
+				e.Update, updateErr = p.ShouldUpdate(pair[0], pair[1])
+				if updateErr != nil {
+					e.Error = updateErr.Error()
+				}
+			} else {
+				e.Error = err.Error()
+			}
+			d.Examples = append(d.Examples, e)
+		}
+		descriptions = append(descriptions, d)
+	}
+	return descriptions
+}
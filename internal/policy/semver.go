@@ -3,6 +3,7 @@ package policy
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/Masterminds/semver"
@@ -22,6 +23,9 @@ const (
 	SemverPolicyTypeMajor
 	SemverPolicyTypeMinor
 	SemverPolicyTypePatch
+	// SemverPolicyTypePreRelease - same as minor but pre-release tags are
+	// accepted as well, ie: v1.1.1 -> v1.1.2-staging -> v1.1.2
+	SemverPolicyTypePreRelease
 )
 
 func (t SemverPolicyType) String() string {
@@ -36,6 +40,8 @@ func (t SemverPolicyType) String() string {
 		return "minor"
 	case SemverPolicyTypePatch:
 		return "patch"
+	case SemverPolicyTypePreRelease:
+		return "prerelease"
 	default:
 		return ""
 	}
@@ -49,9 +55,24 @@ func NewSemverPolicy(spt SemverPolicyType) *SemverPolicy {
 
 type SemverPolicy struct {
 	spt SemverPolicyType
+	// includeBuildMeta - higher numeric build metadata is treated as an update
+	// when versions are otherwise equal, only used by all and patch policies
+	includeBuildMeta bool
+	// preReleaseChannel - only pre-releases of this channel are accepted,
+	// ie: "staging" accepts 1.1.2-staging and 1.1.2-staging.3
+	preReleaseChannel string
 }
 
 func (sp *SemverPolicy) ShouldUpdate(current, new string) (bool, error) {
+	if sp.preReleaseChannel != "" {
+		return shouldUpdateChannel(sp.spt, sp.preReleaseChannel, current, new)
+	}
+	if sp.includeBuildMeta && (sp.spt == SemverPolicyTypeAll || sp.spt == SemverPolicyTypePatch) {
+		update, ok := buildMetaUpdate(current, new)
+		if ok {
+			return update, nil
+		}
+	}
 	return shouldUpdate(sp.spt, current, new)
 }
 
@@ -81,7 +102,7 @@ func shouldUpdate(spt SemverPolicyType, current, new string) (bool, error) {
 		return false, fmt.Errorf("failed to parse new version: %s", err)
 	}
 
-	if currentVersion.Prerelease() != newVersion.Prerelease() && spt != SemverPolicyTypeAll {
+	if currentVersion.Prerelease() != newVersion.Prerelease() && spt != SemverPolicyTypeAll && spt != SemverPolicyTypePreRelease {
 		return false, nil
 	}
 
@@ -90,13 +111,89 @@ func shouldUpdate(spt SemverPolicyType, current, new string) (bool, error) {
 		return false, nil
 	}
 
+	return allowedIncrease(spt, currentVersion, newVersion), nil
+}
+
+// allowedIncrease - checks whether increase from current to new version is
+// allowed by the policy type
+func allowedIncrease(spt SemverPolicyType, currentVersion, newVersion *semver.Version) bool {
 	switch spt {
 	case SemverPolicyTypeAll, SemverPolicyTypeMajor:
-		return true, nil
-	case SemverPolicyTypeMinor:
-		return newVersion.Major() == currentVersion.Major(), nil
+		return true
+	case SemverPolicyTypeMinor, SemverPolicyTypePreRelease:
+		return newVersion.Major() == currentVersion.Major()
 	case SemverPolicyTypePatch:
-		return newVersion.Major() == currentVersion.Major() && newVersion.Minor() == currentVersion.Minor(), nil
+		return newVersion.Major() == currentVersion.Major() && newVersion.Minor() == currentVersion.Minor()
+	}
+	return false
+}
+
+// shouldUpdateChannel - only higher pre-releases of the channel are accepted,
+// stable and other channel tags are ignored
+func shouldUpdateChannel(spt SemverPolicyType, channel, current, new string) (bool, error) {
+	if current == "latest" {
+		return true, nil
+	}
+
+	newVersion, err := semver.NewVersion(new)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse new version: %s", err)
+	}
+
+	if preReleaseChannel(newVersion.Prerelease()) != channel {
+		return false, nil
+	}
+
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse current version: %s", err)
+	}
+
+	if !currentVersion.LessThan(newVersion) {
+		return false, nil
+	}
+
+	return allowedIncrease(spt, currentVersion, newVersion), nil
+}
+
+// preReleaseChannel - first pre-release identifier, ie: staging from staging.3
+func preReleaseChannel(prerelease string) string {
+	return strings.SplitN(prerelease, ".", 2)[0]
+}
+
+// buildMetaUpdate - compares numeric build metadata (ie: 1.4.2+build.58 vs 1.4.2+build.57)
+// of versions that are equal otherwise, ok is false when build metadata can't decide
+func buildMetaUpdate(current, new string) (update bool, ok bool) {
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return false, false
+	}
+	newVersion, err := semver.NewVersion(new)
+	if err != nil {
+		return false, false
+	}
+
+	if !currentVersion.Equal(newVersion) || currentVersion.Prerelease() != newVersion.Prerelease() {
+		return false, false
+	}
+
+	currentBuild, err := buildNumber(currentVersion.Metadata())
+	if err != nil {
+		return false, false
+	}
+	newBuild, err := buildNumber(newVersion.Metadata())
+	if err != nil {
+		return false, false
+	}
+
+	return newBuild > currentBuild, true
+}
+
+// buildNumber - last numeric identifier of the build metadata, ie: 57 from build.57
+func buildNumber(metadata string) (uint64, error) {
+	if metadata == "" {
+		return 0, fmt.Errorf("no build metadata")
 	}
-	return false, nil
+	identifiers := strings.Split(metadata, ".")
+	return strconv.ParseUint(identifiers[len(identifiers)-1], 10, 64)
 }
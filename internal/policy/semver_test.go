@@ -176,6 +176,46 @@ func Test_shouldUpdate(t *testing.T) {
 			want:    false,
 			wantErr: false,
 		},
+		{
+			name: "pre-release accepted, policy prerelease",
+			args: args{
+				current: "v1.1.1",
+				new:     "v1.1.2-staging",
+				spt:     SemverPolicyTypePreRelease,
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "pre-release to stable promotion, policy prerelease",
+			args: args{
+				current: "v1.1.2-staging",
+				new:     "v1.1.2",
+				spt:     SemverPolicyTypePreRelease,
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "stable to pre-release demotion, policy prerelease",
+			args: args{
+				current: "v1.1.2",
+				new:     "v1.1.2-staging",
+				spt:     SemverPolicyTypePreRelease,
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "pre-release major increase, policy prerelease",
+			args: args{
+				current: "v1.1.2",
+				new:     "v2.0.0-staging",
+				spt:     SemverPolicyTypePreRelease,
+			},
+			want:    false,
+			wantErr: false,
+		},
 		{
 			name: "number",
 			args: args{
@@ -200,3 +240,81 @@ func Test_shouldUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestSemverPolicyIncludeBuildMeta(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		current string
+		new     string
+		want    bool
+	}{
+		{"higher build, policy all", "all", "1.4.2+build.57", "1.4.2+build.58", true},
+		{"higher build, policy patch", "patch", "1.4.2+build.57", "1.4.2+build.58", true},
+		{"lower build", "all", "1.4.2+build.58", "1.4.2+build.57", false},
+		{"same build", "all", "1.4.2+build.57", "1.4.2+build.57", false},
+		{"numeric build only", "patch", "1.4.2+57", "1.4.2+100", true},
+		{"higher build, policy minor", "minor", "1.4.2+build.57", "1.4.2+build.58", false},
+		{"higher core version, lower build", "all", "1.4.2+build.57", "1.4.3+build.1", true},
+		{"lower core version, higher build", "all", "1.4.2+build.57", "1.4.1+build.99", false},
+		{"non-numeric build", "all", "1.4.2+build.abc", "1.4.2+build.abd", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plc, err := GetPolicy(tt.policy, &Options{IncludeBuildMeta: true})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			got, err := plc.ShouldUpdate(tt.current, tt.new)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("ShouldUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// build metadata is ignored by default
+	plc, _ := GetPolicy("all", &Options{})
+	got, _ := plc.ShouldUpdate("1.4.2+build.57", "1.4.2+build.58")
+	if got {
+		t.Errorf("expected build metadata to be ignored without IncludeBuildMeta")
+	}
+}
+
+func TestSemverPolicyPreReleaseChannel(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		current string
+		new     string
+		want    bool
+	}{
+		{"stable to channel", "minor", "1.1.1", "1.1.2-staging", true},
+		{"channel to higher channel", "minor", "1.1.2-staging", "1.1.3-staging", true},
+		{"numbered channel pre-release", "minor", "1.1.2-staging.1", "1.1.2-staging.2", true},
+		{"stable tag ignored", "minor", "1.1.2-staging", "1.1.3", false},
+		{"other channel ignored", "minor", "1.1.2-staging", "1.1.3-alpha", false},
+		{"lower channel pre-release", "minor", "1.1.3-staging", "1.1.2-staging", false},
+		{"major increase, policy minor", "minor", "1.1.2-staging", "2.0.0-staging", false},
+		{"major increase, policy major", "major", "1.1.2-staging", "2.0.0-staging", true},
+		{"minor increase, policy patch", "patch", "1.1.2-staging", "1.2.0-staging", false},
+		{"patch increase, policy patch", "patch", "1.1.2-staging", "1.1.3-staging", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plc, err := GetPolicy(tt.policy, &Options{PreReleaseChannel: "staging"})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			got, err := plc.ShouldUpdate(tt.current, tt.new)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("ShouldUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
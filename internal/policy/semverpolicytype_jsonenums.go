@@ -9,19 +9,21 @@ import (
 
 var (
 	_SemverPolicyTypeNameToValue = map[string]SemverPolicyType{
-		"SemverPolicyTypeNone":  SemverPolicyTypeNone,
-		"SemverPolicyTypeAll":   SemverPolicyTypeAll,
-		"SemverPolicyTypeMajor": SemverPolicyTypeMajor,
-		"SemverPolicyTypeMinor": SemverPolicyTypeMinor,
-		"SemverPolicyTypePatch": SemverPolicyTypePatch,
+		"SemverPolicyTypeNone":       SemverPolicyTypeNone,
+		"SemverPolicyTypeAll":        SemverPolicyTypeAll,
+		"SemverPolicyTypeMajor":      SemverPolicyTypeMajor,
+		"SemverPolicyTypeMinor":      SemverPolicyTypeMinor,
+		"SemverPolicyTypePatch":      SemverPolicyTypePatch,
+		"SemverPolicyTypePreRelease": SemverPolicyTypePreRelease,
 	}
 
 	_SemverPolicyTypeValueToName = map[SemverPolicyType]string{
-		SemverPolicyTypeNone:  "SemverPolicyTypeNone",
-		SemverPolicyTypeAll:   "SemverPolicyTypeAll",
-		SemverPolicyTypeMajor: "SemverPolicyTypeMajor",
-		SemverPolicyTypeMinor: "SemverPolicyTypeMinor",
-		SemverPolicyTypePatch: "SemverPolicyTypePatch",
+		SemverPolicyTypeNone:       "SemverPolicyTypeNone",
+		SemverPolicyTypeAll:        "SemverPolicyTypeAll",
+		SemverPolicyTypeMajor:      "SemverPolicyTypeMajor",
+		SemverPolicyTypeMinor:      "SemverPolicyTypeMinor",
+		SemverPolicyTypePatch:      "SemverPolicyTypePatch",
+		SemverPolicyTypePreRelease: "SemverPolicyTypePreRelease",
 	}
 )
 
@@ -29,11 +31,12 @@ func init() {
 	var v SemverPolicyType
 	if _, ok := interface{}(v).(fmt.Stringer); ok {
 		_SemverPolicyTypeNameToValue = map[string]SemverPolicyType{
-			interface{}(SemverPolicyTypeNone).(fmt.Stringer).String():  SemverPolicyTypeNone,
-			interface{}(SemverPolicyTypeAll).(fmt.Stringer).String():   SemverPolicyTypeAll,
-			interface{}(SemverPolicyTypeMajor).(fmt.Stringer).String(): SemverPolicyTypeMajor,
-			interface{}(SemverPolicyTypeMinor).(fmt.Stringer).String(): SemverPolicyTypeMinor,
-			interface{}(SemverPolicyTypePatch).(fmt.Stringer).String(): SemverPolicyTypePatch,
+			interface{}(SemverPolicyTypeNone).(fmt.Stringer).String():       SemverPolicyTypeNone,
+			interface{}(SemverPolicyTypeAll).(fmt.Stringer).String():        SemverPolicyTypeAll,
+			interface{}(SemverPolicyTypeMajor).(fmt.Stringer).String():      SemverPolicyTypeMajor,
+			interface{}(SemverPolicyTypeMinor).(fmt.Stringer).String():      SemverPolicyTypeMinor,
+			interface{}(SemverPolicyTypePatch).(fmt.Stringer).String():      SemverPolicyTypePatch,
+			interface{}(SemverPolicyTypePreRelease).(fmt.Stringer).String(): SemverPolicyTypePreRelease,
 		}
 	}
 }
@@ -0,0 +1,87 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimestampDefaultLayout - default layout for timestamp tags, ie: 20240115120000
+const TimestampDefaultLayout = "20060102150405"
+
+// TimestampEpochLayout - special layout for tags that are unix epoch seconds
+const TimestampEpochLayout = "epoch"
+
+// TimestampPolicy - treats tags as timestamps and updates to the latest one. Policy format:
+// timestamp:<layout>[@<timezone>], for example timestamp:20060102150405@Europe/Berlin
+// or timestamp:epoch
+type TimestampPolicy struct {
+	policy   string
+	layout   string
+	location *time.Location
+}
+
+// NewTimestampPolicy - parses timestamp policy string
+func NewTimestampPolicy(policy string) (*TimestampPolicy, error) {
+	p := &TimestampPolicy{
+		policy:   policy,
+		layout:   TimestampDefaultLayout,
+		location: time.UTC,
+	}
+
+	parts := strings.SplitN(policy, ":", 2)
+	if parts[0] != "timestamp" {
+		return nil, fmt.Errorf("invalid timestamp policy: %s", policy)
+	}
+
+	if len(parts) == 1 || parts[1] == "" {
+		return p, nil
+	}
+
+	spec := parts[1]
+	if idx := strings.LastIndex(spec, "@"); idx >= 0 {
+		loc, err := time.LoadLocation(spec[idx+1:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to load timezone, error: %s", err)
+		}
+		p.location = loc
+		spec = spec[:idx]
+	}
+
+	if spec != "" {
+		p.layout = spec
+	}
+
+	return p, nil
+}
+
+func (p *TimestampPolicy) parse(tag string) (time.Time, error) {
+	if p.layout == TimestampEpochLayout {
+		seconds, err := strconv.ParseInt(tag, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(seconds, 0).In(p.location), nil
+	}
+	return time.ParseInLocation(p.layout, tag, p.location)
+}
+
+// ShouldUpdate - new tag has to be a later timestamp than the current one,
+// tags that don't match the layout are ignored
+func (p *TimestampPolicy) ShouldUpdate(current, new string) (bool, error) {
+	newTime, err := p.parse(new)
+	if err != nil {
+		return false, nil
+	}
+
+	currentTime, err := p.parse(current)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse current timestamp: %s", err)
+	}
+
+	return newTime.After(currentTime), nil
+}
+
+func (p *TimestampPolicy) Name() string     { return p.policy }
+func (p *TimestampPolicy) Type() PolicyType { return PolicyTypeTimestamp }
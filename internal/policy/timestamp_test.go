@@ -0,0 +1,116 @@
+package policy
+
+import "testing"
+
+func TestTimestampPolicy_ShouldUpdate(t *testing.T) {
+	type args struct {
+		current string
+		new     string
+	}
+	tests := []struct {
+		name    string
+		policy  string
+		args    args
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:    "later timestamp",
+			policy:  "timestamp",
+			args:    args{current: "20240114235959", new: "20240115120000"},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name:    "earlier timestamp",
+			policy:  "timestamp",
+			args:    args{current: "20240115120000", new: "20240114235959"},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name:    "same timestamp",
+			policy:  "timestamp",
+			args:    args{current: "20240115120000", new: "20240115120000"},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name:    "non timestamp tag ignored",
+			policy:  "timestamp",
+			args:    args{current: "20240115120000", new: "latest"},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name:    "current not a timestamp",
+			policy:  "timestamp",
+			args:    args{current: "latest", new: "20240115120000"},
+			want:    false,
+			wantErr: true,
+		},
+		{
+			name:    "custom layout with timezone",
+			policy:  "timestamp:2006-01-02T15.04@Europe/Berlin",
+			args:    args{current: "2024-01-14T23.59", new: "2024-01-15T12.00"},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name:    "epoch seconds",
+			policy:  "timestamp:epoch",
+			args:    args{current: "1705233599", new: "1705320000"},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name:    "epoch seconds lower",
+			policy:  "timestamp:epoch",
+			args:    args{current: "1705320000", new: "999"},
+			want:    false,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewTimestampPolicy(tt.policy)
+			if err != nil {
+				t.Fatalf("failed to create policy: %s", err)
+			}
+			got, err := p.ShouldUpdate(tt.args.current, tt.args.new)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TimestampPolicy.ShouldUpdate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("TimestampPolicy.ShouldUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTimestampPolicy(t *testing.T) {
+	p, err := NewTimestampPolicy("timestamp:20060102150405@Europe/Berlin")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if p.layout != TimestampDefaultLayout {
+		t.Errorf("unexpected layout: %s", p.layout)
+	}
+	if p.location.String() != "Europe/Berlin" {
+		t.Errorf("unexpected location: %s", p.location)
+	}
+
+	_, err = NewTimestampPolicy("timestamp:20060102150405@Nowhere/Nothing")
+	if err == nil {
+		t.Errorf("expected error for unknown timezone")
+	}
+
+	plc, err := GetPolicy("timestamp:epoch", &Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if plc.Type() != PolicyTypeTimestamp {
+		t.Errorf("unexpected policy type: %d", plc.Type())
+	}
+}
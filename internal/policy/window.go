@@ -0,0 +1,67 @@
+package policy
+
+import (
+	"time"
+
+	"github.com/alwinius/bow/util/timeutil"
+)
+
+// WindowPolicy - wraps another policy and only allows updates inside
+// the update window
+type WindowPolicy struct {
+	policy Policy
+	window *timeutil.UpdateWindow
+}
+
+// NewWindowPolicy - create new update window policy
+func NewWindowPolicy(policy Policy, window *timeutil.UpdateWindow) *WindowPolicy {
+	return &WindowPolicy{
+		policy: policy,
+		window: window,
+	}
+}
+
+// ShouldUpdate - checks wrapped policy, updates outside of the update window are refused
+func (wp *WindowPolicy) ShouldUpdate(current, new string) (bool, error) {
+	should, err := wp.policy.ShouldUpdate(current, new)
+	if err != nil || !should {
+		return should, err
+	}
+
+	return wp.window.Contains(timeutil.Now()), nil
+}
+
+// Name - name of the wrapped policy
+func (wp *WindowPolicy) Name() string { return wp.policy.Name() }
+
+// Type - type of the wrapped policy
+func (wp *WindowPolicy) Type() PolicyType { return wp.policy.Type() }
+
+// Policy - wrapped policy
+func (wp *WindowPolicy) Policy() Policy { return wp.policy }
+
+// Window - update window of the policy
+func (wp *WindowPolicy) Window() *timeutil.UpdateWindow { return wp.window }
+
+// Open - whether updates are allowed at given time
+func (wp *WindowPolicy) Open(t time.Time) bool { return wp.window.Contains(t) }
+
+// Next - when the update window opens next
+func (wp *WindowPolicy) Next(t time.Time) time.Time { return wp.window.Next(t) }
+
+// Unwrap - returns policy wrapped by the update window policy, other
+// policies are returned as is
+func Unwrap(plc Policy) Policy {
+	if wp, ok := plc.(*WindowPolicy); ok {
+		return wp.policy
+	}
+	return plc
+}
+
+// Window - update window of the policy, nil when updates aren't restricted
+func Window(plc Policy) *timeutil.UpdateWindow {
+	if wp, ok := plc.(*WindowPolicy); ok {
+		return wp.window
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/timeutil"
+)
+
+func TestGetPolicyUpdateWindow(t *testing.T) {
+	defer func() { timeutil.Now = time.Now }()
+
+	plc, err := GetPolicyFromLabelsOrAnnotations(map[string]string{}, map[string]string{
+		types.BowPolicyLabel:            "minor",
+		types.BowUpdateWindowAnnotation: "* 2-5 * * 6,0",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wp, ok := plc.(*WindowPolicy)
+	if !ok {
+		t.Fatalf("expected update window policy, got: %T", plc)
+	}
+	if wp.Type() != PolicyTypeSemver || wp.Name() != "minor" {
+		t.Errorf("expected wrapped policy type and name, got: %d %s", wp.Type(), wp.Name())
+	}
+
+	// Saturday 03:00 UTC
+	timeutil.Now = func() time.Time { return time.Date(2024, 1, 13, 3, 0, 0, 0, time.UTC) }
+	should, err := plc.ShouldUpdate("1.0.0", "1.1.0")
+	if err != nil || !should {
+		t.Errorf("expected update inside window, got: %v, %v", should, err)
+	}
+	should, _ = plc.ShouldUpdate("1.0.0", "2.0.0")
+	if should {
+		t.Errorf("wrapped policy should still refuse major update")
+	}
+
+	// Monday 03:00 UTC
+	timeutil.Now = func() time.Time { return time.Date(2024, 1, 15, 3, 0, 0, 0, time.UTC) }
+	should, _ = plc.ShouldUpdate("1.0.0", "1.1.0")
+	if should {
+		t.Errorf("expected update to be refused outside of window")
+	}
+	if next := wp.Next(timeutil.Now()); !next.Equal(time.Date(2024, 1, 20, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected next window: %s", next)
+	}
+	if Window(plc) != wp.Window() || Window(Unwrap(plc)) != nil {
+		t.Errorf("expected update window of the wrapped policy only")
+	}
+}
+
+func TestGetPolicyInvalidUpdateWindow(t *testing.T) {
+	_, err := GetPolicyFromLabelsOrAnnotations(map[string]string{types.BowPolicyLabel: "force"}, map[string]string{
+		types.BowUpdateWindowAnnotation: "* 25 * * *",
+	})
+	if err == nil {
+		t.Errorf("expected invalid update window to be rejected")
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	window, err := timeutil.ParseUpdateWindow("* 2-5 * * *")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fp := NewForcePolicy(false)
+	if Unwrap(NewWindowPolicy(fp, window)) != fp {
+		t.Errorf("expected wrapped policy")
+	}
+	if Unwrap(fp) != fp {
+		t.Errorf("expected policy to be returned as is")
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// EnvConfig - path to the config file, can also be set with --config flag
+const EnvConfig = "BOW_CONFIG"
+
+// Parse - parses YAML config file. Keys are snake_case names of the environment
+// variables they provide defaults for, ie: slack_token sets SLACK_TOKEN. Keys that
+// don't match any of the known environment variables are rejected
+func Parse(data []byte, known []string) (map[string]string, error) {
+	raw := make(map[string]interface{})
+	err := yaml.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %s", err)
+	}
+
+	knownEnv := make(map[string]bool, len(known))
+	for _, env := range known {
+		knownEnv[env] = true
+	}
+
+	var unknown []string
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		env := strings.ToUpper(key)
+		if !knownEnv[env] {
+			unknown = append(unknown, key)
+			continue
+		}
+
+		switch v := value.(type) {
+		case nil:
+			values[env] = ""
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("config key '%s' must be a scalar value", key)
+		case float64:
+			// ghodss/yaml decodes all numbers as float64, keep integers
+			// in the format the environment variable would have
+			values[env] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			values[env] = fmt.Sprint(v)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown config keys: %s", strings.Join(unknown, ", "))
+	}
+
+	return values, nil
+}
+
+// Load - reads config file and sets environment variables that are not
+// already set, environment always takes precedence over the config file
+func Load(path string, known []string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %s", err)
+	}
+
+	values, err := Parse(data, known)
+	if err != nil {
+		return fmt.Errorf("invalid config file %s: %s", path, err)
+	}
+
+	for env, value := range values {
+		if _, ok := os.LookupEnv(env); ok {
+			continue
+		}
+		err = os.Setenv(env, value)
+		if err != nil {
+			return fmt.Errorf("failed to set %s: %s", env, err)
+		}
+	}
+
+	return nil
+}
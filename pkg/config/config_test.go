@@ -0,0 +1,94 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var known = []string{"SLACK_TOKEN", "SLACK_CHANNELS", "POLL", "HIPCHAT_CONNECTION_ATTEMPTS", "DEBUG"}
+
+func TestParse(t *testing.T) {
+	values, err := Parse([]byte(`
+slack_token: xoxb-123
+slack_channels: general
+poll: false
+hipchat_connection_attempts: 5
+debug:
+`), known)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]string{
+		"SLACK_TOKEN":                 "xoxb-123",
+		"SLACK_CHANNELS":              "general",
+		"POLL":                        "false",
+		"HIPCHAT_CONNECTION_ATTEMPTS": "5",
+		"DEBUG":                       "",
+	}
+
+	if len(values) != len(expected) {
+		t.Errorf("expected %d values, got: %d", len(expected), len(values))
+	}
+	for k, v := range expected {
+		if values[k] != v {
+			t.Errorf("expected %s to be '%s', got: '%s'", k, v, values[k])
+		}
+	}
+}
+
+func TestParseUnknownKeys(t *testing.T) {
+	_, err := Parse([]byte(`
+slack_token: xoxb-123
+slak_channels: general
+`), known)
+	if err == nil {
+		t.Fatalf("expected error for unknown key")
+	}
+	if err.Error() != "unknown config keys: slak_channels" {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestParseNestedValue(t *testing.T) {
+	_, err := Parse([]byte(`
+slack_channels:
+  - general
+`), known)
+	if err == nil {
+		t.Fatalf("expected error for non scalar value")
+	}
+}
+
+func TestLoadEnvOverridesConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bowconfig")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.yaml")
+	err = ioutil.WriteFile(path, []byte("slack_token: from-config\nslack_channels: from-config\n"), 0644)
+	if err != nil {
+		t.Fatalf("failed to write config: %s", err)
+	}
+
+	os.Setenv("SLACK_TOKEN", "from-env")
+	os.Unsetenv("SLACK_CHANNELS")
+	defer os.Unsetenv("SLACK_TOKEN")
+	defer os.Unsetenv("SLACK_CHANNELS")
+
+	err = Load(path, known)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if os.Getenv("SLACK_TOKEN") != "from-env" {
+		t.Errorf("expected environment to take precedence, got: %s", os.Getenv("SLACK_TOKEN"))
+	}
+	if os.Getenv("SLACK_CHANNELS") != "from-config" {
+		t.Errorf("expected value from config, got: %s", os.Getenv("SLACK_CHANNELS"))
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/alwinius/bow/approvals"
 	"github.com/alwinius/bow/pkg/store"
 	"github.com/alwinius/bow/types"
 )
@@ -25,24 +27,131 @@ const (
 	actionArchive = "archive"
 )
 
+// approval statuses that can be used to filter approvals list,
+// archived approvals are only returned when filtering by "archived"
+const (
+	approvalStatusArchived = "archived"
+)
+
+type approvalsFilter struct {
+	status    string
+	provider  string
+	namespace string
+}
+
+func parseApprovalsFilter(req *http.Request) (*approvalsFilter, error) {
+	q := req.URL.Query()
+	filter := &approvalsFilter{
+		status:    q.Get("status"),
+		provider:  q.Get("provider"),
+		namespace: q.Get("namespace"),
+	}
+
+	switch filter.status {
+	case "", approvalStatusArchived, types.ApprovalStatusPending.String(), types.ApprovalStatusApproved.String(), types.ApprovalStatusRejected.String():
+		// ok
+	default:
+		return nil, fmt.Errorf("unknown status '%s', expected one of: pending, approved, rejected, archived", filter.status)
+	}
+
+	switch filter.provider {
+	case "", types.ProviderTypeHelm.String(), types.ProviderTypeKubernetes.String():
+		// ok
+	default:
+		return nil, fmt.Errorf("unknown provider '%s', expected one of: helm, kubernetes", filter.provider)
+	}
+
+	return filter, nil
+}
+
+// getApprovalNamespace - extracts namespace from approval identifier, kubernetes
+// identifiers are <kind>/<namespace>/<name>:<version>, helm <namespace>/<name>:<version>
+func getApprovalNamespace(approval *types.Approval) string {
+	parts := strings.Split(approval.Identifier, "/")
+	switch {
+	case approval.Provider == types.ProviderTypeKubernetes && len(parts) == 3:
+		return parts[1]
+	case approval.Provider == types.ProviderTypeHelm && len(parts) == 2:
+		return parts[0]
+	}
+	return ""
+}
+
+func (f *approvalsFilter) matches(approval *types.Approval) bool {
+	switch f.status {
+	case "":
+		// no status filter, listing both active and archived
+	case approvalStatusArchived:
+		if !approval.Archived {
+			return false
+		}
+	default:
+		if approval.Archived || approval.Status().String() != f.status {
+			return false
+		}
+	}
+
+	if f.provider != "" && approval.Provider.String() != f.provider {
+		return false
+	}
+
+	if f.namespace != "" && getApprovalNamespace(approval) != f.namespace {
+		return false
+	}
+
+	return true
+}
+
+// approvalListItem - approval with allowed voters split into
+// the ones that voted and the ones that are still expected to vote
+type approvalListItem struct {
+	*types.Approval
+	VotedVoters   []string `json:"votedVoters,omitempty"`
+	PendingVoters []string `json:"pendingVoters,omitempty"`
+}
+
+func newApprovalListItem(approval *types.Approval) *approvalListItem {
+	item := &approvalListItem{
+		Approval:      approval,
+		PendingVoters: approval.PendingVoters(),
+	}
+	for _, voter := range approval.AllowedVoters {
+		if approval.HasVoter(voter) {
+			item.VotedVoters = append(item.VotedVoters, voter)
+		}
+	}
+	return item
+}
+
+// approvalsHandler - lists approvals, can be filtered with status, provider
+// and namespace query parameters: /v1/approvals?status=pending&provider=helm
 func (s *TriggerServer) approvalsHandler(resp http.ResponseWriter, req *http.Request) {
 
+	filter, err := parseApprovalsFilter(req)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// lists all (both archived)
-	approvals, err := s.store.ListApprovals(&types.GetApprovalQuery{})
+	all, err := s.approvalsManager.List()
 	if err != nil {
-		fmt.Fprintf(resp, "%s", err)
 		resp.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(resp, "%s", err)
 		return
 	}
 
-	if len(approvals) == 0 {
-		approvals = make([]*types.Approval, 0)
+	approvals := make([]*approvalListItem, 0, len(all))
+	for _, approval := range all {
+		if filter.matches(approval) {
+			approvals = append(approvals, newApprovalListItem(approval))
+		}
 	}
 
 	bts, err := json.Marshal(&approvals)
 	if err != nil {
-		fmt.Fprintf(resp, "%s", err)
 		resp.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(resp, "%s", err)
 		return
 	}
 
@@ -185,6 +294,10 @@ func (s *TriggerServer) approvalApproveHandler(resp http.ResponseWriter, req *ht
 		// "" or "approve"
 		approval, err = s.approvalsManager.Approve(ar.Identifier, ar.Voter)
 		if err != nil {
+			if err == approvals.ErrVoterRequired {
+				http.Error(resp, err.Error(), http.StatusBadRequest)
+				return
+			}
 			if err == store.ErrRecordNotFound {
 				http.Error(resp, fmt.Sprintf("approval '%s' not found", ar.Identifier), http.StatusNotFound)
 				return
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
 
@@ -88,6 +89,101 @@ func TestListApprovals(t *testing.T) {
 	}
 }
 
+func TestListApprovalsFiltered(t *testing.T) {
+
+	fp := &fakeProvider{}
+	store, teardown := NewTestingUtils()
+	defer teardown()
+
+	am := approvals.New(&approvals.Opts{
+		Store: store,
+	})
+
+	authenticator := auth.New(&auth.Opts{
+		Username: "admin",
+		Password: "pass",
+	})
+
+	providers := provider.New([]provider.Provider{fp}, am)
+	srv := NewTriggerServer(&Opts{
+		Providers:       providers,
+		ApprovalManager: am,
+		Authenticator:   authenticator,
+		Store:           store,
+	})
+	srv.registerRoutes(srv.router)
+
+	for _, a := range []*types.Approval{
+		{Provider: types.ProviderTypeHelm, Identifier: "dev/whd-dev:0.0.15", VotesRequired: 1},
+		{Provider: types.ProviderTypeHelm, Identifier: "prod/whd-prod:0.0.15", VotesRequired: 1},
+		{Provider: types.ProviderTypeKubernetes, Identifier: "deployment/dev/wd:1.0.0", VotesRequired: 1},
+		{Provider: types.ProviderTypeKubernetes, Identifier: "deployment/dev/other:1.0.0", VotesRequired: 1, Rejected: true},
+	} {
+		err := am.Create(a)
+		if err != nil {
+			t.Fatalf("failed to create approval: %s", err)
+		}
+	}
+
+	err := am.Archive("prod/whd-prod:0.0.15")
+	if err != nil {
+		t.Fatalf("failed to archive approval: %s", err)
+	}
+
+	tests := []struct {
+		query    string
+		code     int
+		expected []string
+	}{
+		{query: "", code: 200, expected: []string{"dev/whd-dev:0.0.15", "prod/whd-prod:0.0.15", "deployment/dev/wd:1.0.0", "deployment/dev/other:1.0.0"}},
+		{query: "?status=pending&provider=helm", code: 200, expected: []string{"dev/whd-dev:0.0.15"}},
+		{query: "?status=archived", code: 200, expected: []string{"prod/whd-prod:0.0.15"}},
+		{query: "?status=rejected", code: 200, expected: []string{"deployment/dev/other:1.0.0"}},
+		{query: "?provider=kubernetes&namespace=dev", code: 200, expected: []string{"deployment/dev/wd:1.0.0", "deployment/dev/other:1.0.0"}},
+		{query: "?namespace=prod", code: 200, expected: []string{"prod/whd-prod:0.0.15"}},
+		{query: "?status=unknown", code: 400},
+		{query: "?provider=docker", code: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/v1/approvals"+tt.query, nil)
+			if err != nil {
+				t.Fatalf("failed to create req: %s", err)
+			}
+			req.SetBasicAuth("admin", "pass")
+
+			rec := httptest.NewRecorder()
+			srv.router.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+			}
+			if tt.code != 200 {
+				return
+			}
+
+			var approvals []*types.Approval
+			err = json.Unmarshal(rec.Body.Bytes(), &approvals)
+			if err != nil {
+				t.Fatalf("failed to unmarshal response into approvals: %s", err)
+			}
+
+			found := map[string]bool{}
+			for _, a := range approvals {
+				found[a.Identifier] = true
+			}
+			if len(approvals) != len(tt.expected) {
+				t.Errorf("expected %d approvals, got: %d", len(tt.expected), len(approvals))
+			}
+			for _, ident := range tt.expected {
+				if !found[ident] {
+					t.Errorf("expected to find approval %s", ident)
+				}
+			}
+		})
+	}
+}
+
 func TestDeleteApproval(t *testing.T) {
 	fp := &fakeProvider{}
 	store, teardown := NewTestingUtils()
@@ -209,6 +305,62 @@ func TestApprove(t *testing.T) {
 	}
 }
 
+func TestApproveAnonymous(t *testing.T) {
+	fp := &fakeProvider{}
+	store, teardown := NewTestingUtils()
+	defer teardown()
+
+	am := approvals.New(&approvals.Opts{
+		Store: store,
+	})
+	authenticator := auth.New(&auth.Opts{
+		Username: "admin",
+		Password: "pass",
+	})
+
+	providers := provider.New([]provider.Provider{fp}, am)
+	srv := NewTriggerServer(&Opts{
+		Providers:       providers,
+		ApprovalManager: am,
+		Authenticator:   authenticator,
+		Store:           store,
+	})
+	srv.registerRoutes(srv.router)
+
+	err := am.Create(&types.Approval{
+		Identifier:     "dev/whd-dev:0.0.15",
+		VotesRequired:  5,
+		NewVersion:     "2.0.0",
+		CurrentVersion: "1.0.0",
+	})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest("POST", "/v1/approvals", bytes.NewBufferString(`{"identifier": "dev/whd-dev:0.0.15"}`))
+		if err != nil {
+			t.Fatalf("failed to create req: %s", err)
+		}
+		req.SetBasicAuth("admin", "pass")
+
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		if rec.Code != 200 {
+			t.Errorf("unexpected status code: %d", rec.Code)
+			t.Log(rec.Body.String())
+		}
+	}
+
+	approved, err := am.Get("dev/whd-dev:0.0.15")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if approved.VotesReceived != 2 {
+		t.Errorf("expected anonymous votes to be counted, got: %d", approved.VotesReceived)
+	}
+}
+
 func TestApproveNotFound(t *testing.T) {
 	fp := &fakeProvider{}
 	store, teardown := NewTestingUtils()
@@ -609,3 +761,86 @@ func TestAuthListApprovalsB(t *testing.T) {
 		t.Errorf("unexpected current version: %s", approvals[0].CurrentVersion)
 	}
 }
+
+func TestListApprovalsVoters(t *testing.T) {
+	fp := &fakeProvider{}
+	store, teardown := NewTestingUtils()
+	defer teardown()
+
+	am := approvals.New(&approvals.Opts{
+		Store: store,
+	})
+	authenticator := auth.New(&auth.Opts{
+		Username: "admin",
+		Password: "pass",
+	})
+
+	providers := provider.New([]provider.Provider{fp}, am)
+	srv := NewTriggerServer(&Opts{
+		Providers:       providers,
+		ApprovalManager: am,
+		Authenticator:   authenticator,
+		Store:           store,
+	})
+	srv.registerRoutes(srv.router)
+
+	err := am.Create(&types.Approval{
+		Identifier:     "dev/12345",
+		VotesRequired:  2,
+		NewVersion:     "2.0.0",
+		CurrentVersion: "1.0.0",
+		AllowedVoters:  types.StringList{"alice", "bob", "carol"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+
+	for _, voter := range []string{"alice", "mallory"} {
+		req, err := http.NewRequest("POST", "/v1/approvals", bytes.NewBufferString(`{"voter": "`+voter+`", "identifier": "dev/12345"}`))
+		if err != nil {
+			t.Fatalf("failed to create req: %s", err)
+		}
+		req.SetBasicAuth("admin", "pass")
+
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		if rec.Code != 200 {
+			t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	req, err := http.NewRequest("GET", "/v1/approvals", nil)
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+	req.SetBasicAuth("admin", "pass")
+
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	var items []*approvalListItem
+	err = json.Unmarshal(rec.Body.Bytes(), &items)
+	if err != nil {
+		t.Fatalf("failed to unmarshal response: %s", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 approval, got: %d", len(items))
+	}
+
+	// mallory's vote is recorded but doesn't count
+	if items[0].VotesReceived != 1 {
+		t.Errorf("expected 1 counted vote, got: %d", items[0].VotesReceived)
+	}
+	if !items[0].HasVoter("mallory") {
+		t.Errorf("expected unlisted vote to be recorded")
+	}
+	if !reflect.DeepEqual(items[0].VotedVoters, []string{"alice"}) {
+		t.Errorf("unexpected voted voters: %v", items[0].VotedVoters)
+	}
+	if !reflect.DeepEqual(items[0].PendingVoters, []string{"bob", "carol"}) {
+		t.Errorf("unexpected pending voters: %v", items[0].PendingVoters)
+	}
+}
@@ -0,0 +1,255 @@
+package http
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/alwinius/bow/approvals"
+	"github.com/alwinius/bow/pkg/store"
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/timeutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// approvalSignatureHeader - HMAC-SHA256 signature of the request method, path,
+// timestamp and body, signed with the shared approvals secret,
+// format: sha256=<hex encoded signature>
+const approvalSignatureHeader = "X-Bow-Signature"
+
+// approvalTimestampHeader - unix time (seconds) the request was signed at
+const approvalTimestampHeader = "X-Bow-Timestamp"
+
+const approvalSignaturePrefix = "sha256="
+
+// approvalSignatureMaxAge - signed requests older (or further in the future) than
+// this are rejected so captured requests can't be replayed later
+const approvalSignatureMaxAge = 5 * time.Minute
+
+type approvalVoteRequest struct {
+	Voter string `json:"voter"`
+}
+
+type approvalVoteResponse struct {
+	*types.Approval
+	VotesRemaining int `json:"votesRemaining"`
+}
+
+// registerApprovalVoteRoutes - signed voting doesn't need admin credentials so
+// these routes are registered only when the shared secret is configured.
+// Identifiers contain slashes (deployment/default/wd:1.0.0) so they are matched greedily
+func (s *TriggerServer) registerApprovalVoteRoutes(mux *mux.Router) {
+	if len(s.approvalsSigningSecret) == 0 {
+		return
+	}
+
+	mux.HandleFunc("/v1/approvals/{identifier:.+}/approve", s.requireApprovalSignature(s.approvalVoteHandler(actionApprove))).Methods("POST", "OPTIONS")
+	mux.HandleFunc("/v1/approvals/{identifier:.+}/reject", s.requireApprovalSignature(s.approvalVoteHandler(actionReject))).Methods("POST", "OPTIONS")
+	// one-time links from approval requested notifications
+	mux.HandleFunc(approvals.ApproveLinkPath, s.approveLinkHandler).Methods("GET", "POST")
+}
+
+// signApprovalVote - calculates signature for the given request, method and path
+// are signed so the signature can't be replayed against another approval or action
+func signApprovalVote(secret []byte, method, path, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(method + "\n" + path + "\n" + timestamp + "\n"))
+	mac.Write(body)
+	return approvalSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// requireApprovalSignature - verifies request signature and timestamp before passing
+// it to the handler, body is restored so handlers can decode it
+func (s *TriggerServer) requireApprovalSignature(next http.HandlerFunc) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodOptions {
+			return
+		}
+
+		signature := req.Header.Get(approvalSignatureHeader)
+		if !strings.HasPrefix(signature, approvalSignaturePrefix) {
+			http.Error(resp, fmt.Sprintf("missing or malformed %s header", approvalSignatureHeader), http.StatusUnauthorized)
+			return
+		}
+
+		timestamp := req.Header.Get(approvalTimestampHeader)
+		signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			http.Error(resp, fmt.Sprintf("missing or malformed %s header", approvalTimestampHeader), http.StatusUnauthorized)
+			return
+		}
+		age := timeutil.Now().Sub(time.Unix(signedAt, 0))
+		if age > approvalSignatureMaxAge || age < -approvalSignatureMaxAge {
+			log.WithFields(log.Fields{
+				"identifier": mux.Vars(req)["identifier"],
+				"remote":     req.RemoteAddr,
+				"age":        age,
+			}).Warn("http.requireApprovalSignature: stale approval vote timestamp")
+			http.Error(resp, "stale timestamp", http.StatusUnauthorized)
+			return
+		}
+
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			http.Error(resp, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		expected := signApprovalVote(s.approvalsSigningSecret, req.Method, req.URL.Path, timestamp, body)
+		if !hmac.Equal([]byte(signature), []byte(expected)) {
+			log.WithFields(log.Fields{
+				"identifier": mux.Vars(req)["identifier"],
+				"remote":     req.RemoteAddr,
+			}).Warn("http.requireApprovalSignature: invalid approval vote signature")
+			http.Error(resp, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		next(resp, req)
+	}
+}
+
+// approveLinkConfirmTemplate - page shown when approve link is opened, link
+// previews (chat unfurling, mail scanners) only GET it so the vote is recorded
+// once the form is submitted
+var approveLinkConfirmTemplate = template.Must(template.New("approve-link").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Approve {{.Identifier}}</title></head>
+<body>
+<p>Approve {{.Identifier}} ({{.CurrentVersion}} -> {{.NewVersion}}), votes: {{.VotesReceived}}/{{.VotesRequired}}</p>
+<form method="POST" action="{{.Action}}">
+<button type="submit">Approve</button>
+</form>
+</body>
+</html>
+`))
+
+type approveLinkConfirmPage struct {
+	*types.Approval
+	Action string
+}
+
+// approveLinkHandler - GET renders confirmation page of the one-time approve link,
+// POST records its vote, using the same link again doesn't count as another vote
+func (s *TriggerServer) approveLinkHandler(resp http.ResponseWriter, req *http.Request) {
+	identifier, voter, err := approvals.VerifyApproveLink(s.approvalsSigningSecret, req.URL.Query(), timeutil.Now())
+	if err != nil {
+		code := http.StatusUnauthorized
+		if err == approvals.ErrApproveLinkExpired {
+			code = http.StatusGone
+		}
+		log.WithFields(log.Fields{
+			"error":      err,
+			"identifier": req.URL.Query().Get("identifier"),
+			"remote":     req.RemoteAddr,
+		}).Warn("http.approveLinkHandler: approve link rejected")
+		http.Error(resp, err.Error(), code)
+		return
+	}
+
+	if req.Method == http.MethodGet {
+		existing, err := s.approvalsManager.Get(identifier)
+		if err != nil {
+			if err == store.ErrRecordNotFound {
+				http.Error(resp, fmt.Sprintf("approval '%s' not found", identifier), http.StatusNotFound)
+				return
+			}
+			http.Error(resp, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if existing.HasVoter(voter) {
+			http.Error(resp, "approve link was already used", http.StatusConflict)
+			return
+		}
+
+		resp.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = approveLinkConfirmTemplate.Execute(resp, &approveLinkConfirmPage{Approval: existing, Action: req.URL.RequestURI()})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":      err,
+				"identifier": identifier,
+			}).Error("http.approveLinkHandler: failed to render confirmation page")
+		}
+		return
+	}
+
+	approval, err := s.approvalsManager.ApproveOnce(identifier, voter)
+	if err != nil {
+		switch err {
+		case store.ErrRecordNotFound:
+			http.Error(resp, fmt.Sprintf("approval '%s' not found", identifier), http.StatusNotFound)
+		case approvals.ErrAlreadyVoted:
+			http.Error(resp, "approve link was already used", http.StatusConflict)
+		default:
+			http.Error(resp, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(resp, "Approved %s, votes: %d/%d\n", identifier, approval.VotesReceived, approval.VotesRequired)
+}
+
+// approvalVoteHandler - approves or rejects approval identified in the path,
+// body is optional and can specify voter: {"voter": "john"}
+func (s *TriggerServer) approvalVoteHandler(action string) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		identifier := mux.Vars(req)["identifier"]
+
+		var vr approvalVoteRequest
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			http.Error(resp, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		if len(bytes.TrimSpace(body)) > 0 {
+			err = json.Unmarshal(body, &vr)
+			if err != nil {
+				http.Error(resp, fmt.Sprintf("failed to decode request: %s", err), http.StatusBadRequest)
+				return
+			}
+		}
+
+		var approval *types.Approval
+		switch action {
+		case actionReject:
+			approval, err = s.approvalsManager.Reject(identifier)
+		default:
+			if vr.Voter == "" {
+				http.Error(resp, "voter cannot be empty", http.StatusBadRequest)
+				return
+			}
+			approval, err = s.approvalsManager.Approve(identifier, vr.Voter)
+		}
+		if err != nil {
+			if err == store.ErrRecordNotFound {
+				http.Error(resp, fmt.Sprintf("approval '%s' not found", identifier), http.StatusNotFound)
+				return
+			}
+			resp.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(resp, "%s", err)
+			return
+		}
+
+		remaining := approval.VotesRequired - approval.VotesReceived
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		response(&approvalVoteResponse{Approval: approval, VotesRemaining: remaining}, http.StatusOK, nil, resp, req)
+	}
+}
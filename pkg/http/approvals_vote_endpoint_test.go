@@ -0,0 +1,363 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/approvals"
+	"github.com/alwinius/bow/pkg/auth"
+	"github.com/alwinius/bow/provider"
+	"github.com/alwinius/bow/types"
+)
+
+func newSignedVoteServer(t *testing.T) (*TriggerServer, approvals.Manager, func()) {
+	store, teardown := NewTestingUtils()
+
+	am := approvals.New(&approvals.Opts{
+		Store: store,
+	})
+
+	srv := NewTriggerServer(&Opts{
+		Providers:              provider.New([]provider.Provider{&fakeProvider{}}, am),
+		ApprovalManager:        am,
+		Authenticator:          auth.New(&auth.Opts{}),
+		Store:                  store,
+		ApprovalsSigningSecret: []byte("very-secret"),
+	})
+	srv.registerRoutes(srv.router)
+
+	err := am.Create(&types.Approval{
+		Identifier:     "deployment/default/wd:2.0.0",
+		VotesRequired:  2,
+		NewVersion:     "2.0.0",
+		CurrentVersion: "1.0.0",
+	})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+
+	return srv, am, teardown
+}
+
+func signedVoteRequest(t *testing.T, path, secret string, body []byte) *http.Request {
+	return signedVoteRequestAt(t, path, secret, body, time.Now())
+}
+
+func signedVoteRequestAt(t *testing.T, path, secret string, body []byte, signedAt time.Time) *http.Request {
+	req, err := http.NewRequest("POST", path, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	req.Header.Set(approvalTimestampHeader, timestamp)
+	req.Header.Set(approvalSignatureHeader, signApprovalVote([]byte(secret), "POST", req.URL.Path, timestamp, body))
+	return req
+}
+
+func TestSignedApprove(t *testing.T) {
+	srv, am, teardown := newSignedVoteServer(t)
+	defer teardown()
+
+	req := signedVoteRequest(t, "/v1/approvals/deployment/default/wd:2.0.0/approve", "very-secret", []byte(`{"voter": "john"}`))
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp approvalVoteResponse
+	err := json.Unmarshal(rec.Body.Bytes(), &resp)
+	if err != nil {
+		t.Fatalf("failed to unmarshal response: %s", err)
+	}
+
+	if resp.VotesReceived != 1 {
+		t.Errorf("expected 1 vote, got: %d", resp.VotesReceived)
+	}
+	if resp.VotesRemaining != 1 {
+		t.Errorf("expected 1 remaining vote, got: %d", resp.VotesRemaining)
+	}
+
+	stored, err := am.Get("deployment/default/wd:2.0.0")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if stored.VotesReceived != 1 {
+		t.Errorf("expected stored approval to have 1 vote, got: %d", stored.VotesReceived)
+	}
+}
+
+func TestSignedReject(t *testing.T) {
+	srv, am, teardown := newSignedVoteServer(t)
+	defer teardown()
+
+	req := signedVoteRequest(t, "/v1/approvals/deployment/default/wd:2.0.0/reject", "very-secret", nil)
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	stored, err := am.Get("deployment/default/wd:2.0.0")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if !stored.Rejected {
+		t.Errorf("expected approval to be rejected")
+	}
+}
+
+func TestSignedApproveInvalidSignature(t *testing.T) {
+	srv, am, teardown := newSignedVoteServer(t)
+	defer teardown()
+
+	req := signedVoteRequest(t, "/v1/approvals/deployment/default/wd:2.0.0/approve", "wrong-secret", []byte(`{"voter": "john"}`))
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected unauthorized, got: %d", rec.Code)
+	}
+
+	stored, err := am.Get("deployment/default/wd:2.0.0")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if stored.VotesReceived != 0 {
+		t.Errorf("expected no votes, got: %d", stored.VotesReceived)
+	}
+}
+
+func TestSignedApproveStaleTimestamp(t *testing.T) {
+	srv, am, teardown := newSignedVoteServer(t)
+	defer teardown()
+
+	req := signedVoteRequestAt(t, "/v1/approvals/deployment/default/wd:2.0.0/approve", "very-secret", []byte(`{"voter": "john"}`), time.Now().Add(-time.Hour))
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected unauthorized, got: %d", rec.Code)
+	}
+
+	stored, err := am.Get("deployment/default/wd:2.0.0")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if stored.VotesReceived != 0 {
+		t.Errorf("expected no votes, got: %d", stored.VotesReceived)
+	}
+}
+
+func TestSignedApproveReplayedForOtherIdentifier(t *testing.T) {
+	srv, am, teardown := newSignedVoteServer(t)
+	defer teardown()
+
+	err := am.Create(&types.Approval{
+		Identifier:     "deployment/default/other:2.0.0",
+		VotesRequired:  2,
+		NewVersion:     "2.0.0",
+		CurrentVersion: "1.0.0",
+	})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+
+	signed := signedVoteRequest(t, "/v1/approvals/deployment/default/wd:2.0.0/approve", "very-secret", []byte(`{"voter": "john"}`))
+
+	// same body, timestamp and signature sent for another approval
+	req, err := http.NewRequest("POST", "/v1/approvals/deployment/default/other:2.0.0/approve", bytes.NewReader([]byte(`{"voter": "john"}`)))
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+	req.Header = signed.Header
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected unauthorized, got: %d", rec.Code)
+	}
+
+	stored, err := am.Get("deployment/default/other:2.0.0")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if stored.VotesReceived != 0 {
+		t.Errorf("expected no votes, got: %d", stored.VotesReceived)
+	}
+}
+
+func TestSignedApproveNotFound(t *testing.T) {
+	srv, _, teardown := newSignedVoteServer(t)
+	defer teardown()
+
+	req := signedVoteRequest(t, "/v1/approvals/deployment/default/other:2.0.0/approve", "very-secret", []byte(`{"voter": "john"}`))
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected not found, got: %d", rec.Code)
+	}
+}
+
+func TestApproveLink(t *testing.T) {
+	srv, am, teardown := newSignedVoteServer(t)
+	defer teardown()
+
+	link := approvals.ApproveLink("https://bow.example.com/", []byte("very-secret"), "deployment/default/wd:2.0.0", "", time.Now().Add(time.Hour))
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("failed to parse link: %s", err)
+	}
+	if u.Path != approvals.ApproveLinkPath {
+		t.Errorf("unexpected link path: %s", u.Path)
+	}
+
+	visit := func(method string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, u.RequestURI(), nil)
+		if err != nil {
+			t.Fatalf("failed to create req: %s", err)
+		}
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// opening the link only shows confirmation
+	rec := visit("GET")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `<form method="POST"`) {
+		t.Errorf("expected confirmation form, got: %s", rec.Body.String())
+	}
+
+	stored, err := am.Get("deployment/default/wd:2.0.0")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if stored.VotesReceived != 0 {
+		t.Errorf("expected no votes before confirmation, got: %d", stored.VotesReceived)
+	}
+
+	rec = visit("POST")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	// link is one-time
+	rec = visit("POST")
+	if rec.Code != http.StatusConflict {
+		t.Errorf("unexpected status code for used link: %d, body: %s", rec.Code, rec.Body.String())
+	}
+	rec = visit("GET")
+	if rec.Code != http.StatusConflict {
+		t.Errorf("unexpected status code for opening used link: %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	stored, err = am.Get("deployment/default/wd:2.0.0")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if stored.VotesReceived != 1 {
+		t.Errorf("expected stored approval to have 1 vote, got: %d", stored.VotesReceived)
+	}
+}
+
+func TestApproveLinkAllowedVoters(t *testing.T) {
+	srv, am, teardown := newSignedVoteServer(t)
+	defer teardown()
+
+	err := am.Create(&types.Approval{
+		Identifier:     "deployment/default/limited:2.0.0",
+		VotesRequired:  2,
+		NewVersion:     "2.0.0",
+		CurrentVersion: "1.0.0",
+		AllowedVoters:  types.StringList{"alice", "bob"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+
+	vote := func(voter string) {
+		u, err := url.Parse(approvals.ApproveLink("http://bow", []byte("very-secret"), "deployment/default/limited:2.0.0", voter, time.Now().Add(time.Hour)))
+		if err != nil {
+			t.Fatalf("failed to parse link: %s", err)
+		}
+		req, err := http.NewRequest("POST", u.RequestURI(), nil)
+		if err != nil {
+			t.Fatalf("failed to create req: %s", err)
+		}
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	// anonymous link is recorded but doesn't count
+	vote("")
+	vote("alice")
+
+	stored, err := am.Get("deployment/default/limited:2.0.0")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if !stored.HasVoter("alice") {
+		t.Errorf("expected vote to be recorded as alice, got: %v", stored.GetVoters())
+	}
+	if stored.VotesReceived != 1 {
+		t.Errorf("expected only alice's vote to count, got: %d", stored.VotesReceived)
+	}
+	if len(stored.PendingVoters()) != 1 || stored.PendingVoters()[0] != "bob" {
+		t.Errorf("unexpected pending voters: %v", stored.PendingVoters())
+	}
+}
+
+func TestApproveLinkInvalid(t *testing.T) {
+	srv, _, teardown := newSignedVoteServer(t)
+	defer teardown()
+
+	tests := []struct {
+		name string
+		link string
+		want int
+	}{
+		{
+			name: "wrong secret",
+			link: approvals.ApproveLink("http://bow", []byte("other-secret"), "deployment/default/wd:2.0.0", "", time.Now().Add(time.Hour)),
+			want: http.StatusUnauthorized,
+		},
+		{
+			name: "expired",
+			link: approvals.ApproveLink("http://bow", []byte("very-secret"), "deployment/default/wd:2.0.0", "", time.Now().Add(-time.Minute)),
+			want: http.StatusGone,
+		},
+		{
+			name: "not found",
+			link: approvals.ApproveLink("http://bow", []byte("very-secret"), "deployment/default/wd:3.0.0", "", time.Now().Add(time.Hour)),
+			want: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.link)
+			if err != nil {
+				t.Fatalf("failed to parse link: %s", err)
+			}
+			req, err := http.NewRequest("GET", u.RequestURI(), nil)
+			if err != nil {
+				t.Fatalf("failed to create req: %s", err)
+			}
+			rec := httptest.NewRecorder()
+			srv.router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("unexpected status code: %d, want: %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/alwinius/bow/util/deadletter"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type deadLettersResponse struct {
+	Data []deadletter.Entry `json:"data"`
+}
+
+// deadLettersHandler - lists events that kept failing until circuit
+// for their image opened
+func (s *TriggerServer) deadLettersHandler(resp http.ResponseWriter, req *http.Request) {
+	response(&deadLettersResponse{Data: s.deadLetters.List()}, http.StatusOK, nil, resp, req)
+}
+
+// deadLetterRetryHandler - requeues failed event once the underlying issue
+// is resolved, the event is processed even if circuit for its image is open
+func (s *TriggerServer) deadLetterRetryHandler(resp http.ResponseWriter, req *http.Request) {
+	entry, err := s.deadLetters.Retry(getID(req))
+	if err == deadletter.ErrNotFound {
+		http.Error(resp, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusConflict)
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"id":         entry.ID,
+		"provider":   entry.Provider,
+		"repository": entry.Event.Repository.Name,
+		"tag":        entry.Event.Repository.Tag,
+	}).Info("trigger.deadLetterRetryHandler: dead letter requeued")
+
+	response(entry, http.StatusAccepted, nil, resp, req)
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/deadletter"
+)
+
+func TestDeadLetterEndpoints(t *testing.T) {
+	fp := &fakeProvider{}
+	srv, teardown := NewTestingServer(fp)
+	defer teardown()
+
+	var retried []*types.Event
+	dlq := deadletter.New(10)
+	dlq.Register("kubernetes", func(event *types.Event) {
+		retried = append(retried, event)
+	})
+	entry := dlq.Add("kubernetes", types.Event{Repository: types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.0"}}, errors.New("failed to push"), 5)
+
+	srv.deadLetters = dlq
+	srv.router = mux.NewRouter()
+	srv.registerRoutes(srv.router)
+
+	req, err := http.NewRequest("GET", "/v1/dead-letter", nil)
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+	req.SetBasicAuth("user-1", "secret")
+
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	var listResp deadLettersResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &listResp)
+	if err != nil {
+		t.Fatalf("failed to unmarshal response: %s", err)
+	}
+	if len(listResp.Data) != 1 {
+		t.Fatalf("expected 1 dead letter, got: %d", len(listResp.Data))
+	}
+	if listResp.Data[0].Error != "failed to push" || listResp.Data[0].Retries != 5 {
+		t.Errorf("unexpected dead letter: %+v", listResp.Data[0])
+	}
+
+	req, err = http.NewRequest("POST", "/v1/dead-letter/"+entry.ID+"/retry", nil)
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+	req.SetBasicAuth("user-1", "secret")
+
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+	if len(retried) != 1 || retried[0].Repository.Tag != "1.2.0" {
+		t.Errorf("expected event to be requeued, got: %v", retried)
+	}
+
+	// already retried
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+}
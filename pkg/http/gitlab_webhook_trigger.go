@@ -0,0 +1,147 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/image"
+	"github.com/prometheus/client_golang/prometheus"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var newGitlabWebhooksCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "gitlab_webhook_requests_total",
+		Help: "How many /v1/webhooks/gitlab requests processed, partitioned by image.",
+	},
+	[]string{"image"},
+)
+
+func init() {
+	prometheus.MustRegister(newGitlabWebhooksCounter)
+}
+
+// gitlabTokenHeader - secret token configured on the GitLab webhook
+const gitlabTokenHeader = "X-Gitlab-Token"
+
+// gitlabRegistryObjectKind - hooks of other kinds (pushes, merge requests, etc.)
+// are sent to the same URL and ignored
+const gitlabRegistryObjectKind = "build"
+
+// Example of GitLab container registry push hook
+// {
+//   "object_kind": "build",
+//   "project": {
+//     "path_with_namespace": "mygroup/myproject"
+//   },
+//   "registry": {
+//     "path": "registry.gitlab.com/mygroup/myproject/app",
+//     "tag": "1.2.3"
+//   }
+// }
+// tag can also be part of the path: registry.gitlab.com/mygroup/myproject/app:1.2.3
+
+type gitlabWebhook struct {
+	ObjectKind string `json:"object_kind"`
+	Registry   struct {
+		Path   string `json:"path"`
+		Tag    string `json:"tag"`
+		Digest string `json:"digest"`
+	} `json:"registry"`
+}
+
+// gitlabWebhookAuthorization - GitLab sends its secret token in a header, when
+// the GitLab secret is set it replaces the fallback authorization (if any)
+func (s *TriggerServer) gitlabWebhookAuthorization(fallback func(http.HandlerFunc) http.HandlerFunc) http.HandlerFunc {
+	if s.gitlabWebhookSecret != "" {
+		return s.requireGitlabToken(s.gitlabHandler)
+	}
+	if fallback != nil {
+		return fallback(s.gitlabHandler)
+	}
+	return s.gitlabHandler
+}
+
+func (s *TriggerServer) requireGitlabToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodOptions {
+			return
+		}
+
+		token := req.Header.Get(gitlabTokenHeader)
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.gitlabWebhookSecret)) != 1 {
+			log.WithFields(log.Fields{
+				"remote": req.RemoteAddr,
+			}).Warn("trigger.gitlabHandler: invalid token")
+			http.Error(resp, "invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		next(resp, req)
+	}
+}
+
+// gitlabEvent - builds event from the registry path, tag and digest
+// can be either separate fields or part of the path
+func gitlabEvent(gw *gitlabWebhook) (*types.Event, error) {
+	if gw.Registry.Path == "" {
+		return nil, fmt.Errorf("registry.path cannot be empty")
+	}
+
+	name, digest := image.SplitDigest(gw.Registry.Path)
+	name, tag := image.SplitTag(name)
+	if gw.Registry.Tag != "" {
+		tag = gw.Registry.Tag
+	}
+	if gw.Registry.Digest != "" {
+		digest = gw.Registry.Digest
+	}
+	if tag == "" {
+		return nil, fmt.Errorf("registry.tag cannot be empty")
+	}
+
+	event := &types.Event{}
+	event.CreatedAt = time.Now()
+	event.TriggerName = "gitlab"
+	event.Repository.Name = name
+	event.Repository.Tag = tag
+	event.Repository.Digest = digest
+
+	return event, nil
+}
+
+func (s *TriggerServer) gitlabHandler(resp http.ResponseWriter, req *http.Request) {
+	gw := gitlabWebhook{}
+	if err := json.NewDecoder(req.Body).Decode(&gw); err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Error("trigger.gitlabHandler: failed to decode request")
+		resp.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if gw.ObjectKind != gitlabRegistryObjectKind {
+		log.WithFields(log.Fields{
+			"object_kind": gw.ObjectKind,
+		}).Debug("trigger.gitlabHandler: ignoring hook")
+		resp.WriteHeader(http.StatusOK)
+		return
+	}
+
+	event, err := gitlabEvent(&gw)
+	if err != nil {
+		resp.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(resp, "%s", err)
+		return
+	}
+
+	s.trigger(*event)
+	newGitlabWebhooksCounter.With(prometheus.Labels{"image": event.Repository.Name}).Inc()
+
+	resp.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alwinius/bow/approvals"
+	"github.com/alwinius/bow/pkg/auth"
+	"github.com/alwinius/bow/provider"
+)
+
+var fakeGitlabWebhook = `{
+  "object_kind": "build",
+  "project": {
+    "path_with_namespace": "mygroup/myproject"
+  },
+  "registry": {
+    "path": "registry.gitlab.com/mygroup/myproject/app",
+    "tag": "1.2.3"
+  }
+}
+`
+
+func TestGitlabWebhookHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantCode   int
+		wantName   string
+		wantTag    string
+		wantDigest string
+	}{
+		{
+			name:     "path and tag",
+			body:     fakeGitlabWebhook,
+			wantCode: 200,
+			wantName: "registry.gitlab.com/mygroup/myproject/app",
+			wantTag:  "1.2.3",
+		},
+		{
+			name:     "tag in path",
+			body:     `{"object_kind": "build", "registry": {"path": "registry.gitlab.com/mygroup/myproject/app:1.2.3"}}`,
+			wantCode: 200,
+			wantName: "registry.gitlab.com/mygroup/myproject/app",
+			wantTag:  "1.2.3",
+		},
+		{
+			name:       "tag and digest in path",
+			body:       `{"object_kind": "build", "registry": {"path": "registry.gitlab.com/mygroup/app:1.2.3@sha256:a4a7bd4b3a4f1cb6dd2fdd3f2e2cde2b7d6c41a8df2bdb6ac1a4f2e2c4b0e7a1"}}`,
+			wantCode:   200,
+			wantName:   "registry.gitlab.com/mygroup/app",
+			wantTag:    "1.2.3",
+			wantDigest: "sha256:a4a7bd4b3a4f1cb6dd2fdd3f2e2cde2b7d6c41a8df2bdb6ac1a4f2e2c4b0e7a1",
+		},
+		{
+			name:       "separate digest",
+			body:       `{"object_kind": "build", "registry": {"path": "registry.gitlab.com/mygroup/app", "tag": "1.2.3", "digest": "sha256:a4a7bd4b3a4f1cb6dd2fdd3f2e2cde2b7d6c41a8df2bdb6ac1a4f2e2c4b0e7a1"}}`,
+			wantCode:   200,
+			wantName:   "registry.gitlab.com/mygroup/app",
+			wantTag:    "1.2.3",
+			wantDigest: "sha256:a4a7bd4b3a4f1cb6dd2fdd3f2e2cde2b7d6c41a8df2bdb6ac1a4f2e2c4b0e7a1",
+		},
+		{
+			name:     "self-hosted registry with port",
+			body:     `{"object_kind": "build", "registry": {"path": "gitlab.corp:5050/team/app:2.0.0"}}`,
+			wantCode: 200,
+			wantName: "gitlab.corp:5050/team/app",
+			wantTag:  "2.0.0",
+		},
+		{
+			name:     "tag field overrides tag in path",
+			body:     `{"object_kind": "build", "registry": {"path": "registry.gitlab.com/mygroup/app:latest", "tag": "1.2.3"}}`,
+			wantCode: 200,
+			wantName: "registry.gitlab.com/mygroup/app",
+			wantTag:  "1.2.3",
+		},
+		{
+			name:     "other object kind is ignored",
+			body:     `{"object_kind": "push", "ref": "refs/heads/master"}`,
+			wantCode: 200,
+		},
+		{
+			name:     "missing path",
+			body:     `{"object_kind": "build", "registry": {"tag": "1.2.3"}}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "missing tag",
+			body:     `{"object_kind": "build", "registry": {"path": "registry.gitlab.com/mygroup/app"}}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid json",
+			body:     `{"object_kind": `,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := &fakeProvider{}
+			srv, teardown := NewTestingServer(fp)
+			defer teardown()
+
+			req, err := http.NewRequest("POST", "/v1/webhooks/gitlab", bytes.NewBufferString(tt.body))
+			if err != nil {
+				t.Fatalf("failed to create req: %s", err)
+			}
+
+			rec := httptest.NewRecorder()
+			srv.router.ServeHTTP(rec, req)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected status code %d, got: %d, body: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+
+			if tt.wantName == "" {
+				if len(fp.submitted) != 0 {
+					t.Errorf("expected no events submitted, got: %d", len(fp.submitted))
+				}
+				return
+			}
+
+			if len(fp.submitted) != 1 {
+				t.Fatalf("unexpected number of events submitted: %d", len(fp.submitted))
+			}
+			submitted := fp.submitted[0]
+			if submitted.Repository.Name != tt.wantName {
+				t.Errorf("expected %s but got %s", tt.wantName, submitted.Repository.Name)
+			}
+			if submitted.Repository.Tag != tt.wantTag {
+				t.Errorf("expected %s but got %s", tt.wantTag, submitted.Repository.Tag)
+			}
+			if submitted.Repository.Digest != tt.wantDigest {
+				t.Errorf("expected digest %s but got %s", tt.wantDigest, submitted.Repository.Digest)
+			}
+			if submitted.TriggerName != "gitlab" {
+				t.Errorf("unexpected trigger name: %s", submitted.TriggerName)
+			}
+		})
+	}
+}
+
+func TestGitlabWebhookHandlerToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		wantCode int
+	}{
+		{
+			name:     "valid token",
+			token:    "gitlab-secret",
+			wantCode: 200,
+		},
+		{
+			name:     "invalid token",
+			token:    "nope",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "missing token",
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := &fakeProvider{}
+			store, teardown := NewTestingUtils()
+			defer teardown()
+
+			am := approvals.New(&approvals.Opts{
+				Store: store,
+			})
+
+			srv := NewTriggerServer(&Opts{
+				Providers:       provider.New([]provider.Provider{fp}, am),
+				ApprovalManager: am,
+				Authenticator:   auth.New(&auth.Opts{Username: "user-1", Password: "secret"}),
+				Store:           store,
+				// token replaces basic auth, GitLab can't send both
+				AuthenticatedWebhooks: true,
+				GitlabWebhookSecret:   "gitlab-secret",
+			})
+			srv.registerRoutes(srv.router)
+
+			req, err := http.NewRequest("POST", "/v1/webhooks/gitlab", bytes.NewBufferString(fakeGitlabWebhook))
+			if err != nil {
+				t.Fatalf("failed to create req: %s", err)
+			}
+			if tt.token != "" {
+				req.Header.Set(gitlabTokenHeader, tt.token)
+			}
+
+			rec := httptest.NewRecorder()
+			srv.router.ServeHTTP(rec, req)
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected status code %d, got: %d", tt.wantCode, rec.Code)
+			}
+
+			wantSubmitted := 0
+			if tt.wantCode == 200 {
+				wantSubmitted = 1
+			}
+			if len(fp.submitted) != wantSubmitted {
+				t.Errorf("expected %d events submitted, got: %d", wantSubmitted, len(fp.submitted))
+			}
+		})
+	}
+}
@@ -16,11 +16,13 @@ import (
 	"github.com/urfave/negroni"
 
 	"github.com/alwinius/bow/approvals"
+	"github.com/alwinius/bow/extension/notification/stream"
 	"github.com/alwinius/bow/internal/k8s"
 	"github.com/alwinius/bow/pkg/auth"
 	"github.com/alwinius/bow/pkg/store"
 	"github.com/alwinius/bow/provider"
 	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/deadletter"
 	"github.com/alwinius/bow/version"
 
 	log "github.com/sirupsen/logrus"
@@ -44,6 +46,28 @@ type Opts struct {
 	UIDir string
 
 	AuthenticatedWebhooks bool
+
+	// ApprovalsSigningSecret - shared secret used to verify signed
+	// approval votes, signed voting is disabled when empty
+	ApprovalsSigningSecret []byte
+
+	// SlackSigningSecret - Slack app signing secret, enables
+	// interactive approval buttons
+	SlackSigningSecret string
+
+	// QuayWebhookSecret - optional bearer token required from
+	// Quay notifications
+	QuayWebhookSecret string
+
+	// GitlabWebhookSecret - optional secret token required from
+	// GitLab hooks in X-Gitlab-Token header
+	GitlabWebhookSecret string
+
+	// Stream - optional, streams notifications to /v1/stream clients
+	Stream *stream.Broadcaster
+
+	// DeadLetters - optional, failed events exposed on /v1/dead-letter
+	DeadLetters *deadletter.Queue
 }
 
 // TriggerServer - webhook trigger & healthcheck server
@@ -62,6 +86,14 @@ type TriggerServer struct {
 	uiDir string
 
 	authenticatedWebhooks bool
+
+	approvalsSigningSecret []byte
+	slackSigningSecret     string
+	quayWebhookSecret      string
+	gitlabWebhookSecret    string
+
+	stream      *stream.Broadcaster
+	deadLetters *deadletter.Queue
 }
 
 // NewTriggerServer - create new HTTP trigger based server
@@ -76,6 +108,14 @@ func NewTriggerServer(opts *Opts) *TriggerServer {
 		store:                 opts.Store,
 		uiDir:                 opts.UIDir,
 		authenticatedWebhooks: opts.AuthenticatedWebhooks,
+
+		approvalsSigningSecret: opts.ApprovalsSigningSecret,
+		slackSigningSecret:     opts.SlackSigningSecret,
+		quayWebhookSecret:      opts.QuayWebhookSecret,
+		gitlabWebhookSecret:    opts.GitlabWebhookSecret,
+
+		stream:      opts.Stream,
+		deadLetters: opts.DeadLetters,
 	}
 }
 
@@ -117,13 +157,20 @@ func (s *TriggerServer) registerRoutes(mux *mux.Router) {
 	}
 
 	s.registerWebhookRoutes(mux)
+	s.registerApprovalVoteRoutes(mux)
+	s.registerSlackRoutes(mux)
 
 	// health endpoint for k8s to be happy
 	mux.HandleFunc("/healthz", s.healthHandler).Methods("GET", "OPTIONS")
+	// readiness endpoint, fails until providers are ready
+	mux.HandleFunc("/readyz", s.readyHandler).Methods("GET", "OPTIONS")
 	// version handler
 	mux.HandleFunc("/version", s.versionHandler).Methods("GET", "OPTIONS")
 
 	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/v1/metrics/updates", s.updatesMetricsHandler).Methods("GET", "OPTIONS")
+	// available policy types
+	mux.HandleFunc("/v1/policies", s.policiesHandler).Methods("GET", "OPTIONS")
 
 	if s.authenticator.Enabled() {
 		log.Info("authentication enabled, setting up admin HTTP handlers")
@@ -150,10 +197,24 @@ func (s *TriggerServer) registerRoutes(mux *mux.Router) {
 		mux.HandleFunc("/v1/tracked", s.requireAdminAuthorization(s.trackedHandler)).Methods("GET", "OPTIONS")
 		mux.HandleFunc("/v1/tracked", s.requireAdminAuthorization(s.trackSetHandler)).Methods("PUT", "OPTIONS")
 
+		// immediate poll
+		mux.HandleFunc("/v1/poll", s.requireAdminAuthorization(s.pollHandler)).Methods("POST", "OPTIONS")
+
 		// status
 		mux.HandleFunc("/v1/audit", s.requireAdminAuthorization(s.adminAuditLogHandler)).Methods("GET", "OPTIONS")
 		mux.HandleFunc("/v1/stats", s.requireAdminAuthorization(s.statsHandler)).Methods("GET", "OPTIONS")
 
+		// live notifications
+		if s.stream != nil {
+			mux.HandleFunc("/v1/stream", s.requireAdminAuthorization(s.streamHandler)).Methods("GET", "OPTIONS")
+		}
+
+		// failed events
+		if s.deadLetters != nil {
+			mux.HandleFunc("/v1/dead-letter", s.requireAdminAuthorization(s.deadLettersHandler)).Methods("GET", "OPTIONS")
+			mux.HandleFunc("/v1/dead-letter/{id}/retry", s.requireAdminAuthorization(s.deadLetterRetryHandler)).Methods("POST", "OPTIONS")
+		}
+
 		if s.uiDir != "" {
 			// Serve static assets directly.
 			mux.PathPrefix("/css/").Handler(http.FileServer(http.Dir(s.uiDir)))
@@ -175,8 +236,10 @@ func (s *TriggerServer) registerWebhookRoutes(mux *mux.Router) {
 	if s.authenticatedWebhooks {
 		mux.HandleFunc("/v1/webhooks/native", s.requireAdminAuthorization(s.nativeHandler)).Methods("POST", "OPTIONS")
 		mux.HandleFunc("/v1/webhooks/dockerhub", s.requireAdminAuthorization(s.dockerHubHandler)).Methods("POST", "OPTIONS")
-		mux.HandleFunc("/v1/webhooks/quay", s.requireAdminAuthorization(s.quayHandler)).Methods("POST", "OPTIONS")
+		mux.HandleFunc("/v1/webhooks/quay", s.quayWebhookAuthorization(s.requireAdminAuthorization)).Methods("POST", "OPTIONS")
+		mux.HandleFunc("/v1/webhooks/gitlab", s.gitlabWebhookAuthorization(s.requireAdminAuthorization)).Methods("POST", "OPTIONS")
 		mux.HandleFunc("/v1/webhooks/azure", s.requireAdminAuthorization(s.azureHandler)).Methods("POST", "OPTIONS")
+		mux.HandleFunc("/v1/webhooks/oci", s.requireAdminAuthorization(s.ociHandler)).Methods("POST", "OPTIONS")
 
 		// Docker registry notifications, used by Docker, Gitlab, Harbor
 		// https://docs.docker.com/registry/notifications/
@@ -185,8 +248,10 @@ func (s *TriggerServer) registerWebhookRoutes(mux *mux.Router) {
 	} else {
 		mux.HandleFunc("/v1/webhooks/native", s.nativeHandler).Methods("POST", "OPTIONS")
 		mux.HandleFunc("/v1/webhooks/dockerhub", s.dockerHubHandler).Methods("POST", "OPTIONS")
-		mux.HandleFunc("/v1/webhooks/quay", s.quayHandler).Methods("POST", "OPTIONS")
+		mux.HandleFunc("/v1/webhooks/quay", s.quayWebhookAuthorization(nil)).Methods("POST", "OPTIONS")
+		mux.HandleFunc("/v1/webhooks/gitlab", s.gitlabWebhookAuthorization(nil)).Methods("POST", "OPTIONS")
 		mux.HandleFunc("/v1/webhooks/azure", s.azureHandler).Methods("POST", "OPTIONS")
+		mux.HandleFunc("/v1/webhooks/oci", s.ociHandler).Methods("POST", "OPTIONS")
 
 		// Docker registry notifications, used by Docker, Gitlab, Harbor
 		// https://docs.docker.com/registry/notifications/
@@ -199,6 +264,21 @@ func (s *TriggerServer) healthHandler(resp http.ResponseWriter, req *http.Reques
 	resp.WriteHeader(http.StatusOK)
 }
 
+func (s *TriggerServer) readyHandler(resp http.ResponseWriter, req *http.Request) {
+	rc, ok := s.providers.(provider.ReadinessChecker)
+	if !ok {
+		resp.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err := rc.Ready(); err != nil {
+		resp.WriteHeader(http.StatusServiceUnavailable)
+		resp.Write([]byte(err.Error()))
+		return
+	}
+	resp.WriteHeader(http.StatusOK)
+}
+
 func (s *TriggerServer) versionHandler(resp http.ResponseWriter, req *http.Request) {
 	v := version.GetbowVersion()
 
@@ -0,0 +1,13 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/alwinius/bow/util/metrics"
+)
+
+// updatesMetricsHandler - update counters as JSON keyed by counter name and
+// namespace/name, for setups without a prometheus scraper
+func (s *TriggerServer) updatesMetricsHandler(resp http.ResponseWriter, req *http.Request) {
+	response(metrics.Snapshot(), 200, nil, resp, req)
+}
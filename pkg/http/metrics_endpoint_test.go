@@ -0,0 +1,42 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alwinius/bow/util/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestUpdatesMetrics(t *testing.T) {
+	counter := metrics.NewCounter(prometheus.CounterOpts{Name: "http_test_updates_total"}, "chart")
+	counter.Inc("default/wd")
+
+	srv, teardown := NewTestingServer(&fakeProvider{})
+	defer teardown()
+
+	req, err := http.NewRequest("GET", "/v1/metrics/updates", nil)
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+
+	rec := httptest.NewRecorder()
+
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	var counters map[string]map[string]float64
+	err = json.Unmarshal(rec.Body.Bytes(), &counters)
+	if err != nil {
+		t.Fatalf("failed to unmarshal response: %s", err)
+	}
+
+	if counters["http_test_updates_total"]["default/wd"] != 1 {
+		t.Errorf("unexpected counters: %v", counters)
+	}
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alwinius/bow/types"
+	"github.com/prometheus/client_golang/prometheus"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var newOCIWebhooksCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "oci_webhook_requests_total",
+		Help: "How many /v1/webhooks/oci requests processed, partitioned by image.",
+	},
+	[]string{"image"},
+)
+
+func init() {
+	prometheus.MustRegister(newOCIWebhooksCounter)
+}
+
+// ociRegistryHostHeader - optional header that registries (or proxies in front of bow)
+// can set to tell which registry host the pushed image belongs to
+const ociRegistryHostHeader = "X-Registry-Host"
+
+// Example of OCI registry push notification, CloudEvents envelope
+// {
+//   "specversion": "1.0",
+//   "id": "8c7e6a4e-7a3c-4c5e-9d8e-2a6f1a1b3c4d",
+//   "type": "org.opencontainers.image.pushed",
+//   "source": "registry.corp:5000",
+//   "time": "2024-01-15T12:00:00Z",
+//   "data": {
+//     "repository": "team/app",
+//     "tag": "1.2.3",
+//     "subject": {
+//       "mediaType": "application/vnd.oci.image.manifest.v1+json",
+//       "digest": "sha256:4afff550708506c5b8b7384ad10d401a02b29ed587cb2730cb02753095b5178d",
+//       "size": 527
+//     }
+//   }
+// }
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type ociNotification struct {
+	SpecVersion string    `json:"specversion"`
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	Source      string    `json:"source"`
+	Time        time.Time `json:"time"`
+	Data        struct {
+		Repository string        `json:"repository"`
+		Tag        string        `json:"tag"`
+		Subject    ociDescriptor `json:"subject"`
+	} `json:"data"`
+}
+
+// ociRegistryHost - registry host from the header, falling back to event source
+func ociRegistryHost(req *http.Request, on *ociNotification) string {
+	host := req.Header.Get(ociRegistryHostHeader)
+	if host == "" {
+		host = on.Source
+	}
+	host = strings.TrimPrefix(host, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	return strings.TrimSuffix(host, "/")
+}
+
+func (s *TriggerServer) ociHandler(resp http.ResponseWriter, req *http.Request) {
+	on := ociNotification{}
+	if err := json.NewDecoder(req.Body).Decode(&on); err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Error("trigger.ociHandler: failed to decode request")
+		resp.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// only push events are relevant, ignoring deletes and pulls
+	if !strings.Contains(strings.ToLower(on.Type), "push") {
+		log.WithFields(log.Fields{
+			"type": on.Type,
+		}).Debug("trigger.ociHandler: ignoring non push event")
+		resp.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if on.Data.Repository == "" {
+		resp.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(resp, "repository cannot be empty")
+		return
+	}
+
+	if on.Data.Tag == "" {
+		resp.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(resp, "tag cannot be empty")
+		return
+	}
+
+	host := ociRegistryHost(req, &on)
+	if host == "" {
+		resp.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(resp, "registry host cannot be resolved, set %s header or event source", ociRegistryHostHeader)
+		return
+	}
+
+	event := types.Event{}
+	event.CreatedAt = time.Now()
+	event.TriggerName = "oci"
+	event.Repository.Name = host + "/" + on.Data.Repository
+	event.Repository.Tag = on.Data.Tag
+	event.Repository.Digest = on.Data.Subject.Digest
+
+	log.WithFields(log.Fields{
+		"type":       on.Type,
+		"tag":        event.Repository.Tag,
+		"repository": event.Repository.Name,
+		"digest":     event.Repository.Digest,
+	}).Debug("trigger.ociHandler: got OCI registry notification, processing")
+
+	s.trigger(event)
+
+	newOCIWebhooksCounter.With(prometheus.Labels{"image": event.Repository.Name}).Inc()
+
+	resp.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var fakeOCINotification = `{
+	"specversion": "1.0",
+	"id": "8c7e6a4e-7a3c-4c5e-9d8e-2a6f1a1b3c4d",
+	"type": "org.opencontainers.image.pushed",
+	"source": "registry.corp:5000",
+	"time": "2024-01-15T12:00:00Z",
+	"data": {
+		"repository": "team/app",
+		"tag": "1.2.3",
+		"subject": {
+			"mediaType": "application/vnd.oci.image.manifest.v1+json",
+			"digest": "sha256:4afff550708506c5b8b7384ad10d401a02b29ed587cb2730cb02753095b5178d",
+			"size": 527
+		}
+	}
+}`
+
+func TestOCIWebhookHandler(t *testing.T) {
+
+	fp := &fakeProvider{}
+	srv, teardown := NewTestingServer(fp)
+	defer teardown()
+
+	req, err := http.NewRequest("POST", "/v1/webhooks/oci", bytes.NewBuffer([]byte(fakeOCINotification)))
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+
+	rec := httptest.NewRecorder()
+
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Errorf("unexpected status code: %d", rec.Code)
+
+		t.Log(rec.Body.String())
+	}
+
+	if len(fp.submitted) != 1 {
+		t.Fatalf("unexpected number of events submitted: %d", len(fp.submitted))
+	}
+
+	if fp.submitted[0].Repository.Name != "registry.corp:5000/team/app" {
+		t.Errorf("expected registry.corp:5000/team/app but got %s", fp.submitted[0].Repository.Name)
+	}
+
+	if fp.submitted[0].Repository.Tag != "1.2.3" {
+		t.Errorf("expected 1.2.3 but got %s", fp.submitted[0].Repository.Tag)
+	}
+
+	if fp.submitted[0].Repository.Digest != "sha256:4afff550708506c5b8b7384ad10d401a02b29ed587cb2730cb02753095b5178d" {
+		t.Errorf("unexpected digest: %s", fp.submitted[0].Repository.Digest)
+	}
+}
+
+func TestOCIWebhookHandlerRegistryHostHeader(t *testing.T) {
+
+	fp := &fakeProvider{}
+	srv, teardown := NewTestingServer(fp)
+	defer teardown()
+
+	req, err := http.NewRequest("POST", "/v1/webhooks/oci", bytes.NewBuffer([]byte(fakeOCINotification)))
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+	req.Header.Set("X-Registry-Host", "harbor.corp")
+
+	rec := httptest.NewRecorder()
+
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Errorf("unexpected status code: %d", rec.Code)
+	}
+
+	if len(fp.submitted) != 1 {
+		t.Fatalf("unexpected number of events submitted: %d", len(fp.submitted))
+	}
+
+	if fp.submitted[0].Repository.Name != "harbor.corp/team/app" {
+		t.Errorf("expected harbor.corp/team/app but got %s", fp.submitted[0].Repository.Name)
+	}
+}
+
+func TestOCIWebhookHandlerMissingTag(t *testing.T) {
+
+	fp := &fakeProvider{}
+	srv, teardown := NewTestingServer(fp)
+	defer teardown()
+
+	req, err := http.NewRequest("POST", "/v1/webhooks/oci", bytes.NewBuffer([]byte(`{"type": "org.opencontainers.image.pushed", "source": "registry.corp:5000", "data": {"repository": "team/app"}}`)))
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+
+	rec := httptest.NewRecorder()
+
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != 400 {
+		t.Errorf("unexpected status code: %d", rec.Code)
+	}
+
+	if len(fp.submitted) != 0 {
+		t.Errorf("unexpected number of events submitted: %d", len(fp.submitted))
+	}
+}
@@ -5,9 +5,20 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/alwinius/bow/internal/policy"
 	"github.com/alwinius/bow/types"
 )
 
+type policiesResponse struct {
+	Policies []policy.Description `json:"policies"`
+}
+
+// policiesHandler - lists policy types that can be set in bow/policy together
+// with their format and example updates
+func (s *TriggerServer) policiesHandler(resp http.ResponseWriter, req *http.Request) {
+	response(&policiesResponse{Policies: policy.Describe()}, 200, nil, resp, req)
+}
+
 type resourcePolicyUpdateRequest struct {
 	Policy     string `json:"policy"`
 	Identifier string `json:"identifier"`
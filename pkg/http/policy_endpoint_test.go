@@ -0,0 +1,54 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPoliciesHandler(t *testing.T) {
+	srv, teardown := NewTestingServer(&fakeProvider{})
+	defer teardown()
+
+	req, err := http.NewRequest("GET", "/v1/policies", nil)
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	var policies policiesResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &policies)
+	if err != nil {
+		t.Fatalf("failed to unmarshal response: %s", err)
+	}
+
+	byName := make(map[string]bool)
+	for _, p := range policies.Policies {
+		byName[p.Name] = true
+		if p.Format == "" || p.Example == "" || len(p.Examples) == 0 {
+			t.Errorf("incomplete policy description: %+v", p)
+		}
+		for _, e := range p.Examples {
+			if e.Error != "" {
+				t.Errorf("policy %s example %s->%s failed: %s", p.Name, e.Current, e.New, e.Error)
+			}
+		}
+		if p.Name == "minor" {
+			if !p.Examples[0].Update || p.Examples[2].Update {
+				t.Errorf("unexpected minor policy examples: %+v", p.Examples)
+			}
+		}
+	}
+
+	for _, name := range []string{"all", "major", "minor", "patch", "prerelease", "force", "glob", "regexp", "timestamp"} {
+		if !byName[name] {
+			t.Errorf("policy %s is not described", name)
+		}
+	}
+}
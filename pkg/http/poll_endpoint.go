@@ -0,0 +1,91 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/image"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type pollRequest struct {
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+}
+
+type pollResponse struct {
+	Matched int `json:"matched"`
+}
+
+// pollHandler - submits poll style event for tracked images matching the repository
+// straight away, without waiting for the next scheduled poll
+func (s *TriggerServer) pollHandler(resp http.ResponseWriter, req *http.Request) {
+	var pollReq pollRequest
+	dec := json.NewDecoder(req.Body)
+	defer req.Body.Close()
+
+	err := dec.Decode(&pollReq)
+	if err != nil {
+		resp.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(resp, "%s", err)
+		return
+	}
+
+	if pollReq.Repository == "" {
+		resp.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(resp, "repository cannot be empty")
+		return
+	}
+
+	if pollReq.Tag == "" {
+		resp.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(resp, "tag cannot be empty")
+		return
+	}
+
+	ref, err := image.Parse(pollReq.Repository + ":" + pollReq.Tag)
+	if err != nil {
+		resp.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(resp, "invalid repository: %s", err)
+		return
+	}
+
+	trackedImages, err := s.providers.TrackedImages()
+	if err != nil {
+		response(nil, 500, err, resp, req)
+		return
+	}
+
+	matched := 0
+	for _, img := range trackedImages {
+		if img.Image.Repository() == ref.Repository() {
+			matched++
+		}
+	}
+
+	if matched > 0 {
+		event := types.Event{}
+		event.CreatedAt = time.Now()
+		event.TriggerName = types.TriggerTypePoll.String()
+		event.Repository.Name = pollReq.Repository
+		event.Repository.Tag = pollReq.Tag
+
+		log.WithFields(log.Fields{
+			"repository": event.Repository.Name,
+			"tag":        event.Repository.Tag,
+			"matched":    matched,
+		}).Info("trigger.pollHandler: submitting immediate poll event")
+
+		err = s.trigger(event)
+		if err != nil {
+			response(nil, 500, err, resp, req)
+			return
+		}
+	}
+
+	response(&pollResponse{Matched: matched}, http.StatusAccepted, nil, resp, req)
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alwinius/bow/internal/policy"
+	"github.com/alwinius/bow/types"
+)
+
+func TestPollHandler(t *testing.T) {
+	fp := &fakeProvider{
+		images: []*types.TrackedImage{
+			&types.TrackedImage{
+				Image:    mustParseRef("gcr.io/v2-namespace/hello-world:1.1.1"),
+				Trigger:  types.TriggerTypePoll,
+				Provider: "kubernetes",
+				Policy:   policy.NewSemverPolicy(policy.SemverPolicyTypeMinor),
+			},
+			&types.TrackedImage{
+				Image:    mustParseRef("gcr.io/v2-namespace/hello-world:1.0.0"),
+				Trigger:  types.TriggerTypePoll,
+				Provider: "helm",
+				Policy:   policy.NewSemverPolicy(policy.SemverPolicyTypeAll),
+			},
+			&types.TrackedImage{
+				Image:    mustParseRef("karolisr/bow:0.1.0"),
+				Trigger:  types.TriggerTypePoll,
+				Provider: "helm",
+				Policy:   policy.NewSemverPolicy(policy.SemverPolicyTypeAll),
+			},
+		},
+	}
+	srv, teardown := NewTestingServer(fp)
+	defer teardown()
+
+	req, err := http.NewRequest("POST", "/v1/poll", bytes.NewBufferString(`{"repository": "gcr.io/v2-namespace/hello-world", "tag": "1.2.0"}`))
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+	req.SetBasicAuth("user-1", "secret")
+
+	rec := httptest.NewRecorder()
+
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	var pollResp pollResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &pollResp)
+	if err != nil {
+		t.Fatalf("failed to unmarshal response: %s", err)
+	}
+
+	if pollResp.Matched != 2 {
+		t.Errorf("expected 2 matched resources, got: %d", pollResp.Matched)
+	}
+
+	if len(fp.submitted) != 1 {
+		t.Fatalf("expected 1 event to be submitted, got: %d", len(fp.submitted))
+	}
+
+	if fp.submitted[0].Repository.Tag != "1.2.0" {
+		t.Errorf("unexpected tag: %s", fp.submitted[0].Repository.Tag)
+	}
+	if fp.submitted[0].TriggerName != "poll" {
+		t.Errorf("unexpected trigger name: %s", fp.submitted[0].TriggerName)
+	}
+}
+
+func TestPollHandlerNoMatches(t *testing.T) {
+	fp := &fakeProvider{}
+	srv, teardown := NewTestingServer(fp)
+	defer teardown()
+
+	req, err := http.NewRequest("POST", "/v1/poll", bytes.NewBufferString(`{"repository": "gcr.io/v2-namespace/hello-world", "tag": "1.2.0"}`))
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+	req.SetBasicAuth("user-1", "secret")
+
+	rec := httptest.NewRecorder()
+
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	if len(fp.submitted) != 0 {
+		t.Errorf("expected no events to be submitted, got: %d", len(fp.submitted))
+	}
+}
+
+func TestPollHandlerUnauthenticated(t *testing.T) {
+	fp := &fakeProvider{}
+	srv, teardown := NewTestingServer(fp)
+	defer teardown()
+
+	req, err := http.NewRequest("POST", "/v1/poll", bytes.NewBufferString(`{"repository": "gcr.io/v2-namespace/hello-world", "tag": "1.2.0"}`))
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+
+	rec := httptest.NewRecorder()
+
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("unexpected status code: %d", rec.Code)
+	}
+}
@@ -1,9 +1,11 @@
 package http
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/alwinius/bow/types"
@@ -45,6 +47,38 @@ type quayWebhook struct {
 	UpdatedTags []string `json:"updated_tags"`
 }
 
+// quayWebhookAuthorization - Quay notifications can only send a bearer token, when
+// the Quay secret is set it replaces the fallback authorization (if any)
+func (s *TriggerServer) quayWebhookAuthorization(fallback func(http.HandlerFunc) http.HandlerFunc) http.HandlerFunc {
+	if s.quayWebhookSecret != "" {
+		return s.requireQuayToken(s.quayHandler)
+	}
+	if fallback != nil {
+		return fallback(s.quayHandler)
+	}
+	return s.quayHandler
+}
+
+func (s *TriggerServer) requireQuayToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodOptions {
+			return
+		}
+
+		header := req.Header.Get("Authorization")
+		token := strings.TrimPrefix(header, "Bearer ")
+		if token == header || subtle.ConstantTimeCompare([]byte(token), []byte(s.quayWebhookSecret)) != 1 {
+			log.WithFields(log.Fields{
+				"remote": req.RemoteAddr,
+			}).Warn("trigger.quayHandler: invalid token")
+			http.Error(resp, "invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		next(resp, req)
+	}
+}
+
 func (s *TriggerServer) quayHandler(resp http.ResponseWriter, req *http.Request) {
 	qw := quayWebhook{}
 	if err := json.NewDecoder(req.Body).Decode(&qw); err != nil {
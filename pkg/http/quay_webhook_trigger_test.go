@@ -6,6 +6,10 @@ import (
 
 	"net/http/httptest"
 	"testing"
+
+	"github.com/alwinius/bow/approvals"
+	"github.com/alwinius/bow/pkg/auth"
+	"github.com/alwinius/bow/provider"
 )
 
 var fakeQuayWebhook = `{
@@ -53,3 +57,124 @@ func TestQuayWebhookHandler(t *testing.T) {
 		t.Errorf("expected 1.2.3 but got %s", fp.submitted[0].Repository.Tag)
 	}
 }
+
+var fakeQuayMultiTagWebhook = `{
+  "name": "repository",
+  "repository": "mynamespace/repository",
+  "namespace": "mynamespace",
+  "docker_url": "quay.io/mynamespace/repository",
+  "homepage": "https://quay.io/repository/mynamespace/repository",
+  "updated_tags": [
+    "1.2.3",
+    "1.2",
+    "latest"
+  ]
+}
+`
+
+func TestQuayWebhookHandlerMultipleTags(t *testing.T) {
+
+	fp := &fakeProvider{}
+	srv, teardown := NewTestingServer(fp)
+	defer teardown()
+
+	req, err := http.NewRequest("POST", "/v1/webhooks/quay", bytes.NewBuffer([]byte(fakeQuayMultiTagWebhook)))
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+
+	rec := httptest.NewRecorder()
+
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Errorf("unexpected status code: %d", rec.Code)
+
+		t.Log(rec.Body.String())
+	}
+
+	if len(fp.submitted) != 3 {
+		t.Fatalf("unexpected number of events submitted: %d", len(fp.submitted))
+	}
+
+	for i, tag := range []string{"1.2.3", "1.2", "latest"} {
+		if fp.submitted[i].Repository.Name != "quay.io/mynamespace/repository" {
+			t.Errorf("expected quay.io/mynamespace/repository but got %s", fp.submitted[i].Repository.Name)
+		}
+		if fp.submitted[i].Repository.Tag != tag {
+			t.Errorf("expected %s but got %s", tag, fp.submitted[i].Repository.Tag)
+		}
+	}
+}
+
+func TestQuayWebhookHandlerToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		wantCode      int
+	}{
+		{
+			name:          "valid token",
+			authorization: "Bearer quay-secret",
+			wantCode:      200,
+		},
+		{
+			name:          "invalid token",
+			authorization: "Bearer nope",
+			wantCode:      http.StatusUnauthorized,
+		},
+		{
+			name:          "token without bearer prefix",
+			authorization: "quay-secret",
+			wantCode:      http.StatusUnauthorized,
+		},
+		{
+			name:     "missing token",
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := &fakeProvider{}
+			store, teardown := NewTestingUtils()
+			defer teardown()
+
+			am := approvals.New(&approvals.Opts{
+				Store: store,
+			})
+
+			srv := NewTriggerServer(&Opts{
+				Providers:       provider.New([]provider.Provider{fp}, am),
+				ApprovalManager: am,
+				Authenticator:   auth.New(&auth.Opts{Username: "user-1", Password: "secret"}),
+				Store:           store,
+				// token replaces basic auth, Quay can't send both
+				AuthenticatedWebhooks: true,
+				QuayWebhookSecret:     "quay-secret",
+			})
+			srv.registerRoutes(srv.router)
+
+			req, err := http.NewRequest("POST", "/v1/webhooks/quay", bytes.NewBuffer([]byte(fakeQuayWebhook)))
+			if err != nil {
+				t.Fatalf("failed to create req: %s", err)
+			}
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			srv.router.ServeHTTP(rec, req)
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected status code %d, got: %d", tt.wantCode, rec.Code)
+			}
+
+			wantSubmitted := 0
+			if tt.wantCode == 200 {
+				wantSubmitted = 1
+			}
+			if len(fp.submitted) != wantSubmitted {
+				t.Errorf("expected %d events submitted, got: %d", wantSubmitted, len(fp.submitted))
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type readinessProvider struct {
+	fakeProvider
+	err error
+}
+
+func (p *readinessProvider) Ready() error {
+	return p.err
+}
+
+func TestReadyHandler(t *testing.T) {
+	fp := &readinessProvider{err: errors.New("event loop not started")}
+	srv, teardown := NewTestingServer(fp)
+	defer teardown()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatalf("failed to create req: %s", err)
+		}
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := get("/readyz"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 while provider isn't ready, got: %d", rec.Code)
+	}
+	// liveness doesn't depend on providers
+	if rec := get("/healthz"); rec.Code != http.StatusOK {
+		t.Errorf("expected healthz 200, got: %d", rec.Code)
+	}
+
+	fp.err = nil
+	if rec := get("/readyz"); rec.Code != http.StatusOK {
+		t.Errorf("expected 200 once provider is ready, got: %d", rec.Code)
+	}
+}
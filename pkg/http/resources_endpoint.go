@@ -28,7 +28,8 @@ func (s *TriggerServer) resourcesHandler(resp http.ResponseWriter, req *http.Req
 
 	for _, v := range vals {
 
-		p := policy.GetPolicyFromLabelsOrAnnotations(v.GetLabels(), v.GetAnnotations())
+		// invalid policies are reported by the provider, listed here as nil policy
+		p, _ := policy.GetPolicyFromLabelsOrAnnotations(v.GetLabels(), v.GetAnnotations())
 
 		res = append(res, resource{
 			Provider:    "kubernetes",
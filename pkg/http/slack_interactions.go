@@ -0,0 +1,157 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+	"github.com/nlopes/slack"
+
+	"github.com/alwinius/bow/constants"
+	"github.com/alwinius/bow/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// registerSlackRoutes - Slack interactions are verified with the Slack app
+// signing secret so the endpoint is only available when it's configured
+func (s *TriggerServer) registerSlackRoutes(mux *mux.Router) {
+	if s.slackSigningSecret == "" {
+		return
+	}
+
+	mux.HandleFunc("/v1/slack/interactions", s.slackInteractionHandler).Methods("POST", "OPTIONS")
+}
+
+// slackInteractionHandler - handles approve/reject button clicks on approval requests,
+// response replaces original message so the channel can see who voted
+func (s *TriggerServer) slackInteractionHandler(resp http.ResponseWriter, req *http.Request) {
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		http.Error(resp, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	sv, err := slack.NewSecretsVerifier(req.Header, s.slackSigningSecret)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	sv.Write(body)
+	if err := sv.Ensure(); err != nil {
+		log.WithFields(log.Fields{
+			"remote": req.RemoteAddr,
+		}).Warn("http.slackInteractionHandler: invalid Slack signature")
+		http.Error(resp, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(resp, fmt.Sprintf("failed to parse request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var callback slack.InteractionCallback
+	err = json.Unmarshal([]byte(values.Get("payload")), &callback)
+	if err != nil {
+		http.Error(resp, fmt.Sprintf("failed to decode payload: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if callback.CallbackID != constants.SlackApprovalCallbackID || len(callback.Actions) == 0 {
+		log.WithFields(log.Fields{
+			"callback_id": callback.CallbackID,
+		}).Debug("http.slackInteractionHandler: ignoring unknown interaction")
+		resp.WriteHeader(http.StatusOK)
+		return
+	}
+
+	action := callback.Actions[0]
+	identifier := action.Value
+
+	var approval *types.Approval
+	switch action.Name {
+	case actionApprove:
+		approval, err = s.approvalsManager.Approve(identifier, slackVoter(callback.User))
+	case actionReject:
+		approval, err = s.approvalsManager.Reject(identifier)
+	default:
+		http.Error(resp, fmt.Sprintf("unknown action '%s'", action.Name), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":      err,
+			"identifier": identifier,
+			"action":     action.Name,
+		}).Error("http.slackInteractionHandler: failed to record vote")
+
+		// ephemeral message is only visible to the user that clicked the button
+		msg := slack.Msg{
+			ResponseType: "ephemeral",
+			Text:         fmt.Sprintf("Failed to %s '%s': %s", action.Name, identifier, err),
+		}
+		response(&msg, http.StatusOK, nil, resp, req)
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"identifier": identifier,
+		"action":     action.Name,
+		"user":       callback.User.ID,
+	}).Info("http.slackInteractionHandler: vote recorded")
+
+	msg := updatedApprovalMessage(&callback.OriginalMessage, approval, action.Name, callback.User.ID)
+	response(msg, http.StatusOK, nil, resp, req)
+}
+
+// slackVoter - Slack username votes are recorded as, same identity as in
+// bow/approval-voters, user ID is used when username isn't available
+func slackVoter(user slack.User) string {
+	if user.Name != "" {
+		return user.Name
+	}
+	return user.ID
+}
+
+// updatedApprovalMessage - copy of the original approval request with votes and
+// voter, buttons are removed once approval is no longer pending
+func updatedApprovalMessage(original *slack.Message, approval *types.Approval, action, userID string) *slack.Msg {
+	msg := original.Msg
+	msg.ReplaceOriginal = true
+
+	title := "Approved by"
+	if action == actionReject {
+		title = "Rejected by"
+	}
+
+	attachments := make([]slack.Attachment, len(msg.Attachments))
+	copy(attachments, msg.Attachments)
+	for i := range attachments {
+		fields := make([]slack.AttachmentField, 0, len(attachments[i].Fields)+1)
+		for _, f := range attachments[i].Fields {
+			if f.Title == "Votes" {
+				f.Value = fmt.Sprintf("%d/%d", approval.VotesReceived, approval.VotesRequired)
+			}
+			fields = append(fields, f)
+		}
+		fields = append(fields, slack.AttachmentField{
+			Title: title,
+			Value: fmt.Sprintf("<@%s>", userID),
+			Short: true,
+		})
+		attachments[i].Fields = fields
+
+		if approval.Status() != types.ApprovalStatusPending {
+			attachments[i].Actions = nil
+		}
+	}
+	msg.Attachments = attachments
+
+	return &msg
+}
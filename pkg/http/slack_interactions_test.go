@@ -0,0 +1,233 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nlopes/slack"
+
+	"github.com/alwinius/bow/approvals"
+	"github.com/alwinius/bow/constants"
+	"github.com/alwinius/bow/pkg/auth"
+	"github.com/alwinius/bow/provider"
+	"github.com/alwinius/bow/types"
+)
+
+const testSlackSigningSecret = "slack-secret"
+
+func newSlackInteractionRequest(t *testing.T, secret string, callback *slack.InteractionCallback) *http.Request {
+	payload, err := json.Marshal(callback)
+	if err != nil {
+		t.Fatalf("failed to marshal callback: %s", err)
+	}
+
+	body := url.Values{"payload": {string(payload)}}.Encode()
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+
+	req, err := http.NewRequest("POST", "/v1/slack/interactions", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+
+	return req
+}
+
+func approvalButtonCallback(action, identifier string) *slack.InteractionCallback {
+	callback := &slack.InteractionCallback{
+		Type:       slack.InteractionTypeInteractionMessage,
+		CallbackID: constants.SlackApprovalCallbackID,
+	}
+	callback.User.ID = "U123"
+	callback.Actions = []slack.AttachmentAction{{Name: action, Type: "button", Value: identifier}}
+	callback.OriginalMessage.Attachments = []slack.Attachment{
+		{
+			CallbackID: constants.SlackApprovalCallbackID,
+			Fields:     []slack.AttachmentField{{Title: "Votes", Value: "0/2"}},
+			Actions:    approvalButtonActions(identifier),
+		},
+	}
+	return callback
+}
+
+func approvalButtonActions(identifier string) []slack.AttachmentAction {
+	return []slack.AttachmentAction{
+		{Name: "approve", Text: "Approve", Type: "button", Value: identifier},
+		{Name: "reject", Text: "Reject", Type: "button", Value: identifier},
+	}
+}
+
+func newSlackInteractionServer(t *testing.T) (*TriggerServer, approvals.Manager, func()) {
+	store, teardown := NewTestingUtils()
+
+	am := approvals.New(&approvals.Opts{
+		Store: store,
+	})
+
+	srv := NewTriggerServer(&Opts{
+		Providers:          provider.New([]provider.Provider{&fakeProvider{}}, am),
+		ApprovalManager:    am,
+		Authenticator:      auth.New(&auth.Opts{}),
+		Store:              store,
+		SlackSigningSecret: testSlackSigningSecret,
+	})
+	srv.registerRoutes(srv.router)
+
+	err := am.Create(&types.Approval{
+		Identifier:     "deployment/default/wd:2.0.0",
+		VotesRequired:  2,
+		NewVersion:     "2.0.0",
+		CurrentVersion: "1.0.0",
+	})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+
+	return srv, am, teardown
+}
+
+func TestSlackInteractionApprove(t *testing.T) {
+	srv, am, teardown := newSlackInteractionServer(t)
+	defer teardown()
+
+	req := newSlackInteractionRequest(t, testSlackSigningSecret, approvalButtonCallback("approve", "deployment/default/wd:2.0.0"))
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	stored, err := am.Get("deployment/default/wd:2.0.0")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if stored.VotesReceived != 1 {
+		t.Errorf("expected 1 vote, got: %d", stored.VotesReceived)
+	}
+
+	var msg slack.Msg
+	err = json.Unmarshal(rec.Body.Bytes(), &msg)
+	if err != nil {
+		t.Fatalf("failed to unmarshal response: %s", err)
+	}
+
+	if !msg.ReplaceOriginal {
+		t.Errorf("expected original message to be replaced")
+	}
+	if len(msg.Attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got: %d", len(msg.Attachments))
+	}
+
+	fields := msg.Attachments[0].Fields
+	if fields[0].Value != "1/2" {
+		t.Errorf("unexpected votes: %s", fields[0].Value)
+	}
+	if fields[len(fields)-1].Title != "Approved by" || fields[len(fields)-1].Value != "<@U123>" {
+		t.Errorf("unexpected voter field: %+v", fields[len(fields)-1])
+	}
+	// still pending, others can vote
+	if len(msg.Attachments[0].Actions) != 2 {
+		t.Errorf("expected buttons to stay while approval is pending")
+	}
+}
+
+func TestSlackInteractionApproveAllowedVoters(t *testing.T) {
+	srv, am, teardown := newSlackInteractionServer(t)
+	defer teardown()
+
+	err := am.Create(&types.Approval{
+		Identifier:     "deployment/default/limited:2.0.0",
+		VotesRequired:  1,
+		NewVersion:     "2.0.0",
+		CurrentVersion: "1.0.0",
+		AllowedVoters:  types.StringList{"alice"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+
+	// votes are recorded with the username
+	callback := approvalButtonCallback("approve", "deployment/default/limited:2.0.0")
+	callback.User.Name = "alice"
+	req := newSlackInteractionRequest(t, testSlackSigningSecret, callback)
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	stored, err := am.Get("deployment/default/limited:2.0.0")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if !stored.HasVoter("alice") {
+		t.Errorf("expected vote to be recorded as alice, got: %v", stored.GetVoters())
+	}
+	if stored.Status() != types.ApprovalStatusApproved {
+		t.Errorf("expected allowed voter's vote to count, got: %s", stored.Status())
+	}
+}
+
+func TestSlackInteractionReject(t *testing.T) {
+	srv, am, teardown := newSlackInteractionServer(t)
+	defer teardown()
+
+	req := newSlackInteractionRequest(t, testSlackSigningSecret, approvalButtonCallback("reject", "deployment/default/wd:2.0.0"))
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	stored, err := am.Get("deployment/default/wd:2.0.0")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if !stored.Rejected {
+		t.Errorf("expected approval to be rejected")
+	}
+
+	var msg slack.Msg
+	err = json.Unmarshal(rec.Body.Bytes(), &msg)
+	if err != nil {
+		t.Fatalf("failed to unmarshal response: %s", err)
+	}
+	if len(msg.Attachments[0].Actions) != 0 {
+		t.Errorf("expected buttons to be removed from rejected approval")
+	}
+}
+
+func TestSlackInteractionInvalidSignature(t *testing.T) {
+	srv, am, teardown := newSlackInteractionServer(t)
+	defer teardown()
+
+	req := newSlackInteractionRequest(t, "wrong-secret", approvalButtonCallback("approve", "deployment/default/wd:2.0.0"))
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected unauthorized, got: %d", rec.Code)
+	}
+
+	stored, err := am.Get("deployment/default/wd:2.0.0")
+	if err != nil {
+		t.Fatalf("failed to get approval: %s", err)
+	}
+	if stored.VotesReceived != 0 {
+		t.Errorf("expected no votes, got: %d", stored.VotesReceived)
+	}
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/alwinius/bow/extension/notification/stream"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const streamWriteTimeout = 10 * time.Second
+
+var streamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// streamHandler - streams notifications as JSON over websocket, optional
+// provider and namespace query parameters filter them
+func (s *TriggerServer) streamHandler(resp http.ResponseWriter, req *http.Request) {
+	conn, err := streamUpgrader.Upgrade(resp, req, nil)
+	if err != nil {
+		// upgrader already responded with an error
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Error("trigger.http: failed to upgrade stream connection")
+		return
+	}
+	defer conn.Close()
+
+	sub := s.stream.Subscribe(stream.Filter{
+		Provider:  req.URL.Query().Get("provider"),
+		Namespace: req.URL.Query().Get("namespace"),
+	})
+	defer func() {
+		s.stream.Unsubscribe(sub)
+		if dropped := sub.Dropped(); dropped > 0 {
+			log.WithFields(log.Fields{
+				"dropped": dropped,
+				"remote":  req.RemoteAddr,
+			}).Warn("trigger.http: stream client was too slow, notifications were dropped")
+		}
+	}()
+
+	// clients aren't expected to send anything, reading
+	// to notice when they go away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case event := <-sub.Events():
+			conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			err := conn.WriteJSON(event)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"error":  err,
+					"remote": req.RemoteAddr,
+				}).Debug("trigger.http: failed to write to stream client")
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
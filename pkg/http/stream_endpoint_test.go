@@ -0,0 +1,87 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/alwinius/bow/approvals"
+	"github.com/alwinius/bow/extension/notification/stream"
+	"github.com/alwinius/bow/pkg/auth"
+	"github.com/alwinius/bow/provider"
+	"github.com/alwinius/bow/types"
+)
+
+func TestStreamHandler(t *testing.T) {
+	store, teardown := NewTestingUtils()
+	defer teardown()
+
+	am := approvals.New(&approvals.Opts{
+		Store: store,
+	})
+	broadcaster := stream.New(10)
+	srv := NewTriggerServer(&Opts{
+		Providers:       provider.New([]provider.Provider{&fakeProvider{}}, am),
+		ApprovalManager: am,
+		Authenticator:   auth.New(&auth.Opts{Username: "user-1", Password: "secret"}),
+		Store:           store,
+		Stream:          broadcaster,
+	})
+	srv.registerRoutes(srv.router)
+
+	ts := httptest.NewServer(srv.router)
+	defer ts.Close()
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/v1/stream?provider=helm"
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Fatalf("expected unauthenticated connection to fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 for unauthenticated connection, got: %v", resp)
+	}
+
+	req := &http.Request{Header: http.Header{}}
+	req.SetBasicAuth("user-1", "secret")
+	conn, _, err := websocket.DefaultDialer.Dial(url, req.Header)
+	if err != nil {
+		t.Fatalf("failed to connect: %s", err)
+	}
+	defer conn.Close()
+
+	// handler subscribes after the upgrade, sending until client receives something
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			broadcaster.Send(types.EventNotification{
+				Name:     "update deployment",
+				Metadata: map[string]string{"provider": "kubernetes", "namespace": "prod"},
+			})
+			broadcaster.Send(types.EventNotification{
+				Name:     "update release",
+				Metadata: map[string]string{"provider": "helm", "namespace": "prod"},
+			})
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event types.EventNotification
+	err = conn.ReadJSON(&event)
+	if err != nil {
+		t.Fatalf("failed to read event: %s", err)
+	}
+	if event.Name != "update release" {
+		t.Errorf("expected only helm notifications, got: %s", event.Name)
+	}
+}
@@ -17,6 +17,8 @@ type trackedImage struct {
 	Namespace    string `json:"namespace"`
 	Policy       string `json:"policy"`
 	Registry     string `json:"registry"`
+	// Meta - provider supplied metadata, ie: circuit state
+	Meta map[string]string `json:"meta"`
 }
 
 func (s *TriggerServer) trackedHandler(resp http.ResponseWriter, req *http.Request) {
@@ -30,8 +32,10 @@ func (s *TriggerServer) trackedHandler(resp http.ResponseWriter, req *http.Reque
 			Trigger:      img.Trigger.String(),
 			PollSchedule: img.PollSchedule,
 			Provider:     img.Provider,
+			Namespace:    img.Namespace,
 			Policy:       img.Policy.Name(),
 			Registry:     img.Image.Registry(),
+			Meta:         img.Meta,
 		})
 	}
 
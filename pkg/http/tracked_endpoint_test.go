@@ -0,0 +1,78 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alwinius/bow/internal/policy"
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/image"
+)
+
+func mustParseRef(img string) *image.Reference {
+	ref, err := image.Parse(img)
+	if err != nil {
+		panic(err)
+	}
+	return ref
+}
+
+func TestTrackedImagesPollSchedule(t *testing.T) {
+	fp := &fakeProvider{
+		images: []*types.TrackedImage{
+			&types.TrackedImage{
+				Image:        mustParseRef("gcr.io/v2-namespace/hello-world:1.1.1"),
+				Trigger:      types.TriggerTypePoll,
+				PollSchedule: "@every 2m",
+				Provider:     "helm",
+				Namespace:    "default",
+				Policy:       policy.NewSemverPolicy(policy.SemverPolicyTypeMinor),
+			},
+			&types.TrackedImage{
+				Image:        mustParseRef("karolisr/bow:0.1.0"),
+				Trigger:      types.TriggerTypePoll,
+				PollSchedule: types.BowPollDefaultSchedule,
+				Provider:     "helm",
+				Namespace:    "default",
+				Policy:       policy.NewSemverPolicy(policy.SemverPolicyTypeAll),
+			},
+		},
+	}
+	srv, teardown := NewTestingServer(fp)
+	defer teardown()
+
+	req, err := http.NewRequest("GET", "/v1/tracked", nil)
+	if err != nil {
+		t.Fatalf("failed to create req: %s", err)
+	}
+	req.SetBasicAuth("user-1", "secret")
+
+	rec := httptest.NewRecorder()
+
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("unexpected status code: %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	var tracked []trackedImage
+	err = json.Unmarshal(rec.Body.Bytes(), &tracked)
+	if err != nil {
+		t.Fatalf("failed to unmarshal response: %s", err)
+	}
+
+	if len(tracked) != 2 {
+		t.Fatalf("expected 2 tracked images, got: %d", len(tracked))
+	}
+
+	if tracked[0].PollSchedule != "@every 2m" {
+		t.Errorf("unexpected poll schedule: %s", tracked[0].PollSchedule)
+	}
+	if tracked[1].PollSchedule != types.BowPollDefaultSchedule {
+		t.Errorf("unexpected poll schedule: %s", tracked[1].PollSchedule)
+	}
+	if tracked[0].Namespace != "default" {
+		t.Errorf("unexpected namespace: %s", tracked[0].Namespace)
+	}
+}
@@ -0,0 +1,299 @@
+//go:generate protoc --go_out=plugins=grpc:. bow.proto
+
+package rpc
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/alwinius/bow/approvals"
+	"github.com/alwinius/bow/pkg/auth"
+	"github.com/alwinius/bow/pkg/store"
+	"github.com/alwinius/bow/provider"
+	"github.com/alwinius/bow/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Opts - gRPC server options
+type Opts struct {
+	Port int
+
+	// available providers
+	Providers provider.Providers
+
+	ApprovalManager approvals.Manager
+
+	// Authenticator - when enabled, every call has to supply basic or
+	// bearer credentials in the "authorization" metadata
+	Authenticator auth.Authenticator
+
+	// CertFile, KeyFile - TLS certificate and key, server
+	// is started without TLS when they are not set
+	CertFile string
+	KeyFile  string
+}
+
+// Server - gRPC server, exposes the same operations as HTTP API
+type Server struct {
+	providers        provider.Providers
+	approvalsManager approvals.Manager
+	authenticator    auth.Authenticator
+
+	port     int
+	certFile string
+	keyFile  string
+
+	server *grpc.Server
+}
+
+// New - create new gRPC server
+func New(opts *Opts) *Server {
+	return &Server{
+		providers:        opts.Providers,
+		approvalsManager: opts.ApprovalManager,
+		authenticator:    opts.Authenticator,
+		port:             opts.Port,
+		certFile:         opts.CertFile,
+		keyFile:          opts.KeyFile,
+	}
+}
+
+// Start - start server, blocks until server is stopped
+func (s *Server) Start() error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return err
+	}
+	return s.Serve(lis)
+}
+
+// Serve - serve on the given listener
+func (s *Server) Serve(lis net.Listener) error {
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(s.authInterceptor),
+	}
+
+	if s.certFile != "" || s.keyFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(s.certFile, s.keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS certificate: %s", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	s.server = grpc.NewServer(opts...)
+	RegisterBowServer(s.server, s)
+
+	log.WithFields(log.Fields{
+		"address": lis.Addr().String(),
+		"tls":     s.certFile != "",
+	}).Info("grpc server starting...")
+
+	return s.server.Serve(lis)
+}
+
+// Stop - stop server, waits for pending calls to finish
+func (s *Server) Stop() {
+	if s.server != nil {
+		s.server.GracefulStop()
+	}
+}
+
+// authInterceptor - authenticates calls same way as HTTP API, with
+// basic auth or a token issued by the login endpoint
+func (s *Server) authInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if s.authenticator == nil || !s.authenticator.Enabled() {
+		return handler(ctx, req)
+	}
+
+	authReq, ok := authRequest(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+	}
+
+	_, err := s.authenticator.Authenticate(authReq)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":  err,
+			"method": info.FullMethod,
+		}).Warn("rpc: authentication failed")
+		return nil, status.Error(codes.Unauthenticated, "authentication failed")
+	}
+
+	return handler(ctx, req)
+}
+
+// authRequest - parses "authorization" metadata, either
+// "Basic <base64 user:password>" or "Bearer <token>"
+func authRequest(ctx context.Context) (*auth.AuthRequest, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	values := md["authorization"]
+	if len(values) == 0 {
+		return nil, false
+	}
+
+	parts := strings.SplitN(values[0], " ", 2)
+	if len(parts) != 2 {
+		return nil, false
+	}
+
+	switch strings.ToLower(parts[0]) {
+	case "basic":
+		decoded, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, false
+		}
+		creds := strings.SplitN(string(decoded), ":", 2)
+		if len(creds) != 2 {
+			return nil, false
+		}
+		return &auth.AuthRequest{
+			Username: creds[0],
+			Password: creds[1],
+			AuthType: auth.AuthTypeBasic,
+		}, true
+	case "bearer":
+		return &auth.AuthRequest{
+			Token:    parts[1],
+			AuthType: auth.AuthTypeToken,
+		}, true
+	}
+
+	return nil, false
+}
+
+// SubmitEvent - submits event to providers, same as native webhook
+func (s *Server) SubmitEvent(ctx context.Context, req *SubmitEventRequest) (*SubmitEventResponse, error) {
+	repo := req.GetRepository()
+	if repo.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "repository name cannot be empty")
+	}
+	if repo.GetTag() == "" {
+		return nil, status.Error(codes.InvalidArgument, "repository tag cannot be empty")
+	}
+
+	err := s.providers.Submit(types.Event{
+		Repository: types.Repository{
+			Host:   repo.Host,
+			Name:   repo.Name,
+			Tag:    repo.Tag,
+			Digest: repo.Digest,
+		},
+		CreatedAt:   time.Now(),
+		TriggerName: "grpc",
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &SubmitEventResponse{}, nil
+}
+
+// ListApprovals - lists approvals, archived approvals are only included when requested
+func (s *Server) ListApprovals(ctx context.Context, req *ListApprovalsRequest) (*ListApprovalsResponse, error) {
+	list, err := s.approvalsManager.List()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &ListApprovalsResponse{}
+	for _, approval := range list {
+		if approval.Archived && !req.GetArchived() {
+			continue
+		}
+		resp.Approvals = append(resp.Approvals, approvalMessage(approval))
+	}
+	return resp, nil
+}
+
+// Approve - votes for the approval
+func (s *Server) Approve(ctx context.Context, req *ApproveRequest) (*ApprovalResponse, error) {
+	if req.GetIdentifier() == "" {
+		return nil, status.Error(codes.InvalidArgument, "identifier cannot be empty")
+	}
+
+	approval, err := s.approvalsManager.Approve(req.Identifier, req.Voter)
+	if err != nil {
+		return nil, approvalError(req.Identifier, err)
+	}
+	return &ApprovalResponse{Approval: approvalMessage(approval)}, nil
+}
+
+// Reject - rejects the approval
+func (s *Server) Reject(ctx context.Context, req *RejectRequest) (*ApprovalResponse, error) {
+	if req.GetIdentifier() == "" {
+		return nil, status.Error(codes.InvalidArgument, "identifier cannot be empty")
+	}
+
+	approval, err := s.approvalsManager.Reject(req.Identifier)
+	if err != nil {
+		return nil, approvalError(req.Identifier, err)
+	}
+	return &ApprovalResponse{Approval: approvalMessage(approval)}, nil
+}
+
+// ListTrackedImages - lists images tracked by providers
+func (s *Server) ListTrackedImages(ctx context.Context, req *ListTrackedImagesRequest) (*ListTrackedImagesResponse, error) {
+	trackedImages, err := s.providers.TrackedImages()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &ListTrackedImagesResponse{}
+	for _, img := range trackedImages {
+		resp.Images = append(resp.Images, &TrackedImage{
+			Image:        img.Image.Name(),
+			Trigger:      img.Trigger.String(),
+			PollSchedule: img.PollSchedule,
+			Provider:     img.Provider,
+			Namespace:    img.Namespace,
+			Policy:       img.Policy.Name(),
+			Registry:     img.Image.Registry(),
+			Meta:         img.Meta,
+		})
+	}
+	return resp, nil
+}
+
+func approvalError(identifier string, err error) error {
+	switch err {
+	case store.ErrRecordNotFound:
+		return status.Errorf(codes.NotFound, "approval '%s' not found", identifier)
+	case approvals.ErrVoterRequired:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func approvalMessage(approval *types.Approval) *Approval {
+	return &Approval{
+		Id:             approval.ID,
+		Identifier:     approval.Identifier,
+		Provider:       approval.Provider.String(),
+		CurrentVersion: approval.CurrentVersion,
+		NewVersion:     approval.NewVersion,
+		Message:        approval.Message,
+		VotesRequired:  int32(approval.VotesRequired),
+		VotesReceived:  int32(approval.VotesReceived),
+		Voters:         approval.GetVoters(),
+		AllowedVoters:  approval.AllowedVoters,
+		Status:         approval.Status().String(),
+		Archived:       approval.Archived,
+		Deadline:       approval.Deadline.Unix(),
+		CreatedAt:      approval.CreatedAt.Unix(),
+	}
+}
@@ -0,0 +1,257 @@
+package rpc
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/alwinius/bow/approvals"
+	"github.com/alwinius/bow/pkg/auth"
+	"github.com/alwinius/bow/pkg/store/sql"
+	"github.com/alwinius/bow/provider"
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/image"
+)
+
+type fakeProvider struct {
+	submitted []types.Event
+	images    []*types.TrackedImage
+}
+
+func (p *fakeProvider) Submit(event types.Event) error {
+	p.submitted = append(p.submitted, event)
+	return nil
+}
+
+func (p *fakeProvider) TrackedImages() ([]*types.TrackedImage, error) {
+	return p.images, nil
+}
+func (p *fakeProvider) List() []string {
+	return []string{"fakeprovider"}
+}
+func (p *fakeProvider) Stop() {
+	return
+}
+func (p *fakeProvider) GetName() string {
+	return "fp"
+}
+
+// newTestingServer - starts server on a random port and returns connected client
+func newTestingServer(t *testing.T, fp *fakeProvider) (BowClient, approvals.Manager, func()) {
+	dir, err := ioutil.TempDir("", "rpcstoretest")
+	if err != nil {
+		log.Fatal(err)
+	}
+	store, err := sql.New(sql.Opts{DatabaseType: "sqlite3", URI: filepath.Join(dir, "gorm.db")})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	am := approvals.New(&approvals.Opts{
+		Store: store,
+	})
+
+	srv := New(&Opts{
+		Providers:       provider.New([]provider.Provider{fp}, am),
+		ApprovalManager: am,
+		Authenticator: auth.New(&auth.Opts{
+			Username: "user-1",
+			Password: "secret",
+		}),
+	})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	go srv.Serve(lis)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+
+	return NewBowClient(conn), am, func() {
+		conn.Close()
+		srv.Stop()
+		os.RemoveAll(dir)
+	}
+}
+
+func authenticated(username, password string) context.Context {
+	creds := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", "Basic "+creds))
+}
+
+func TestSubmitEvent(t *testing.T) {
+	fp := &fakeProvider{}
+	client, _, teardown := newTestingServer(t, fp)
+	defer teardown()
+
+	_, err := client.SubmitEvent(authenticated("user-1", "secret"), &SubmitEventRequest{
+		Repository: &Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.1"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(fp.submitted) != 1 {
+		t.Fatalf("expected 1 event, got: %d", len(fp.submitted))
+	}
+	if fp.submitted[0].Repository.Name != "gcr.io/v2-namespace/hello-world" || fp.submitted[0].Repository.Tag != "1.1.1" {
+		t.Errorf("unexpected repository: %v", fp.submitted[0].Repository)
+	}
+	if fp.submitted[0].TriggerName != "grpc" {
+		t.Errorf("unexpected trigger name: %s", fp.submitted[0].TriggerName)
+	}
+
+	_, err = client.SubmitEvent(authenticated("user-1", "secret"), &SubmitEventRequest{
+		Repository: &Repository{Name: "gcr.io/v2-namespace/hello-world"},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected invalid argument, got: %v", err)
+	}
+}
+
+func TestUnauthenticated(t *testing.T) {
+	fp := &fakeProvider{}
+	client, _, teardown := newTestingServer(t, fp)
+	defer teardown()
+
+	for _, ctx := range []context.Context{context.Background(), authenticated("user-1", "wrong")} {
+		_, err := client.ListTrackedImages(ctx, &ListTrackedImagesRequest{})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("expected unauthenticated, got: %v", err)
+		}
+	}
+}
+
+func TestApprovals(t *testing.T) {
+	fp := &fakeProvider{}
+	client, am, teardown := newTestingServer(t, fp)
+	defer teardown()
+
+	for _, a := range []*types.Approval{
+		{Provider: types.ProviderTypeHelm, Identifier: "dev/whd-dev:0.0.15", VotesRequired: 2, NewVersion: "0.0.15"},
+		{Provider: types.ProviderTypeHelm, Identifier: "prod/whd-prod:0.0.15", VotesRequired: 1, NewVersion: "0.0.15"},
+		{Provider: types.ProviderTypeKubernetes, Identifier: "deployment/dev/wd:1.0.0", VotesRequired: 1, Archived: true},
+	} {
+		if err := am.Create(a); err != nil {
+			t.Fatalf("failed to create approval: %s", err)
+		}
+	}
+
+	ctx := authenticated("user-1", "secret")
+
+	resp, err := client.ListApprovals(ctx, &ListApprovalsRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(resp.Approvals) != 2 {
+		t.Errorf("expected 2 active approvals, got: %d", len(resp.Approvals))
+	}
+
+	resp, err = client.ListApprovals(ctx, &ListApprovalsRequest{Archived: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(resp.Approvals) != 3 {
+		t.Errorf("expected 3 approvals, got: %d", len(resp.Approvals))
+	}
+
+	approved, err := client.Approve(ctx, &ApproveRequest{Identifier: "dev/whd-dev:0.0.15", Voter: "john"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if approved.Approval.VotesReceived != 1 || approved.Approval.Status != types.ApprovalStatusPending.String() {
+		t.Errorf("unexpected approval: %v", approved.Approval)
+	}
+	if len(approved.Approval.Voters) != 1 || approved.Approval.Voters[0] != "john" {
+		t.Errorf("unexpected voters: %v", approved.Approval.Voters)
+	}
+
+	rejected, err := client.Reject(ctx, &RejectRequest{Identifier: "prod/whd-prod:0.0.15"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rejected.Approval.Status != types.ApprovalStatusRejected.String() {
+		t.Errorf("unexpected status: %s", rejected.Approval.Status)
+	}
+
+	// anonymous votes are counted
+	approved, err = client.Approve(ctx, &ApproveRequest{Identifier: "dev/whd-dev:0.0.15"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if approved.Approval.VotesReceived != 2 || approved.Approval.Status != types.ApprovalStatusApproved.String() {
+		t.Errorf("unexpected approval: %v", approved.Approval)
+	}
+
+	// unless approval limits who can vote
+	err = am.Create(&types.Approval{Provider: types.ProviderTypeHelm, Identifier: "stg/whd-stg:0.0.15", VotesRequired: 1, NewVersion: "0.0.15", AllowedVoters: []string{"john"}})
+	if err != nil {
+		t.Fatalf("failed to create approval: %s", err)
+	}
+	_, err = client.Approve(ctx, &ApproveRequest{Identifier: "stg/whd-stg:0.0.15"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected invalid argument, got: %v", err)
+	}
+
+	_, err = client.Reject(ctx, &RejectRequest{Identifier: "dev/missing:1.0.0"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected not found, got: %v", err)
+	}
+}
+
+func TestListTrackedImages(t *testing.T) {
+	ref, _ := image.Parse("gcr.io/v2-namespace/hello-world:1.1.1")
+	fp := &fakeProvider{
+		images: []*types.TrackedImage{
+			{
+				Image:        ref,
+				Trigger:      types.TriggerTypePoll,
+				PollSchedule: "@every 1m",
+				Provider:     "kubernetes",
+				Namespace:    "default",
+				Policy:       fakePolicy{},
+				Meta:         map[string]string{"foo": "bar"},
+			},
+		},
+	}
+	client, _, teardown := newTestingServer(t, fp)
+	defer teardown()
+
+	resp, err := client.ListTrackedImages(authenticated("user-1", "secret"), &ListTrackedImagesRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(resp.Images) != 1 {
+		t.Fatalf("expected 1 image, got: %d", len(resp.Images))
+	}
+
+	img := resp.Images[0]
+	if img.Image != "v2-namespace/hello-world:1.1.1" || img.Registry != "gcr.io" {
+		t.Errorf("unexpected image: %s (%s)", img.Image, img.Registry)
+	}
+	if img.Trigger != "poll" || img.PollSchedule != "@every 1m" || img.Policy != "fake" {
+		t.Errorf("unexpected image settings: %v", img)
+	}
+	if img.Meta["foo"] != "bar" {
+		t.Errorf("unexpected meta: %v", img.Meta)
+	}
+}
+
+type fakePolicy struct{}
+
+func (fakePolicy) ShouldUpdate(current, new string) (bool, error) { return false, nil }
+func (fakePolicy) Name() string                                   { return "fake" }
@@ -1,11 +1,15 @@
 package helm
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/alwinius/bow/pkg/store"
 	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/trace"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -15,12 +19,37 @@ func getIdentifier(namespace, name, version string) string {
 	return namespace + "/" + name + ":" + version
 }
 
-func (p *Provider) checkForApprovals(event *types.Event, plans []*UpdatePlan) (approvedPlans []*UpdatePlan) {
+// getValuesPreview - describes each changed value together with its previous value and,
+// for tag updates, the image repository, ie: image.repository=myapp image.tag: 1.0.0 → 1.1.0
+func getValuesPreview(plan *UpdatePlan) []string {
+	var paths []string
+	for path := range plan.Values {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var preview []string
+	for _, path := range paths {
+		change := fmt.Sprintf("%s: %s → %s", path, plan.CurrentValues[path], plan.Values[path])
+		if plan.Config != nil {
+			for _, img := range plan.Config.Images {
+				if img.TagPath == path && img.RepositoryPath != "" {
+					change = fmt.Sprintf("%s=%s %s", img.RepositoryPath, plan.CurrentValues[img.RepositoryPath], change)
+					break
+				}
+			}
+		}
+		preview = append(preview, change)
+	}
+	return preview
+}
+
+func (p *Provider) checkForApprovals(ctx context.Context, event *types.Event, plans []*UpdatePlan) (approvedPlans []*UpdatePlan) {
 	approvedPlans = []*UpdatePlan{}
 	for _, plan := range plans {
-		approved, err := p.isApproved(event, plan)
+		approved, err := p.isApproved(ctx, event, plan)
 		if err != nil {
-			log.WithFields(log.Fields{
+			trace.Log(ctx).WithFields(log.Fields{
 				"error":        err,
 				"release_name": plan.Name,
 				"namespace":    plan.Namespace,
@@ -39,7 +68,7 @@ func (p *Provider) updateComplete(plan *UpdatePlan) error {
 	return p.approvalManager.Archive(getIdentifier(plan.Namespace, plan.Name, plan.NewVersion))
 }
 
-func (p *Provider) isApproved(event *types.Event, plan *UpdatePlan) (bool, error) {
+func (p *Provider) isApproved(ctx context.Context, event *types.Event, plan *UpdatePlan) (bool, error) {
 	if plan.Config.Approvals == 0 {
 		return true, nil
 	}
@@ -71,16 +100,24 @@ func (p *Provider) isApproved(event *types.Event, plan *UpdatePlan) (bool, error
 				NewVersion:     plan.NewVersion,
 				VotesRequired:  plan.Config.Approvals,
 				VotesReceived:  0,
+				AllowedVoters:  plan.Config.ApprovalVoters,
 				Rejected:       false,
 				Deadline:       time.Now().Add(time.Duration(plan.Config.ApprovalDeadline) * time.Hour),
 			}
 
-			approval.Message = fmt.Sprintf("New image is available for release %s/%s (%s).",
+			approval.Message = fmt.Sprintf("New image is available for release %s/%s (%s): %s",
 				plan.Namespace,
 				plan.Name,
 				approval.Delta(),
+				strings.Join(getValuesPreview(plan), ", "),
 			)
 
+			trace.Log(ctx).WithFields(log.Fields{
+				"name":      plan.Name,
+				"namespace": plan.Namespace,
+				"approvals": plan.Config.Approvals,
+			}).Info("provider.helm: requesting approval")
+
 			return false, p.approvalManager.Create(approval)
 		}
 
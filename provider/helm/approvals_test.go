@@ -0,0 +1,41 @@
+package helm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetValuesPreview(t *testing.T) {
+	plan := &UpdatePlan{
+		Values: map[string]string{
+			"image.tag":         "1.1.0",
+			"sidecar.image":     "gcr.io/v2-namespace/sidecar:0.2.0",
+			"image.digest":      "sha256:new",
+			"unrelated.setting": "x",
+		},
+		CurrentValues: map[string]string{
+			"image.repository": "myapp",
+			"image.tag":        "1.0.0",
+			"image.digest":     "sha256:old",
+			"sidecar.image":    "gcr.io/v2-namespace/sidecar:0.1.0",
+		},
+		Config: &bowChartConfig{
+			Images: []ImageDetails{
+				{RepositoryPath: "image.repository", TagPath: "image.tag", DigestPath: "image.digest"},
+				{RepositoryPath: "sidecar.image"},
+			},
+		},
+	}
+
+	expected := []string{
+		"image.digest: sha256:old → sha256:new",
+		"image.repository=myapp image.tag: 1.0.0 → 1.1.0",
+		"sidecar.image: gcr.io/v2-namespace/sidecar:0.1.0 → gcr.io/v2-namespace/sidecar:0.2.0",
+		"unrelated.setting:  → x",
+	}
+
+	got := getValuesPreview(plan)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("getValuesPreview() = %v, want %v", got, expected)
+	}
+}
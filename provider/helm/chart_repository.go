@@ -0,0 +1,104 @@
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/helm/pkg/chartutil"
+	hapi_chart "k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/repo"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ChartRepository - fetches charts, used to upgrade releases to the chart
+// version that ships with the new image
+type ChartRepository interface {
+	Get(name, version string) (*hapi_chart.Chart, error)
+}
+
+// HTTPChartRepository - chart repository serving index.yaml and chart archives
+// over http, ie: chartmuseum or charts hosted in a bucket
+type HTTPChartRepository struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPChartRepository - create new chart repository client for the repository url
+func NewHTTPChartRepository(url string) *HTTPChartRepository {
+	return &HTTPChartRepository{
+		url:    url,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Get - downloads chart version, versions with "v" prefix (as image tags
+// often have) also match chart versions without it
+func (r *HTTPChartRepository) Get(name, version string) (*hapi_chart.Chart, error) {
+	indexURL, err := repo.ResolveReferenceURL(r.url, "index.yaml")
+	if err != nil {
+		return nil, err
+	}
+	body, err := r.download(indexURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository index: %s", err)
+	}
+
+	var index repo.IndexFile
+	err = yaml.Unmarshal(body, &index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse repository index: %s", err)
+	}
+
+	cv := chartVersion(index.Entries[name], version)
+	if cv == nil || len(cv.URLs) == 0 {
+		return nil, fmt.Errorf("chart %s version %s not found", name, version)
+	}
+
+	chartURL, err := repo.ResolveReferenceURL(r.url, cv.URLs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"chart":   name,
+		"version": cv.Version,
+		"url":     chartURL,
+	}).Debug("provider.helm: downloading chart")
+
+	archive, err := r.download(chartURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download chart: %s", err)
+	}
+
+	return chartutil.LoadArchive(bytes.NewReader(archive))
+}
+
+func (r *HTTPChartRepository) download(url string) ([]byte, error) {
+	resp, err := r.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, url)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func chartVersion(versions repo.ChartVersions, version string) *repo.ChartVersion {
+	for _, candidate := range []string{version, strings.TrimPrefix(version, "v")} {
+		for _, cv := range versions {
+			if cv.Version == candidate {
+				return cv
+			}
+		}
+	}
+	return nil
+}
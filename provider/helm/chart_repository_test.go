@@ -0,0 +1,71 @@
+package helm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/helm/pkg/chartutil"
+	hapi_chart "k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+func TestHTTPChartRepositoryGet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chartrepotest")
+	if err != nil {
+		t.Fatalf("failed to create dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	archive, err := chartutil.Save(&hapi_chart.Chart{
+		Metadata: &hapi_chart.Metadata{Name: "wd", Version: "1.2.3", ApiVersion: chartutil.ApiVersionV1},
+		Values:   &hapi_chart.Config{Raw: "image:\n  tag: 1.2.3\n"},
+	}, dir)
+	if err != nil {
+		t.Fatalf("failed to package chart: %s", err)
+	}
+
+	index := `apiVersion: v1
+entries:
+  wd:
+  - name: wd
+    version: 1.2.4
+    urls:
+    - charts/wd-1.2.4.tgz
+  - name: wd
+    version: 1.2.3
+    urls:
+    - charts/wd-1.2.3.tgz
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.yaml":
+			fmt.Fprint(w, index)
+		case "/charts/" + filepath.Base(archive):
+			http.ServeFile(w, r, archive)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repository := NewHTTPChartRepository(ts.URL)
+
+	chart, err := repository.Get("wd", "v1.2.3")
+	if err != nil {
+		t.Fatalf("failed to get chart: %s", err)
+	}
+	if chart.GetMetadata().GetVersion() != "1.2.3" {
+		t.Errorf("unexpected chart version: %s", chart.GetMetadata().GetVersion())
+	}
+
+	if _, err := repository.Get("wd", "1.3.0"); err == nil {
+		t.Errorf("expected error for missing version")
+	}
+	if _, err := repository.Get("wd", "1.2.4"); err == nil {
+		t.Errorf("expected error for missing archive")
+	}
+}
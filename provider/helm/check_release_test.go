@@ -0,0 +1,211 @@
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alwinius/bow/types"
+
+	"k8s.io/helm/pkg/chartutil"
+	hapi_chart "k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+// fakeValues - release values with bow configuration tracking image.repository
+// and image.tag, bow config fields are merged into the defaults
+func fakeValues(tag string, bowCfg map[string]interface{}) chartutil.Values {
+	cfg := map[string]interface{}{
+		"trigger": "poll",
+		"images": []interface{}{
+			map[string]interface{}{
+				"repository": "image.repository",
+				"tag":        "image.tag",
+			},
+		},
+	}
+	for k, v := range bowCfg {
+		cfg[k] = v
+	}
+	return chartutil.Values{
+		"image": map[string]interface{}{
+			"repository": "gcr.io/v2-namespace/hello-world",
+			"tag":        tag,
+		},
+		"bow": cfg,
+	}
+}
+
+// fakeWorkerValues - same as fakeValues with worker.tag for a second
+// component running the image.repository image
+func fakeWorkerValues(tag, workerTag string, bowCfg map[string]interface{}) chartutil.Values {
+	vals := fakeValues(tag, bowCfg)
+	vals["worker"] = map[string]interface{}{"tag": workerTag}
+	return vals
+}
+
+var workerImages = []interface{}{
+	map[string]interface{}{"repository": "image.repository", "tag": "image.tag"},
+	map[string]interface{}{"repository": "image.repository", "tag": "worker.tag"},
+}
+
+var workerTagPathsImages = []interface{}{
+	map[string]interface{}{"repository": "image.repository", "tag": "image.tag, worker.tag"},
+}
+
+var tagSuffixImages = []interface{}{
+	map[string]interface{}{
+		"repository": "image.repository",
+		"tag":        "image.tag",
+		"tagSuffix":  "-prod",
+	},
+}
+
+func TestCheckRelease(t *testing.T) {
+	tests := []struct {
+		name       string
+		vals       chartutil.Values
+		repo       *types.Repository
+		wantUpdate bool
+		wantPlan   *UpdatePlan
+		wantCfgErr error
+	}{
+		{
+			name:       "semver bump",
+			vals:       fakeValues("1.1.0", map[string]interface{}{"policy": "minor"}),
+			repo:       &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.0"},
+			wantUpdate: true,
+			wantPlan: &UpdatePlan{
+				Values:         map[string]string{"image.tag": "1.2.0"},
+				CurrentVersion: "1.1.0",
+				NewVersion:     "1.2.0",
+			},
+		},
+		{
+			name: "same version",
+			vals: fakeValues("1.1.0", map[string]interface{}{"policy": "minor"}),
+			repo: &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.0"},
+		},
+		{
+			name:       "force policy same tag",
+			vals:       fakeValues("latest", map[string]interface{}{"policy": "force"}),
+			repo:       &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "latest"},
+			wantUpdate: true,
+			wantPlan: &UpdatePlan{
+				Values:         map[string]string{"image.tag": "latest"},
+				CurrentVersion: "latest",
+				NewVersion:     "latest",
+			},
+		},
+		{
+			name: "force policy with matchTag ignores different tag",
+			vals: fakeValues("latest", map[string]interface{}{"policy": "force", "matchTag": true}),
+			repo: &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.0"},
+		},
+		{
+			name: "different image",
+			vals: fakeValues("1.1.0", map[string]interface{}{"policy": "minor"}),
+			repo: &types.Repository{Name: "gcr.io/v2-namespace/other", Tag: "1.2.0"},
+		},
+		{
+			name: "tag without suffix",
+			vals: fakeValues("1.1.0-prod", map[string]interface{}{"policy": "all", "images": tagSuffixImages}),
+			repo: &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.0-staging"},
+		},
+		{
+			name:       "tag with suffix",
+			vals:       fakeValues("1.1.0-prod", map[string]interface{}{"policy": "all", "images": tagSuffixImages}),
+			repo:       &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.0-prod"},
+			wantUpdate: true,
+			wantPlan: &UpdatePlan{
+				Values:         map[string]string{"image.tag": "1.2.0-prod"},
+				CurrentVersion: "1.1.0-prod",
+				NewVersion:     "1.2.0-prod",
+			},
+		},
+		{
+			name:       "same repository in two images",
+			vals:       fakeWorkerValues("1.1.0", "1.1.0", map[string]interface{}{"policy": "minor", "images": workerImages}),
+			repo:       &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.0"},
+			wantUpdate: true,
+			wantPlan: &UpdatePlan{
+				Values:         map[string]string{"image.tag": "1.2.0", "worker.tag": "1.2.0"},
+				CurrentVersion: "1.1.0",
+				NewVersion:     "1.2.0",
+			},
+		},
+		{
+			name:       "comma separated tag paths",
+			vals:       fakeWorkerValues("1.1.0", "1.1.0", map[string]interface{}{"policy": "minor", "images": workerTagPathsImages}),
+			repo:       &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.0"},
+			wantUpdate: true,
+			wantPlan: &UpdatePlan{
+				Values:         map[string]string{"image.tag": "1.2.0", "worker.tag": "1.2.0"},
+				CurrentVersion: "1.1.0",
+				NewVersion:     "1.2.0",
+			},
+		},
+		{
+			name:       "comma separated tag paths, one already updated",
+			vals:       fakeWorkerValues("1.2.0", "1.1.0", map[string]interface{}{"policy": "minor", "images": workerTagPathsImages}),
+			repo:       &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.0"},
+			wantUpdate: true,
+			wantPlan: &UpdatePlan{
+				Values:         map[string]string{"worker.tag": "1.2.0"},
+				CurrentVersion: "1.1.0",
+				NewVersion:     "1.2.0",
+			},
+		},
+		{
+			name:       "policy not specified",
+			vals:       fakeValues("1.1.0", nil),
+			repo:       &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.0"},
+			wantCfgErr: ErrPolicyNotSpecified,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := &parsedRelease{vals: tt.vals}
+			parsed.cfg, parsed.cfgErr = getbowConfig(tt.vals)
+			if parsed.cfgErr != tt.wantCfgErr {
+				t.Fatalf("getbowConfig() error = %v, want %v", parsed.cfgErr, tt.wantCfgErr)
+			}
+
+			chart := &hapi_chart.Chart{Metadata: &hapi_chart.Metadata{Name: "app-x"}}
+			plan, shouldUpdate, err := checkParsedRelease(context.Background(), tt.repo, "default", "release-1", chart, parsed)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if shouldUpdate != tt.wantUpdate {
+				t.Fatalf("checkRelease() shouldUpdate = %v, want %v", shouldUpdate, tt.wantUpdate)
+			}
+			if !tt.wantUpdate {
+				if len(plan.Values) != 0 {
+					t.Errorf("expected no values to be updated, got: %v", plan.Values)
+				}
+				return
+			}
+
+			if plan.Namespace != "default" || plan.Name != "release-1" || plan.Chart != chart {
+				t.Errorf("unexpected release: %s/%s", plan.Namespace, plan.Name)
+			}
+			if plan.CurrentVersion != tt.wantPlan.CurrentVersion || plan.NewVersion != tt.wantPlan.NewVersion {
+				t.Errorf("unexpected versions: %s->%s, want %s->%s", plan.CurrentVersion, plan.NewVersion, tt.wantPlan.CurrentVersion, tt.wantPlan.NewVersion)
+			}
+			if len(plan.Values) != len(tt.wantPlan.Values) {
+				t.Errorf("unexpected values: %v, want %v", plan.Values, tt.wantPlan.Values)
+			}
+			for k, v := range tt.wantPlan.Values {
+				if plan.Values[k] != v {
+					t.Errorf("unexpected value of %s: %s, want %s", k, plan.Values[k], v)
+				}
+			}
+			for k := range tt.wantPlan.Values {
+				if plan.CurrentValues[k] != tt.wantPlan.CurrentVersion {
+					t.Errorf("unexpected current values: %v", plan.CurrentValues)
+				}
+			}
+			if plan.Config == nil || plan.Config.Policy != parsed.cfg.Policy {
+				t.Errorf("expected bow config to be set on the plan")
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package helm
+
+import (
+	"context"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/deadletter"
+)
+
+// handleEvent - processes event unless circuit for the image is open, events
+// are added to the dead letter queue when their circuit opens
+func (p *Provider) handleEvent(ctx context.Context, event *types.Event) {
+	p.breaker.HandleEvent(ctx, ProviderName, event, p.deadLetters, func(ctx context.Context, event *types.Event) error {
+		return p.processEvent(ctx, event)
+	})
+}
+
+// SetDeadLetterQueue - events are added to the queue when their circuit opens,
+// retried events bypass the open circuit
+func (p *Provider) SetDeadLetterQueue(q *deadletter.Queue) {
+	p.deadLetters = q
+	q.Register(ProviderName, p.retryDeadLetter)
+}
+
+func (p *Provider) retryDeadLetter(event *types.Event) {
+	p.breaker.Retry(event)
+	p.requeue(event)
+}
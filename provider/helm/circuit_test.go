@@ -0,0 +1,93 @@
+package helm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/approvals"
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/circuit"
+	"github.com/alwinius/bow/util/deadletter"
+	"k8s.io/helm/pkg/helm"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+type failingImplementer struct {
+	fakeImplementer
+	calls int
+}
+
+func (i *failingImplementer) ListReleases(opts ...helm.ReleaseListOption) (*rls.ListReleasesResponse, error) {
+	i.calls++
+	return nil, errors.New("tiller unavailable")
+}
+
+func TestHandleEventCircuitBreaker(t *testing.T) {
+	fi := &failingImplementer{}
+	provider := NewProvider(fi, &fakeSender{}, approvals.New(&approvals.Opts{}), nil, nil, nil, nil)
+	provider.breaker = circuit.New(circuit.Opts{Threshold: 2, Backoff: time.Hour})
+
+	event := &types.Event{Repository: types.Repository{Name: "karolisr/webhook-demo", Tag: "0.0.11"}}
+	for i := 0; i < 5; i++ {
+		provider.handleEvent(context.Background(), event)
+	}
+
+	if fi.calls != 2 {
+		t.Errorf("expected events to be processed until circuit opens, got %d calls", fi.calls)
+	}
+
+	if state := provider.breaker.State(circuit.Key("index.docker.io/karolisr/webhook-demo")); state != circuit.StateOpen {
+		t.Errorf("expected open circuit, got: %s", state)
+	}
+}
+
+func TestHandleEventDeadLetter(t *testing.T) {
+	fi := &failingImplementer{}
+	provider := NewProvider(fi, &fakeSender{}, approvals.New(&approvals.Opts{}), nil, nil, nil, nil)
+	provider.breaker = circuit.New(circuit.Opts{Threshold: 2, Backoff: time.Hour})
+	dlq := deadletter.New(10)
+	provider.SetDeadLetterQueue(dlq)
+
+	event := &types.Event{Repository: types.Repository{Name: "karolisr/webhook-demo", Tag: "0.0.11"}}
+	for i := 0; i < 3; i++ {
+		provider.handleEvent(context.Background(), event)
+	}
+
+	entries := dlq.List()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 dead letter, got: %d", len(entries))
+	}
+	if entries[0].Provider != ProviderName {
+		t.Errorf("unexpected provider: %s", entries[0].Provider)
+	}
+	if entries[0].Retries != 2 {
+		t.Errorf("expected 2 retries, got: %d", entries[0].Retries)
+	}
+	if entries[0].Error != "tiller unavailable" {
+		t.Errorf("unexpected error: %s", entries[0].Error)
+	}
+
+	_, err := dlq.Retry(entries[0].ID)
+	if err != nil {
+		t.Fatalf("failed to retry: %s", err)
+	}
+
+	if state := provider.breaker.State(circuit.Key("index.docker.io/karolisr/webhook-demo")); state != circuit.StateClosed {
+		t.Errorf("expected retry to close circuit, got: %s", state)
+	}
+
+	select {
+	case queued := <-provider.events:
+		if queued.event.Repository.Tag != "0.0.11" {
+			t.Errorf("unexpected requeued tag: %s", queued.event.Repository.Tag)
+		}
+	default:
+		t.Errorf("expected event to be requeued")
+	}
+
+	if len(dlq.List()) != 0 {
+		t.Errorf("expected retried dead letter to be removed")
+	}
+}
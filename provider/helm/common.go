@@ -3,9 +3,11 @@ package helm
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/alwinius/bow/types"
 	"github.com/alwinius/bow/util/image"
+	"github.com/alwinius/bow/util/timeutil"
 
 	"k8s.io/helm/pkg/chartutil"
 
@@ -32,6 +34,17 @@ func getImages(vals chartutil.Values) ([]*types.TrackedImage, error) {
 		return nil, ErrbowConfigNotFound
 	}
 
+	// resolving effective schedule so tracked images report what
+	// the poll trigger will actually use
+	if bowCfg.PollSchedule == "" {
+		bowCfg.PollSchedule = types.BowPollDefaultSchedule
+	}
+
+	_, err = timeutil.ParseSchedule(bowCfg.PollSchedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid poll schedule: %s", err)
+	}
+
 	for _, imageDetails := range bowCfg.Images {
 		imageRef, err := parseImage(vals, &imageDetails)
 		if err != nil {
@@ -43,11 +56,30 @@ func getImages(vals chartutil.Values) ([]*types.TrackedImage, error) {
 			continue
 		}
 
+		schedule := bowCfg.PollSchedule
+		if imageDetails.PollSchedule != "" {
+			_, err = timeutil.ParseSchedule(imageDetails.PollSchedule)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"error":           err,
+					"repository_name": imageDetails.RepositoryPath,
+					"schedule":        imageDetails.PollSchedule,
+				}).Error("provider.helm: invalid image poll schedule")
+				continue
+			}
+			schedule = imageDetails.PollSchedule
+		}
+
 		trackedImage := &types.TrackedImage{
 			Image:        imageRef,
-			PollSchedule: bowCfg.PollSchedule,
+			PollSchedule: schedule,
 			Trigger:      bowCfg.Trigger,
 			Policy:       bowCfg.Plc,
+
+			UseWorkloadIdentity: bowCfg.UseWorkloadIdentity,
+		}
+		if imageDetails.ImagePullSecret != "" {
+			trackedImage.Secrets = []string{imageDetails.ImagePullSecret}
 		}
 
 		images = append(images, trackedImage)
@@ -81,6 +113,32 @@ func getUpdatedImage(ref *image.Reference, version string) string {
 	return fmt.Sprintf("%s:%s", ref.Repository(), version)
 }
 
+// splitTagPaths - image with comma separated tag paths, ie: app.image.tag,worker.image.tag
+// is tracked as one image per tag path, components deploying the same repository get
+// their tags checked and updated separately
+func splitTagPaths(images []ImageDetails) []ImageDetails {
+	var split []ImageDetails
+	for _, details := range images {
+		if !strings.Contains(details.TagPath, ",") {
+			split = append(split, details)
+			continue
+		}
+		for _, tagPath := range strings.Split(details.TagPath, ",") {
+			tagPath = strings.TrimSpace(tagPath)
+			if tagPath == "" {
+				continue
+			}
+			d := details
+			d.TagPath = tagPath
+			split = append(split, d)
+		}
+	}
+	if split == nil {
+		return images
+	}
+	return split
+}
+
 func parseImage(vals chartutil.Values, details *ImageDetails) (*image.Reference, error) {
 	if details.RepositoryPath == "" {
 		return nil, fmt.Errorf("repository name path cannot be empty")
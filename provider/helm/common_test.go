@@ -7,7 +7,9 @@ import (
 	"github.com/alwinius/bow/internal/policy"
 	"github.com/alwinius/bow/types"
 	"github.com/alwinius/bow/util/image"
+	"github.com/golang/protobuf/ptypes/any"
 	"k8s.io/helm/pkg/chartutil"
+	hapi_chart "k8s.io/helm/pkg/proto/hapi/chart"
 )
 
 var chartValuesA = `
@@ -58,9 +60,10 @@ func Test_getImages(t *testing.T) {
 			},
 			want: []*types.TrackedImage{
 				&types.TrackedImage{
-					Image:   img,
-					Trigger: types.TriggerTypePoll,
-					Policy:  policy.NewSemverPolicy(policy.SemverPolicyTypeAll),
+					Image:        img,
+					Trigger:      types.TriggerTypePoll,
+					PollSchedule: types.BowPollDefaultSchedule,
+					Policy:       policy.NewSemverPolicy(policy.SemverPolicyTypeAll),
 				},
 			},
 			wantErr: false,
@@ -72,19 +75,22 @@ func Test_getImages(t *testing.T) {
 			},
 			want: []*types.TrackedImage{
 				&types.TrackedImage{
-					Image:   mustParse("quay.io/prometheus/alertmanager:v0.16.2"),
-					Trigger: types.TriggerTypePoll,
-					Policy:  policy.NewSemverPolicy(policy.SemverPolicyTypeAll),
+					Image:        mustParse("quay.io/prometheus/alertmanager:v0.16.2"),
+					Trigger:      types.TriggerTypePoll,
+					PollSchedule: types.BowPollDefaultSchedule,
+					Policy:       policy.NewSemverPolicy(policy.SemverPolicyTypeAll),
 				},
 				&types.TrackedImage{
-					Image:   mustParse("quay.io/coreos/prometheus-operator:v0.29.0"),
-					Trigger: types.TriggerTypePoll,
-					Policy:  policy.NewSemverPolicy(policy.SemverPolicyTypeAll),
+					Image:        mustParse("quay.io/coreos/prometheus-operator:v0.29.0"),
+					Trigger:      types.TriggerTypePoll,
+					PollSchedule: types.BowPollDefaultSchedule,
+					Policy:       policy.NewSemverPolicy(policy.SemverPolicyTypeAll),
 				},
 				&types.TrackedImage{
-					Image:   mustParse("quay.io/prometheus/prometheus:v2.7.2"),
-					Trigger: types.TriggerTypePoll,
-					Policy:  policy.NewSemverPolicy(policy.SemverPolicyTypeAll),
+					Image:        mustParse("quay.io/prometheus/prometheus:v2.7.2"),
+					Trigger:      types.TriggerTypePoll,
+					PollSchedule: types.BowPollDefaultSchedule,
+					Policy:       policy.NewSemverPolicy(policy.SemverPolicyTypeAll),
 				},
 			},
 			wantErr: false,
@@ -1511,3 +1517,118 @@ prometheus:
           ##
           # serverName: ""
 `
+
+var chartValuesImageSchedules = `
+image:
+  repository: gcr.io/v2-namespace/hello-world
+  tag: 1.1.0
+base:
+  repository: gcr.io/v2-namespace/base
+  tag: 2.0.0
+broken:
+  repository: gcr.io/v2-namespace/broken
+  tag: 3.0.0
+
+bow:
+  policy: all
+  trigger: poll
+  pollSchedule: "@every 5m"
+  images:
+    - repository: image.repository
+      tag: image.tag
+    - repository: base.repository
+      tag: base.tag
+      pollSchedule: "@every 720h"
+    - repository: broken.repository
+      tag: broken.tag
+      pollSchedule: "not a schedule"
+`
+
+func Test_getImagesPerImageSchedule(t *testing.T) {
+	vals, err := chartutil.ReadValues([]byte(chartValuesImageSchedules))
+	if err != nil {
+		t.Fatalf("failed to read values: %s", err)
+	}
+
+	images, err := getImages(vals)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// image with invalid schedule is skipped
+	if len(images) != 2 {
+		t.Fatalf("expected 2 images, got: %d", len(images))
+	}
+
+	if images[0].PollSchedule != "@every 5m" {
+		t.Errorf("expected release schedule, got: %s", images[0].PollSchedule)
+	}
+	if images[1].PollSchedule != "@every 720h" {
+		t.Errorf("expected image schedule, got: %s", images[1].PollSchedule)
+	}
+}
+
+var parentChartValues = `
+bow:
+  policy: all
+  trigger: poll
+  images:
+    - repository: mysubchart.image.repository
+      tag: mysubchart.image.tag
+    - repository: worker.image.repository
+      tag: worker.image.tag
+`
+
+var subchartValues = `
+image:
+  repository: gcr.io/v2-namespace/hello-world
+  tag: 1.1.0
+`
+
+var parentChartRequirements = `
+dependencies:
+  - name: mysubchart
+    version: 0.1.0
+  - name: mysubchart
+    version: 0.1.0
+    alias: worker
+`
+
+func Test_getImagesSubcharts(t *testing.T) {
+	subchart := &hapi_chart.Chart{
+		Metadata: &hapi_chart.Metadata{Name: "mysubchart", Version: "0.1.0"},
+		Values:   &hapi_chart.Config{Raw: subchartValues},
+	}
+	parent := &hapi_chart.Chart{
+		Metadata:     &hapi_chart.Metadata{Name: "app-x", Version: "1.0.0"},
+		Values:       &hapi_chart.Config{Raw: parentChartValues},
+		Files:        []*any.Any{{TypeUrl: "requirements.yaml", Value: []byte(parentChartRequirements)}},
+		Dependencies: []*hapi_chart.Chart{subchart},
+	}
+	// aliased dependency only overrides the tag, repository comes from subchart values
+	config := &hapi_chart.Config{Raw: "worker:\n  image:\n    tag: 1.0.5\n"}
+
+	vals, err := values(parent, config)
+	if err != nil {
+		t.Fatalf("failed to get values: %s", err)
+	}
+
+	images, err := getImages(vals)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(images) != 2 {
+		t.Fatalf("expected 2 images, got: %d", len(images))
+	}
+	if images[0].Image.Remote() != "gcr.io/v2-namespace/hello-world:1.1.0" {
+		t.Errorf("unexpected subchart image: %s", images[0].Image.Remote())
+	}
+	if images[1].Image.Remote() != "gcr.io/v2-namespace/hello-world:1.0.5" {
+		t.Errorf("unexpected aliased subchart image: %s", images[1].Image.Remote())
+	}
+
+	// release chart is upgraded as is, aliases are resolved on a copy
+	if len(parent.Dependencies) != 1 || parent.Dependencies[0].Metadata.Name != "mysubchart" {
+		t.Errorf("release chart dependencies were modified")
+	}
+}
@@ -0,0 +1,111 @@
+package helm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/timeutil"
+
+	"k8s.io/helm/pkg/chartutil"
+	hapi_chart "k8s.io/helm/pkg/proto/hapi/chart"
+	hapi_release5 "k8s.io/helm/pkg/proto/hapi/release"
+)
+
+// DefaultConfigCacheTTL - how long values and bow configuration parsed from
+// a release are reused by TrackedImages and createUpdatePlans
+const DefaultConfigCacheTTL = 30 * time.Second
+
+// parsedRelease - values, bow configuration and tracked images of a release,
+// errors are kept so callers can report them as if they parsed the release
+type parsedRelease struct {
+	vals    chartutil.Values
+	valsErr error
+
+	cfg    *bowChartConfig
+	cfgErr error
+
+	// tracked images are shared, callers have to copy them before modifying
+	images    []*types.TrackedImage
+	imagesErr error
+
+	key      string
+	revision int32
+	expires  time.Time
+}
+
+func parseRelease(chart *hapi_chart.Chart, config *hapi_chart.Config) *parsedRelease {
+	parsed := &parsedRelease{}
+
+	parsed.vals, parsed.valsErr = values(chart, config)
+	if parsed.valsErr != nil {
+		return parsed
+	}
+
+	parsed.cfg, parsed.cfgErr = getbowConfig(parsed.vals)
+	if parsed.cfgErr != nil {
+		return parsed
+	}
+
+	parsed.images, parsed.imagesErr = getImages(parsed.vals)
+	return parsed
+}
+
+// configCache - parsed releases by namespace/name, entries are reused until they
+// expire or release revision, chart version or values change
+type configCache struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	releases map[string]*parsedRelease
+}
+
+func newConfigCache(ttl time.Duration) *configCache {
+	return &configCache{
+		ttl:      ttl,
+		releases: make(map[string]*parsedRelease),
+	}
+}
+
+// releaseKey - chart name and version together with a hash of chart values and
+// release config, new chart versions or changed values are parsed again
+func releaseKey(release *hapi_release5.Release) string {
+	h := sha256.New()
+	h.Write([]byte(release.GetChart().GetValues().GetRaw()))
+	h.Write([]byte{0})
+	h.Write([]byte(release.GetConfig().GetRaw()))
+
+	metadata := release.GetChart().GetMetadata()
+	return metadata.GetName() + "-" + metadata.GetVersion() + "/" + hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *configCache) get(release *hapi_release5.Release) *parsedRelease {
+	id := release.Namespace + "/" + release.Name
+	key := releaseKey(release)
+	now := timeutil.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.releases[id]
+	if ok && cached.key == key && cached.revision == release.Version && now.Before(cached.expires) {
+		return cached
+	}
+
+	// dropping expired entries so deleted releases don't stay around
+	for existingID, existing := range c.releases {
+		if !now.Before(existing.expires) {
+			delete(c.releases, existingID)
+		}
+	}
+
+	parsed := parseRelease(release.Chart, release.Config)
+	parsed.key = key
+	parsed.revision = release.Version
+	parsed.expires = now.Add(c.ttl)
+	c.releases[id] = parsed
+
+	return parsed
+}
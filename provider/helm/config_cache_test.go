@@ -0,0 +1,87 @@
+package helm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/util/timeutil"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	hapi_release5 "k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestConfigCache(t *testing.T) {
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeutil.Now = func() time.Time { return now }
+	defer func() { timeutil.Now = time.Now }()
+
+	newRelease := func() *hapi_release5.Release {
+		return &hapi_release5.Release{
+			Name:      "release-1",
+			Namespace: "default",
+			Version:   1,
+			Chart: &chart.Chart{
+				Values:   &chart.Config{Raw: chartValuesA},
+				Metadata: &chart.Metadata{Name: "app-x", Version: "0.1.0"},
+			},
+			Config: &chart.Config{Raw: ""},
+		}
+	}
+
+	cache := newConfigCache(time.Minute)
+	release := newRelease()
+
+	first := cache.get(release)
+	if first.valsErr != nil || first.cfgErr != nil || first.imagesErr != nil {
+		t.Fatalf("unexpected errors: %v, %v, %v", first.valsErr, first.cfgErr, first.imagesErr)
+	}
+	if len(first.images) != 1 || first.images[0].Image.Tag() != "1.1.0" {
+		t.Fatalf("unexpected images: %v", first.images)
+	}
+
+	if cache.get(newRelease()) != first {
+		t.Errorf("expected parsed release to be reused")
+	}
+
+	upgraded := newRelease()
+	upgraded.Version = 2
+	second := cache.get(upgraded)
+	if second == first {
+		t.Errorf("expected release to be parsed again after revision change")
+	}
+
+	reconfigured := newRelease()
+	reconfigured.Version = 2
+	reconfigured.Config = &chart.Config{Raw: "image:\n  tag: 1.2.0\n"}
+	third := cache.get(reconfigured)
+	if third == second {
+		t.Errorf("expected release to be parsed again after config change")
+	}
+	if third.images[0].Image.Tag() != "1.2.0" {
+		t.Errorf("unexpected tag: %s", third.images[0].Image.Tag())
+	}
+
+	now = now.Add(2 * time.Minute)
+	if cache.get(reconfigured) == third {
+		t.Errorf("expected expired entry to be parsed again")
+	}
+}
+
+func TestConfigCacheNoPolicy(t *testing.T) {
+	cache := newConfigCache(time.Minute)
+	parsed := cache.get(&hapi_release5.Release{
+		Name:      "release-1",
+		Namespace: "default",
+		Chart: &chart.Chart{
+			Values:   &chart.Config{Raw: "image:\n  tag: 1.1.0\n"},
+			Metadata: &chart.Metadata{Name: "app-x", Version: "0.1.0"},
+		},
+		Config: &chart.Config{Raw: ""},
+	})
+	if parsed.cfgErr != ErrPolicyNotSpecified {
+		t.Errorf("expected ErrPolicyNotSpecified, got: %v", parsed.cfgErr)
+	}
+	if parsed.images != nil {
+		t.Errorf("expected no images, got: %v", parsed.images)
+	}
+}
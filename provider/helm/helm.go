@@ -1,17 +1,29 @@
 package helm
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/alwinius/bow/approvals"
 	"github.com/alwinius/bow/internal/policy"
 	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/circuit"
+	"github.com/alwinius/bow/util/deadletter"
+	"github.com/alwinius/bow/util/dedup"
 	"github.com/alwinius/bow/util/image"
+	"github.com/alwinius/bow/util/metrics"
+	"github.com/alwinius/bow/util/pending"
+	"github.com/alwinius/bow/util/stopper"
+	"github.com/alwinius/bow/util/timeutil"
+	"github.com/alwinius/bow/util/trace"
 
 	hapi_chart "k8s.io/helm/pkg/proto/hapi/chart"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -22,27 +34,38 @@ import (
 	"k8s.io/helm/pkg/chartutil"
 	"k8s.io/helm/pkg/helm"
 	"k8s.io/helm/pkg/strvals"
+	"k8s.io/helm/pkg/version"
 )
 
-var helmVersionedUpdatesCounter = prometheus.NewCounterVec(
+var helmVersionedUpdatesCounter = metrics.NewCounter(
 	prometheus.CounterOpts{
 		Name: "helm_versioned_updates_total",
 		Help: "How many versioned helm charts were updated, partitioned by chart name.",
 	},
-	[]string{"chart"},
+	"chart",
 )
 
-var helmUnversionedUpdatesCounter = prometheus.NewCounterVec(
+var helmUnversionedUpdatesCounter = metrics.NewCounter(
 	prometheus.CounterOpts{
 		Name: "helm_unversioned_updates_total",
 		Help: "How many unversioned helm charts were updated, partitioned by chart name.",
 	},
+	"chart",
+)
+
+var helmUpdateDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "helm_update_duration_seconds",
+		Help:    "How long helm release updates took, including failed ones, partitioned by chart name.",
+		Buckets: []float64{1, 5, 15, 30, 60, 120, 180, 300},
+	},
 	[]string{"chart"},
 )
 
 func init() {
 	prometheus.MustRegister(helmVersionedUpdatesCounter)
 	prometheus.MustRegister(helmUnversionedUpdatesCounter)
+	prometheus.MustRegister(helmUpdateDuration)
 }
 
 // ErrPolicyNotSpecified helm related errors
@@ -62,6 +85,14 @@ const ProviderName = "helm"
 // DefaultUpdateTimeout - update timeout in seconds
 const DefaultUpdateTimeout = 300
 
+// StopTimeout - how long Stop waits for the event that is being processed,
+// Stop returns afterwards even if release upgrades are still running
+var StopTimeout = 20 * time.Second
+
+// EventDedupWindow - events for the same repository and tag received within the
+// window are processed once, ie: several webhooks fired for the same push
+var EventDedupWindow = 5 * time.Second
+
 // UpdatePlan - release update plan
 type UpdatePlan struct {
 	Namespace string
@@ -74,6 +105,9 @@ type UpdatePlan struct {
 
 	// values to update path=value
 	Values map[string]string
+	// CurrentValues - values before the update path=value, also contains
+	// repository paths of images whose tag is updated
+	CurrentValues map[string]string
 
 	// Current (last seen cluster version)
 	CurrentVersion string
@@ -82,36 +116,96 @@ type UpdatePlan struct {
 
 	// ReleaseNotes is a slice of combined release notes.
 	ReleaseNotes []string
+
+	// images whose labels hold release notes, read once plan is created
+	releaseNotesLabels []*releaseNotesLabel
+
+	// Platform (os/arch) the update is for, set when event
+	// came from a multi-arch manifest list
+	Platform string
 }
 
 // bow:
-//   # bow policy (all/major/minor/patch/force)
+//   # bow policy (all/major/minor/patch/prerelease/force)
 //   policy: all
+//   # optional, all/patch policies update 1.4.2+build.57 to 1.4.2+build.58
+//   includeBuildMeta: true
+//   # optional, only 1.1.2-staging style pre-releases are tracked
+//   preReleaseChannel: staging
 //   # trigger type, defaults to events such as pubsub, webhooks
 //   trigger: poll
 //   pollSchedule: "@every 2m"
+//   # optional, updates are only applied inside the window (weekdays 02:00-04:00 UTC)
+//   updateWindow: "* 2-3 * * 1-5"
+//   # optional, no notifications when only the digest of the same tag changes
+//   notifyOnNoUpdate: false
+//   # optional, chart in the chart repository, release is also upgraded to the
+//   # chart version equal to the new image tag
+//   chartVersionPath: wd
+//   # optional, chart stored as OCI artifact, release is also upgraded to the
+//   # chart version equal to the new image tag
+//   chartRef: oci://ghcr.io/org/charts/wd
+//   # optional, polling authenticates to Artifact Registry and GCR with the
+//   # GKE Workload Identity service account instead of image pull secrets
+//   useWorkloadIdentity: true
 //   # images to track and update
 //   images:
 //     - repository: image.repository
 //       tag: image.tag
+//       # optional, overrides release level matchTag for this image
+//       matchTag: true
+//       # optional, overrides release level pollSchedule for this image
+//       pollSchedule: "@every 720h"
+//       # optional, release notes are read from this label of the new image
+//       releaseNotesLabel: org.opencontainers.image.description
+//       # optional, only tags ending with the suffix are updated to
+//       tagSuffix: -prod
+//     # components deploying the same repository can share the image entry
+//     - repository: app.image.repository
+//       tag: app.image.tag,worker.image.tag
 
 // Root - root element of the values yaml
 type Root struct {
-	bow bowChartConfig `json:"bow"`
+	Bow bowChartConfig `json:"bow"`
 }
 
 // bowChartConfig - bow related configuration taken from values.yaml
 type bowChartConfig struct {
 	Policy               string            `json:"policy"`
 	MatchTag             bool              `json:"matchTag"`
+	IncludeBuildMeta     bool              `json:"includeBuildMeta"`  // optional, all and patch policies update on higher build metadata
+	PreReleaseChannel    string            `json:"preReleaseChannel"` // optional, semver policies only track pre-releases of this channel
 	Trigger              types.TriggerType `json:"trigger"`
 	PollSchedule         string            `json:"pollSchedule"`
 	Approvals            int               `json:"approvals"`        // Minimum required approvals
 	ApprovalDeadline     int               `json:"approvalDeadline"` // Deadline in hours
+	ApprovalVoters       []string          `json:"approvalVoters"`   // optional, only votes of these voters count towards approvals
 	Images               []ImageDetails    `json:"images"`
 	NotificationChannels []string          `json:"notificationChannels"` // optional notification channels
+	UpdateWindow         string            `json:"updateWindow"`         // optional cron range expression, updates are deferred until it opens
+	NotifyOnNoUpdate     *bool             `json:"notifyOnNoUpdate"`     // optional, set to false to suppress notifications when version doesn't change
+	ChartVersionPath     string            `json:"chartVersionPath"`     // optional chart in the chart repository, upgraded to the version equal to the new tag
+	ChartRef             string            `json:"chartRef"`             // optional chart in OCI registry (oci://...), upgraded to the version equal to the new tag
+	UseWorkloadIdentity  bool              `json:"useWorkloadIdentity"`  // optional, polling authenticates to Google registries with the GKE Workload Identity
 
 	Plc policy.Policy `json:"-"`
+
+	// fields that aren't in the configuration schema, ie: typos
+	unknownFields []string
+}
+
+// notify - whether update notifications should be sent for the plan, same version
+// is only updated because of a new digest and charts can opt out of these
+func (c *bowChartConfig) notify(plan *UpdatePlan) bool {
+	return plan.CurrentVersion != plan.NewVersion || c.NotifyOnNoUpdate == nil || *c.NotifyOnNoUpdate
+}
+
+// imagePolicy - policy for the image, images can override release level matchTag
+func (c *bowChartConfig) imagePolicy(details *ImageDetails) (policy.Policy, error) {
+	if details.MatchTag == nil || *details.MatchTag == c.MatchTag {
+		return c.Plc, nil
+	}
+	return policy.GetPolicy(c.Policy, &policy.Options{MatchTag: *details.MatchTag, IncludeBuildMeta: c.IncludeBuildMeta, PreReleaseChannel: c.PreReleaseChannel})
 }
 
 // ImageDetails - image details
@@ -121,29 +215,102 @@ type ImageDetails struct {
 	DigestPath      string `json:"digest"`
 	ReleaseNotes    string `json:"releaseNotes"`
 	ImagePullSecret string `json:"imagePullSecret"`
+	Platform        string `json:"platform"`     // optional os/arch, ie: linux/amd64
+	MatchTag        *bool  `json:"matchTag"`     // optional, overrides release level matchTag for this image
+	PollSchedule    string `json:"pollSchedule"` // optional, overrides release level pollSchedule for this image
+	// optional image label with release notes, ie: org.opencontainers.image.description
+	ReleaseNotesLabel string `json:"releaseNotesLabel"`
+	// optional, only tags ending with the suffix are updated to, ie: -prod when
+	// staging and production images are built from the same chart
+	TagSuffix string `json:"tagSuffix"`
 }
 
 // Provider - helm provider, responsible for managing release updates
 type Provider struct {
+	// MaxParallelUpdates - how many releases are upgraded at the same
+	// time, defaults to 1 (releases are upgraded one by one)
+	MaxParallelUpdates int
+
+	// OCICharts - optional, pulls charts of releases that reference
+	// charts in OCI registries with chartRef
+	OCICharts ChartRepository
+
 	implementer Implementer
 
 	sender notification.Sender
 
 	approvalManager approvals.Manager
 
-	events chan *types.Event
+	// optional, resolves image pull secrets so polling can
+	// authenticate to private registries
+	secretResolver SecretResolver
+
+	// optional, reads release notes from image labels
+	labelsGetter LabelsGetter
+
+	// optional, fetches charts for releases that update
+	// chart version together with image tags
+	chartRepository ChartRepository
+
+	// optional, releases outside allowed namespaces are ignored
+	namespaces *NamespaceFilter
+
+	// values and bow configuration parsed from releases, reused
+	// between TrackedImages and createUpdatePlans calls
+	configs *configCache
+
+	// configuration errors that users were already notified about,
+	// map[namespace/release]error
+	configErrors   map[string]string
+	configErrorsMu sync.Mutex
+
+	// events waiting for update windows to open
+	deferred *pending.Events
+
+	// recently processed events, duplicates are skipped
+	recent *dedup.Window
+
+	// breaker - suppresses events for images that keep failing
+	breaker *circuit.Breaker
+
+	// optional, events are added once their circuit opens
+	deadLetters *deadletter.Queue
+
+	// readiness, set atomically once event loop started and
+	// releases were listed for the first time
+	started int32
+	listed  int32
+
+	// event loop, Stop waits for the event that is being
+	// processed and its release upgrades
+	running sync.WaitGroup
+
+	events chan *queuedEvent
 	stop   chan struct{}
 }
 
-// NewProvider - create new Helm provider
-func NewProvider(implementer Implementer, sender notification.Sender, approvalManager approvals.Manager) *Provider {
-	return &Provider{
+// NewProvider - create new Helm provider, secret resolver can be nil if image
+// pull secrets shouldn't be resolved, labels getter can be nil if release notes
+// shouldn't be read from image labels, chart repository can be nil if chart
+// versions aren't updated, namespace filter can be nil to track all namespaces
+func NewProvider(implementer Implementer, sender notification.Sender, approvalManager approvals.Manager, secretResolver SecretResolver, labelsGetter LabelsGetter, chartRepository ChartRepository, namespaces *NamespaceFilter) *Provider {
+	p := &Provider{
 		implementer:     implementer,
 		approvalManager: approvalManager,
 		sender:          sender,
-		events:          make(chan *types.Event, 100),
+		secretResolver:  secretResolver,
+		labelsGetter:    labelsGetter,
+		chartRepository: chartRepository,
+		namespaces:      namespaces,
+		configs:         newConfigCache(DefaultConfigCacheTTL),
+		configErrors:    make(map[string]string),
+		breaker:         circuit.New(circuit.DefaultOpts),
+		recent:          dedup.New(EventDedupWindow),
+		events:          make(chan *queuedEvent, 100),
 		stop:            make(chan struct{}),
 	}
+	p.deferred = pending.New(p.requeue)
+	return p
 }
 
 // GetName - get provider name
@@ -151,9 +318,26 @@ func (p *Provider) GetName() string {
 	return ProviderName
 }
 
-// Submit - submit event to provider
+// queuedEvent - event waiting for the event loop, context carries its trace ID
+type queuedEvent struct {
+	ctx   context.Context
+	event *types.Event
+	// replay - deferred event or dead letter retry, these are never
+	// dropped as duplicates
+	replay bool
+}
+
+// Submit - submit event to provider, event gets a trace ID that is
+// logged by every processing step
 func (p *Provider) Submit(event types.Event) error {
-	p.events <- &event
+	ctx := trace.NewContext(context.Background())
+	trace.Log(ctx).WithFields(log.Fields{
+		"image":   event.Repository.Name,
+		"tag":     event.Repository.Tag,
+		"trigger": event.TriggerName,
+	}).Debug("provider.helm: event received")
+
+	p.events <- &queuedEvent{ctx: ctx, event: &event}
 	return nil
 }
 
@@ -162,65 +346,168 @@ func (p *Provider) Start() error {
 	return p.startInternal()
 }
 
-// Stop - stops kubernetes provider
+// Stop - stops helm provider, waits up to StopTimeout for the event that is
+// being processed so releases aren't left half-updated
 func (p *Provider) Stop() {
+	p.deferred.Stop()
 	close(p.stop)
+	if !stopper.WaitTimeout(&p.running, StopTimeout) {
+		log.WithFields(log.Fields{
+			"timeout": StopTimeout,
+		}).Warn("provider.helm: release upgrades didn't finish in time, stopping anyway")
+	}
+}
+
+// Ready - provider is ready once its event loop started and releases were
+// listed at least once, releases are listed here until that succeeds
+func (p *Provider) Ready() error {
+	if atomic.LoadInt32(&p.started) == 0 {
+		return errors.New("event loop not started")
+	}
+	if atomic.LoadInt32(&p.listed) == 0 {
+		_, err := p.listReleases()
+		if err != nil {
+			return fmt.Errorf("failed to list releases: %s", err)
+		}
+	}
+	return nil
+}
+
+func (p *Provider) listReleases() (*rls.ListReleasesResponse, error) {
+	releaseList, err := p.implementer.ListReleases()
+	if err != nil {
+		return nil, err
+	}
+	atomic.StoreInt32(&p.listed, 1)
+	return p.namespaces.filter(releaseList), nil
+}
+
+// notifyConfigError - sends a warning once per release and error, TrackedImages
+// is called on every poll manager scan so repeated notifications are suppressed
+func (p *Provider) notifyConfigError(namespace, name string, configErr error) {
+	p.notifyConfig(namespace, name, "invalid bow configuration",
+		fmt.Sprintf("Bow configuration for release %s/%s is ignored: %s", namespace, name, configErr))
+}
+
+// notifyUnknownFields - sends a warning once per release about configuration
+// fields that bow doesn't know, they are most likely misspelled
+func (p *Provider) notifyUnknownFields(namespace, name string, fields []string) {
+	p.notifyConfig(namespace, name, "unknown bow configuration fields",
+		fmt.Sprintf("Bow configuration for release %s/%s has unknown fields, they are ignored: %s", namespace, name, strings.Join(fields, ", ")))
+}
+
+func (p *Provider) notifyConfig(namespace, name, title, message string) {
+	key := namespace + "/" + name
+
+	p.configErrorsMu.Lock()
+	notified, ok := p.configErrors[key]
+	p.configErrors[key] = message
+	p.configErrorsMu.Unlock()
+
+	if ok && notified == message {
+		return
+	}
+
+	p.sender.Send(types.EventNotification{
+		ResourceKind: "chart",
+		Identifier:   fmt.Sprintf("%s/%s/%s", "chart", namespace, name),
+		Name:         title,
+		Message:      message,
+		CreatedAt:    time.Now(),
+		Type:         types.NotificationSystemEvent,
+		Level:        types.LevelWarn,
+		Metadata: map[string]string{
+			"provider":  p.GetName(),
+			"namespace": namespace,
+			"name":      name,
+		},
+	})
+}
+
+// configValid - forgets previously reported errors so that the release
+// gets notified again if it breaks later
+func (p *Provider) configValid(namespace, name string) {
+	p.configErrorsMu.Lock()
+	delete(p.configErrors, namespace+"/"+name)
+	p.configErrorsMu.Unlock()
 }
 
 // TrackedImages - returns tracked images from all releases that have bow configuration
 func (p *Provider) TrackedImages() ([]*types.TrackedImage, error) {
 	var trackedImages []*types.TrackedImage
 
-	releaseList, err := p.implementer.ListReleases()
+	releaseList, err := p.listReleases()
 	if err != nil {
 		return nil, err
 	}
 
 	for _, release := range releaseList.Releases {
 		// getting configuration
-		vals, err := values(release.Chart, release.Config)
-		if err != nil {
+		parsed := p.configs.get(release)
+		if parsed.valsErr != nil {
 			log.WithFields(log.Fields{
-				"error":     err,
+				"error":     parsed.valsErr,
 				"release":   release.Name,
 				"namespace": release.Namespace,
 			}).Error("provider.helm: failed to get values.yaml for release")
 			continue
 		}
 
-		cfg, err := getbowConfig(vals)
+		err = parsed.cfgErr
 		if err != nil {
+			if err == ErrPolicyNotSpecified {
+				log.WithFields(log.Fields{
+					"release":   release.Name,
+					"namespace": release.Namespace,
+				}).Debug("provider.helm: policy not specified for release")
+				continue
+			}
 			log.WithFields(log.Fields{
 				"error":     err,
 				"release":   release.Name,
 				"namespace": release.Namespace,
-			}).Debug("provider.helm: failed to get config for release")
+			}).Error("provider.helm: failed to get config for release")
+			p.notifyConfigError(release.Namespace, release.Name, err)
 			continue
 		}
 
-		if cfg.PollSchedule == "" {
-			cfg.PollSchedule = types.BowPollDefaultSchedule
-		}
 		// used to check pod secrets
 		selector := fmt.Sprintf("app=%s,release=%s", release.Chart.Metadata.Name, release.Name)
 
-		releaseImages, err := getImages(vals)
+		releaseImages, err := parsed.images, parsed.imagesErr
 		if err != nil {
 			log.WithFields(log.Fields{
 				"error":     err,
 				"release":   release.Name,
 				"namespace": release.Namespace,
 			}).Error("provider.helm: failed to get images for release")
+			p.notifyConfigError(release.Namespace, release.Name, err)
 			continue
 		}
 
-		for _, img := range releaseImages {
+		if unknown := parsed.cfg.unknownFields; len(unknown) > 0 {
+			log.WithFields(log.Fields{
+				"fields":    strings.Join(unknown, ", "),
+				"release":   release.Name,
+				"namespace": release.Namespace,
+			}).Warn("provider.helm: unknown fields in release config")
+			p.notifyUnknownFields(release.Namespace, release.Name, unknown)
+		} else {
+			p.configValid(release.Namespace, release.Name)
+		}
+
+		for _, cached := range releaseImages {
+			// cached images are shared with later calls
+			img := *cached
 			img.Meta = map[string]string{
 				"selector":      selector,
 				"helm.sh/chart": fmt.Sprintf("%s-%s", release.Chart.Metadata.Name, release.Chart.Metadata.Version),
+				circuit.MetaKey: p.breaker.State(img.Image.Repository()).String(),
 			}
 			img.Provider = ProviderName
-			trackedImages = append(trackedImages, img)
+			img.Namespace = release.Namespace
+			p.resolveCredentials(&img)
+			trackedImages = append(trackedImages, &img)
 		}
 
 	}
@@ -229,17 +516,26 @@ func (p *Provider) TrackedImages() ([]*types.TrackedImage, error) {
 }
 
 func (p *Provider) startInternal() error {
+	p.running.Add(1)
+	defer p.running.Done()
+
+	atomic.StoreInt32(&p.started, 1)
 	for {
 		select {
-		case event := <-p.events:
-			err := p.processEvent(event)
-			if err != nil {
-				log.WithFields(log.Fields{
-					"error": err,
-					"image": event.Repository.Name,
-					"tag":   event.Repository.Tag,
-				}).Error("provider.helm: failed to process event")
+		case queued := <-p.events:
+			if p.stopping() {
+				log.Info("provider.helm: got shutdown signal, stopping...")
+				return nil
+			}
+			// approved and replayed events are always applied
+			if !queued.replay && queued.event.TriggerName != types.TriggerTypeApproval.String() && p.recent.Seen(dedup.Key(&queued.event.Repository), timeutil.Now()) {
+				trace.Log(queued.ctx).WithFields(log.Fields{
+					"image": queued.event.Repository.Name,
+					"tag":   queued.event.Repository.Tag,
+				}).Debug("provider.helm: same event was just processed, skipping")
+				continue
 			}
+			p.handleEvent(queued.ctx, queued.event)
 		case <-p.stop:
 			log.Info("provider.helm: got shutdown signal, stopping...")
 			return nil
@@ -247,21 +543,26 @@ func (p *Provider) startInternal() error {
 	}
 }
 
-func (p *Provider) processEvent(event *types.Event) (err error) {
-	plans, err := p.createUpdatePlans(event)
+func (p *Provider) processEvent(ctx context.Context, event *types.Event) (err error) {
+	plans, err := p.createUpdatePlans(ctx, event)
 	if err != nil {
 		return err
 	}
 
-	approved := p.checkForApprovals(event, plans)
+	approved := p.checkForApprovals(ctx, event, plans)
 
-	return p.applyPlans(approved)
+	ready, next := checkUpdateWindows(approved, timeutil.Now())
+	if !next.IsZero() {
+		p.deferred.Add(event, next)
+	}
+
+	return p.applyPlans(ctx, ready)
 }
 
-func (p *Provider) createUpdatePlans(event *types.Event) ([]*UpdatePlan, error) {
+func (p *Provider) createUpdatePlans(ctx context.Context, event *types.Event) ([]*UpdatePlan, error) {
 	var plans []*UpdatePlan
 
-	releaseList, err := p.implementer.ListReleases()
+	releaseList, err := p.listReleases()
 	if err != nil {
 		return nil, err
 	}
@@ -269,9 +570,9 @@ func (p *Provider) createUpdatePlans(event *types.Event) ([]*UpdatePlan, error)
 	for _, release := range releaseList.Releases {
 
 		// plan, update, err := checkRelease(newVersion, &event.Repository, release.Namespace, release.Name, release.Chart, release.Config)
-		plan, update, err := checkRelease(&event.Repository, release.Namespace, release.Name, release.Chart, release.Config)
+		plan, update, err := checkParsedRelease(ctx, &event.Repository, release.Namespace, release.Name, release.Chart, p.configs.get(release))
 		if err != nil {
-			log.WithFields(log.Fields{
+			trace.Log(ctx).WithFields(log.Fields{
 				"error":     err,
 				"name":      release.Name,
 				"namespace": release.Namespace,
@@ -279,7 +580,19 @@ func (p *Provider) createUpdatePlans(event *types.Event) ([]*UpdatePlan, error)
 			continue
 		}
 		if update {
-			helmVersionedUpdatesCounter.With(prometheus.Labels{"chart": fmt.Sprintf("%s/%s", release.Namespace, release.Name)}).Inc()
+			err = p.updateChartVersion(plan)
+			if err != nil {
+				trace.Log(ctx).WithFields(log.Fields{
+					"error":            err,
+					"name":             release.Name,
+					"namespace":        release.Namespace,
+					"chartVersionPath": plan.Config.ChartVersionPath,
+					"chartRef":         plan.Config.ChartRef,
+					"version":          plan.NewVersion,
+				}).Error("provider.helm: failed to get chart version for release, skipping update")
+				continue
+			}
+			p.addLabelReleaseNotes(plan)
 			plans = append(plans, plan)
 		}
 	}
@@ -287,9 +600,137 @@ func (p *Provider) createUpdatePlans(event *types.Event) ([]*UpdatePlan, error)
 	return plans, nil
 }
 
-func (p *Provider) applyPlans(plans []*UpdatePlan) error {
+// updateChartVersion - replaces plan chart with the chart version equal to the new
+// tag for releases that set chartVersionPath or chartRef
+func (p *Provider) updateChartVersion(plan *UpdatePlan) error {
+	if plan.Config == nil {
+		return nil
+	}
+
+	if plan.Config.ChartRef != "" {
+		if p.OCICharts == nil {
+			return fmt.Errorf("OCI chart repository is not configured")
+		}
+		chart, err := p.OCICharts.Get(plan.Config.ChartRef, plan.NewVersion)
+		if err != nil {
+			return err
+		}
+		plan.Chart = chart
+		return nil
+	}
+
+	if plan.Config.ChartVersionPath == "" {
+		return nil
+	}
+	if p.chartRepository == nil {
+		return fmt.Errorf("chart repository is not configured")
+	}
+
+	chart, err := p.chartRepository.Get(plan.Config.ChartVersionPath, plan.NewVersion)
+	if err != nil {
+		return err
+	}
+	plan.Chart = chart
+	return nil
+}
+
+// mergePlans - coalesces plans for the same release so it's upgraded once
+// with all value changes, plans keep the order of their first occurrence
+func mergePlans(ctx context.Context, plans []*UpdatePlan) []*UpdatePlan {
+	var merged []*UpdatePlan
+	byRelease := make(map[string]*UpdatePlan)
+
 	for _, plan := range plans {
+		key := plan.Namespace + "/" + plan.Name
+		existing, ok := byRelease[key]
+		if !ok {
+			byRelease[key] = plan
+			merged = append(merged, plan)
+			continue
+		}
+
+		if existing.Values == nil {
+			existing.Values = make(map[string]string)
+		}
+		for k, v := range plan.Values {
+			existing.Values[k] = v
+		}
+		if len(plan.CurrentValues) > 0 && existing.CurrentValues == nil {
+			existing.CurrentValues = make(map[string]string)
+		}
+		for k, v := range plan.CurrentValues {
+			if _, ok := existing.CurrentValues[k]; !ok {
+				existing.CurrentValues[k] = v
+			}
+		}
+		for _, notes := range plan.ReleaseNotes {
+			if !contains(existing.ReleaseNotes, notes) {
+				existing.ReleaseNotes = append(existing.ReleaseNotes, notes)
+			}
+		}
+
+		trace.Log(ctx).WithFields(log.Fields{
+			"name":      plan.Name,
+			"namespace": plan.Namespace,
+		}).Debug("provider.helm: merged update plans for release")
+	}
+
+	return merged
+}
+
+// applyPlans - upgrades releases, up to MaxParallelUpdates releases are
+// upgraded at the same time. Plans for the same release are merged first,
+// so a release is never upgraded by two workers at once. Once the provider
+// is stopping running upgrades finish and the rest is skipped
+func (p *Provider) applyPlans(ctx context.Context, plans []*UpdatePlan) error {
+	sem := make(chan struct{}, p.maxParallelUpdates())
+	var wg sync.WaitGroup
+
+	for _, plan := range mergePlans(ctx, plans) {
+		sem <- struct{}{}
+		if p.stopping() {
+			<-sem
+			trace.Log(ctx).WithFields(log.Fields{
+				"name":      plan.Name,
+				"namespace": plan.Namespace,
+			}).Info("provider.helm: provider is stopping, skipping release upgrade")
+			continue
+		}
+		wg.Add(1)
+		go func(plan *UpdatePlan) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			p.applyPlan(ctx, plan)
+		}(plan)
+	}
+
+	wg.Wait()
+	return nil
+}
 
+// stopping - whether Stop was called
+func (p *Provider) stopping() bool {
+	select {
+	case <-p.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Provider) maxParallelUpdates() int {
+	if p.MaxParallelUpdates < 1 {
+		return 1
+	}
+	return p.MaxParallelUpdates
+}
+
+func (p *Provider) applyPlan(ctx context.Context, plan *UpdatePlan) {
+	notify := plan.Config.notify(plan)
+
+	if notify {
 		p.sender.Send(types.EventNotification{
 			ResourceKind: "chart",
 			Identifier:   fmt.Sprintf("%s/%s/%s", "chart", plan.Namespace, plan.Name),
@@ -300,47 +741,60 @@ func (p *Provider) applyPlans(plans []*UpdatePlan) error {
 			Level:        types.LevelDebug,
 			Channels:     plan.Config.NotificationChannels,
 			Metadata: map[string]string{
-				"provider":  p.GetName(),
-				"namespace": plan.Namespace,
-				"name":      plan.Name,
+				"provider":                 p.GetName(),
+				"namespace":                plan.Namespace,
+				"name":                     plan.Name,
+				"current_version":          plan.CurrentVersion,
+				"new_version":              plan.NewVersion,
+				types.ReleaseNotesMetadata: types.EncodeReleaseNotes(plan.ReleaseNotes),
+				"policy":                   plan.Config.Policy,
 			},
 		})
+	}
 
-		err := updateHelmRelease(p.implementer, plan.Name, plan.Chart, plan.Values)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"error":     err,
-				"name":      plan.Name,
-				"namespace": plan.Namespace,
-			}).Error("provider.helm: failed to apply plan")
-
-			p.sender.Send(types.EventNotification{
-				ResourceKind: "chart",
-				Identifier:   fmt.Sprintf("%s/%s/%s", "chart", plan.Namespace, plan.Name),
-				Name:         "update release",
-				Message:      fmt.Sprintf("Release update failed %s/%s %s->%s (%s), error: %s", plan.Namespace, plan.Name, plan.CurrentVersion, plan.NewVersion, strings.Join(mapToSlice(plan.Values), ", "), err),
-				CreatedAt:    time.Now(),
-				Type:         types.NotificationReleaseUpdate,
-				Level:        types.LevelError,
-				Channels:     plan.Config.NotificationChannels,
-				Metadata: map[string]string{
-					"provider":  p.GetName(),
-					"namespace": plan.Namespace,
-					"name":      plan.Name,
-				},
-			})
-			continue
-		}
+	err := updateHelmRelease(ctx, p.implementer, plan.Name, plan.Chart, plan.Values)
+	if err != nil {
+		trace.Log(ctx).WithFields(log.Fields{
+			"error":     err,
+			"name":      plan.Name,
+			"namespace": plan.Namespace,
+		}).Error("provider.helm: failed to apply plan")
 
-		err = p.updateComplete(plan)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"error":     err,
-				"name":      plan.Name,
-				"namespace": plan.Namespace,
-			}).Warn("provider.helm: got error while resetting approvals counter after successful update")
-		}
+		p.sender.Send(types.EventNotification{
+			ResourceKind: "chart",
+			Identifier:   fmt.Sprintf("%s/%s/%s", "chart", plan.Namespace, plan.Name),
+			Name:         "update release",
+			Message:      fmt.Sprintf("Release update failed %s/%s %s->%s (%s), error: %s", plan.Namespace, plan.Name, plan.CurrentVersion, plan.NewVersion, strings.Join(mapToSlice(plan.Values), ", "), err),
+			CreatedAt:    time.Now(),
+			Type:         types.NotificationReleaseUpdate,
+			Level:        types.LevelError,
+			Channels:     plan.Config.NotificationChannels,
+			Metadata: map[string]string{
+				"provider":        p.GetName(),
+				"namespace":       plan.Namespace,
+				"name":            plan.Name,
+				"current_version": plan.CurrentVersion,
+				"new_version":     plan.NewVersion,
+				"policy":          plan.Config.Policy,
+			},
+		})
+		return
+	}
+
+	// counted once the release is upgraded, plans can still be rejected,
+	// deferred or merged with other plans for the release
+	helmVersionedUpdatesCounter.Inc(fmt.Sprintf("%s/%s", plan.Namespace, plan.Name))
 
+	err = p.updateComplete(plan)
+	if err != nil {
+		trace.Log(ctx).WithFields(log.Fields{
+			"error":     err,
+			"name":      plan.Name,
+			"namespace": plan.Namespace,
+		}).Warn("provider.helm: got error while resetting approvals counter after successful update")
+	}
+
+	if notify {
 		var msg string
 		if len(plan.ReleaseNotes) == 0 {
 			msg = fmt.Sprintf("Successfully updated release %s/%s %s->%s (%s)", plan.Namespace, plan.Name, plan.CurrentVersion, plan.NewVersion, strings.Join(mapToSlice(plan.Values), ", "))
@@ -358,24 +812,26 @@ func (p *Provider) applyPlans(plans []*UpdatePlan) error {
 			Level:        types.LevelSuccess,
 			Channels:     plan.Config.NotificationChannels,
 			Metadata: map[string]string{
-				"provider":  p.GetName(),
-				"namespace": plan.Namespace,
-				"name":      plan.Name,
+				"provider":                 p.GetName(),
+				"namespace":                plan.Namespace,
+				"name":                     plan.Name,
+				"current_version":          plan.CurrentVersion,
+				"new_version":              plan.NewVersion,
+				types.ReleaseNotesMetadata: types.EncodeReleaseNotes(plan.ReleaseNotes),
+				"policy":                   plan.Config.Policy,
 			},
 		})
-
 	}
-
-	return nil
 }
 
-func updateHelmRelease(implementer Implementer, releaseName string, chart *hapi_chart.Chart, overrideValues map[string]string) error {
+func updateHelmRelease(ctx context.Context, implementer Implementer, releaseName string, chart *hapi_chart.Chart, overrideValues map[string]string) error {
 
 	overrideBts, err := convertToYaml(mapToSlice(overrideValues))
 	if err != nil {
 		return err
 	}
 
+	start := time.Now()
 	resp, err := implementer.UpdateReleaseFromChart(releaseName, chart,
 		helm.UpdateValueOverrides(overrideBts),
 		helm.UpgradeDryRun(false),
@@ -386,12 +842,13 @@ func updateHelmRelease(implementer Implementer, releaseName string, chart *hapi_
 		helm.ResetValues(false),
 		helm.ReuseValues(true),
 		helm.UpgradeWait(true))
+	helmUpdateDuration.WithLabelValues(chart.GetMetadata().GetName()).Observe(time.Since(start).Seconds())
 
 	if err != nil {
 		return err
 	}
 
-	log.WithFields(log.Fields{
+	trace.Log(ctx).WithFields(log.Fields{
 		"version": resp.Release.Version,
 		"release": releaseName,
 	}).Info("provider.helm: release updated")
@@ -407,6 +864,15 @@ func mapToSlice(values map[string]string) []string {
 	return converted
 }
 
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // parse
 func convertToYaml(values []string) ([]byte, error) {
 	base := map[string]interface{}{}
@@ -433,7 +899,68 @@ func getValueAsString(vals chartutil.Values, path string) (string, error) {
 }
 
 func values(chart *hapi_chart.Chart, config *hapi_chart.Config) (chartutil.Values, error) {
-	return chartutil.CoalesceValues(chart, config)
+	return chartutil.CoalesceValues(aliasedChart(chart), config)
+}
+
+// aliasedChart - copy of the chart with dependencies renamed to their requirements.yaml
+// aliases, as Tiller does before rendering, so values of aliased subcharts (ie: worker.image.tag)
+// get subchart defaults. Release chart itself is passed to upgrades as is
+func aliasedChart(chart *hapi_chart.Chart) *hapi_chart.Chart {
+	if len(chart.GetDependencies()) == 0 {
+		return chart
+	}
+
+	// missing requirements are fine, subcharts can still have aliased dependencies
+	reqs, err := chartutil.LoadRequirements(chart)
+	if err != nil {
+		reqs = &chartutil.Requirements{}
+	}
+
+	var dependencies []*hapi_chart.Chart
+	for _, dep := range chart.Dependencies {
+		if dep.GetMetadata() == nil || requirementFor(reqs, dep) != nil {
+			continue
+		}
+		dependencies = append(dependencies, aliasedChart(dep))
+	}
+
+	// same subchart can be required multiple times with different aliases
+	for _, req := range reqs.Dependencies {
+		for _, dep := range chart.Dependencies {
+			if dep.GetMetadata() == nil || !requirementMatches(req, dep) {
+				continue
+			}
+			aliased := *aliasedChart(dep)
+			metadata := *dep.Metadata
+			if req.Alias != "" {
+				metadata.Name = req.Alias
+			}
+			aliased.Metadata = &metadata
+			dependencies = append(dependencies, &aliased)
+			break
+		}
+	}
+
+	copied := *chart
+	copied.Dependencies = dependencies
+	return &copied
+}
+
+func requirementFor(reqs *chartutil.Requirements, dep *hapi_chart.Chart) *chartutil.Dependency {
+	for _, req := range reqs.Dependencies {
+		if requirementMatches(req, dep) {
+			return req
+		}
+	}
+	return nil
+}
+
+// requirementMatches - requirements without version match any version
+func requirementMatches(req *chartutil.Dependency, dep *hapi_chart.Chart) bool {
+	if req.Name != dep.Metadata.Name {
+		return false
+	}
+	return req.Version == "" || version.IsCompatibleRange(req.Version, dep.Metadata.Version)
 }
 
 func getbowConfig(vals chartutil.Values) (*bowChartConfig, error) {
@@ -442,19 +969,54 @@ func getbowConfig(vals chartutil.Values) (*bowChartConfig, error) {
 		return nil, fmt.Errorf("failed to get vals config, error: %s", err)
 	}
 
+	// type mismatches would fail decoding with less helpful errors,
+	// unknown fields are kept for a warning
+	schemaErr := validateBowConfig(vals["bow"])
+	if schemaErr != nil && len(schemaErr.Invalid) > 0 {
+		return nil, schemaErr
+	}
+
 	var r Root
 	err = yaml.Unmarshal([]byte(yamlFull), &r)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse bow config: %s", err)
 	}
 
-	if r.bow.Policy == "" {
+	if r.Bow.Policy == "" {
+		if schemaErr != nil {
+			// policy is most likely misspelled
+			return nil, fmt.Errorf("%s, %s", ErrPolicyNotSpecified, schemaErr)
+		}
 		return nil, ErrPolicyNotSpecified
 	}
 
-	cfg := r.bow
+	cfg := r.Bow
+	if schemaErr != nil {
+		cfg.unknownFields = schemaErr.Unknown
+	}
+
+	cfg.Plc, err = policy.GetPolicy(cfg.Policy, &policy.Options{MatchTag: cfg.MatchTag, IncludeBuildMeta: cfg.IncludeBuildMeta, PreReleaseChannel: cfg.PreReleaseChannel})
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ChartRef != "" {
+		if !strings.HasPrefix(cfg.ChartRef, OCIChartScheme) {
+			return nil, fmt.Errorf("chartRef %s has to start with %s", cfg.ChartRef, OCIChartScheme)
+		}
+		if cfg.ChartVersionPath != "" {
+			return nil, fmt.Errorf("chartRef and chartVersionPath can't be used together")
+		}
+	}
+
+	if cfg.UpdateWindow != "" {
+		_, err = timeutil.ParseUpdateWindow(cfg.UpdateWindow)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	cfg.Plc = policy.GetPolicy(cfg.Policy, &policy.Options{MatchTag: cfg.MatchTag})
+	cfg.Images = splitTagPaths(cfg.Images)
 
 	return &cfg, nil
 }
@@ -0,0 +1,145 @@
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	core_v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	hapi_release5 "k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// helm3ReleaseSelector - Helm 3 stores every release revision in a secret
+// labelled with its owner and status
+const helm3ReleaseSelector = "owner=helm,status=deployed"
+
+// ErrHelm3UpgradeNotSupported - Helm 3 upgrades render and apply manifests client side,
+// this needs the Helm 3 SDK which can't be vendored next to the Helm 2 client
+var ErrHelm3UpgradeNotSupported = errors.New("helm 3 release upgrades are not supported yet")
+
+// Helm3Implementer - reads Helm 3 releases from their storage secrets, no Tiller
+// is needed for tracking images in Helm 3 releases
+type Helm3Implementer struct {
+	client    core_v1.SecretsGetter
+	namespace string
+}
+
+// NewHelm3Implementer - create new Helm 3 implementer, releases from all namespaces
+// are listed when namespace is empty
+func NewHelm3Implementer(client core_v1.SecretsGetter, namespace string) *Helm3Implementer {
+	return &Helm3Implementer{
+		client:    client,
+		namespace: namespace,
+	}
+}
+
+// helm3Release - fields of the Helm 3 release that bow uses
+type helm3Release struct {
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace"`
+	Version   int32                  `json:"version"`
+	Config    map[string]interface{} `json:"config"`
+	Chart     struct {
+		Metadata *chart.Metadata        `json:"metadata"`
+		Values   map[string]interface{} `json:"values"`
+	} `json:"chart"`
+}
+
+// ListReleases - lists deployed releases, list options are ignored as
+// deployed revisions are the only ones bow updates
+func (i *Helm3Implementer) ListReleases(opts ...helm.ReleaseListOption) (*rls.ListReleasesResponse, error) {
+	secrets, err := i.client.Secrets(i.namespace).List(meta_v1.ListOptions{LabelSelector: helm3ReleaseSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &rls.ListReleasesResponse{}
+	for _, secret := range secrets.Items {
+		release, err := decodeHelm3Release(secret.Data["release"])
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":     err,
+				"secret":    secret.Name,
+				"namespace": secret.Namespace,
+			}).Error("provider.helm: failed to decode helm 3 release")
+			continue
+		}
+		resp.Releases = append(resp.Releases, release)
+	}
+	resp.Count = int64(len(resp.Releases))
+	resp.Total = resp.Count
+
+	return resp, nil
+}
+
+// UpdateReleaseFromChart - not supported yet, see ErrHelm3UpgradeNotSupported
+func (i *Helm3Implementer) UpdateReleaseFromChart(rlsName string, chart *chart.Chart, opts ...helm.UpdateOption) (*rls.UpdateReleaseResponse, error) {
+	return nil, ErrHelm3UpgradeNotSupported
+}
+
+// decodeHelm3Release - decodes base64 encoded, gzipped JSON release
+// as stored by Helm 3 into the Helm 2 release type
+func decodeHelm3Release(data []byte) (*hapi_release5.Release, error) {
+	b, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// releases are gzipped unless they were written by early Helm 3 betas
+	if len(b) > 2 && b[0] == 0x1f && b[1] == 0x8b {
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		b, err = ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var release helm3Release
+	err = json.Unmarshal(b, &release)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := rawValues(release.Chart.Values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode chart values: %s", err)
+	}
+	config, err := rawValues(release.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode release config: %s", err)
+	}
+
+	return &hapi_release5.Release{
+		Name:      release.Name,
+		Namespace: release.Namespace,
+		Version:   release.Version,
+		Chart: &chart.Chart{
+			Metadata: release.Chart.Metadata,
+			Values:   &chart.Config{Raw: values},
+		},
+		Config: &chart.Config{Raw: config},
+	}, nil
+}
+
+func rawValues(values map[string]interface{}) (string, error) {
+	if len(values) == 0 {
+		return "", nil
+	}
+	b, err := yaml.Marshal(values)
+	return string(b), err
+}
@@ -0,0 +1,99 @@
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *fakeSecrets) List(opts meta_v1.ListOptions) (*v1.SecretList, error) {
+	list := &v1.SecretList{}
+	for _, secret := range s.secrets {
+		list.Items = append(list.Items, *secret)
+	}
+	return list, nil
+}
+
+func helm3Secret(release string) *v1.Secret {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(release))
+	w.Close()
+
+	return &v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "sh.helm.release.v1.wd.v2",
+			Namespace: "default",
+			Labels:    map[string]string{"owner": "helm", "status": "deployed"},
+		},
+		Data: map[string][]byte{
+			"release": []byte(base64.StdEncoding.EncodeToString(buf.Bytes())),
+		},
+	}
+}
+
+func TestHelm3ImplementerListReleases(t *testing.T) {
+	release := `{
+  "name": "wd",
+  "namespace": "default",
+  "version": 2,
+  "config": {"image": {"tag": "0.0.10"}},
+  "chart": {
+    "metadata": {"name": "webhook-demo", "version": "0.1.0"},
+    "values": {
+      "image": {"repository": "karolisr/webhook-demo", "tag": "0.0.9"},
+      "bow": {"policy": "all", "images": [{"repository": "image.repository", "tag": "image.tag"}]}
+    }
+  }
+}`
+
+	implementer := NewHelm3Implementer(&fakeSecretsGetter{
+		namespaces: map[string]*fakeSecrets{
+			"": {secrets: map[string]*v1.Secret{
+				"wd":     helm3Secret(release),
+				"broken": {Data: map[string][]byte{"release": []byte("not base64")}},
+			}},
+		},
+	}, "")
+
+	resp, err := implementer.ListReleases()
+	if err != nil {
+		t.Fatalf("failed to list releases: %s", err)
+	}
+	if len(resp.Releases) != 1 {
+		t.Fatalf("expected 1 release, got: %d", len(resp.Releases))
+	}
+
+	rel := resp.Releases[0]
+	if rel.Name != "wd" || rel.Namespace != "default" || rel.Version != 2 {
+		t.Errorf("unexpected release: %s/%s v%d", rel.Namespace, rel.Name, rel.Version)
+	}
+	if rel.Chart.GetMetadata().GetName() != "webhook-demo" {
+		t.Errorf("unexpected chart: %s", rel.Chart.GetMetadata().GetName())
+	}
+
+	vals, err := values(rel.Chart, rel.Config)
+	if err != nil {
+		t.Fatalf("failed to get values: %s", err)
+	}
+	tag, err := getValueAsString(vals, "image.tag")
+	if err != nil || tag != "0.0.10" {
+		t.Errorf("expected release config to override chart values, got: %s (%v)", tag, err)
+	}
+	cfg, err := getbowConfig(vals)
+	if err != nil {
+		t.Fatalf("failed to get bow config: %s", err)
+	}
+	if cfg.Policy != "all" || len(cfg.Images) != 1 {
+		t.Errorf("unexpected bow config: %+v", cfg)
+	}
+
+	_, err = implementer.UpdateReleaseFromChart("wd", rel.Chart)
+	if err != ErrHelm3UpgradeNotSupported {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
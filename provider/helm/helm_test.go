@@ -1,15 +1,23 @@
 package helm
 
 import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/alwinius/bow/approvals"
-	"github.com/alwinius/bow/cache/memory"
 	"github.com/alwinius/bow/extension/notification"
 	"github.com/alwinius/bow/internal/policy"
+	"github.com/alwinius/bow/pkg/store/sql"
 	"github.com/alwinius/bow/types"
 	"github.com/ghodss/yaml"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"k8s.io/helm/pkg/chartutil"
 	"k8s.io/helm/pkg/helm"
 	"k8s.io/helm/pkg/proto/hapi/chart"
@@ -18,13 +26,25 @@ import (
 )
 
 func approver() *approvals.DefaultManager {
-	cache := memory.NewMemoryCache()
+	dir, err := ioutil.TempDir("", "helmstoretest")
+	if err != nil {
+		panic(err)
+	}
+
+	store, err := sql.New(sql.Opts{DatabaseType: "sqlite3", URI: filepath.Join(dir, "gorm.db")})
+	if err != nil {
+		panic(err)
+	}
 
-	return approvals.New(cache)
+	return approvals.New(&approvals.Opts{
+		Store: store,
+	})
 }
 
 type fakeSender struct {
-	sentEvent types.EventNotification
+	mu         sync.Mutex
+	sentEvent  types.EventNotification
+	sentEvents []types.EventNotification
 }
 
 func (s *fakeSender) Configure(cfg *notification.Config) (bool, error) {
@@ -32,7 +52,10 @@ func (s *fakeSender) Configure(cfg *notification.Config) (bool, error) {
 }
 
 func (s *fakeSender) Send(event types.EventNotification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.sentEvent = event
+	s.sentEvents = append(s.sentEvents, event)
 	return nil
 }
 
@@ -43,6 +66,8 @@ type fakeImplementer struct {
 	updatedRlsName string
 	updatedChart   *chart.Chart
 	updatedOptions []helm.UpdateOption
+	updateErr      error
+	updates        int
 }
 
 func (i *fakeImplementer) ListReleases(opts ...helm.ReleaseListOption) (*rls.ListReleasesResponse, error) {
@@ -53,6 +78,11 @@ func (i *fakeImplementer) UpdateReleaseFromChart(rlsName string, chart *chart.Ch
 	i.updatedRlsName = rlsName
 	i.updatedChart = chart
 	i.updatedOptions = opts
+	i.updates++
+
+	if i.updateErr != nil {
+		return nil, i.updateErr
+	}
 
 	return &rls.UpdateReleaseResponse{
 		Release: &hapi_release5.Release{
@@ -63,7 +93,7 @@ func (i *fakeImplementer) UpdateReleaseFromChart(rlsName string, chart *chart.Ch
 
 // helper function to generate bow configuration
 func testingConfigYaml(cfg *bowChartConfig) (vals chartutil.Values, err error) {
-	root := &Root{bow: *cfg}
+	root := &Root{Bow: *cfg}
 	bts, err := yaml.Marshal(root)
 	if err != nil {
 		return nil, err
@@ -221,7 +251,7 @@ bow:
 		},
 	}
 
-	prov := NewProvider(fakeImpl, &fakeSender{}, approver())
+	prov := NewProvider(fakeImpl, &fakeSender{}, approver(), nil, nil, nil, nil)
 
 	tracked, _ := prov.TrackedImages()
 
@@ -262,7 +292,7 @@ image2:
 		},
 	}
 
-	prov := NewProvider(fakeImpl, &fakeSender{}, approver())
+	prov := NewProvider(fakeImpl, &fakeSender{}, approver(), nil, nil, nil, nil)
 
 	tracked, _ := prov.TrackedImages()
 
@@ -272,6 +302,80 @@ image2:
 
 }
 
+func TestGetTrackedReleasesMalformedConfig(t *testing.T) {
+
+	malformedVals := `
+image:
+  repository: gcr.io/v2-namespace/bye-world
+  tag: 1.1.0
+
+bow:
+  policy: all
+  trigger: poll
+  approvals: "two"
+  images:
+    - repository: image.repository
+      tag: image.tag
+`
+
+	noPolicyVals := `
+image:
+  repository: gcr.io/v2-namespace/hello-world
+  tag: 1.2.0
+`
+
+	fakeImpl := &fakeImplementer{
+		listReleasesResponse: &rls.ListReleasesResponse{
+			Releases: []*hapi_release5.Release{
+				&hapi_release5.Release{
+					Name:      "release-1",
+					Namespace: "default",
+					Chart: &chart.Chart{
+						Values:   &chart.Config{Raw: malformedVals},
+						Metadata: &chart.Metadata{Name: "app-x"},
+					},
+					Config: &chart.Config{Raw: ""},
+				},
+				&hapi_release5.Release{
+					Name:      "release-2",
+					Namespace: "default",
+					Chart: &chart.Chart{
+						Values:   &chart.Config{Raw: noPolicyVals},
+						Metadata: &chart.Metadata{Name: "app-y"},
+					},
+					Config: &chart.Config{Raw: ""},
+				},
+			},
+		},
+	}
+
+	sender := &fakeSender{}
+	prov := NewProvider(fakeImpl, sender, approver(), nil, nil, nil, nil)
+
+	// tracked images are requested on every poll scan, warning should be sent once
+	for i := 0; i < 3; i++ {
+		tracked, err := prov.TrackedImages()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(tracked) != 0 {
+			t.Errorf("didn't expect to find any tracked releases, found: %d", len(tracked))
+		}
+	}
+
+	if len(sender.sentEvents) != 1 {
+		t.Fatalf("expected 1 notification, got: %d", len(sender.sentEvents))
+	}
+
+	if sender.sentEvents[0].Level != types.LevelWarn {
+		t.Errorf("unexpected level: %s", sender.sentEvents[0].Level)
+	}
+
+	if sender.sentEvents[0].Identifier != "chart/default/release-1" {
+		t.Errorf("unexpected identifier: %s", sender.sentEvents[0].Identifier)
+	}
+}
+
 func TestGetTrackedReleasesTotallyNonStandard(t *testing.T) {
 
 	chartVals := `
@@ -311,7 +415,7 @@ bow:
 		},
 	}
 
-	prov := NewProvider(fakeImpl, &fakeSender{}, approver())
+	prov := NewProvider(fakeImpl, &fakeSender{}, approver(), nil, nil, nil, nil)
 
 	tracked, _ := prov.TrackedImages()
 
@@ -336,6 +440,22 @@ func TestGetTriggerFromConfig(t *testing.T) {
 	}
 }
 
+func TestGetApprovalVotersFromConfig(t *testing.T) {
+	vals, err := testingConfigYaml(&bowChartConfig{Policy: "all", Approvals: 1, ApprovalVoters: []string{"alice", "bob"}})
+	if err != nil {
+		t.Fatalf("Failed to load testdata: %s", err)
+	}
+
+	cfg, err := getbowConfig(vals)
+	if err != nil {
+		t.Fatalf("failed to get config: %s", err)
+	}
+
+	if !reflect.DeepEqual(cfg.ApprovalVoters, []string{"alice", "bob"}) {
+		t.Errorf("unexpected approval voters: %v", cfg.ApprovalVoters)
+	}
+}
+
 func TestGetPolicyFromConfig(t *testing.T) {
 	vals, err := testingConfigYaml(&bowChartConfig{Policy: "all"})
 	if err != nil {
@@ -414,9 +534,9 @@ bow:
 		},
 	}
 
-	provider := NewProvider(fakeImpl, &fakeSender{}, approver())
+	provider := NewProvider(fakeImpl, &fakeSender{}, approver(), nil, nil, nil, nil)
 
-	err := provider.processEvent(&types.Event{
+	err := provider.processEvent(context.Background(), &types.Event{
 		Repository: types.Repository{
 			Name: "karolisr/webhook-demo",
 			Tag:  "0.0.11",
@@ -436,6 +556,204 @@ bow:
 	}
 }
 
+func TestUpdateReleaseMultipleImages(t *testing.T) {
+	chartVals := `
+image:
+  repository: karolisr/webhook-demo
+  tag: 0.0.10
+sidecar:
+  repository: karolisr/webhook-demo
+  tag: 0.0.9
+
+bow:
+  policy: all
+  trigger: poll
+  images:
+    - repository: image.repository
+      tag: image.tag
+      releaseNotes: https://github.com/bow-hq/bow/releases
+    - repository: sidecar.repository
+      tag: sidecar.tag
+      releaseNotes: https://github.com/bow-hq/sidecar/releases
+
+`
+	myChart := &chart.Chart{
+		Values: &chart.Config{Raw: chartVals},
+	}
+
+	fakeImpl := &fakeImplementer{
+		listReleasesResponse: &rls.ListReleasesResponse{
+			Releases: []*hapi_release5.Release{
+				&hapi_release5.Release{
+					Name:   "release-1",
+					Chart:  myChart,
+					Config: &chart.Config{Raw: ""},
+				},
+			},
+		},
+	}
+
+	provider := NewProvider(fakeImpl, &fakeSender{}, approver(), nil, nil, nil, nil)
+
+	plans, err := provider.createUpdatePlans(context.Background(), &types.Event{
+		Repository: types.Repository{
+			Name: "karolisr/webhook-demo",
+			Tag:  "0.0.11",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create plans, error: %s", err)
+	}
+
+	// the same release planned twice, ie: by a deferred event
+	plans = append(plans, &UpdatePlan{
+		Namespace:    plans[0].Namespace,
+		Name:         plans[0].Name,
+		Chart:        myChart,
+		Config:       plans[0].Config,
+		Values:       map[string]string{"extra.tag": "0.0.11"},
+		ReleaseNotes: []string{"https://github.com/bow-hq/bow/releases", "https://example.com/notes"},
+	})
+
+	err = provider.applyPlans(context.Background(), plans)
+	if err != nil {
+		t.Fatalf("failed to apply plans, error: %s", err)
+	}
+
+	if fakeImpl.updates != 1 {
+		t.Errorf("expected a single release upgrade, got: %d", fakeImpl.updates)
+	}
+
+	merged := mergePlans(context.Background(), plans)
+	if len(merged) != 1 {
+		t.Fatalf("expected 1 merged plan, got: %d", len(merged))
+	}
+	expected := map[string]string{
+		"image.tag":   "0.0.11",
+		"sidecar.tag": "0.0.11",
+		"extra.tag":   "0.0.11",
+	}
+	if !reflect.DeepEqual(merged[0].Values, expected) {
+		t.Errorf("unexpected values: %v", merged[0].Values)
+	}
+	expectedNotes := []string{"https://github.com/bow-hq/bow/releases", "https://github.com/bow-hq/sidecar/releases", "https://example.com/notes"}
+	if !reflect.DeepEqual(merged[0].ReleaseNotes, expectedNotes) {
+		t.Errorf("unexpected release notes: %v", merged[0].ReleaseNotes)
+	}
+}
+
+type fakeChartRepository struct {
+	charts map[string]*chart.Chart
+}
+
+func (r *fakeChartRepository) Get(name, version string) (*chart.Chart, error) {
+	c, ok := r.charts[name+"-"+version]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return c, nil
+}
+
+func TestUpdateReleaseChartVersion(t *testing.T) {
+	chartVals := `
+image:
+  repository: karolisr/webhook-demo
+  tag: 0.0.10
+
+bow:
+  policy: all
+  trigger: poll
+  chartVersionPath: webhook-demo
+  images:
+    - repository: image.repository
+      tag: image.tag
+
+`
+	myChart := &chart.Chart{
+		Values: &chart.Config{Raw: chartVals},
+	}
+	newChart := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "webhook-demo", Version: "0.0.11"},
+		Values:   &chart.Config{Raw: chartVals},
+	}
+
+	fakeImpl := &fakeImplementer{
+		listReleasesResponse: &rls.ListReleasesResponse{
+			Releases: []*hapi_release5.Release{
+				&hapi_release5.Release{
+					Name:   "release-1",
+					Chart:  myChart,
+					Config: &chart.Config{Raw: ""},
+				},
+			},
+		},
+	}
+
+	repository := &fakeChartRepository{charts: map[string]*chart.Chart{"webhook-demo-0.0.11": newChart}}
+	provider := NewProvider(fakeImpl, &fakeSender{}, approver(), nil, nil, repository, nil)
+
+	err := provider.processEvent(context.Background(), &types.Event{
+		Repository: types.Repository{
+			Name: "karolisr/webhook-demo",
+			Tag:  "0.0.11",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to process event, error: %s", err)
+	}
+	if fakeImpl.updatedChart != newChart {
+		t.Errorf("expected release to be upgraded to the new chart version")
+	}
+
+	// chart version that doesn't exist yet, image isn't updated alone
+	fakeImpl.updatedChart = nil
+	err = provider.processEvent(context.Background(), &types.Event{
+		Repository: types.Repository{
+			Name: "karolisr/webhook-demo",
+			Tag:  "0.0.12",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to process event, error: %s", err)
+	}
+	if fakeImpl.updatedChart != nil {
+		t.Errorf("expected release not to be updated without chart version")
+	}
+}
+
+func TestUpdateReleaseDuration(t *testing.T) {
+	observed := func(chartName string) uint64 {
+		var m dto.Metric
+		err := helmUpdateDuration.WithLabelValues(chartName).(prometheus.Metric).Write(&m)
+		if err != nil {
+			t.Fatalf("failed to write metric: %s", err)
+		}
+		return m.GetHistogram().GetSampleCount()
+	}
+
+	myChart := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "duration-chart"},
+		Values:   &chart.Config{Raw: ""},
+	}
+	values := map[string]string{"image.tag": "0.0.11"}
+
+	err := updateHelmRelease(context.Background(), &fakeImplementer{}, "release-1", myChart, values)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if count := observed("duration-chart"); count != 1 {
+		t.Errorf("expected 1 observation after successful update, got: %d", count)
+	}
+
+	err = updateHelmRelease(context.Background(), &fakeImplementer{updateErr: errors.New("timed out waiting for the condition")}, "release-1", myChart, values)
+	if err == nil {
+		t.Fatalf("expected update to fail")
+	}
+	if count := observed("duration-chart"); count != 2 {
+		t.Errorf("expected failed update to be observed too, got: %d observations", count)
+	}
+}
+
 var pollingValues = `
 name: al Rashid
 where:
@@ -658,3 +976,293 @@ bow:
 		t.Errorf("policy not found")
 	}
 }
+
+func TestApplyPlansNotifyOnNoUpdate(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name       string
+		cfg        *bowChartConfig
+		newVersion string
+		wantEvents int
+	}{
+		{
+			name:       "same version, notifications by default",
+			cfg:        &bowChartConfig{Policy: "force"},
+			newVersion: "1.1.0",
+			wantEvents: 2,
+		},
+		{
+			name:       "same version, notifications disabled",
+			cfg:        &bowChartConfig{Policy: "force", NotifyOnNoUpdate: &disabled},
+			newVersion: "1.1.0",
+			wantEvents: 0,
+		},
+		{
+			name:       "new version, notifications disabled only for no-op updates",
+			cfg:        &bowChartConfig{Policy: "force", NotifyOnNoUpdate: &disabled},
+			newVersion: "1.2.0",
+			wantEvents: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &fakeSender{}
+			provider := NewProvider(&fakeImplementer{}, sender, approver(), nil, nil, nil, nil)
+
+			err := provider.applyPlans(context.Background(), []*UpdatePlan{
+				{
+					Namespace:      "default",
+					Name:           "release-1",
+					Chart:          &chart.Chart{},
+					Values:         map[string]string{"image.tag": tt.newVersion},
+					Config:         tt.cfg,
+					CurrentVersion: "1.1.0",
+					NewVersion:     tt.newVersion,
+				},
+			})
+			if err != nil {
+				t.Fatalf("failed to apply plans: %s", err)
+			}
+
+			if len(sender.sentEvents) != tt.wantEvents {
+				t.Errorf("expected %d notifications, got: %d", tt.wantEvents, len(sender.sentEvents))
+			}
+		})
+	}
+}
+
+// parallelImplementer - records how many release upgrades run at the same time
+type parallelImplementer struct {
+	fakeImplementer
+
+	mu          sync.Mutex
+	running     int
+	maxRunning  int
+	upgraded    []string
+	upgradeTime time.Duration
+}
+
+func (i *parallelImplementer) UpdateReleaseFromChart(rlsName string, chart *chart.Chart, opts ...helm.UpdateOption) (*rls.UpdateReleaseResponse, error) {
+	i.mu.Lock()
+	i.running++
+	if i.running > i.maxRunning {
+		i.maxRunning = i.running
+	}
+	i.upgraded = append(i.upgraded, rlsName)
+	i.mu.Unlock()
+
+	time.Sleep(i.upgradeTime)
+
+	i.mu.Lock()
+	i.running--
+	i.mu.Unlock()
+
+	return &rls.UpdateReleaseResponse{Release: &hapi_release5.Release{Version: 2}}, nil
+}
+
+func TestApplyPlansMaxParallelUpdates(t *testing.T) {
+	var plans []*UpdatePlan
+	for _, name := range []string{"release-1", "release-2", "release-3", "release-4", "release-5"} {
+		plans = append(plans, &UpdatePlan{
+			Namespace: "default",
+			Name:      name,
+			Chart:     &chart.Chart{Metadata: &chart.Metadata{Name: "app"}},
+			Config:    &bowChartConfig{},
+			Values:    map[string]string{"image.tag": "1.1.0"},
+		})
+	}
+
+	tests := []struct {
+		name               string
+		maxParallelUpdates int
+		wantMaxRunning     int
+	}{
+		{"default", 0, 1},
+		{"sequential", 1, 1},
+		{"parallel", 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impl := &parallelImplementer{upgradeTime: 20 * time.Millisecond}
+			provider := NewProvider(impl, &fakeSender{}, approver(), nil, nil, nil, nil)
+			provider.MaxParallelUpdates = tt.maxParallelUpdates
+
+			err := provider.applyPlans(context.Background(), plans)
+			if err != nil {
+				t.Fatalf("failed to apply plans, error: %s", err)
+			}
+
+			if len(impl.upgraded) != len(plans) {
+				t.Errorf("expected %d upgrades, got: %d", len(plans), len(impl.upgraded))
+			}
+			if impl.maxRunning != tt.wantMaxRunning {
+				t.Errorf("expected %d upgrades at the same time, got: %d", tt.wantMaxRunning, impl.maxRunning)
+			}
+		})
+	}
+}
+
+func TestApplyPlansParallelNotifications(t *testing.T) {
+	plan := func(name, path string) *UpdatePlan {
+		return &UpdatePlan{
+			Namespace:      "parallel",
+			Name:           name,
+			Chart:          &chart.Chart{Metadata: &chart.Metadata{Name: "app"}},
+			Config:         &bowChartConfig{NotificationChannels: []string{name}},
+			Values:         map[string]string{path: "1.1.0"},
+			CurrentVersion: "1.0.0",
+			NewVersion:     "1.1.0",
+		}
+	}
+	// release-1 runs the image twice, its plans are merged into one upgrade
+	plans := []*UpdatePlan{
+		plan("release-1", "image.tag"),
+		plan("release-2", "image.tag"),
+		plan("release-1", "worker.tag"),
+		plan("release-3", "image.tag"),
+	}
+
+	impl := &parallelImplementer{upgradeTime: 20 * time.Millisecond}
+	sender := &fakeSender{}
+	provider := NewProvider(impl, sender, approver(), nil, nil, nil, nil)
+	provider.MaxParallelUpdates = 3
+
+	before := helmVersionedUpdatesCounter.Values()
+
+	err := provider.applyPlans(context.Background(), plans)
+	if err != nil {
+		t.Fatalf("failed to apply plans, error: %s", err)
+	}
+
+	if len(impl.upgraded) != 3 {
+		t.Errorf("expected 3 upgrades, got: %v", impl.upgraded)
+	}
+
+	after := helmVersionedUpdatesCounter.Values()
+	sent := make(map[string][]types.Notification)
+	for _, event := range sender.sentEvents {
+		name := event.Metadata["name"]
+		if len(event.Channels) != 1 || event.Channels[0] != name {
+			t.Errorf("unexpected channels of %s notification: %v", name, event.Channels)
+		}
+		sent[name] = append(sent[name], event.Type)
+	}
+	for _, name := range []string{"release-1", "release-2", "release-3"} {
+		if !reflect.DeepEqual(sent[name], []types.Notification{types.NotificationPreReleaseUpdate, types.NotificationReleaseUpdate}) {
+			t.Errorf("unexpected %s notifications: %v", name, sent[name])
+		}
+		key := "parallel/" + name
+		if after[key]-before[key] != 1 {
+			t.Errorf("expected %s to be counted once, got: %v", name, after[key]-before[key])
+		}
+	}
+}
+
+func TestStopWaitsForReleaseUpgrades(t *testing.T) {
+	defer func(timeout time.Duration) { StopTimeout = timeout }(StopTimeout)
+
+	tests := []struct {
+		name        string
+		upgradeTime time.Duration
+		stopTimeout time.Duration
+		wantRunning int
+	}{
+		{name: "upgrade finishes", upgradeTime: 100 * time.Millisecond, stopTimeout: 5 * time.Second},
+		{name: "timeout", upgradeTime: 2 * time.Second, stopTimeout: 50 * time.Millisecond, wantRunning: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			StopTimeout = tt.stopTimeout
+
+			var plans []*UpdatePlan
+			for _, name := range []string{"release-1", "release-2", "release-3"} {
+				plans = append(plans, &UpdatePlan{
+					Namespace: "default",
+					Name:      name,
+					Chart:     &chart.Chart{Metadata: &chart.Metadata{Name: "app"}},
+					Config:    &bowChartConfig{},
+					Values:    map[string]string{"image.tag": "1.1.0"},
+				})
+			}
+
+			impl := &parallelImplementer{upgradeTime: tt.upgradeTime}
+			provider := NewProvider(impl, &fakeSender{}, approver(), nil, nil, nil, nil)
+
+			// same as the event loop processing an event
+			provider.running.Add(1)
+			go func() {
+				defer provider.running.Done()
+				provider.applyPlans(context.Background(), plans)
+			}()
+
+			deadline := time.Now().Add(time.Second)
+			for time.Now().Before(deadline) {
+				impl.mu.Lock()
+				started := len(impl.upgraded)
+				impl.mu.Unlock()
+				if started > 0 {
+					break
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+
+			provider.Stop()
+
+			impl.mu.Lock()
+			defer impl.mu.Unlock()
+			if impl.running != tt.wantRunning {
+				t.Errorf("expected %d running upgrades once stopped, got: %d", tt.wantRunning, impl.running)
+			}
+			// remaining plans are skipped once the provider is stopping
+			if len(impl.upgraded) != 1 {
+				t.Errorf("expected a single release upgrade, got: %v", impl.upgraded)
+			}
+		})
+	}
+}
+
+// listingImplementer - reports every ListReleases call
+type listingImplementer struct {
+	fakeImplementer
+	listed chan struct{}
+}
+
+func (i *listingImplementer) ListReleases(opts ...helm.ReleaseListOption) (*rls.ListReleasesResponse, error) {
+	i.listed <- struct{}{}
+	return &rls.ListReleasesResponse{}, nil
+}
+
+func TestEventLoopSkipsDuplicateEvents(t *testing.T) {
+	impl := &listingImplementer{listed: make(chan struct{}, 10)}
+	provider := NewProvider(impl, &fakeSender{}, approver(), nil, nil, nil, nil)
+
+	go provider.Start()
+	defer provider.Stop()
+
+	push := types.Event{Repository: types.Repository{Name: "karolisr/webhook-demo", Tag: "0.0.11"}, TriggerName: "webhook"}
+	for i := 0; i < 3; i++ {
+		provider.Submit(push)
+	}
+	provider.Submit(types.Event{Repository: types.Repository{Name: "karolisr/webhook-demo", Tag: "0.0.12"}, TriggerName: "webhook"})
+	approved := push
+	approved.TriggerName = types.TriggerTypeApproval.String()
+	provider.Submit(approved)
+
+	// first push, other tag and approved event
+	for i := 0; i < 3; i++ {
+		select {
+		case <-impl.listed:
+		case <-time.After(time.Second):
+			t.Fatalf("expected 3 events to be processed, got: %d", i)
+		}
+	}
+	select {
+	case <-impl.listed:
+		t.Errorf("duplicate events should be skipped")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
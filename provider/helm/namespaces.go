@@ -0,0 +1,64 @@
+package helm
+
+import (
+	"strings"
+
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+// NamespaceFilter - limits releases bow tracks to allowed namespaces,
+// denied namespaces are ignored even if they are allowed
+type NamespaceFilter struct {
+	allow map[string]bool
+	deny  map[string]bool
+}
+
+// NewNamespaceFilter - create new filter from comma separated namespace lists,
+// all namespaces are allowed when allow list is empty
+func NewNamespaceFilter(allow, deny string) *NamespaceFilter {
+	return &NamespaceFilter{
+		allow: namespaceSet(allow),
+		deny:  namespaceSet(deny),
+	}
+}
+
+func namespaceSet(namespaces string) map[string]bool {
+	set := make(map[string]bool)
+	for _, ns := range strings.Split(namespaces, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns != "" {
+			set[ns] = true
+		}
+	}
+	return set
+}
+
+// Allowed - whether releases in the namespace should be tracked
+func (f *NamespaceFilter) Allowed(namespace string) bool {
+	if f == nil {
+		return true
+	}
+	if f.deny[namespace] {
+		return false
+	}
+	return len(f.allow) == 0 || f.allow[namespace]
+}
+
+// filter - drops releases from namespaces that aren't allowed
+func (f *NamespaceFilter) filter(releaseList *rls.ListReleasesResponse) *rls.ListReleasesResponse {
+	if f == nil || (len(f.allow) == 0 && len(f.deny) == 0) {
+		return releaseList
+	}
+
+	filtered := &rls.ListReleasesResponse{
+		Next:  releaseList.Next,
+		Total: releaseList.Total,
+	}
+	for _, release := range releaseList.Releases {
+		if f.Allowed(release.Namespace) {
+			filtered.Releases = append(filtered.Releases, release)
+		}
+	}
+	filtered.Count = int64(len(filtered.Releases))
+	return filtered
+}
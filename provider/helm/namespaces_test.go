@@ -0,0 +1,51 @@
+package helm
+
+import (
+	"reflect"
+	"testing"
+
+	hapi_release5 "k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+func TestListReleasesNamespaceFilter(t *testing.T) {
+	fakeImpl := &fakeImplementer{
+		listReleasesResponse: &rls.ListReleasesResponse{
+			Releases: []*hapi_release5.Release{
+				{Name: "app", Namespace: "team-a"},
+				{Name: "app", Namespace: "team-b"},
+				{Name: "ingress", Namespace: "kube-system"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		filter *NamespaceFilter
+		want   []string
+	}{
+		{"unset", nil, []string{"team-a", "team-b", "kube-system"}},
+		{"empty lists", NewNamespaceFilter("", ""), []string{"team-a", "team-b", "kube-system"}},
+		{"allowed", NewNamespaceFilter("team-a, team-b", ""), []string{"team-a", "team-b"}},
+		{"denied", NewNamespaceFilter("", "kube-system"), []string{"team-a", "team-b"}},
+		{"denied wins", NewNamespaceFilter("team-a,kube-system", "kube-system"), []string{"team-a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProvider(fakeImpl, &fakeSender{}, approver(), nil, nil, nil, tt.filter)
+			releaseList, err := p.listReleases()
+			if err != nil {
+				t.Fatalf("failed to list releases: %s", err)
+			}
+
+			var namespaces []string
+			for _, release := range releaseList.Releases {
+				namespaces = append(namespaces, release.Namespace)
+			}
+			if !reflect.DeepEqual(namespaces, tt.want) {
+				t.Errorf("listReleases() namespaces = %v, want %v", namespaces, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/alwinius/bow/extension/credentialshelper"
+	"github.com/alwinius/bow/registry"
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/image"
+
+	"k8s.io/helm/pkg/chartutil"
+	hapi_chart "k8s.io/helm/pkg/proto/hapi/chart"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// OCIChartScheme - prefix of chart references stored in OCI registries,
+// ie: oci://ghcr.io/org/charts/app
+const OCIChartScheme = "oci://"
+
+// helmChartContentMediaType - media type of the chart archive layer pushed by helm
+const helmChartContentMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+
+// LayerGetter - downloads layers of OCI artifacts
+type LayerGetter interface {
+	Layer(opts registry.Opts, mediaType string) ([]byte, error)
+}
+
+// OCIChartRepository - pulls charts stored as OCI artifacts, chart name is the
+// full reference without tag, ie: oci://ghcr.io/org/charts/app
+type OCIChartRepository struct {
+	layers LayerGetter
+}
+
+// NewOCIChartRepository - create new OCI chart repository, registry credentials
+// come from registered credentials helpers
+func NewOCIChartRepository(layers LayerGetter) *OCIChartRepository {
+	return &OCIChartRepository{
+		layers: layers,
+	}
+}
+
+// Get - pulls chart version, versions with "v" prefix (as image tags often have)
+// also match chart versions without it
+func (r *OCIChartRepository) Get(name, version string) (*hapi_chart.Chart, error) {
+	if !strings.HasPrefix(name, OCIChartScheme) {
+		return nil, fmt.Errorf("chart reference %s doesn't start with %s", name, OCIChartScheme)
+	}
+
+	var err error
+	for _, candidate := range []string{version, strings.TrimPrefix(version, "v")} {
+		var chart *hapi_chart.Chart
+		chart, err = r.get(strings.TrimPrefix(name, OCIChartScheme), candidate)
+		if err == nil {
+			return chart, nil
+		}
+	}
+	return nil, err
+}
+
+func (r *OCIChartRepository) get(name, version string) (*hapi_chart.Chart, error) {
+	// helm pushes semver build metadata with "_" as "+" isn't allowed in tags
+	ref, err := image.Parse(name + ":" + strings.Replace(version, "+", "_", -1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid chart reference %s: %s", name, err)
+	}
+
+	creds := credentialshelper.GetCredentials(&types.TrackedImage{Image: ref})
+
+	log.WithFields(log.Fields{
+		"chart":   ref.Repository(),
+		"version": version,
+	}).Debug("provider.helm: pulling chart from OCI registry")
+
+	archive, err := r.layers.Layer(registry.Opts{
+		Registry: ref.Scheme() + "://" + ref.Registry(),
+		Name:     ref.ShortName(),
+		Tag:      ref.Tag(),
+		Username: creds.Username,
+		Password: creds.Password,
+	}, helmChartContentMediaType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull chart %s version %s: %s", ref.Repository(), version, err)
+	}
+
+	return chartutil.LoadArchive(bytes.NewReader(archive))
+}
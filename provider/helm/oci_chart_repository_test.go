@@ -0,0 +1,151 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/alwinius/bow/registry"
+	"github.com/alwinius/bow/types"
+
+	"k8s.io/helm/pkg/chartutil"
+	hapi_chart "k8s.io/helm/pkg/proto/hapi/chart"
+	hapi_release5 "k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+type fakeLayerGetter struct {
+	layers map[string][]byte
+	opts   []registry.Opts
+}
+
+func (g *fakeLayerGetter) Layer(opts registry.Opts, mediaType string) ([]byte, error) {
+	g.opts = append(g.opts, opts)
+	layer, ok := g.layers[opts.Name+":"+opts.Tag+" "+mediaType]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return layer, nil
+}
+
+func TestOCIChartRepositoryGet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocichartrepotest")
+	if err != nil {
+		t.Fatalf("failed to create dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	archive, err := chartutil.Save(&hapi_chart.Chart{
+		Metadata: &hapi_chart.Metadata{Name: "wd", Version: "1.2.3+build.1", ApiVersion: chartutil.ApiVersionV1},
+		Values:   &hapi_chart.Config{Raw: "image:\n  tag: 1.2.3\n"},
+	}, dir)
+	if err != nil {
+		t.Fatalf("failed to package chart: %s", err)
+	}
+	data, err := ioutil.ReadFile(archive)
+	if err != nil {
+		t.Fatalf("failed to read chart archive: %s", err)
+	}
+
+	layers := &fakeLayerGetter{layers: map[string][]byte{
+		"org/charts/wd:1.2.3_build.1 " + helmChartContentMediaType: data,
+	}}
+	repository := NewOCIChartRepository(layers)
+
+	chart, err := repository.Get("oci://ghcr.io/org/charts/wd", "v1.2.3+build.1")
+	if err != nil {
+		t.Fatalf("failed to get chart: %s", err)
+	}
+	if chart.GetMetadata().GetVersion() != "1.2.3+build.1" {
+		t.Errorf("unexpected chart version: %s", chart.GetMetadata().GetVersion())
+	}
+	if layers.opts[0].Registry != "https://ghcr.io" {
+		t.Errorf("unexpected registry: %s", layers.opts[0].Registry)
+	}
+
+	if _, err := repository.Get("oci://ghcr.io/org/charts/wd", "1.3.0"); err == nil {
+		t.Errorf("expected error for missing version")
+	}
+	if _, err := repository.Get("ghcr.io/org/charts/wd", "1.2.3+build.1"); err == nil {
+		t.Errorf("expected error for reference without oci scheme")
+	}
+}
+
+func TestUpdateReleaseOCIChart(t *testing.T) {
+	chartVals := `
+image:
+  repository: karolisr/webhook-demo
+  tag: 0.0.10
+
+bow:
+  policy: all
+  trigger: poll
+  chartRef: oci://ghcr.io/org/charts/webhook-demo
+  images:
+    - repository: image.repository
+      tag: image.tag
+
+`
+	newChart := &hapi_chart.Chart{
+		Metadata: &hapi_chart.Metadata{Name: "webhook-demo", Version: "0.0.11"},
+		Values:   &hapi_chart.Config{Raw: chartVals},
+	}
+
+	fakeImpl := &fakeImplementer{
+		listReleasesResponse: &rls.ListReleasesResponse{
+			Releases: []*hapi_release5.Release{
+				&hapi_release5.Release{
+					Name:   "release-1",
+					Chart:  &hapi_chart.Chart{Values: &hapi_chart.Config{Raw: chartVals}},
+					Config: &hapi_chart.Config{Raw: ""},
+				},
+			},
+		},
+	}
+
+	provider := NewProvider(fakeImpl, &fakeSender{}, approver(), nil, nil, nil, nil)
+
+	// without OCI chart repository release isn't updated
+	err := provider.processEvent(context.Background(), &types.Event{
+		Repository: types.Repository{Name: "karolisr/webhook-demo", Tag: "0.0.11"},
+	})
+	if err != nil {
+		t.Fatalf("failed to process event, error: %s", err)
+	}
+	if fakeImpl.updatedChart != nil {
+		t.Errorf("expected release not to be updated without OCI chart repository")
+	}
+
+	provider.OCICharts = &fakeChartRepository{charts: map[string]*hapi_chart.Chart{"oci://ghcr.io/org/charts/webhook-demo-0.0.11": newChart}}
+	err = provider.processEvent(context.Background(), &types.Event{
+		Repository: types.Repository{Name: "karolisr/webhook-demo", Tag: "0.0.11"},
+	})
+	if err != nil {
+		t.Fatalf("failed to process event, error: %s", err)
+	}
+	if fakeImpl.updatedChart != newChart {
+		t.Errorf("expected release to be upgraded to the new chart version")
+	}
+}
+
+func TestGetbowConfigChartRef(t *testing.T) {
+	for _, tt := range []struct {
+		config  string
+		wantErr bool
+	}{
+		{"chartRef: oci://ghcr.io/org/charts/wd", false},
+		{"chartRef: ghcr.io/org/charts/wd", true},
+		{"chartRef: oci://ghcr.io/org/charts/wd\n  chartVersionPath: wd", true},
+	} {
+		vals, err := chartutil.ReadValues([]byte("bow:\n  policy: all\n  " + tt.config + "\n"))
+		if err != nil {
+			t.Fatalf("failed to read values: %s", err)
+		}
+		_, err = getbowConfig(vals)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("getbowConfig(%q) error = %v, wantErr %v", tt.config, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package helm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/approvals"
+)
+
+func TestProviderReady(t *testing.T) {
+	fi := &failingImplementer{}
+	provider := NewProvider(fi, &fakeSender{}, approvals.New(&approvals.Opts{}), nil, nil, nil, nil)
+
+	if err := provider.Ready(); err == nil {
+		t.Fatalf("provider shouldn't be ready before event loop starts")
+	}
+
+	go provider.Start()
+	defer provider.Stop()
+
+	// waiting for event loop, releases can't be listed yet
+	deadline := time.Now().Add(time.Second)
+	for fi.calls == 0 && time.Now().Before(deadline) {
+		provider.Ready()
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := provider.Ready(); err == nil {
+		t.Fatalf("provider shouldn't be ready until releases are listed")
+	}
+
+	// tiller became available
+	provider.implementer = &fakeImplementer{}
+	if err := provider.Ready(); err != nil {
+		t.Fatalf("expected provider to be ready, got: %s", err)
+	}
+
+	// readiness doesn't depend on tiller once releases were listed
+	provider.implementer = fi
+	if err := provider.Ready(); err != nil {
+		t.Errorf("expected provider to stay ready, got: %s", err)
+	}
+}
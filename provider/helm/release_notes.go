@@ -0,0 +1,77 @@
+package helm
+
+import (
+	"strings"
+
+	"github.com/alwinius/bow/extension/credentialshelper"
+	"github.com/alwinius/bow/registry"
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/image"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LabelsGetter - gets image config labels from the registry, used to read
+// release notes that are baked into images
+type LabelsGetter interface {
+	Labels(opts registry.Opts) (map[string]string, error)
+}
+
+// releaseNotesLabel - new image whose config label holds release notes
+type releaseNotesLabel struct {
+	image  string
+	label  string
+	secret string
+}
+
+// addLabelReleaseNotes - reads release notes from the labels of the new images,
+// images without the label are skipped
+func (p *Provider) addLabelReleaseNotes(plan *UpdatePlan) {
+	if p.labelsGetter == nil {
+		return
+	}
+
+	for _, rl := range plan.releaseNotesLabels {
+		ref, err := image.Parse(rl.image)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+				"image": rl.image,
+			}).Error("provider.helm: failed to parse image for release notes")
+			continue
+		}
+
+		ti := &types.TrackedImage{Image: ref, Namespace: plan.Namespace}
+		if rl.secret != "" {
+			ti.Secrets = []string{rl.secret}
+		}
+		p.resolveCredentials(ti)
+		creds := credentialshelper.GetCredentials(ti)
+
+		labels, err := p.labelsGetter.Labels(registry.Opts{
+			Registry: ref.Scheme() + "://" + ref.Registry(),
+			Name:     ref.ShortName(),
+			Tag:      ref.Tag(),
+			Username: creds.Username,
+			Password: creds.Password,
+		})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+				"image": rl.image,
+			}).Warn("provider.helm: failed to get image labels for release notes")
+			continue
+		}
+
+		notes := strings.TrimSpace(labels[rl.label])
+		if notes == "" {
+			log.WithFields(log.Fields{
+				"image": rl.image,
+				"label": rl.label,
+			}).Debug("provider.helm: image has no release notes label")
+			continue
+		}
+
+		plan.ReleaseNotes = append(plan.ReleaseNotes, notes)
+	}
+}
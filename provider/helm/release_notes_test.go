@@ -0,0 +1,89 @@
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alwinius/bow/registry"
+	"github.com/alwinius/bow/types"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	hapi_release5 "k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+type fakeLabelsGetter struct {
+	labels map[string]map[string]string // image:tag -> labels
+	opts   []registry.Opts
+}
+
+func (g *fakeLabelsGetter) Labels(opts registry.Opts) (map[string]string, error) {
+	g.opts = append(g.opts, opts)
+	return g.labels[opts.Name+":"+opts.Tag], nil
+}
+
+func TestCreateUpdatePlansLabelReleaseNotes(t *testing.T) {
+	chartVals := `
+image:
+  repository: karolisr/webhook-demo
+  tag: 0.0.10
+
+bow:
+  policy: all
+  trigger: poll
+  images:
+    - repository: image.repository
+      tag: image.tag
+      releaseNotesLabel: org.opencontainers.image.description
+`
+
+	fakeImpl := &fakeImplementer{
+		listReleasesResponse: &rls.ListReleasesResponse{
+			Releases: []*hapi_release5.Release{
+				&hapi_release5.Release{
+					Name:      "release-1",
+					Namespace: "default",
+					Chart: &chart.Chart{
+						Values:   &chart.Config{Raw: chartVals},
+						Metadata: &chart.Metadata{Name: "app-x"},
+					},
+					Config: &chart.Config{Raw: ""},
+				},
+			},
+		},
+	}
+
+	getter := &fakeLabelsGetter{
+		labels: map[string]map[string]string{
+			"karolisr/webhook-demo:0.0.11": {"org.opencontainers.image.description": "fixes things"},
+		},
+	}
+
+	prov := NewProvider(fakeImpl, &fakeSender{}, approver(), nil, getter, nil, nil)
+
+	plans, err := prov.createUpdatePlans(context.Background(), &types.Event{Repository: types.Repository{Name: "karolisr/webhook-demo", Tag: "0.0.11"}})
+	if err != nil {
+		t.Fatalf("failed to create plans: %s", err)
+	}
+	if len(plans) != 1 {
+		t.Fatalf("expected 1 plan, got: %d", len(plans))
+	}
+	if len(plans[0].ReleaseNotes) != 1 || plans[0].ReleaseNotes[0] != "fixes things" {
+		t.Errorf("unexpected release notes: %v", plans[0].ReleaseNotes)
+	}
+	if getter.opts[0].Registry != "https://index.docker.io" {
+		t.Errorf("unexpected registry: %s", getter.opts[0].Registry)
+	}
+
+	// new image without the label
+	plans, err = prov.createUpdatePlans(context.Background(), &types.Event{Repository: types.Repository{Name: "karolisr/webhook-demo", Tag: "0.0.12"}})
+	if err != nil {
+		t.Fatalf("failed to create plans: %s", err)
+	}
+	if len(plans) != 1 {
+		t.Fatalf("expected 1 plan, got: %d", len(plans))
+	}
+	if len(plans[0].ReleaseNotes) != 0 {
+		t.Errorf("expected no release notes, got: %v", plans[0].ReleaseNotes)
+	}
+}
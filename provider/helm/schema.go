@@ -0,0 +1,107 @@
+package helm
+
+import (
+	_ "embed" // bow configuration schema
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"k8s.io/helm/pkg/chartutil"
+)
+
+// bowConfigSchemaJSON - JSON Schema of bowChartConfig, additional properties aren't
+// allowed by the schema so typos such as "polcy" don't go unnoticed
+//
+//go:embed bow_config.schema.json
+var bowConfigSchemaJSON []byte
+
+var bowConfigSchema = mustParseSchema(bowConfigSchemaJSON)
+
+func mustParseSchema(data []byte) *gojsonschema.Schema {
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		panic(fmt.Sprintf("provider.helm: invalid bow configuration schema: %s", err))
+	}
+	return schema
+}
+
+// SchemaError - bow configuration doesn't match the schema, unknown fields alone
+// don't make the configuration invalid
+type SchemaError struct {
+	// Unknown - paths of fields that aren't in the schema, ie: bow.polcy
+	Unknown []string
+	// Invalid - type mismatches, ie: bow.approvals: expected integer, got string
+	Invalid []string
+}
+
+func (e *SchemaError) Error() string {
+	var parts []string
+	if len(e.Invalid) > 0 {
+		parts = append(parts, "type mismatches: "+strings.Join(e.Invalid, "; "))
+	}
+	if len(e.Unknown) > 0 {
+		parts = append(parts, "unknown fields: "+strings.Join(e.Unknown, ", "))
+	}
+	return "bow configuration doesn't match schema, " + strings.Join(parts, ", ")
+}
+
+// validateBowConfig - validates bow section of the values against the schema,
+// nil is returned when the configuration matches it. Additional properties are
+// reported as unknown fields as they don't make the configuration invalid
+func validateBowConfig(cfg interface{}) *SchemaError {
+	if cfg == nil {
+		return nil
+	}
+	if v, ok := cfg.(chartutil.Values); ok {
+		cfg = map[string]interface{}(v)
+	}
+
+	result, err := bowConfigSchema.Validate(gojsonschema.NewGoLoader(cfg))
+	if err != nil {
+		return &SchemaError{Invalid: []string{fmt.Sprintf("bow: %s", err)}}
+	}
+
+	e := &SchemaError{}
+	for _, resultErr := range result.Errors() {
+		path := schemaPath(resultErr.Field())
+		switch resultErr.(type) {
+		case *gojsonschema.AdditionalPropertyNotAllowedError:
+			e.Unknown = append(e.Unknown, fmt.Sprintf("%s.%v", path, resultErr.Details()["property"]))
+		case *gojsonschema.InvalidTypeError:
+			given := resultErr.Details()["given"]
+			if given == gojsonschema.TYPE_NULL {
+				// unset values, ie: "images:" without items, are zero values
+				continue
+			}
+			e.Invalid = append(e.Invalid, fmt.Sprintf("%s: expected %v, got %v", path, resultErr.Details()["expected"], given))
+		default:
+			e.Invalid = append(e.Invalid, fmt.Sprintf("%s: %s", path, resultErr.Description()))
+		}
+	}
+	if len(e.Unknown) == 0 && len(e.Invalid) == 0 {
+		return nil
+	}
+	sort.Strings(e.Unknown)
+	sort.Strings(e.Invalid)
+	return e
+}
+
+// schemaPath - path of the validated field in the values, ie: images.0.tag
+// becomes bow.images[0].tag
+func schemaPath(field string) string {
+	path := "bow"
+	if field == gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+		return path
+	}
+	for _, part := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(part); err == nil {
+			path += "[" + part + "]"
+			continue
+		}
+		path += "." + part
+	}
+	return path
+}
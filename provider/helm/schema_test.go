@@ -0,0 +1,183 @@
+package helm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alwinius/bow/types"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	hapi_release5 "k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+func TestValidateBowConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      string
+		wantUnknown []string
+		wantInvalid []string
+	}{
+		{
+			name: "valid",
+			values: `
+bow:
+  policy: all
+  approvals: 2
+  notifyOnNoUpdate: false
+  images:
+    - repository: image.repository
+      tag: image.tag
+      matchTag: true
+`,
+		},
+		{
+			name: "unset values",
+			values: `
+bow:
+  policy: all
+  approvals:
+  images:
+`,
+		},
+		{
+			name: "unknown fields",
+			values: `
+bow:
+  polcy: all
+  images:
+    - repository: image.repository
+      tga: image.tag
+`,
+			wantUnknown: []string{"bow.images[0].tga", "bow.polcy"},
+		},
+		{
+			name: "type mismatches",
+			values: `
+bow:
+  policy: all
+  approvals: "two"
+  matchTag: "true"
+  notificationChannels: chan1
+  images:
+    - repository: image.repository
+      tag: image.tag
+`,
+			wantInvalid: []string{
+				"bow.approvals: expected integer, got string",
+				"bow.matchTag: expected boolean, got string",
+				"bow.notificationChannels: expected array, got string",
+			},
+		},
+		{
+			name: "fractional integer",
+			values: `
+bow:
+  policy: all
+  approvalDeadline: 1.5
+`,
+			wantInvalid: []string{"bow.approvalDeadline: expected integer, got number"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vals, err := chartutil.ReadValues([]byte(tt.values))
+			if err != nil {
+				t.Fatalf("failed to read values: %s", err)
+			}
+
+			schemaErr := validateBowConfig(vals["bow"])
+			if tt.wantUnknown == nil && tt.wantInvalid == nil {
+				if schemaErr != nil {
+					t.Fatalf("unexpected error: %s", schemaErr)
+				}
+				return
+			}
+			if schemaErr == nil {
+				t.Fatalf("expected schema error")
+			}
+			if !reflect.DeepEqual(schemaErr.Unknown, tt.wantUnknown) {
+				t.Errorf("unexpected unknown fields: %v, want %v", schemaErr.Unknown, tt.wantUnknown)
+			}
+			if !reflect.DeepEqual(schemaErr.Invalid, tt.wantInvalid) {
+				t.Errorf("unexpected type mismatches: %v, want %v", schemaErr.Invalid, tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestGetbowConfigMisspelledPolicy(t *testing.T) {
+	vals, _ := chartutil.ReadValues([]byte(`
+bow:
+  polcy: all
+  images:
+    - repository: image.repository
+      tag: image.tag
+`))
+
+	_, err := getbowConfig(vals)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if !strings.Contains(err.Error(), "unknown fields: bow.polcy") {
+		t.Errorf("expected unknown field in error, got: %s", err)
+	}
+}
+
+func TestGetTrackedReleasesUnknownFields(t *testing.T) {
+	vals := `
+image:
+  repository: gcr.io/v2-namespace/hello-world
+  tag: 1.1.0
+
+bow:
+  policy: all
+  trigger: poll
+  pollSchedul: "@every 1m"
+  images:
+    - repository: image.repository
+      tag: image.tag
+`
+
+	fakeImpl := &fakeImplementer{
+		listReleasesResponse: &rls.ListReleasesResponse{
+			Releases: []*hapi_release5.Release{
+				&hapi_release5.Release{
+					Name:      "release-1",
+					Namespace: "default",
+					Chart: &chart.Chart{
+						Values:   &chart.Config{Raw: vals},
+						Metadata: &chart.Metadata{Name: "app-x"},
+					},
+					Config: &chart.Config{Raw: ""},
+				},
+			},
+		},
+	}
+
+	sender := &fakeSender{}
+	prov := NewProvider(fakeImpl, sender, approver(), nil, nil, nil, nil)
+
+	for i := 0; i < 3; i++ {
+		tracked, err := prov.TrackedImages()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(tracked) != 1 {
+			t.Fatalf("expected release to be tracked despite unknown fields, found: %d", len(tracked))
+		}
+	}
+
+	if len(sender.sentEvents) != 1 {
+		t.Fatalf("expected 1 notification, got: %d", len(sender.sentEvents))
+	}
+	if sender.sentEvents[0].Level != types.LevelWarn {
+		t.Errorf("unexpected level: %s", sender.sentEvents[0].Level)
+	}
+	if !strings.Contains(sender.sentEvents[0].Message, "bow.pollSchedul") {
+		t.Errorf("expected unknown field in message, got: %s", sender.sentEvents[0].Message)
+	}
+}
@@ -0,0 +1,85 @@
+package helm
+
+import (
+	"fmt"
+
+	"github.com/alwinius/bow/secrets"
+	"github.com/alwinius/bow/types"
+
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	core_v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SecretResolver - resolves image pull secrets referenced in bow chart config
+// into registry credentials
+type SecretResolver interface {
+	Resolve(namespace, name string, image *types.TrackedImage) (*types.Credentials, error)
+}
+
+// KubernetesSecretResolver - resolves kubernetes.io/dockerconfigjson secrets
+type KubernetesSecretResolver struct {
+	client core_v1.SecretsGetter
+}
+
+// NewKubernetesSecretResolver - create new kubernetes secret resolver
+func NewKubernetesSecretResolver(client core_v1.SecretsGetter) *KubernetesSecretResolver {
+	return &KubernetesSecretResolver{client: client}
+}
+
+// Resolve - gets secret and looks up credentials for image registry
+func (r *KubernetesSecretResolver) Resolve(namespace, name string, image *types.TrackedImage) (*types.Credentials, error) {
+	secret, err := r.client.Secrets(namespace).Get(name, meta_v1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if secret.Type != v1.SecretTypeDockerConfigJson {
+		return nil, fmt.Errorf("unexpected secret type '%s', expected %s", secret.Type, v1.SecretTypeDockerConfigJson)
+	}
+
+	cfg, err := secrets.DecodeDockerCfgJson(secret.Data[v1.DockerConfigJsonKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret: %s", err)
+	}
+
+	creds, found := secrets.CredentialsFromConfig(image, cfg)
+	if !found {
+		return nil, fmt.Errorf("secret has no credentials for registry %s", image.Image.Registry())
+	}
+
+	return creds, nil
+}
+
+// resolveCredentials - sets credentials from the first image pull secret that
+// has them, polling falls back to credentials helpers otherwise
+func (p *Provider) resolveCredentials(image *types.TrackedImage) {
+	if image.WorkloadIdentity() || len(image.Secrets) == 0 {
+		return
+	}
+	if p.secretResolver == nil {
+		log.WithFields(log.Fields{
+			"secrets":   image.Secrets,
+			"namespace": image.Namespace,
+			"image":     image.Image.Repository(),
+		}).Warn("provider.helm: kubernetes API is not available, image pull secrets can't be resolved")
+		return
+	}
+
+	for _, name := range image.Secrets {
+		creds, err := p.secretResolver.Resolve(image.Namespace, name, image)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":     err,
+				"secret":    name,
+				"namespace": image.Namespace,
+				"image":     image.Image.Repository(),
+			}).Warn("provider.helm: failed to resolve image pull secret")
+			continue
+		}
+		image.Credentials = creds
+		return
+	}
+}
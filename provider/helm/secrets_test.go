@@ -0,0 +1,208 @@
+package helm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alwinius/bow/secrets"
+	"github.com/alwinius/bow/types"
+
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	core_v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	hapi_release5 "k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+type fakeSecrets struct {
+	core_v1.SecretInterface
+	secrets map[string]*v1.Secret
+}
+
+func (s *fakeSecrets) Get(name string, options meta_v1.GetOptions) (*v1.Secret, error) {
+	secret, ok := s.secrets[name]
+	if !ok {
+		return nil, fmt.Errorf("secret %s not found", name)
+	}
+	return secret, nil
+}
+
+type fakeSecretsGetter struct {
+	namespaces map[string]*fakeSecrets
+}
+
+func (g *fakeSecretsGetter) Secrets(namespace string) core_v1.SecretInterface {
+	if s, ok := g.namespaces[namespace]; ok {
+		return s
+	}
+	return &fakeSecrets{}
+}
+
+func dockerConfigJSONSecret(registry, username, password string) *v1.Secret {
+	data, err := secrets.EncodeDockerCfgJson(&secrets.DockerCfg{
+		registry: &secrets.Auth{
+			Auth: secrets.EncodeBase64Secret(username, password),
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &v1.Secret{
+		Type: v1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{v1.DockerConfigJsonKey: data},
+	}
+}
+
+func TestKubernetesSecretResolver(t *testing.T) {
+	resolver := NewKubernetesSecretResolver(&fakeSecretsGetter{
+		namespaces: map[string]*fakeSecrets{
+			"default": &fakeSecrets{
+				secrets: map[string]*v1.Secret{
+					"registry-creds": dockerConfigJSONSecret("quay.io", "user-1", "secret"),
+					"opaque":         &v1.Secret{Type: v1.SecretTypeOpaque},
+				},
+			},
+		},
+	})
+
+	img := &types.TrackedImage{Image: mustParse("quay.io/bow/app:1.0.0"), Namespace: "default"}
+
+	creds, err := resolver.Resolve("default", "registry-creds", img)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if creds.Username != "user-1" || creds.Password != "secret" {
+		t.Errorf("unexpected credentials: %v", creds)
+	}
+
+	_, err = resolver.Resolve("default", "opaque", img)
+	if err == nil {
+		t.Errorf("expected error for secret of unexpected type")
+	}
+
+	_, err = resolver.Resolve("default", "registry-creds", &types.TrackedImage{Image: mustParse("gcr.io/v2-namespace/hello-world:1.1.0")})
+	if err == nil {
+		t.Errorf("expected error for registry without credentials")
+	}
+}
+
+func TestGetTrackedReleasesImagePullSecret(t *testing.T) {
+	chartVals := `
+image:
+  repository: quay.io/bow/app
+  tag: 1.1.0
+
+bow:
+  policy: all
+  trigger: poll
+  images:
+    - repository: image.repository
+      tag: image.tag
+      imagePullSecret: registry-creds
+`
+
+	fakeImpl := &fakeImplementer{
+		listReleasesResponse: &rls.ListReleasesResponse{
+			Releases: []*hapi_release5.Release{
+				&hapi_release5.Release{
+					Name:      "release-1",
+					Namespace: "default",
+					Chart: &chart.Chart{
+						Values:   &chart.Config{Raw: chartVals},
+						Metadata: &chart.Metadata{Name: "app-x"},
+					},
+					Config: &chart.Config{Raw: ""},
+				},
+			},
+		},
+	}
+
+	resolver := NewKubernetesSecretResolver(&fakeSecretsGetter{
+		namespaces: map[string]*fakeSecrets{
+			"default": &fakeSecrets{
+				secrets: map[string]*v1.Secret{
+					"registry-creds": dockerConfigJSONSecret("quay.io", "user-1", "secret"),
+				},
+			},
+		},
+	})
+
+	prov := NewProvider(fakeImpl, &fakeSender{}, approver(), resolver, nil, nil, nil)
+
+	tracked, err := prov.TrackedImages()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(tracked) != 1 {
+		t.Fatalf("expected 1 tracked image, got: %d", len(tracked))
+	}
+
+	if tracked[0].Credentials == nil {
+		t.Fatalf("expected credentials to be resolved from image pull secret")
+	}
+	if tracked[0].Credentials.Username != "user-1" || tracked[0].Credentials.Password != "secret" {
+		t.Errorf("unexpected credentials: %v", tracked[0].Credentials)
+	}
+}
+
+func TestGetTrackedReleasesWorkloadIdentity(t *testing.T) {
+	chartVals := `
+image:
+  repository: europe-west1-docker.pkg.dev/project/repo/app
+  tag: 1.1.0
+
+bow:
+  policy: all
+  trigger: poll
+  useWorkloadIdentity: true
+  images:
+    - repository: image.repository
+      tag: image.tag
+      imagePullSecret: registry-creds
+`
+
+	fakeImpl := &fakeImplementer{
+		listReleasesResponse: &rls.ListReleasesResponse{
+			Releases: []*hapi_release5.Release{
+				&hapi_release5.Release{
+					Name:      "release-1",
+					Namespace: "default",
+					Chart: &chart.Chart{
+						Values:   &chart.Config{Raw: chartVals},
+						Metadata: &chart.Metadata{Name: "app-x"},
+					},
+					Config: &chart.Config{Raw: ""},
+				},
+			},
+		},
+	}
+
+	resolver := NewKubernetesSecretResolver(&fakeSecretsGetter{
+		namespaces: map[string]*fakeSecrets{
+			"default": &fakeSecrets{
+				secrets: map[string]*v1.Secret{
+					"registry-creds": dockerConfigJSONSecret("europe-west1-docker.pkg.dev", "user-1", "secret"),
+				},
+			},
+		},
+	})
+
+	prov := NewProvider(fakeImpl, &fakeSender{}, approver(), resolver, nil, nil, nil)
+
+	tracked, err := prov.TrackedImages()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(tracked) != 1 {
+		t.Fatalf("expected 1 tracked image, got: %d", len(tracked))
+	}
+	if !tracked[0].UseWorkloadIdentity {
+		t.Errorf("expected image to use workload identity")
+	}
+	if tracked[0].Credentials != nil {
+		t.Errorf("expected image pull secret to be ignored, got: %v", tracked[0].Credentials)
+	}
+}
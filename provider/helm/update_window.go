@@ -0,0 +1,59 @@
+package helm
+
+import (
+	"context"
+	"time"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/pending"
+	"github.com/alwinius/bow/util/timeutil"
+	"github.com/alwinius/bow/util/trace"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// checkUpdateWindows - splits plans into the ones that can be applied now and
+// the ones that have to wait for their update window. Returned time is when the
+// earliest deferred window opens, zero if nothing was deferred
+func checkUpdateWindows(plans []*UpdatePlan, now time.Time) (ready []*UpdatePlan, next time.Time) {
+	var windows pending.Windows
+	for _, plan := range plans {
+		var window *timeutil.UpdateWindow
+		if plan.Config != nil && plan.Config.UpdateWindow != "" {
+			var err error
+			window, err = timeutil.ParseUpdateWindow(plan.Config.UpdateWindow)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"error":     err,
+					"name":      plan.Name,
+					"namespace": plan.Namespace,
+				}).Error("provider.helm: invalid update window, skipping update")
+				continue
+			}
+		}
+
+		opens := windows.Defer(window, now)
+		if opens.IsZero() {
+			ready = append(ready, plan)
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"name":          plan.Name,
+			"namespace":     plan.Namespace,
+			"update_window": window.String(),
+			"opens":         opens,
+		}).Info("provider.helm: outside of update window, deferring update")
+	}
+
+	return ready, windows.Next()
+}
+
+// requeue - submits deferred event back to the event loop once its window opens,
+// replayed event gets a new trace ID and skips deduplication
+func (p *Provider) requeue(event *types.Event) {
+	select {
+	case p.events <- &queuedEvent{ctx: trace.NewContext(context.Background()), event: event, replay: true}:
+	case <-p.stop:
+	}
+}
@@ -0,0 +1,98 @@
+package helm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/timeutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	hapi_release5 "k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+var updateWindowValues = `
+image:
+  repository: karolisr/webhook-demo
+  tag: 0.0.10
+
+bow:
+  policy: all
+  trigger: poll
+  updateWindow: "* 2-3 * * 1-5"
+  images:
+    - repository: image.repository
+      tag: image.tag
+`
+
+func TestUpdateReleaseUpdateWindow(t *testing.T) {
+	defer func() { timeutil.Now = time.Now }()
+
+	tests := []struct {
+		name        string
+		now         time.Time
+		wantUpdated bool
+		wantQueued  bool
+	}{
+		{
+			name:        "inside window",
+			now:         time.Date(2024, 1, 12, 2, 30, 0, 0, time.UTC),
+			wantUpdated: true,
+		},
+		{
+			name:       "outside window",
+			now:        time.Date(2024, 1, 12, 12, 0, 0, 0, time.UTC),
+			wantQueued: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeutil.Now = func() time.Time {
+				return tt.now
+			}
+
+			myChart := &chart.Chart{
+				Values: &chart.Config{Raw: updateWindowValues},
+			}
+
+			fakeImpl := &fakeImplementer{
+				listReleasesResponse: &rls.ListReleasesResponse{
+					Releases: []*hapi_release5.Release{
+						&hapi_release5.Release{
+							Name:   "release-1",
+							Chart:  myChart,
+							Config: &chart.Config{Raw: ""},
+						},
+					},
+				},
+			}
+
+			provider := NewProvider(fakeImpl, &fakeSender{}, approver(), nil, nil, nil, nil)
+			defer provider.Stop()
+
+			err := provider.processEvent(context.Background(), &types.Event{
+				Repository: types.Repository{
+					Name: "karolisr/webhook-demo",
+					Tag:  "0.0.11",
+				},
+			})
+			if err != nil {
+				t.Errorf("failed to process event, error: %s", err)
+			}
+
+			if updated := fakeImpl.updatedRlsName == "release-1"; updated != tt.wantUpdated {
+				t.Errorf("expected release updated: %v, got: %v", tt.wantUpdated, updated)
+			}
+
+			_, until, queued := provider.deferred.Get(&types.Repository{Name: "karolisr/webhook-demo", Tag: "0.0.11"})
+			if queued != tt.wantQueued {
+				t.Fatalf("expected event queued: %v, got: %v", tt.wantQueued, queued)
+			}
+			if queued && !until.Equal(time.Date(2024, 1, 15, 2, 0, 0, 0, time.UTC)) {
+				t.Errorf("unexpected re-queue time: %s", until)
+			}
+		})
+	}
+}
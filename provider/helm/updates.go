@@ -1,16 +1,26 @@
 package helm
 
 import (
+	"context"
+	"strings"
+
 	"github.com/alwinius/bow/internal/policy"
 	"github.com/alwinius/bow/types"
 	"github.com/alwinius/bow/util/image"
+	"github.com/alwinius/bow/util/trace"
 
 	hapi_chart "k8s.io/helm/pkg/proto/hapi/chart"
 
 	log "github.com/sirupsen/logrus"
 )
 
-func checkRelease(repo *types.Repository, namespace, name string, chart *hapi_chart.Chart, config *hapi_chart.Config) (plan *UpdatePlan, shouldUpdateRelease bool, err error) {
+func checkRelease(ctx context.Context, repo *types.Repository, namespace, name string, chart *hapi_chart.Chart, config *hapi_chart.Config) (plan *UpdatePlan, shouldUpdateRelease bool, err error) {
+	return checkParsedRelease(ctx, repo, namespace, name, chart, parseRelease(chart, config))
+}
+
+// checkParsedRelease - same as checkRelease, values and bow configuration
+// are taken from the already parsed release
+func checkParsedRelease(ctx context.Context, repo *types.Repository, namespace, name string, chart *hapi_chart.Chart, parsed *parsedRelease) (plan *UpdatePlan, shouldUpdateRelease bool, err error) {
 
 	plan = &UpdatePlan{
 		Chart:     chart,
@@ -21,7 +31,7 @@ func checkRelease(repo *types.Repository, namespace, name string, chart *hapi_ch
 
 	eventRepoRef, err := image.Parse(repo.String())
 	if err != nil {
-		log.WithFields(log.Fields{
+		trace.Log(ctx).WithFields(log.Fields{
 			"error":           err,
 			"repository_name": repo.Name,
 		}).Error("provider.helm: failed to parse event repository name")
@@ -29,27 +39,27 @@ func checkRelease(repo *types.Repository, namespace, name string, chart *hapi_ch
 	}
 
 	// getting configuration
-	vals, err := values(chart, config)
+	vals, err := parsed.vals, parsed.valsErr
 	if err != nil {
-		log.WithFields(log.Fields{
+		trace.Log(ctx).WithFields(log.Fields{
 			"error": err,
 		}).Error("provider.helm: failed to get values.yaml for release")
 		return
 	}
 
-	bowCfg, err := getbowConfig(vals)
+	bowCfg, err := parsed.cfg, parsed.cfgErr
 	if err != nil {
 		if err == ErrPolicyNotSpecified {
 			// nothing to do
 			return plan, false, nil
 		}
-		log.WithFields(log.Fields{
+		trace.Log(ctx).WithFields(log.Fields{
 			"error": err,
 		}).Error("provider.helm: failed to get bow configuration for release")
 		// ignoring this release, no bow config found
 		return plan, false, nil
 	}
-	log.Infof("policy for release %s/%s parsed: %s", namespace, name, bowCfg.Plc.Name())
+	trace.Log(ctx).Infof("policy for release %s/%s parsed: %s", namespace, name, bowCfg.Plc.Name())
 
 	if bowCfg.Plc.Type() == policy.PolicyTypeNone {
 		// policy is not set, ignoring release
@@ -60,7 +70,7 @@ func checkRelease(repo *types.Repository, namespace, name string, chart *hapi_ch
 	for _, imageDetails := range bowCfg.Images {
 		imageRef, err := parseImage(vals, &imageDetails)
 		if err != nil {
-			log.WithFields(log.Fields{
+			trace.Log(ctx).WithFields(log.Fields{
 				"error":           err,
 				"repository_name": imageDetails.RepositoryPath,
 				"repository_tag":  imageDetails.TagPath,
@@ -69,16 +79,44 @@ func checkRelease(repo *types.Repository, namespace, name string, chart *hapi_ch
 		}
 
 		if imageRef.Repository() != eventRepoRef.Repository() {
-			log.WithFields(log.Fields{
+			trace.Log(ctx).WithFields(log.Fields{
 				"parsed_image_name": imageRef.Remote(),
 				"target_image_name": repo.Name,
 			}).Debug("provider.helm: images do not match, ignoring")
 			continue
 		}
 
-		shouldUpdate, err := bowCfg.Plc.ShouldUpdate(imageRef.Tag(), eventRepoRef.Tag())
+		if repo.Platform != "" && imageDetails.Platform != "" && imageDetails.Platform != repo.Platform {
+			trace.Log(ctx).WithFields(log.Fields{
+				"parsed_image_name": imageRef.Remote(),
+				"platform":          imageDetails.Platform,
+				"target_platform":   repo.Platform,
+			}).Debug("provider.helm: image uses a different platform, ignoring")
+			continue
+		}
+
+		if imageDetails.TagSuffix != "" && !strings.HasSuffix(eventRepoRef.Tag(), imageDetails.TagSuffix) {
+			trace.Log(ctx).WithFields(log.Fields{
+				"parsed_image_name": imageRef.Remote(),
+				"tag_suffix":        imageDetails.TagSuffix,
+				"target_tag":        eventRepoRef.Tag(),
+			}).Debug("provider.helm: tag does not have the image tag suffix, ignoring")
+			continue
+		}
+
+		plc, err := bowCfg.imagePolicy(&imageDetails)
 		if err != nil {
-			log.WithFields(log.Fields{
+			trace.Log(ctx).WithFields(log.Fields{
+				"error":           err,
+				"repository_name": imageDetails.RepositoryPath,
+				"repository_tag":  imageDetails.TagPath,
+			}).Error("provider.helm: failed to get policy for image")
+			continue
+		}
+
+		shouldUpdate, err := plc.ShouldUpdate(imageRef.Tag(), eventRepoRef.Tag())
+		if err != nil {
+			trace.Log(ctx).WithFields(log.Fields{
 				"error":           err,
 				"repository_name": imageDetails.RepositoryPath,
 				"repository_tag":  imageDetails.TagPath,
@@ -87,40 +125,63 @@ func checkRelease(repo *types.Repository, namespace, name string, chart *hapi_ch
 		}
 
 		if !shouldUpdate {
-			log.WithFields(log.Fields{
+			trace.Log(ctx).WithFields(log.Fields{
 				"parsed_image_name": imageRef.Remote(),
 				"target_image_name": repo.Name,
-				"policy":            bowCfg.Plc.Name(),
+				"policy":            plc.Name(),
 			}).Info("provider.helm: ignoring")
 			continue
 		}
 
-		// if bowCfg.MatchTag && imageRef.Tag() != eventRepoRef.Tag() {
-		// 	log.WithFields(log.Fields{
-		// 		"parsed_image_name": imageRef.Remote(),
-		// 		"target_image_name": repo.Name,
-		// 		"policy":            bowCfg.Policy.String(),
-		// 	}).Info("provider.helm: match tag set but tags do not match, ignoring")
-		// 	continue
-		// }
+		// digest can only be compared when the chart sets it
+		if policy.MatchDigest(plc) {
+			currentDigest, _ := getValueAsString(vals, imageDetails.DigestPath)
+			if imageDetails.DigestPath == "" || repo.Digest == "" || repo.Digest == currentDigest {
+				trace.Log(ctx).WithFields(log.Fields{
+					"parsed_image_name": imageRef.Remote(),
+					"digest_path":       imageDetails.DigestPath,
+					"digest":            repo.Digest,
+				}).Debug("provider.helm: digest path is not set, digest is unknown or did not change, ignoring")
+				continue
+			}
+		}
 
 		if imageDetails.DigestPath != "" {
 			plan.Values[imageDetails.DigestPath] = repo.Digest
-			log.WithFields(log.Fields{
+			trace.Log(ctx).WithFields(log.Fields{
 				"image_details_digestPath": imageDetails.DigestPath,
 				"target_image_digest":      repo.Digest,
 			}).Debug("provider.helm: setting image Digest")
 		}
 
+		if plan.CurrentValues == nil {
+			plan.CurrentValues = make(map[string]string)
+		}
+		if imageDetails.DigestPath != "" {
+			plan.CurrentValues[imageDetails.DigestPath], _ = getValueAsString(vals, imageDetails.DigestPath)
+		}
+		plan.CurrentValues[imageDetails.RepositoryPath], _ = getValueAsString(vals, imageDetails.RepositoryPath)
+		if imageDetails.TagPath != "" {
+			plan.CurrentValues[imageDetails.TagPath] = imageRef.Tag()
+		}
+
 		path, value := getUnversionedPlanValues(repo.Tag, imageRef, &imageDetails)
 		plan.Values[path] = value
 		plan.NewVersion = repo.Tag
 		plan.CurrentVersion = imageRef.Tag()
 		plan.Config = bowCfg
+		plan.Platform = repo.Platform
 		shouldUpdateRelease = true
 		if imageDetails.ReleaseNotes != "" {
 			plan.ReleaseNotes = append(plan.ReleaseNotes, imageDetails.ReleaseNotes)
 		}
+		if imageDetails.ReleaseNotesLabel != "" {
+			plan.releaseNotesLabels = append(plan.releaseNotesLabels, &releaseNotesLabel{
+				image:  imageRef.Repository() + ":" + repo.Tag,
+				label:  imageDetails.ReleaseNotesLabel,
+				secret: imageDetails.ImagePullSecret,
+			})
+		}
 	}
 
 	return plan, shouldUpdateRelease, nil
@@ -1,6 +1,7 @@
 package helm
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -104,6 +105,7 @@ bow:
 				Name:           "release-1",
 				Chart:          helloWorldChart,
 				Values:         map[string]string{"image.tag": "latest"},
+				CurrentValues:  map[string]string{"image.repository": "gcr.io/v2-namespace/hello-world", "image.tag": "1.1.0"},
 				CurrentVersion: "1.1.0",
 				NewVersion:     "latest",
 				Config: &bowChartConfig{
@@ -135,6 +137,7 @@ bow:
 				Name:           "release-1",
 				Chart:          helloWorldChartPolicyMajorReleaseNotes,
 				Values:         map[string]string{"image.tag": "1.2.0"},
+				CurrentValues:  map[string]string{"image.repository": "gcr.io/v2-namespace/hello-world", "image.tag": "1.1.0"},
 				CurrentVersion: "1.1.0",
 				NewVersion:     "1.2.0",
 				ReleaseNotes:   []string{"https://github.com/alwinius/bow/releases"},
@@ -174,7 +177,7 @@ bow:
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotPlan, gotShouldUpdateRelease, err := checkRelease(tt.args.repo, tt.args.namespace, tt.args.name, tt.args.chart, tt.args.config)
+			gotPlan, gotShouldUpdateRelease, err := checkRelease(context.Background(), tt.args.repo, tt.args.namespace, tt.args.name, tt.args.chart, tt.args.config)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("checkRelease() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -315,6 +318,7 @@ image:
 				Name:           "release-1",
 				Chart:          helloWorldChart,
 				Values:         map[string]string{"image.tag": "1.1.2"},
+				CurrentValues:  map[string]string{"image.repository": "gcr.io/v2-namespace/hello-world", "image.tag": "1.1.0"},
 				NewVersion:     "1.1.2",
 				CurrentVersion: "1.1.0",
 				Config: &bowChartConfig{
@@ -371,6 +375,7 @@ image:
 				Name:           "release-1",
 				Chart:          helloWorldNonSemverChart,
 				Values:         map[string]string{"image.tag": "1.1.0"},
+				CurrentValues:  map[string]string{"image.repository": "gcr.io/v2-namespace/hello-world", "image.tag": "alpha"},
 				NewVersion:     "1.1.0",
 				CurrentVersion: "alpha",
 				Config: &bowChartConfig{
@@ -414,6 +419,7 @@ image:
 				Name:           "release-1-no-tag",
 				Chart:          helloWorldNoTagChart,
 				Values:         map[string]string{"image.repository": "gcr.io/v2-namespace/hello-world:1.1.0"},
+				CurrentValues:  map[string]string{"image.repository": "gcr.io/v2-namespace/hello-world:1.0.0"},
 				NewVersion:     "1.1.0",
 				CurrentVersion: "1.0.0",
 				Config: &bowChartConfig{
@@ -445,7 +451,7 @@ image:
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotPlan, gotShouldUpdateRelease, err := checkRelease(tt.args.repo, tt.args.namespace, tt.args.name, tt.args.chart, tt.args.config)
+			gotPlan, gotShouldUpdateRelease, err := checkRelease(context.Background(), tt.args.repo, tt.args.namespace, tt.args.name, tt.args.chart, tt.args.config)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("checkRelease() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -459,3 +465,189 @@ image:
 		})
 	}
 }
+
+func Test_checkReleaseManifestList(t *testing.T) {
+	chartValues := `
+image:
+  repository: gcr.io/v2-namespace/hello-world
+  tag: 1.1.0
+  digest: sha256:aaa
+imageArm:
+  repository: gcr.io/v2-namespace/hello-world
+  tag: 1.1.0
+  digest: sha256:bbb
+
+bow:
+  policy: force
+  matchTag: true
+  trigger: poll
+  images:
+    - repository: image.repository
+      tag: image.tag
+      digest: image.digest
+      platform: linux/amd64
+    - repository: imageArm.repository
+      tag: imageArm.tag
+      digest: imageArm.digest
+      platform: linux/arm64
+`
+	helloWorldChart := &hapi_chart.Chart{
+		Values:   &hapi_chart.Config{Raw: chartValues},
+		Metadata: &hapi_chart.Metadata{Name: "app-x"},
+	}
+
+	// only linux/amd64 entry of the manifest list changed
+	repo := &types.Repository{
+		Name:     "gcr.io/v2-namespace/hello-world",
+		Tag:      "1.1.0",
+		Digest:   "sha256:ccc",
+		Platform: "linux/amd64",
+	}
+
+	plan, shouldUpdate, err := checkRelease(context.Background(), repo, "default", "release-1", helloWorldChart, &hapi_chart.Config{Raw: ""})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !shouldUpdate {
+		t.Fatalf("expected release to be updated")
+	}
+
+	if plan.Platform != "linux/amd64" {
+		t.Errorf("unexpected platform: %s", plan.Platform)
+	}
+	if plan.Values["image.digest"] != "sha256:ccc" {
+		t.Errorf("unexpected amd64 digest: %s", plan.Values["image.digest"])
+	}
+	if _, ok := plan.Values["imageArm.digest"]; ok {
+		t.Errorf("arm64 image should not be updated, got values: %v", plan.Values)
+	}
+}
+
+func Test_checkReleaseDigestPolicy(t *testing.T) {
+	chartValues := `
+image:
+  repository: gcr.io/v2-namespace/hello-world
+  tag: 1.2.3
+  digest: sha256:aaa
+sidecar:
+  repository: gcr.io/v2-namespace/sidecar
+  tag: 1.2.3
+
+bow:
+  policy: digest
+  trigger: poll
+  images:
+    - repository: image.repository
+      tag: image.tag
+      digest: image.digest
+    - repository: sidecar.repository
+      tag: sidecar.tag
+`
+	chart := &hapi_chart.Chart{
+		Values: &hapi_chart.Config{Raw: chartValues},
+	}
+
+	tests := []struct {
+		name       string
+		repo       *types.Repository
+		wantUpdate bool
+	}{
+		{
+			name:       "rebuilt tag",
+			repo:       &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.3", Digest: "sha256:bbb"},
+			wantUpdate: true,
+		},
+		{
+			name:       "same digest",
+			repo:       &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.3", Digest: "sha256:aaa"},
+			wantUpdate: false,
+		},
+		{
+			name:       "unknown digest",
+			repo:       &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.3"},
+			wantUpdate: false,
+		},
+		{
+			name:       "different tag",
+			repo:       &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.4", Digest: "sha256:bbb"},
+			wantUpdate: false,
+		},
+		{
+			name:       "no digest path",
+			repo:       &types.Repository{Name: "gcr.io/v2-namespace/sidecar", Tag: "1.2.3", Digest: "sha256:bbb"},
+			wantUpdate: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, shouldUpdate, err := checkRelease(context.Background(), tt.repo, "default", "release-1", chart, &hapi_chart.Config{Raw: ""})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if shouldUpdate != tt.wantUpdate {
+				t.Fatalf("expected update: %v, got: %v", tt.wantUpdate, shouldUpdate)
+			}
+			if shouldUpdate && plan.Values["image.digest"] != tt.repo.Digest {
+				t.Errorf("unexpected digest: %s", plan.Values["image.digest"])
+			}
+		})
+	}
+}
+
+func Test_checkReleaseImageMatchTag(t *testing.T) {
+	chartValues := `
+image:
+  repository: gcr.io/v2-namespace/hello-world
+  tag: 1.1.0
+sidecar:
+  repository: gcr.io/v2-namespace/sidecar
+  tag: 1.1.0
+
+bow:
+  policy: force
+  trigger: poll
+  images:
+    - repository: image.repository
+      tag: image.tag
+    - repository: sidecar.repository
+      tag: sidecar.tag
+      matchTag: true
+
+`
+	chart := &hapi_chart.Chart{
+		Values: &hapi_chart.Config{Raw: chartValues},
+	}
+
+	tests := []struct {
+		name       string
+		repo       *types.Repository
+		wantUpdate bool
+	}{
+		{
+			name:       "image without matchTag is force updated",
+			repo:       &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "latest"},
+			wantUpdate: true,
+		},
+		{
+			name:       "image with matchTag ignores different tag",
+			repo:       &types.Repository{Name: "gcr.io/v2-namespace/sidecar", Tag: "latest"},
+			wantUpdate: false,
+		},
+		{
+			name:       "image with matchTag is updated on same tag",
+			repo:       &types.Repository{Name: "gcr.io/v2-namespace/sidecar", Tag: "1.1.0", Digest: "sha256:aaa"},
+			wantUpdate: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, shouldUpdate, err := checkRelease(context.Background(), tt.repo, "default", "release-1", chart, &hapi_chart.Config{Raw: ""})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if shouldUpdate != tt.wantUpdate {
+				t.Errorf("expected update: %v, got: %v", tt.wantUpdate, shouldUpdate)
+			}
+		})
+	}
+}
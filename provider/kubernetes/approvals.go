@@ -1,12 +1,16 @@
 package kubernetes
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alwinius/bow/pkg/store"
 	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/image"
+	"github.com/alwinius/bow/util/trace"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -16,12 +20,12 @@ func getApprovalIdentifier(resourceIdentifier, version string) string {
 }
 
 // checkForApprovals - filters out deployments and only passes forward approved ones
-func (p *Provider) checkForApprovals(event *types.Event, plans []*UpdatePlan) (approvedPlans []*UpdatePlan) {
+func (p *Provider) checkForApprovals(ctx context.Context, event *types.Event, plans []*UpdatePlan) (approvedPlans []*UpdatePlan) {
 	approvedPlans = []*UpdatePlan{}
 	for _, plan := range plans {
-		approved, err := p.isApproved(event, plan)
+		approved, err := p.isApproved(ctx, event, plan)
 		if err != nil {
-			log.WithFields(log.Fields{
+			trace.Log(ctx).WithFields(log.Fields{
 				"error":     err,
 				"name":      plan.Resource.Name,
 				"namespace": plan.Resource.Namespace,
@@ -35,6 +39,32 @@ func (p *Provider) checkForApprovals(event *types.Event, plans []*UpdatePlan) (a
 	return approvedPlans
 }
 
+// getImagesPreview - describes each container image changed by the plan,
+// ie: container app: gcr.io/v2-namespace/myapp 1.0.0 → 1.1.0
+func getImagesPreview(event *types.Event, plan *UpdatePlan) []string {
+	eventRef, err := image.Parse(event.Repository.String())
+	if err != nil {
+		return nil
+	}
+
+	var preview []string
+	for _, c := range resourceContainers(plan.Resource) {
+		ref, err := image.Parse(c.Image)
+		if err != nil {
+			continue
+		}
+		if ref.Repository() != eventRef.Repository() || ref.Tag() != plan.NewVersion {
+			continue
+		}
+		kind := "container"
+		if c.init {
+			kind = "init container"
+		}
+		preview = append(preview, fmt.Sprintf("%s %s: %s %s → %s", kind, c.Name, ref.Repository(), plan.CurrentVersion, plan.NewVersion))
+	}
+	return preview
+}
+
 // updateComplete is called after we successfully update resource
 func (p *Provider) updateComplete(plan *UpdatePlan) error {
 	return p.approvalManager.Archive(getApprovalIdentifier(plan.Resource.Identifier, plan.NewVersion))
@@ -68,7 +98,7 @@ func getInt(key string, labels map[string]string, annotations map[string]string)
 	return 0, nil
 }
 
-func (p *Provider) isApproved(event *types.Event, plan *UpdatePlan) (bool, error) {
+func (p *Provider) isApproved(ctx context.Context, event *types.Event, plan *UpdatePlan) (bool, error) {
 
 	minApprovals, err := getInt(types.BowMinimumApprovalsLabel, plan.Resource.GetLabels(), plan.Resource.GetAnnotations())
 	if err != nil {
@@ -83,7 +113,7 @@ func (p *Provider) isApproved(event *types.Event, plan *UpdatePlan) (bool, error
 	deadline := types.BowApprovalDeadlineDefault
 	d, err := getInt(types.BowApprovalDeadlineLabel, plan.Resource.GetLabels(), plan.Resource.GetAnnotations())
 	if err != nil {
-		log.WithFields(log.Fields{
+		trace.Log(ctx).WithFields(log.Fields{
 			"error":    err,
 			"resource": plan.Resource.GetName(),
 		}).Warn("failed to parse approvals deadline, using default value")
@@ -114,14 +144,16 @@ func (p *Provider) isApproved(event *types.Event, plan *UpdatePlan) (bool, error
 				NewVersion:     plan.NewVersion,
 				VotesRequired:  minApprovals,
 				VotesReceived:  0,
+				AllowedVoters:  types.ParseApprovalVoters(plan.Resource.GetAnnotations()),
 				Rejected:       false,
 				Deadline:       time.Now().Add(time.Duration(deadline) * time.Hour),
 			}
 
-			approval.Message = fmt.Sprintf("New image is available for resource %s/%s (%s).",
+			approval.Message = fmt.Sprintf("New image is available for resource %s/%s (%s): %s",
 				plan.Resource.Namespace,
 				plan.Resource.Name,
 				approval.Delta(),
+				strings.Join(getImagesPreview(event, plan), ", "),
 			)
 
 			return false, p.approvalManager.Create(approval)
@@ -137,7 +169,7 @@ func (p *Provider) isApproved(event *types.Event, plan *UpdatePlan) (bool, error
 	// 	}
 	// 	return false, nil
 	// }
-	// log.WithFields(log.Fields{
+	// trace.Log(ctx).WithFields(log.Fields{
 	// 	"previous": existing.Digest,
 	// 	"new":      event.Repository.Digest,
 	// }).Info("digests match")
@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -13,17 +14,7 @@ import (
 )
 
 func TestCheckRequestedApproval(t *testing.T) {
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 	deployments := []*apps_v1.Deployment{
 		{
 			meta_v1.TypeMeta{},
@@ -53,7 +44,7 @@ func TestCheckRequestedApproval(t *testing.T) {
 	grc.Add(grs...)
 
 	approver := approver()
-	provider, err := NewProvider(fp, &fakeSender{}, approver, grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver, Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -63,7 +54,7 @@ func TestCheckRequestedApproval(t *testing.T) {
 		Tag:  "1.1.2",
 	}
 
-	deps, err := provider.processEvent(&types.Event{Repository: repo})
+	deps, err := provider.processEvent(context.Background(), &types.Event{Repository: repo})
 	if err != nil {
 		t.Errorf("failed to get deployments: %s", err)
 	}
@@ -84,17 +75,7 @@ func TestCheckRequestedApproval(t *testing.T) {
 }
 
 func TestCheckRequestedApprovalAnnotation(t *testing.T) {
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 	deployments := []*apps_v1.Deployment{
 		{
 			meta_v1.TypeMeta{},
@@ -128,7 +109,7 @@ func TestCheckRequestedApprovalAnnotation(t *testing.T) {
 	grc.Add(grs...)
 
 	approver := approver()
-	provider, err := NewProvider(fp, &fakeSender{}, approver, grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver, Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -138,7 +119,7 @@ func TestCheckRequestedApprovalAnnotation(t *testing.T) {
 		Tag:  "1.1.2",
 	}
 
-	deps, err := provider.processEvent(&types.Event{Repository: repo})
+	deps, err := provider.processEvent(context.Background(), &types.Event{Repository: repo})
 	if err != nil {
 		t.Errorf("failed to get deployments: %s", err)
 	}
@@ -166,17 +147,7 @@ func TestCheckRequestedApprovalAnnotation(t *testing.T) {
 }
 
 func TestApprovedCheck(t *testing.T) {
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 	deployments := []*apps_v1.Deployment{
 		{
 			meta_v1.TypeMeta{},
@@ -205,7 +176,7 @@ func TestApprovedCheck(t *testing.T) {
 	grc.Add(grs...)
 
 	approver := approver()
-	provider, err := NewProvider(fp, &fakeSender{}, approver, grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver, Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -235,7 +206,7 @@ func TestApprovedCheck(t *testing.T) {
 		Tag:  "1.1.2",
 	}
 
-	deps, err := provider.processEvent(&types.Event{Repository: repo})
+	deps, err := provider.processEvent(context.Background(), &types.Event{Repository: repo})
 	if err != nil {
 		t.Errorf("failed to get deployments: %s", err)
 	}
@@ -246,17 +217,7 @@ func TestApprovedCheck(t *testing.T) {
 }
 
 func TestApprovalsCleanup(t *testing.T) {
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 	deployments := []*apps_v1.Deployment{
 		{
 			meta_v1.TypeMeta{},
@@ -285,7 +246,7 @@ func TestApprovalsCleanup(t *testing.T) {
 	grc.Add(grs...)
 
 	approver := approver()
-	provider, err := NewProvider(fp, &fakeSender{}, approver, grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver, Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -315,7 +276,7 @@ func TestApprovalsCleanup(t *testing.T) {
 		Tag:  "1.1.2",
 	}
 
-	deps, err := provider.processEvent(&types.Event{Repository: repo})
+	deps, err := provider.processEvent(context.Background(), &types.Event{Repository: repo})
 	if err != nil {
 		t.Errorf("failed to get deployments: %s", err)
 	}
@@ -324,14 +285,51 @@ func TestApprovalsCleanup(t *testing.T) {
 		t.Errorf("expected to find 1 updated deployment but found %d", len(deps))
 	}
 
-	// no approvals expected
+	// approval is archived once resource is updated
 
-	approvals, err := provider.approvalManager.List()
-	if err != nil {
-		t.Fatalf("failed to get a list of approvals: %s", err)
+	_, err = provider.approvalManager.Get("deployment/xxxx/dep-1:1.1.2")
+	if err == nil {
+		t.Errorf("expected approval to be archived")
+	}
+}
+
+func TestGetImagesPreview(t *testing.T) {
+	plan := &UpdatePlan{
+		Resource: MustParseGR(&apps_v1.Deployment{
+			meta_v1.TypeMeta{},
+			meta_v1.ObjectMeta{
+				Name:      "dep-1",
+				Namespace: "xxxx",
+			},
+			apps_v1.DeploymentSpec{
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{
+							v1.Container{
+								Name:  "app",
+								Image: "gcr.io/v2-namespace/hello-world:1.1.2",
+							},
+							v1.Container{
+								Name:  "sidecar",
+								Image: "gcr.io/v2-namespace/sidecar:1.1.2",
+							},
+						},
+					},
+				},
+			},
+			apps_v1.DeploymentStatus{},
+		}),
+		CurrentVersion: "1.1.1",
+		NewVersion:     "1.1.2",
 	}
 
-	if len(approvals) != 0 {
-		t.Errorf("expected to find 0 but found %d", len(approvals))
+	event := &types.Event{Repository: types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.2"}}
+
+	preview := getImagesPreview(event, plan)
+	if len(preview) != 1 {
+		t.Fatalf("expected 1 changed image, got: %v", preview)
+	}
+	if preview[0] != "container app: gcr.io/v2-namespace/hello-world 1.1.1 → 1.1.2" {
+		t.Errorf("unexpected preview: %s", preview[0])
 	}
 }
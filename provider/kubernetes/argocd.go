@@ -0,0 +1,65 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/trace"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ArgoCDSyncer - syncs ArgoCD applications, ie: argocd.Client
+type ArgoCDSyncer interface {
+	Sync(app string) error
+}
+
+// syncArgoCD - force syncs ArgoCD application of the updated resource so the new
+// image from git is deployed instead of ArgoCD reverting the drift
+func (p *Provider) syncArgoCD(ctx context.Context, resource *k8s.GenericResource, app string) {
+	if p.argoCD == nil {
+		trace.Log(ctx).WithFields(log.Fields{
+			"name":      resource.Name,
+			"namespace": resource.Namespace,
+			"app":       app,
+		}).Warn("provider.kubernetes: ArgoCD is not configured, application won't be synced")
+		return
+	}
+
+	err := p.argoCD.Sync(app)
+	if err != nil {
+		trace.Log(ctx).WithFields(log.Fields{
+			"error":     err,
+			"name":      resource.Name,
+			"namespace": resource.Namespace,
+			"app":       app,
+		}).Error("provider.kubernetes: failed to sync ArgoCD application")
+
+		p.sender.Send(types.EventNotification{
+			ResourceKind: resource.Kind(),
+			Identifier:   resource.Identifier,
+			Name:         "sync ArgoCD application",
+			Message:      fmt.Sprintf("Failed to sync ArgoCD application %s after updating %s %s/%s: %s", app, resource.Kind(), resource.Namespace, resource.Name, err),
+			CreatedAt:    time.Now(),
+			Type:         types.NotificationDeploymentUpdate,
+			Level:        types.LevelError,
+			Channels:     types.ParseEventNotificationChannels(resource.GetAnnotations()),
+			Metadata: map[string]string{
+				"provider":  p.GetName(),
+				"namespace": resource.GetNamespace(),
+				"name":      resource.GetName(),
+				"app":       app,
+			},
+		})
+		return
+	}
+
+	trace.Log(ctx).WithFields(log.Fields{
+		"name":      resource.Name,
+		"namespace": resource.Namespace,
+		"app":       app,
+	}).Info("provider.kubernetes: ArgoCD application synced")
+}
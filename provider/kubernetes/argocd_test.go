@@ -0,0 +1,74 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alwinius/bow/extension/notification"
+	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/types"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeArgoCDSyncer struct {
+	synced []string
+	err    error
+}
+
+func (s *fakeArgoCDSyncer) Sync(app string) error {
+	s.synced = append(s.synced, app)
+	return s.err
+}
+
+type recordingSender struct {
+	events []types.EventNotification
+}
+
+func (s *recordingSender) Configure(cfg *notification.Config) (bool, error) { return true, nil }
+
+func (s *recordingSender) Send(event types.EventNotification) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func TestSyncArgoCD(t *testing.T) {
+	resource, err := k8s.NewGenericResource(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "dep-1",
+			Namespace:   "xxxx",
+			Annotations: map[string]string{types.BowArgoCDAppAnnotation: " team-a/guestbook "},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create resource: %s", err)
+	}
+	app := types.ParseArgoCDApp(resource.GetAnnotations())
+	if app != "team-a/guestbook" {
+		t.Fatalf("unexpected app: %s", app)
+	}
+
+	syncer := &fakeArgoCDSyncer{}
+	sender := &recordingSender{}
+	p := &Provider{argoCD: syncer, sender: sender}
+
+	p.syncArgoCD(context.Background(), resource, app)
+	if len(syncer.synced) != 1 || syncer.synced[0] != "team-a/guestbook" {
+		t.Errorf("unexpected synced apps: %v", syncer.synced)
+	}
+	if len(sender.events) != 0 {
+		t.Errorf("didn't expect notifications, got: %d", len(sender.events))
+	}
+
+	syncer.err = errors.New("permission denied")
+	p.syncArgoCD(context.Background(), resource, app)
+	if len(sender.events) != 1 || sender.events[0].Level != types.LevelError {
+		t.Fatalf("expected error notification, got: %v", sender.events)
+	}
+
+	// not configured
+	p = &Provider{sender: sender}
+	p.syncArgoCD(context.Background(), resource, app)
+}
@@ -0,0 +1,91 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/util/trace"
+
+	policy_v1beta1 "k8s.io/api/policy/v1beta1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DisruptionBudgetBackoff - how long updates of resources whose pods are protected
+// by a pod disruption budget that doesn't allow disruptions are deferred
+var DisruptionBudgetBackoff = 60 * time.Second
+
+// checkDisruptionBudgets - plans of resources whose pods are covered by a pod disruption
+// budget without allowed disruptions are deferred by DisruptionBudgetBackoff, rolling
+// them out now would violate the budget. Plans are applied when budgets can't be listed
+func (p *Provider) checkDisruptionBudgets(ctx context.Context, plans []*UpdatePlan, now time.Time) (ready []*UpdatePlan, next time.Time) {
+	if p.disruptionBudgets == nil {
+		return plans, next
+	}
+
+	budgets := make(map[string][]policy_v1beta1.PodDisruptionBudget)
+
+	for _, plan := range plans {
+		resource := plan.Resource
+		if plan.CurrentVersion == plan.NewVersion && plan.Digest == "" {
+			ready = append(ready, plan)
+			continue
+		}
+
+		nsBudgets, ok := budgets[resource.Namespace]
+		if !ok {
+			var err error
+			nsBudgets, err = p.disruptionBudgets.ListDisruptionBudgets(resource.Namespace)
+			if err != nil {
+				trace.Log(ctx).WithFields(log.Fields{
+					"error":     err,
+					"namespace": resource.Namespace,
+				}).Warn("provider.kubernetes: failed to list pod disruption budgets, updating without checking them")
+			}
+			budgets[resource.Namespace] = nsBudgets
+		}
+
+		blocking := blockingDisruptionBudget(resource, nsBudgets)
+		if blocking == "" {
+			ready = append(ready, plan)
+			continue
+		}
+
+		trace.Log(ctx).WithFields(log.Fields{
+			"name":                  resource.Name,
+			"namespace":             resource.Namespace,
+			"kind":                  resource.Kind(),
+			"pod_disruption_budget": blocking,
+			"retry_in":              DisruptionBudgetBackoff,
+		}).Info("provider.kubernetes: pod disruption budget doesn't allow disruptions, deferring update")
+
+		next = now.Add(DisruptionBudgetBackoff)
+	}
+
+	return ready, next
+}
+
+// blockingDisruptionBudget - name of the budget selecting resource pods that
+// currently doesn't allow any disruptions
+func blockingDisruptionBudget(resource *k8s.GenericResource, budgets []policy_v1beta1.PodDisruptionBudget) string {
+	podLabels := labels.Set(resource.GetSpecLabels())
+
+	for _, budget := range budgets {
+		// empty policy/v1beta1 selectors select no pods
+		if budget.Spec.Selector == nil || (len(budget.Spec.Selector.MatchLabels) == 0 && len(budget.Spec.Selector.MatchExpressions) == 0) {
+			continue
+		}
+		selector, err := meta_v1.LabelSelectorAsSelector(budget.Spec.Selector)
+		if err != nil || !selector.Matches(podLabels) {
+			continue
+		}
+		if budget.Status.PodDisruptionsAllowed == 0 {
+			return budget.Name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,120 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/internal/k8s"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	core_v1 "k8s.io/api/core/v1"
+	policy_v1beta1 "k8s.io/api/policy/v1beta1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeDisruptionBudgetLister struct {
+	budgets []policy_v1beta1.PodDisruptionBudget
+	err     error
+	calls   int
+}
+
+func (l *fakeDisruptionBudgetLister) ListDisruptionBudgets(namespace string) ([]policy_v1beta1.PodDisruptionBudget, error) {
+	l.calls++
+	return l.budgets, l.err
+}
+
+func disruptionBudget(name string, matchLabels map[string]string, allowed int32) policy_v1beta1.PodDisruptionBudget {
+	return policy_v1beta1.PodDisruptionBudget{
+		ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: "xxxx"},
+		Spec: policy_v1beta1.PodDisruptionBudgetSpec{
+			Selector: &meta_v1.LabelSelector{MatchLabels: matchLabels},
+		},
+		Status: policy_v1beta1.PodDisruptionBudgetStatus{PodDisruptionsAllowed: allowed},
+	}
+}
+
+func TestCheckDisruptionBudgets(t *testing.T) {
+	defer func(backoff time.Duration) { DisruptionBudgetBackoff = backoff }(DisruptionBudgetBackoff)
+	DisruptionBudgetBackoff = time.Minute
+
+	gr, err := k8s.NewGenericResource(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "dep-1", Namespace: "xxxx"},
+		Spec: apps_v1.DeploymentSpec{
+			Template: core_v1.PodTemplateSpec{
+				ObjectMeta: meta_v1.ObjectMeta{Labels: map[string]string{"app": "web", "tier": "frontend"}},
+				Spec: core_v1.PodSpec{
+					Containers: []core_v1.Container{{Image: "gcr.io/v2-namespace/hello-world:1.1.1"}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create resource: %s", err)
+	}
+	plan := &UpdatePlan{Resource: gr, CurrentVersion: "1.1.1", NewVersion: "1.1.2"}
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		lister    *fakeDisruptionBudgetLister
+		wantReady bool
+	}{
+		{
+			name:      "no budgets",
+			lister:    &fakeDisruptionBudgetLister{},
+			wantReady: true,
+		},
+		{
+			name: "disruptions allowed",
+			lister: &fakeDisruptionBudgetLister{budgets: []policy_v1beta1.PodDisruptionBudget{
+				disruptionBudget("web", map[string]string{"app": "web"}, 1),
+			}},
+			wantReady: true,
+		},
+		{
+			name: "other pods",
+			lister: &fakeDisruptionBudgetLister{budgets: []policy_v1beta1.PodDisruptionBudget{
+				disruptionBudget("api", map[string]string{"app": "api"}, 0),
+				disruptionBudget("empty", nil, 0),
+			}},
+			wantReady: true,
+		},
+		{
+			name: "no disruptions allowed",
+			lister: &fakeDisruptionBudgetLister{budgets: []policy_v1beta1.PodDisruptionBudget{
+				disruptionBudget("api", map[string]string{"app": "api"}, 0),
+				disruptionBudget("frontend", map[string]string{"tier": "frontend"}, 0),
+			}},
+		},
+		{
+			name:      "api error",
+			lister:    &fakeDisruptionBudgetLister{err: errors.New("forbidden")},
+			wantReady: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Provider{disruptionBudgets: tt.lister}
+			ready, next := p.checkDisruptionBudgets(context.Background(), []*UpdatePlan{plan, plan}, now)
+
+			if tt.wantReady {
+				if len(ready) != 2 || !next.IsZero() {
+					t.Errorf("expected plans to be ready, got: %d, next: %s", len(ready), next)
+				}
+			} else {
+				if len(ready) != 0 || !next.Equal(now.Add(time.Minute)) {
+					t.Errorf("expected plans to be deferred by a minute, got: %d, next: %s", len(ready), next)
+				}
+			}
+
+			// budgets are listed once per namespace
+			if tt.lister.calls != 1 {
+				t.Errorf("expected budgets to be listed once, listed %d times", tt.lister.calls)
+			}
+		})
+	}
+}
@@ -1,14 +1,17 @@
 package kubernetes
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/alwinius/bow/internal/gitrepo"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Masterminds/semver"
-	"github.com/rusenask/cron"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -17,36 +20,64 @@ import (
 	"github.com/alwinius/bow/internal/k8s"
 	"github.com/alwinius/bow/internal/policy"
 	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/circuit"
+	"github.com/alwinius/bow/util/deadletter"
+	"github.com/alwinius/bow/util/dedup"
 	"github.com/alwinius/bow/util/image"
+	"github.com/alwinius/bow/util/metrics"
+	"github.com/alwinius/bow/util/pending"
 	"github.com/alwinius/bow/util/policies"
+	"github.com/alwinius/bow/util/stopper"
+	"github.com/alwinius/bow/util/timeutil"
+	"github.com/alwinius/bow/util/trace"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	log "github.com/sirupsen/logrus"
 )
 
-var kubernetesVersionedUpdatesCounter = prometheus.NewCounterVec(
+var kubernetesVersionedUpdatesCounter = metrics.NewCounter(
 	prometheus.CounterOpts{
 		Name: "kubernetes_versioned_updates_total",
 		Help: "How many versioned deployments were updated, partitioned by deployment name.",
 	},
-	[]string{"kubernetes"},
+	"kubernetes",
 )
 
-var kubernetesUnversionedUpdatesCounter = prometheus.NewCounterVec(
+var kubernetesUnversionedUpdatesCounter = metrics.NewCounter(
 	prometheus.CounterOpts{
 		Name: "kubernetes_unversioned_updates_total",
 		Help: "How many unversioned deployments were updated, partitioned by deployment name.",
 	},
+	"kubernetes",
+)
+
+var invalidPolicyCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "invalid_policy_total",
+		Help: "How many invalid policies were found, repeated checks of the same invalid policy are counted once, partitioned by resource name.",
+	},
 	[]string{"kubernetes"},
 )
 
 func init() {
 	prometheus.MustRegister(kubernetesVersionedUpdatesCounter)
 	prometheus.MustRegister(kubernetesUnversionedUpdatesCounter)
+	prometheus.MustRegister(invalidPolicyCounter)
 }
 
 // ProviderName - provider name
 const ProviderName = "kubernetes"
 
+// StopTimeout - how long Stop waits for the event that is being processed,
+// Stop returns afterwards even if updates are still being pushed
+var StopTimeout = 20 * time.Second
+
+// EventDedupWindow - events for the same repository and tag received within the
+// window are processed once, ie: several webhooks fired for the same push
+var EventDedupWindow = 5 * time.Second
+
 var versionreg = regexp.MustCompile(`:[^:]*$`)
 
 // GenericResourceCache an interface for generic resource cache.
@@ -69,6 +100,27 @@ type UpdatePlan struct {
 	CurrentVersion string
 	// New version that's already in the deployment
 	NewVersion string
+
+	// Platform (os/arch) the update is for, set when event
+	// came from a multi-arch manifest list
+	Platform string
+	// Digest of the new image for the platform
+	Digest string
+
+	// Images - current images of all containers updated by the plan, containers
+	// can run the same repository with different tags, ie: app and debug sidecar
+	Images []string
+
+	// ReleaseNotes is a slice of combined release notes.
+	ReleaseNotes []string
+
+	// TargetVersion - version of the event, NewVersion is lower when the
+	// update is limited by bow/max-version-delta
+	TargetVersion string
+
+	// Window - bow/update-window of the resource policy, plans outside
+	// of it are deferred until it opens
+	Window *timeutil.UpdateWindow
 }
 
 func (p *UpdatePlan) String() string {
@@ -80,7 +132,7 @@ func (p *UpdatePlan) String() string {
 
 // Provider - kubernetes provider for auto update
 type Provider struct {
-	repo gitrepo.Repo
+	repo GitRepo
 
 	sender notification.Sender
 
@@ -88,25 +140,148 @@ type Provider struct {
 
 	cache GenericResourceCache
 
-	events chan *types.Event
+	// optional, records kubernetes events on updated resources
+	recorder k8s.EventRecorder
+
+	// optional, used by resources with minimum image age,
+	// skipping unchanged digests or limiting version deltas
+	registryClient RegistryClient
+
+	// optional, used by resources that roll back failed updates
+	deployments k8s.DeploymentGetter
+
+	// optional, updates are deferred while pod disruption
+	// budgets don't allow disruptions
+	disruptionBudgets k8s.DisruptionBudgetLister
+
+	// optional, resolves image pull secrets of tracked resources
+	// so polling can authenticate to private registries
+	secretResolver SecretResolver
+
+	// optional, syncs ArgoCD applications of updated resources
+	argoCD ArgoCDSyncer
+
+	// registry credentials of tracked images are taken from
+	// the GKE metadata server
+	useWorkloadIdentity bool
+
+	// invalid poll schedules that users were already notified about,
+	// map[resource identifier]schedule
+	invalidSchedules   map[string]string
+	invalidSchedulesMu sync.Mutex
+
+	// invalid policies that users were already notified about,
+	// map[resource identifier]policy
+	invalidPolicies   map[string]string
+	invalidPoliciesMu sync.Mutex
+
+	// events waiting for update windows to open
+	deferred *pending.Events
+
+	// recently processed events, duplicates are skipped
+	recent *dedup.Window
+
+	// breaker - suppresses events for images that keep failing
+	breaker *circuit.Breaker
+
+	// optional, events are added once their circuit opens
+	deadLetters *deadletter.Queue
+
+	// set atomically once event loop started
+	started int32
+
+	// event loop, Stop waits for the event that is being processed
+	running sync.WaitGroup
+
+	events chan *queuedEvent
 	stop   chan struct{}
 }
 
+// GitRepo - repository with manifests of the tracked resources, updates are
+// pushed to it
+type GitRepo interface {
+	GrepAndReplace(oldImage string, newTag string)
+	// SetSpecAnnotations - sets spec template annotations of the resource
+	// manifests, annotations with empty values are removed
+	SetSpecAnnotations(kind, name string, annotations map[string]string)
+	// SetImagePullPolicy - sets image pull policy of the resource containers
+	// running the image
+	SetImagePullPolicy(kind, name, image, pullPolicy string)
+	// SaveConfigMap - writes config map manifest, existing one is replaced
+	SaveConfigMap(namespace, name string, data map[string]string)
+	CommitAndPushAll(msg string) error
+}
+
+// Opts - kubernetes provider dependencies, only Cache is required
+type Opts struct {
+	Sender          notification.Sender
+	ApprovalManager approvals.Manager
+	Cache           GenericResourceCache
+	Repo            GitRepo
+
+	// Recorder - optional, records kubernetes events of updates
+	Recorder k8s.EventRecorder
+	// RegistryClient - optional, needed by minimum image age, unchanged
+	// digest and max version delta checks
+	RegistryClient RegistryClient
+	// Deployments - optional, failed updates are rolled back
+	Deployments k8s.DeploymentGetter
+	// DisruptionBudgets - optional, pod disruption budgets are checked
+	DisruptionBudgets k8s.DisruptionBudgetLister
+	// SecretResolver - optional, resolves image pull secrets
+	SecretResolver SecretResolver
+	// ArgoCD - optional, syncs ArgoCD applications of updated resources
+	ArgoCD ArgoCDSyncer
+	// UseWorkloadIdentity - registry credentials come from the
+	// GKE Workload Identity service account
+	UseWorkloadIdentity bool
+}
+
 // NewProvider - create new kubernetes based provider
-func NewProvider(sender notification.Sender, approvalManager approvals.Manager, cache GenericResourceCache, repo gitrepo.Repo) (*Provider, error) {
-	return &Provider{
-		cache:           cache,
-		approvalManager: approvalManager,
-		events:          make(chan *types.Event, 100),
-		stop:            make(chan struct{}),
-		sender:          sender,
-		repo:            repo,
-	}, nil
+func NewProvider(opts *Opts) (*Provider, error) {
+	p := &Provider{
+		cache:               opts.Cache,
+		recorder:            opts.Recorder,
+		registryClient:      opts.RegistryClient,
+		deployments:         opts.Deployments,
+		disruptionBudgets:   opts.DisruptionBudgets,
+		secretResolver:      opts.SecretResolver,
+		argoCD:              opts.ArgoCD,
+		useWorkloadIdentity: opts.UseWorkloadIdentity,
+		approvalManager:     opts.ApprovalManager,
+		invalidSchedules:    make(map[string]string),
+		invalidPolicies:     make(map[string]string),
+		breaker:             circuit.New(circuit.DefaultOpts),
+		recent:              dedup.New(EventDedupWindow),
+		events:              make(chan *queuedEvent, 100),
+		stop:                make(chan struct{}),
+		sender:              opts.Sender,
+		repo:                opts.Repo,
+	}
+	p.deferred = pending.New(p.requeue)
+	return p, nil
+}
+
+// queuedEvent - event waiting for the event loop, context carries its trace ID
+type queuedEvent struct {
+	ctx   context.Context
+	event *types.Event
+	// replay - deferred event or dead letter retry, these are never
+	// dropped as duplicates
+	replay bool
 }
 
-// Submit - submit event to provider
+// Submit - submit event to provider, event gets a trace ID that is
+// logged by every processing step
 func (p *Provider) Submit(event types.Event) error {
-	p.events <- &event
+	ctx := trace.NewContext(context.Background())
+	trace.Log(ctx).WithFields(log.Fields{
+		"image":   event.Repository.Name,
+		"tag":     event.Repository.Tag,
+		"trigger": event.TriggerName,
+	}).Debug("provider.kubernetes: event received")
+
+	p.events <- &queuedEvent{ctx: ctx, event: &event}
 	return nil
 }
 
@@ -120,9 +295,34 @@ func (p *Provider) Start() error {
 	return p.startInternal()
 }
 
-// Stop - stops kubernetes provider
+// Ready - provider is ready once its event loop started
+func (p *Provider) Ready() error {
+	if atomic.LoadInt32(&p.started) == 0 {
+		return errors.New("event loop not started")
+	}
+	return nil
+}
+
+// Stop - stops kubernetes provider, waits up to StopTimeout for the event that
+// is being processed so resources aren't left half-updated
 func (p *Provider) Stop() {
+	p.deferred.Stop()
 	close(p.stop)
+	if !stopper.WaitTimeout(&p.running, StopTimeout) {
+		log.WithFields(log.Fields{
+			"timeout": StopTimeout,
+		}).Warn("provider.kubernetes: updates didn't finish in time, stopping anyway")
+	}
+}
+
+// stopping - whether Stop was called
+func (p *Provider) stopping() bool {
+	select {
+	case <-p.stop:
+		return true
+	default:
+		return false
+	}
 }
 
 func getImagePullSecretFromMeta(labels map[string]string, annotations map[string]string) string {
@@ -144,6 +344,75 @@ func getImagePullSecretFromMeta(labels map[string]string, annotations map[string
 	return ""
 }
 
+// notifyInvalidSchedule - sends a warning once per resource and schedule, TrackedImages
+// is called on every poll manager scan so repeated notifications are suppressed
+func (p *Provider) notifyInvalidSchedule(gr *k8s.GenericResource, schedule string, err error) {
+	p.invalidSchedulesMu.Lock()
+	notified, ok := p.invalidSchedules[gr.Identifier]
+	p.invalidSchedules[gr.Identifier] = schedule
+	p.invalidSchedulesMu.Unlock()
+
+	if ok && notified == schedule {
+		return
+	}
+
+	p.sender.Send(types.EventNotification{
+		ResourceKind: gr.Kind(),
+		Identifier:   gr.Identifier,
+		Name:         "invalid poll schedule",
+		Message:      fmt.Sprintf("Invalid poll schedule '%s' for %s %s/%s, images will not be polled: %s", schedule, gr.Kind(), gr.Namespace, gr.Name, err),
+		CreatedAt:    time.Now(),
+		Type:         types.NotificationSystemEvent,
+		Level:        types.LevelWarn,
+		Channels:     types.ParseEventNotificationChannels(gr.GetAnnotations()),
+		Metadata: map[string]string{
+			"provider":  p.GetName(),
+			"namespace": gr.GetNamespace(),
+			"name":      gr.GetName(),
+		},
+	})
+}
+
+// invalidPolicy - skipped resources are counted and notified about once per
+// resource and invalid policy value
+func (p *Provider) invalidPolicy(gr *k8s.GenericResource, err error) {
+	value := err.Error()
+
+	p.invalidPoliciesMu.Lock()
+	notified, ok := p.invalidPolicies[gr.Identifier]
+	p.invalidPolicies[gr.Identifier] = value
+	p.invalidPoliciesMu.Unlock()
+
+	if ok && notified == value {
+		return
+	}
+
+	invalidPolicyCounter.With(prometheus.Labels{"kubernetes": fmt.Sprintf("%s/%s", gr.Namespace, gr.Name)}).Inc()
+
+	log.WithFields(log.Fields{
+		"error":     err,
+		"name":      gr.Name,
+		"kind":      gr.Kind(),
+		"namespace": gr.Namespace,
+	}).Error("provider.kubernetes: invalid policy, resource will be skipped")
+
+	p.sender.Send(types.EventNotification{
+		ResourceKind: gr.Kind(),
+		Identifier:   gr.Identifier,
+		Name:         "invalid policy",
+		Message:      fmt.Sprintf("Invalid policy for %s %s/%s, resource will not be updated: %s", gr.Kind(), gr.Namespace, gr.Name, err),
+		CreatedAt:    time.Now(),
+		Type:         types.NotificationSystemEvent,
+		Level:        types.LevelError,
+		Channels:     types.ParseEventNotificationChannels(gr.GetAnnotations()),
+		Metadata: map[string]string{
+			"provider":  p.GetName(),
+			"namespace": gr.GetNamespace(),
+			"name":      gr.GetName(),
+		},
+	})
+}
+
 // TrackedImages returns a list of tracked images.
 func (p *Provider) TrackedImages() ([]*types.TrackedImage, error) {
 	var trackedImages []*types.TrackedImage
@@ -153,22 +422,27 @@ func (p *Provider) TrackedImages() ([]*types.TrackedImage, error) {
 		annotations := gr.GetAnnotations()
 		// by default we want to track every deployment, not just specifically labeled (for now)
 		// NOT ignoring unlabelled deployments
-		plc := policy.GetPolicyFromLabelsOrAnnotations(labels, annotations)
+		plc, err := policy.GetPolicyFromLabelsOrAnnotations(labels, annotations)
+		if err != nil {
+			p.invalidPolicy(gr, err)
+			continue
+		}
 		//if plc.Type() == policy.PolicyTypeNone {
 		//	continue
 		//}
 
 		schedule, ok := annotations[types.BowPollScheduleAnnotation]
 		if ok {
-			_, err := cron.Parse(schedule)
+			_, err := timeutil.ParseSchedule(schedule)
 			if err != nil {
 				log.WithFields(log.Fields{
 					"error":     err,
 					"schedule":  schedule,
 					"name":      gr.Name,
 					"namespace": gr.Namespace,
-				}).Error("provider.kubernetes: failed to parse poll schedule, setting default schedule")
-				schedule = types.BowPollDefaultSchedule
+				}).Error("provider.kubernetes: failed to parse poll schedule, resource images will not be tracked")
+				p.notifyInvalidSchedule(gr, schedule, err)
+				continue
 			}
 		} else {
 			schedule = types.BowPollDefaultSchedule
@@ -178,14 +452,12 @@ func (p *Provider) TrackedImages() ([]*types.TrackedImage, error) {
 		trigger := policies.GetTriggerPolicy(labels, annotations)
 
 		// getting image pull secrets
-		var secrets []string
-		specifiedSecret := getImagePullSecretFromMeta(labels, annotations)
-		if specifiedSecret != "" {
-			secrets = append(secrets, specifiedSecret)
-		}
+		secrets := imagePullSecrets(gr)
 
+		kustomized := types.ParseKustomizeImages(annotations)
 		images := gr.GetImages()
 		for _, img := range images {
+			img = kustomizedImage(kustomized, img)
 			ref, err := image.Parse(img)
 			if err != nil {
 				log.WithFields(log.Fields{
@@ -205,31 +477,47 @@ func (p *Provider) TrackedImages() ([]*types.TrackedImage, error) {
 				}
 			}
 
-			trackedImages = append(trackedImages, &types.TrackedImage{
+			trackedImage := &types.TrackedImage{
 				Image:        ref,
 				PollSchedule: schedule,
 				Trigger:      trigger,
 				Provider:     ProviderName,
-				Meta:         make(map[string]string),
-				Policy:       plc,
-			})
+				Namespace:    gr.Namespace,
+				Secrets:      secrets,
+				Meta:         map[string]string{circuit.MetaKey: p.breaker.State(ref.Repository()).String()},
+				// events outside of the update window are deferred by the provider
+				Policy: policy.Unwrap(plc),
+
+				UseWorkloadIdentity: p.useWorkloadIdentity,
+			}
+			p.resolveCredentials(trackedImage)
+			trackedImages = append(trackedImages, trackedImage)
 		}
 	}
 	return trackedImages, nil
 }
 
 func (p *Provider) startInternal() error {
+	p.running.Add(1)
+	defer p.running.Done()
+
+	atomic.StoreInt32(&p.started, 1)
 	for {
 		select {
-		case event := <-p.events:
-			_, err := p.processEvent(event)
-			if err != nil {
-				log.WithFields(log.Fields{
-					"error": err,
-					"image": event.Repository.Name,
-					"tag":   event.Repository.Tag,
-				}).Error("provider.kubernetes: failed to process event")
+		case queued := <-p.events:
+			if p.stopping() {
+				log.Info("provider.kubernetes: got shutdown signal, stopping...")
+				return nil
+			}
+			// approved and replayed events are always applied
+			if !queued.replay && queued.event.TriggerName != types.TriggerTypeApproval.String() && p.recent.Seen(dedup.Key(&queued.event.Repository), timeutil.Now()) {
+				trace.Log(queued.ctx).WithFields(log.Fields{
+					"image": queued.event.Repository.Name,
+					"tag":   queued.event.Repository.Tag,
+				}).Debug("provider.kubernetes: same event was just processed, skipping")
+				continue
 			}
+			p.handleEvent(queued.ctx, queued.event)
 		case <-p.stop:
 			log.Info("provider.kubernetes: got shutdown signal, stopping...")
 			return nil
@@ -237,52 +525,94 @@ func (p *Provider) startInternal() error {
 	}
 }
 
-func (p *Provider) processEvent(event *types.Event) (updated []*k8s.GenericResource, err error) {
-	plans, err := p.createUpdatePlans(&event.Repository)
+func (p *Provider) processEvent(ctx context.Context, event *types.Event) (updated []*k8s.GenericResource, err error) {
+	plans, err := p.createUpdatePlans(ctx, &event.Repository)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(plans) == 0 {
-		log.WithFields(log.Fields{
+		trace.Log(ctx).WithFields(log.Fields{
 			"image": event.Repository.Name,
 			"tag":   event.Repository.Tag,
 		}).Debug("provider.kubernetes: no plans for deployment updates found for this event")
 		return
 	}
 
-	approvedPlans := p.checkForApprovals(event, plans)
+	// next step is taken once the intermediate update rolled out
+	if stepped(plans) {
+		p.deferred.Add(event, timeutil.Now().Add(VersionDeltaStepDelay))
+	}
+
+	approvedPlans := p.checkForApprovals(ctx, event, plans)
+
+	readyPlans, next := checkUpdateWindows(approvedPlans, timeutil.Now())
+	if !next.IsZero() {
+		p.deferred.Add(event, next)
+	}
+
+	readyPlans, next = p.checkMinAge(readyPlans, &event.Repository, timeutil.Now())
+	if !next.IsZero() {
+		p.deferred.Add(event, next)
+	}
+
+	readyPlans, next = p.checkDisruptionBudgets(ctx, readyPlans, timeutil.Now())
+	if !next.IsZero() {
+		p.deferred.Add(event, next)
+	}
 
-	return p.updateDeployments(approvedPlans)
+	return p.updateDeployments(ctx, readyPlans)
 }
 
-func (p *Provider) updateDeployments(plans []*UpdatePlan) (updated []*k8s.GenericResource, err error) {
+// updateDeployments - pushes updates of the plans, once the provider is stopping
+// the update that is being pushed finishes and the rest is skipped
+func (p *Provider) updateDeployments(ctx context.Context, plans []*UpdatePlan) (updated []*k8s.GenericResource, err error) {
 	for _, plan := range plans {
-		if plan.CurrentVersion == plan.NewVersion {
+		if plan.CurrentVersion == plan.NewVersion && plan.Digest == "" {
+			continue
+		}
+
+		if p.stopping() {
+			trace.Log(ctx).WithFields(log.Fields{
+				"name":      plan.Resource.Name,
+				"kind":      plan.Resource.Kind(),
+				"namespace": plan.Resource.Namespace,
+			}).Info("provider.kubernetes: provider is stopping, skipping update")
 			continue
 		}
 
 		resource := plan.Resource
 
 		annotations := resource.GetAnnotations()
+		plc, _ := policy.GetPolicyFromLabelsOrAnnotations(resource.GetLabels(), annotations)
 
 		notificationChannels := types.ParseEventNotificationChannels(annotations)
 
-		p.sender.Send(types.EventNotification{
-			ResourceKind: resource.Kind(),
-			Identifier:   resource.Identifier,
-			Name:         "preparing to update resource",
-			Message:      fmt.Sprintf("Preparing to update %s %s/%s %s->%s (%s)", resource.Kind(), resource.Namespace, resource.Name, plan.CurrentVersion, plan.NewVersion, strings.Join(resource.GetImages(), ", ")),
-			CreatedAt:    time.Now(),
-			Type:         types.NotificationPreDeploymentUpdate,
-			Level:        types.LevelDebug,
-			Channels:     notificationChannels,
-			Metadata: map[string]string{
-				"provider":  p.GetName(),
-				"namespace": resource.GetNamespace(),
-				"name":      resource.GetName(),
-			},
-		})
+		// same version is only updated because of a new digest, resources
+		// can opt out of notifications for these
+		notify := plan.CurrentVersion != plan.NewVersion || types.ParseNotifyOnNoUpdate(annotations)
+
+		if notify {
+			p.sender.Send(types.EventNotification{
+				ResourceKind: resource.Kind(),
+				Identifier:   resource.Identifier,
+				Name:         "preparing to update resource",
+				Message:      fmt.Sprintf("Preparing to update %s %s/%s %s->%s (%s)", resource.Kind(), resource.Namespace, resource.Name, plan.CurrentVersion, plan.NewVersion, strings.Join(resource.GetImages(), ", ")),
+				CreatedAt:    time.Now(),
+				Type:         types.NotificationPreDeploymentUpdate,
+				Level:        types.LevelDebug,
+				Channels:     notificationChannels,
+				Metadata: map[string]string{
+					"provider":                 p.GetName(),
+					"namespace":                resource.GetNamespace(),
+					"name":                     resource.GetName(),
+					"current_version":          plan.CurrentVersion,
+					"new_version":              plan.NewVersion,
+					types.ReleaseNotesMetadata: types.EncodeReleaseNotes(plan.ReleaseNotes),
+					"policy":                   plc.Name(),
+				},
+			})
+		}
 
 		var err error
 
@@ -291,58 +621,105 @@ func (p *Provider) updateDeployments(plans []*UpdatePlan) (updated []*k8s.Generi
 
 		resource.SetAnnotations(annotations)
 
-		for _, img := range resource.GetImages() { // maybe only one of multiple containers needs to be updated, so filter
-			parts := strings.Split(img, ":")
-			if len(parts) > 1 && parts[1] == plan.CurrentVersion { // images without a tag will be ignored
-				p.repo.GrepAndReplace(img, plan.NewVersion)
-				err := p.repo.CommitAndPushAll("updating " + img + " to " + plan.NewVersion)
-				if err != nil {
-					log.WithFields(log.Fields{
-						"error":      err,
-						"deployment": resource.Name,
-						"kind":       resource.Kind(),
-						"update":     fmt.Sprintf("%s->%s", plan.CurrentVersion, plan.NewVersion),
-					}).Error("provider.kubernetes: got error while committing and pushing")
+		setReleaseNotes(resource, plan.ReleaseNotes)
+
+		// invalid values were already reported while checking for the update
+		pullPolicy, _ := types.ParseImagePullPolicy(annotations)
+
+		updatedContainers := false
+		// new image -> previous tag, used to revert failed updates
+		rollbacks := make(map[string]string)
+		for _, img := range plan.Images { // ignored containers and other platforms were already filtered out
+			_, tag := image.SplitTag(img)
+			if tag == "" { // images without a tag will be ignored
+				continue
+			}
+			newVersion := plan.NewVersion
+			if plan.Digest != "" {
+				// pinning platform specific image
+				newVersion = newVersion + "@" + plan.Digest
+			}
+			p.repo.GrepAndReplace(img, newVersion)
+			if pullPolicy != "" {
+				p.repo.SetImagePullPolicy(resource.Kind(), resource.Name, gitrepo.ReplacedImage(img, newVersion), pullPolicy)
+			}
+			if !updatedContainers {
+				// annotations set while checking for the update only reach the
+				// cluster through the manifests, edits are idempotent so they
+				// are repeated until a commit succeeds
+				p.repo.SetSpecAnnotations(resource.Kind(), resource.Name, persistedSpecAnnotations(resource))
+				if len(plan.ReleaseNotes) > 0 {
+					p.repo.SaveConfigMap(resource.Namespace, releaseNotesConfigMap(resource), map[string]string{
+						ReleaseNotesConfigMapKey: strings.Join(plan.ReleaseNotes, ", "),
+					})
 				}
 			}
+			err := p.repo.CommitAndPushAll("updating " + img + " to " + newVersion)
+			if err != nil {
+				trace.Log(ctx).WithFields(log.Fields{
+					"error":      err,
+					"deployment": resource.Name,
+					"kind":       resource.Kind(),
+					"update":     fmt.Sprintf("%s->%s", tag, plan.NewVersion),
+				}).Error("provider.kubernetes: got error while committing and pushing")
+				continue
+			}
+			p.recordImageUpdated(resource, img, newVersion)
+			rollbacks[gitrepo.ReplacedImage(img, newVersion)] = tag
+			updatedContainers = true
+		}
+
+		if updatedContainers {
+			if app := types.ParseArgoCDApp(annotations); app != "" {
+				p.syncArgoCD(ctx, resource, app)
+			}
+			if window, ok := types.ParseRollbackOnFailure(annotations); ok {
+				p.watchRollback(plan, rollbacks, window)
+			}
 		}
 
-		kubernetesVersionedUpdatesCounter.With(prometheus.Labels{"kubernetes": fmt.Sprintf("%s/%s", resource.Namespace, resource.Name)}).Inc()
+		kubernetesVersionedUpdatesCounter.Inc(fmt.Sprintf("%s/%s", resource.Namespace, resource.Name))
 
 		err = p.updateComplete(plan)
 		if err != nil {
-			log.WithFields(log.Fields{
+			trace.Log(ctx).WithFields(log.Fields{
 				"error": err,
 				"name":  resource.Name,
 				"kind":  resource.Kind(),
 			}).Warn("provider.kubernetes: got error while resetting approvals counter after successful update")
 		}
 
-		var msg string
-		releaseNotes := types.ParseReleaseNotesURL(resource.GetAnnotations())
-		if releaseNotes != "" {
-			msg = fmt.Sprintf("Successfully updated %s %s/%s %s->%s (%s). Release notes: %s", resource.Kind(), resource.Namespace, resource.Name, plan.CurrentVersion, plan.NewVersion, strings.Join(resource.GetImages(), ", "), releaseNotes)
-		} else {
-			msg = fmt.Sprintf("Successfully updated %s %s/%s %s->%s (%s)", resource.Kind(), resource.Namespace, resource.Name, plan.CurrentVersion, plan.NewVersion, strings.Join(resource.GetImages(), ", "))
-		}
+		if notify {
+			var msg string
+			releaseNotes := types.ParseReleaseNotesURL(resource.GetAnnotations())
+			if releaseNotes != "" {
+				msg = fmt.Sprintf("Successfully updated %s %s/%s %s->%s (%s). Release notes: %s", resource.Kind(), resource.Namespace, resource.Name, plan.CurrentVersion, plan.NewVersion, strings.Join(resource.GetImages(), ", "), releaseNotes)
+			} else {
+				msg = fmt.Sprintf("Successfully updated %s %s/%s %s->%s (%s)", resource.Kind(), resource.Namespace, resource.Name, plan.CurrentVersion, plan.NewVersion, strings.Join(resource.GetImages(), ", "))
+			}
 
-		p.sender.Send(types.EventNotification{
-			ResourceKind: resource.Kind(),
-			Identifier:   resource.Identifier,
-			Name:         "update resource",
-			Message:      msg,
-			CreatedAt:    time.Now(),
-			Type:         types.NotificationDeploymentUpdate,
-			Level:        types.LevelSuccess,
-			Channels:     notificationChannels,
-			Metadata: map[string]string{
-				"provider":  p.GetName(),
-				"namespace": resource.GetNamespace(),
-				"name":      resource.GetName(),
-			},
-		})
+			p.sender.Send(types.EventNotification{
+				ResourceKind: resource.Kind(),
+				Identifier:   resource.Identifier,
+				Name:         "update resource",
+				Message:      msg,
+				CreatedAt:    time.Now(),
+				Type:         types.NotificationDeploymentUpdate,
+				Level:        types.LevelSuccess,
+				Channels:     notificationChannels,
+				Metadata: map[string]string{
+					"provider":                 p.GetName(),
+					"namespace":                resource.GetNamespace(),
+					"name":                     resource.GetName(),
+					"current_version":          plan.CurrentVersion,
+					"new_version":              plan.NewVersion,
+					types.ReleaseNotesMetadata: types.EncodeReleaseNotes(plan.ReleaseNotes),
+					"policy":                   plc.Name(),
+				},
+			})
+		}
 
-		log.WithFields(log.Fields{
+		trace.Log(ctx).WithFields(log.Fields{
 			"name":      resource.Name,
 			"kind":      resource.Kind(),
 			"previous":  plan.CurrentVersion,
@@ -355,8 +732,23 @@ func (p *Provider) updateDeployments(plans []*UpdatePlan) (updated []*k8s.Generi
 	return
 }
 
+// recordImageUpdated - emits kubernetes event on the updated resource
+func (p *Provider) recordImageUpdated(resource *k8s.GenericResource, oldImage, newVersion string) {
+	if p.recorder == nil {
+		return
+	}
+
+	obj, ok := resource.GetResource().(runtime.Object)
+	if !ok {
+		return
+	}
+
+	name, _ := image.SplitTag(oldImage)
+	p.recorder.Event(obj, v1.EventTypeNormal, "ImageUpdated", fmt.Sprintf("Image updated %s -> %s:%s", oldImage, name, newVersion))
+}
+
 // createUpdatePlans - impacted deployments by changed repository
-func (p *Provider) createUpdatePlans(repo *types.Repository) ([]*UpdatePlan, error) {
+func (p *Provider) createUpdatePlans(ctx context.Context, repo *types.Repository) ([]*UpdatePlan, error) {
 	impacted := []*UpdatePlan{}
 
 	for _, resource := range p.cache.Values() {
@@ -364,14 +756,29 @@ func (p *Provider) createUpdatePlans(repo *types.Repository) ([]*UpdatePlan, err
 		labels := resource.GetLabels()
 		annotations := resource.GetAnnotations()
 
-		plc := policy.GetPolicyFromLabelsOrAnnotations(labels, annotations)
+		plc, err := policy.GetPolicyFromLabelsOrAnnotations(labels, annotations)
+		if err != nil {
+			p.invalidPolicy(resource, err)
+			continue
+		}
 		if plc.Type() == policy.PolicyTypeNone {
 			continue
 		}
 
-		updated, shouldUpdateDeployment, err := checkForUpdate(plc, repo, resource)
+		if repo.Digest == "" && (types.ParseSkipUnchangedDigest(annotations) || policy.MatchDigest(plc)) {
+			repo = p.withResolvedDigest(repo, resource)
+		}
+
+		// update window is checked once the plans are approved, so updates outside
+		// of it are deferred instead of dropped
+		window := policy.Window(plc)
+		plc = policy.Unwrap(plc)
+
+		resourceRepo := p.withVersionDeltaStep(ctx, plc, repo, resource)
+
+		updated, shouldUpdateDeployment, err := checkForUpdate(ctx, plc, resourceRepo, resource)
 		if err != nil {
-			log.WithFields(log.Fields{
+			trace.Log(ctx).WithFields(log.Fields{
 				"error":      err,
 				"deployment": resource.Name,
 				"kind":       resource.Kind(),
@@ -381,6 +788,8 @@ func (p *Provider) createUpdatePlans(repo *types.Repository) ([]*UpdatePlan, err
 		}
 
 		if shouldUpdateDeployment {
+			updated.TargetVersion = repo.Tag
+			updated.Window = window
 			impacted = append(impacted, updated)
 		}
 	}
@@ -1,17 +1,26 @@
 package kubernetes
 
 import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/alwinius/bow/approvals"
 	"github.com/alwinius/bow/extension/notification"
+	"github.com/alwinius/bow/internal/gitrepo"
 	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/pkg/store/sql"
 	"github.com/alwinius/bow/types"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	apps_v1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	core_v1 "k8s.io/client-go/kubernetes/typed/core/v1"
 )
 
 type fakeProvider struct {
@@ -37,59 +46,59 @@ func (p *fakeProvider) GetName() string {
 	return "fp"
 }
 
-type fakeImplementer struct {
-	namespaces     *v1.NamespaceList
-	deployment     *apps_v1.Deployment
-	deploymentList *apps_v1.DeploymentList
-
-	podList     *v1.PodList
-	deletedPods []*v1.Pod
-
-	// stores value of an updated deployment
-	updated *k8s.GenericResource
-
-	availableSecret *v1.Secret
-}
-
-func (i *fakeImplementer) Namespaces() (*v1.NamespaceList, error) {
-	return i.namespaces, nil
-}
-
-func (i *fakeImplementer) Deployment(namespace, name string) (*apps_v1.Deployment, error) {
-	return i.deployment, nil
-}
-
-func (i *fakeImplementer) Deployments(namespace string) (*apps_v1.DeploymentList, error) {
-	return i.deploymentList, nil
+// fakeRepo - records images replaced in the manifests repository
+type fakeRepo struct {
+	// map[old image]new tag
+	replaced map[string]string
+	// map[kind/name]spec annotations written to the manifests
+	specAnnotations map[string]map[string]string
+	// map[kind/name/image]image pull policy
+	pullPolicies map[string]string
+	// map[namespace/name]config map data
+	configMaps map[string]map[string]string
+	commits    []string
 }
 
-func (i *fakeImplementer) Update(obj *k8s.GenericResource) error {
-	i.updated = obj
-	return nil
+func (r *fakeRepo) GrepAndReplace(oldImage string, newTag string) {
+	if r.replaced == nil {
+		r.replaced = make(map[string]string)
+	}
+	r.replaced[oldImage] = newTag
 }
 
-func (i *fakeImplementer) Secret(namespace, name string) (*v1.Secret, error) {
-	return i.availableSecret, nil
+func (r *fakeRepo) SetSpecAnnotations(kind, name string, annotations map[string]string) {
+	if r.specAnnotations == nil {
+		r.specAnnotations = make(map[string]map[string]string)
+	}
+	key := kind + "/" + name
+	if r.specAnnotations[key] == nil {
+		r.specAnnotations[key] = make(map[string]string)
+	}
+	for k, v := range annotations {
+		if v == "" {
+			delete(r.specAnnotations[key], k)
+			continue
+		}
+		r.specAnnotations[key][k] = v
+	}
 }
 
-func (i *fakeImplementer) Pods(namespace, labelSelector string) (*v1.PodList, error) {
-	return i.podList, nil
+func (r *fakeRepo) SetImagePullPolicy(kind, name, image, pullPolicy string) {
+	if r.pullPolicies == nil {
+		r.pullPolicies = make(map[string]string)
+	}
+	r.pullPolicies[kind+"/"+name+"/"+image] = pullPolicy
 }
 
-func (i *fakeImplementer) DeletePod(namespace, name string, opts *meta_v1.DeleteOptions) error {
-	i.deletedPods = append(i.deletedPods, &v1.Pod{
-		meta_v1.TypeMeta{},
-		meta_v1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-		},
-		v1.PodSpec{},
-		v1.PodStatus{},
-	})
-	return nil
+func (r *fakeRepo) SaveConfigMap(namespace, name string, data map[string]string) {
+	if r.configMaps == nil {
+		r.configMaps = make(map[string]map[string]string)
+	}
+	r.configMaps[namespace+"/"+name] = data
 }
 
-func (i *fakeImplementer) ConfigMaps(namespace string) core_v1.ConfigMapInterface {
+func (r *fakeRepo) CommitAndPushAll(msg string) error {
+	r.commits = append(r.commits, msg)
 	return nil
 }
 
@@ -107,39 +116,19 @@ func (s *fakeSender) Send(event types.EventNotification) error {
 }
 
 func approver() *approvals.DefaultManager {
-	cache := memory.NewMemoryCache()
-	return approvals.New(cache)
-}
-
-func TestGetNamespaces(t *testing.T) {
-	fi := &fakeImplementer{
-		namespaces: &v1.NamespaceList{
-			Items: []v1.Namespace{
-				v1.Namespace{
-					meta_v1.TypeMeta{},
-					meta_v1.ObjectMeta{Name: "xxxx"},
-					v1.NamespaceSpec{},
-					v1.NamespaceStatus{},
-				},
-			},
-		},
-	}
-
-	grc := &k8s.GenericResourceCache{}
-
-	provider, err := NewProvider(fi, &fakeSender{}, approver(), grc)
+	dir, err := ioutil.TempDir("", "kubernetesstoretest")
 	if err != nil {
-		t.Fatalf("failed to get provider: %s", err)
+		panic(err)
 	}
 
-	namespaces, err := provider.namespaces()
+	store, err := sql.New(sql.Opts{DatabaseType: "sqlite3", URI: filepath.Join(dir, "gorm.db")})
 	if err != nil {
-		t.Errorf("failed to get namespaces: %s", err)
+		panic(err)
 	}
 
-	if namespaces.Items[0].Name != "xxxx" {
-		t.Errorf("expected xxxx but got %s", namespaces.Items[0].Name)
-	}
+	return approvals.New(&approvals.Opts{
+		Store: store,
+	})
 }
 
 func TestGetImageName(t *testing.T) {
@@ -172,17 +161,7 @@ func MustParseGRS(objs []*apps_v1.Deployment) []*k8s.GenericResource {
 }
 
 func TestGetImpacted(t *testing.T) {
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 
 	deps := []*apps_v1.Deployment{
 		{
@@ -231,7 +210,7 @@ func TestGetImpacted(t *testing.T) {
 	grc := &k8s.GenericResourceCache{}
 	grc.Add(grs...)
 
-	provider, err := NewProvider(fp, &fakeSender{}, approver(), grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -242,7 +221,7 @@ func TestGetImpacted(t *testing.T) {
 		Tag:  "1.1.2",
 	}
 
-	plans, err := provider.createUpdatePlans(repo)
+	plans, err := provider.createUpdatePlans(context.Background(), repo)
 	if err != nil {
 		t.Errorf("failed to get deployments: %s", err)
 	}
@@ -267,17 +246,7 @@ func TestGetImpacted(t *testing.T) {
 
 }
 func TestGetImpactedPolicyAnnotations(t *testing.T) {
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 
 	deps := []*apps_v1.Deployment{
 		{
@@ -327,7 +296,7 @@ func TestGetImpactedPolicyAnnotations(t *testing.T) {
 	grc := &k8s.GenericResourceCache{}
 	grc.Add(grs...)
 
-	provider, err := NewProvider(fp, &fakeSender{}, approver(), grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -338,7 +307,7 @@ func TestGetImpactedPolicyAnnotations(t *testing.T) {
 		Tag:  "1.1.2",
 	}
 
-	plans, err := provider.createUpdatePlans(repo)
+	plans, err := provider.createUpdatePlans(context.Background(), repo)
 	if err != nil {
 		t.Errorf("failed to get deployments: %s", err)
 	}
@@ -368,17 +337,7 @@ func TestPrereleaseGetImpactedA(t *testing.T) {
 	// is to get one update plan for the second deployment. Deployment with prerelease tag
 	// should be ignored
 
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 
 	deps := []*apps_v1.Deployment{
 		{
@@ -427,7 +386,7 @@ func TestPrereleaseGetImpactedA(t *testing.T) {
 	grc := &k8s.GenericResourceCache{}
 	grc.Add(grs...)
 
-	provider, err := NewProvider(fp, &fakeSender{}, approver(), grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -438,7 +397,7 @@ func TestPrereleaseGetImpactedA(t *testing.T) {
 		Tag:  "1.1.2",
 	}
 
-	plans, err := provider.createUpdatePlans(repo)
+	plans, err := provider.createUpdatePlans(context.Background(), repo)
 	if err != nil {
 		t.Errorf("failed to get deployments: %s", err)
 	}
@@ -458,17 +417,7 @@ func TestPrereleaseGetImpactedB(t *testing.T) {
 	// is to get one update plan for the second deployment. Deployment with prerelease tag
 	// should be ignored
 
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 
 	deps := []*apps_v1.Deployment{
 		{
@@ -517,7 +466,7 @@ func TestPrereleaseGetImpactedB(t *testing.T) {
 	grc := &k8s.GenericResourceCache{}
 	grc.Add(grs...)
 
-	provider, err := NewProvider(fp, &fakeSender{}, approver(), grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -528,7 +477,7 @@ func TestPrereleaseGetImpactedB(t *testing.T) {
 		Tag:  "1.1.2-staging",
 	}
 
-	plans, err := provider.createUpdatePlans(repo)
+	plans, err := provider.createUpdatePlans(context.Background(), repo)
 	if err != nil {
 		t.Errorf("failed to get deployments: %s", err)
 	}
@@ -543,17 +492,7 @@ func TestPrereleaseGetImpactedB(t *testing.T) {
 }
 
 func TestProcessEvent(t *testing.T) {
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 	deps := []*apps_v1.Deployment{
 		{
 			meta_v1.TypeMeta{},
@@ -644,7 +583,7 @@ func TestProcessEvent(t *testing.T) {
 	grc := &k8s.GenericResourceCache{}
 	grc.Add(grs...)
 
-	provider, err := NewProvider(fp, &fakeSender{}, approver(), grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -655,32 +594,18 @@ func TestProcessEvent(t *testing.T) {
 	}
 
 	event := &types.Event{Repository: repo}
-	_, err = provider.processEvent(event)
+	_, err = provider.processEvent(context.Background(), event)
 	if err != nil {
 		t.Errorf("got error while processing event: %s", err)
 	}
 
-	if fp.updated == nil {
-		t.Fatalf("resource was not updated")
-	}
-
-	if fp.updated.Containers()[0].Image != repo.Name+":"+repo.Tag {
-		t.Errorf("expected to find a deployment with updated image but found: %s", fp.updated.Containers()[0].Image)
+	if tag := fp.replaced["gcr.io/v2-namespace/hello-world:1.1.1"]; tag != repo.Tag {
+		t.Errorf("expected image to be updated to %s but got: '%s'", repo.Tag, tag)
 	}
 }
 
 func TestProcessEventBuildNumber(t *testing.T) {
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 	deps := []*apps_v1.Deployment{
 		{
 			meta_v1.TypeMeta{},
@@ -709,7 +634,7 @@ func TestProcessEventBuildNumber(t *testing.T) {
 	grc := &k8s.GenericResourceCache{}
 	grc.Add(grs...)
 
-	provider, err := NewProvider(fp, &fakeSender{}, approver(), grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -720,28 +645,18 @@ func TestProcessEventBuildNumber(t *testing.T) {
 	}
 
 	event := &types.Event{Repository: repo}
-	_, err = provider.processEvent(event)
+	_, err = provider.processEvent(context.Background(), event)
 	if err != nil {
 		t.Errorf("got error while processing event: %s", err)
 	}
 
-	if fp.updated != nil {
-		t.Errorf("didn't expect to get updated containers, bot got: %s", fp.updated.Identifier)
+	if len(fp.replaced) != 0 {
+		t.Errorf("didn't expect to get updated images, but got: %v", fp.replaced)
 	}
 }
 
 func TestEventSent(t *testing.T) {
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 	deps := []*apps_v1.Deployment{
 		{
 			meta_v1.TypeMeta{},
@@ -771,7 +686,7 @@ func TestEventSent(t *testing.T) {
 	grc.Add(grs...)
 
 	fs := &fakeSender{}
-	provider, err := NewProvider(fp, fs, approver(), grc)
+	provider, err := NewProvider(&Opts{Sender: fs, ApprovalManager: approver(), Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -782,13 +697,13 @@ func TestEventSent(t *testing.T) {
 	}
 
 	event := &types.Event{Repository: repo}
-	_, err = provider.processEvent(event)
+	_, err = provider.processEvent(context.Background(), event)
 	if err != nil {
 		t.Errorf("got error while processing event: %s", err)
 	}
 
-	if fp.updated.Containers()[0].Image != repo.Name+":"+repo.Tag {
-		t.Errorf("expected to find a deployment with updated image but found: %s", fp.updated.Containers()[0].Image)
+	if tag := fp.replaced["gcr.io/v2-namespace/hello-world:10.0.0"]; tag != repo.Tag {
+		t.Errorf("expected image to be updated to %s but got: '%s'", repo.Tag, tag)
 	}
 
 	if fs.sentEvent.Message != "Successfully updated deployment xxxx/deployment-1 10.0.0->11.0.0 (gcr.io/v2-namespace/hello-world:11.0.0)" {
@@ -797,17 +712,7 @@ func TestEventSent(t *testing.T) {
 }
 
 func TestEventSentWithReleaseNotes(t *testing.T) {
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 	deps := []*apps_v1.Deployment{
 		{
 			meta_v1.TypeMeta{},
@@ -837,7 +742,7 @@ func TestEventSentWithReleaseNotes(t *testing.T) {
 	grc.Add(grs...)
 
 	fs := &fakeSender{}
-	provider, err := NewProvider(fp, fs, approver(), grc)
+	provider, err := NewProvider(&Opts{Sender: fs, ApprovalManager: approver(), Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -848,13 +753,13 @@ func TestEventSentWithReleaseNotes(t *testing.T) {
 	}
 
 	event := &types.Event{Repository: repo}
-	_, err = provider.processEvent(event)
+	_, err = provider.processEvent(context.Background(), event)
 	if err != nil {
 		t.Errorf("got error while processing event: %s", err)
 	}
 
-	if fp.updated.Containers()[0].Image != repo.Name+":"+repo.Tag {
-		t.Errorf("expected to find a deployment with updated image but found: %s", fp.updated.Containers()[0].Image)
+	if tag := fp.replaced["gcr.io/v2-namespace/hello-world:10.0.0"]; tag != repo.Tag {
+		t.Errorf("expected image to be updated to %s but got: '%s'", repo.Tag, tag)
 	}
 
 	if fs.sentEvent.Message != "Successfully updated deployment xxxx/deployment-1 10.0.0->11.0.0 (gcr.io/v2-namespace/hello-world:11.0.0). Release notes: https://github.com/alwinius/bow/releases" {
@@ -864,17 +769,7 @@ func TestEventSentWithReleaseNotes(t *testing.T) {
 
 // Test to check how many deployments are "impacted" if we have sidecar container
 func TestGetImpactedTwoContainersInSameDeployment(t *testing.T) {
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 	deps := []*apps_v1.Deployment{
 		{
 			meta_v1.TypeMeta{},
@@ -925,7 +820,7 @@ func TestGetImpactedTwoContainersInSameDeployment(t *testing.T) {
 	grc := &k8s.GenericResourceCache{}
 	grc.Add(grs...)
 
-	provider, err := NewProvider(fp, &fakeSender{}, approver(), grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -936,7 +831,7 @@ func TestGetImpactedTwoContainersInSameDeployment(t *testing.T) {
 		Tag:  "1.1.2",
 	}
 
-	plans, err := provider.createUpdatePlans(repo)
+	plans, err := provider.createUpdatePlans(context.Background(), repo)
 	if err != nil {
 		t.Errorf("failed to get deployments: %s", err)
 	}
@@ -963,17 +858,7 @@ func TestGetImpactedTwoContainersInSameDeployment(t *testing.T) {
 
 func TestGetImpactedTwoSameContainersInSameDeployment(t *testing.T) {
 
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 	deps := []*apps_v1.Deployment{
 		{
 			meta_v1.TypeMeta{},
@@ -1026,7 +911,7 @@ func TestGetImpactedTwoSameContainersInSameDeployment(t *testing.T) {
 	grc := &k8s.GenericResourceCache{}
 	grc.Add(grs...)
 
-	provider, err := NewProvider(fp, &fakeSender{}, approver(), grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -1037,7 +922,7 @@ func TestGetImpactedTwoSameContainersInSameDeployment(t *testing.T) {
 		Tag:  "1.1.2",
 	}
 
-	plans, err := provider.createUpdatePlans(repo)
+	plans, err := provider.createUpdatePlans(context.Background(), repo)
 	if err != nil {
 		t.Errorf("failed to get deployments: %s", err)
 	}
@@ -1063,17 +948,7 @@ func TestGetImpactedTwoSameContainersInSameDeployment(t *testing.T) {
 }
 
 func TestGetImpactedUntaggedImage(t *testing.T) {
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 	deps := []*apps_v1.Deployment{
 		{
 			meta_v1.TypeMeta{},
@@ -1122,7 +997,7 @@ func TestGetImpactedUntaggedImage(t *testing.T) {
 	grc := &k8s.GenericResourceCache{}
 	grc.Add(grs...)
 
-	provider, err := NewProvider(fp, &fakeSender{}, approver(), grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -1133,7 +1008,7 @@ func TestGetImpactedUntaggedImage(t *testing.T) {
 		Tag:  "1.1.2",
 	}
 
-	plans, err := provider.createUpdatePlans(repo)
+	plans, err := provider.createUpdatePlans(context.Background(), repo)
 	if err != nil {
 		t.Errorf("failed to get deployments: %s", err)
 	}
@@ -1160,17 +1035,7 @@ func TestGetImpactedUntaggedImage(t *testing.T) {
 
 // test to check whether we get impacted deployment when it's untagged (we should)
 func TestGetImpactedUntaggedOneImage(t *testing.T) {
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 	deps := []*apps_v1.Deployment{
 		{
 			meta_v1.TypeMeta{},
@@ -1219,7 +1084,7 @@ func TestGetImpactedUntaggedOneImage(t *testing.T) {
 	grc := &k8s.GenericResourceCache{}
 	grc.Add(grs...)
 
-	provider, err := NewProvider(fp, &fakeSender{}, approver(), grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -1230,7 +1095,7 @@ func TestGetImpactedUntaggedOneImage(t *testing.T) {
 		Tag:  "1.1.2",
 	}
 
-	plans, err := provider.createUpdatePlans(repo)
+	plans, err := provider.createUpdatePlans(context.Background(), repo)
 	if err != nil {
 		t.Errorf("failed to get deployments: %s", err)
 	}
@@ -1258,17 +1123,7 @@ func TestGetImpactedUntaggedOneImage(t *testing.T) {
 }
 
 func TestTrackedImages(t *testing.T) {
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 	deps := []*apps_v1.Deployment{
 		{
 			meta_v1.TypeMeta{},
@@ -1301,7 +1156,7 @@ func TestTrackedImages(t *testing.T) {
 	grc := &k8s.GenericResourceCache{}
 	grc.Add(grs...)
 
-	provider, err := NewProvider(fp, &fakeSender{}, approver(), grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -1320,17 +1175,7 @@ func TestTrackedImages(t *testing.T) {
 }
 
 func TestTrackedImagesWithSecrets(t *testing.T) {
-	fp := &fakeImplementer{}
-	fp.namespaces = &v1.NamespaceList{
-		Items: []v1.Namespace{
-			v1.Namespace{
-				meta_v1.TypeMeta{},
-				meta_v1.ObjectMeta{Name: "xxxx"},
-				v1.NamespaceSpec{},
-				v1.NamespaceStatus{},
-			},
-		},
-	}
+	fp := &fakeRepo{}
 	deps := []*apps_v1.Deployment{
 		{
 			meta_v1.TypeMeta{},
@@ -1366,7 +1211,7 @@ func TestTrackedImagesWithSecrets(t *testing.T) {
 	grc := &k8s.GenericResourceCache{}
 	grc.Add(grs...)
 
-	provider, err := NewProvider(fp, &fakeSender{}, approver(), grc)
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
 	if err != nil {
 		t.Fatalf("failed to get provider: %s", err)
 	}
@@ -1386,3 +1231,451 @@ func TestTrackedImagesWithSecrets(t *testing.T) {
 		t.Errorf("expected very-secret, got: %s", imgs[0].Secrets[1])
 	}
 }
+
+func TestUpdateDeploymentsNotificationChannels(t *testing.T) {
+	deployment := func(name string, annotations map[string]string) *k8s.GenericResource {
+		return MustParseGR(&apps_v1.Deployment{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:        name,
+				Namespace:   "xxxx",
+				Annotations: annotations,
+			},
+		})
+	}
+
+	sender := &recordingSender{}
+	p := &Provider{sender: sender, approvalManager: approver()}
+
+	plans := []*UpdatePlan{
+		{Resource: deployment("ops", map[string]string{types.BowNotificationChannelsAnnotation: "slack-ops,pagerduty"}), CurrentVersion: "1.0.0", NewVersion: "1.1.0"},
+		{Resource: deployment("dev", map[string]string{types.BowNotificationChanAnnotation: "dev"}), CurrentVersion: "1.0.0", NewVersion: "1.1.0"},
+		{Resource: deployment("default", nil), CurrentVersion: "1.0.0", NewVersion: "1.1.0"},
+	}
+	_, err := p.updateDeployments(context.Background(), plans)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string][]string{
+		"ops":     {"slack-ops", "pagerduty"},
+		"dev":     {"dev"},
+		"default": {},
+	}
+	if len(sender.events) != 6 {
+		t.Fatalf("expected 6 events, got: %d", len(sender.events))
+	}
+	for _, event := range sender.events {
+		name := event.Metadata["name"]
+		if !reflect.DeepEqual(event.Channels, expected[name]) {
+			t.Errorf("unexpected channels of %s '%s' event: %v, want %v", name, event.Name, event.Channels, expected[name])
+		}
+	}
+}
+
+func TestInvalidPolicyCountedOnce(t *testing.T) {
+	gr := MustParseGR(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "invalid-policy",
+			Namespace: "xxxx",
+		},
+	})
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, Cache: &k8s.GenericResourceCache{}})
+	if err != nil {
+		t.Fatalf("failed to get provider: %s", err)
+	}
+
+	counted := func() float64 {
+		m := &dto.Metric{}
+		invalidPolicyCounter.With(prometheus.Labels{"kubernetes": "xxxx/invalid-policy"}).Write(m)
+		return m.GetCounter().GetValue()
+	}
+	before := counted()
+
+	for i := 0; i < 3; i++ {
+		provider.invalidPolicy(gr, errors.New("unknown policy 'minr'"))
+	}
+	if got := counted() - before; got != 1 {
+		t.Errorf("expected invalid policy to be counted once, got: %v", got)
+	}
+
+	provider.invalidPolicy(gr, errors.New("unknown policy 'mjor'"))
+	if got := counted() - before; got != 2 {
+		t.Errorf("expected changed invalid policy to be counted again, got: %v", got)
+	}
+}
+
+func TestEventRegistryWithPort(t *testing.T) {
+	fp := &fakeRepo{}
+	grc := &k8s.GenericResourceCache{}
+	grc.Add(MustParseGR(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "dep-1",
+			Namespace: "xxxx",
+			Labels:    map[string]string{types.BowPolicyLabel: "all"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Image: "registry.corp:5000/team/app:1.2.3"},
+					},
+				},
+			},
+		},
+	}))
+
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
+	if err != nil {
+		t.Fatalf("failed to get provider: %s", err)
+	}
+
+	event := &types.Event{Repository: types.Repository{Name: "registry.corp:5000/team/app", Tag: "1.2.4"}}
+	updated, err := provider.processEvent(context.Background(), event)
+	if err != nil {
+		t.Fatalf("got error while processing event: %s", err)
+	}
+	if len(updated) != 1 {
+		t.Fatalf("expected 1 updated resource, got: %d", len(updated))
+	}
+
+	if tag := fp.replaced["registry.corp:5000/team/app:1.2.3"]; tag != "1.2.4" {
+		t.Errorf("expected image to be updated to 1.2.4 but got: '%s'", tag)
+	}
+	if img := gitrepo.ReplacedImage("registry.corp:5000/team/app:1.2.3", "1.2.4"); img != "registry.corp:5000/team/app:1.2.4" {
+		t.Errorf("expected registry port to be kept in the manifests, got: %s", img)
+	}
+	if images := updated[0].GetImages(); !reflect.DeepEqual(images, []string{"registry.corp:5000/team/app:1.2.4"}) {
+		t.Errorf("unexpected images of the updated resource: %v", images)
+	}
+}
+
+func TestResolvedDigestWrittenToManifests(t *testing.T) {
+	fp := &fakeRepo{}
+	grc := &k8s.GenericResourceCache{}
+	grc.Add(MustParseGR(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "dep-1",
+			Namespace: "xxxx",
+			Labels:    map[string]string{types.BowPolicyLabel: "force"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Image: "gcr.io/v2-namespace/hello-world:master"},
+					},
+				},
+			},
+		},
+	}))
+
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
+	if err != nil {
+		t.Fatalf("failed to get provider: %s", err)
+	}
+
+	event := &types.Event{Repository: types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.0.0", Digest: "sha256:abc"}}
+	if _, err := provider.processEvent(context.Background(), event); err != nil {
+		t.Fatalf("got error while processing event: %s", err)
+	}
+	if digest := fp.specAnnotations["deployment/dep-1"][types.BowResolvedDigestAnnotation]; digest != "sha256:abc" {
+		t.Errorf("expected resolved digest to be written to the manifests, got: '%s'", digest)
+	}
+
+	// event without a digest removes the stale one
+	event = &types.Event{Repository: types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.0.1"}}
+	if _, err := provider.processEvent(context.Background(), event); err != nil {
+		t.Fatalf("got error while processing event: %s", err)
+	}
+	if digest, ok := fp.specAnnotations["deployment/dep-1"][types.BowResolvedDigestAnnotation]; ok {
+		t.Errorf("expected stale resolved digest to be removed from the manifests, got: '%s'", digest)
+	}
+}
+
+func TestSkipUnchangedDigestFromManifests(t *testing.T) {
+	fp := &fakeRepo{}
+	gr := MustParseGR(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "dep-1",
+			Namespace:   "xxxx",
+			Labels:      map[string]string{types.BowPolicyLabel: "force"},
+			Annotations: map[string]string{types.BowSkipUnchangedDigestAnnotation: "true"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Image: "gcr.io/v2-namespace/hello-world:1.0.0"},
+					},
+				},
+			},
+		},
+	})
+	grc := &k8s.GenericResourceCache{}
+	grc.Add(gr)
+
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
+	if err != nil {
+		t.Fatalf("failed to get provider: %s", err)
+	}
+
+	// update is pushed and the repository watcher reloads the changed manifests
+	update := func(tag, digest string) int {
+		event := &types.Event{Repository: types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: tag, Digest: digest}}
+		updated, err := provider.processEvent(context.Background(), event)
+		if err != nil {
+			t.Fatalf("got error while processing event: %s", err)
+		}
+		if len(updated) > 0 {
+			gr.UpdateContainer(0, "gcr.io/v2-namespace/hello-world:"+tag)
+		}
+		gr.SetSpecAnnotations(fp.specAnnotations["deployment/dep-1"])
+		return len(updated)
+	}
+
+	if updated := update("1.0.1", "sha256:abc"); updated != 1 {
+		t.Fatalf("expected resource to be updated, got: %d", updated)
+	}
+	if updated := update("1.0.2", "sha256:abc"); updated != 0 {
+		t.Errorf("expected tag with the digest recorded in the manifests to be skipped, got: %d updated", updated)
+	}
+	if updated := update("1.0.2", "sha256:def"); updated != 1 {
+		t.Errorf("expected changed digest to be updated, got: %d", updated)
+	}
+	if len(fp.commits) != 2 {
+		t.Errorf("expected 2 commits, got: %v", fp.commits)
+	}
+}
+
+func TestDigestPolicyFromManifests(t *testing.T) {
+	digestA := "sha256:" + strings.Repeat("a", 64)
+	digestB := "sha256:" + strings.Repeat("b", 64)
+
+	fp := &fakeRepo{}
+	gr := MustParseGR(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "dep-1",
+			Namespace: "xxxx",
+			Labels:    map[string]string{types.BowPolicyLabel: "digest"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Image: "gcr.io/v2-namespace/hello-world:1.2.3"},
+					},
+				},
+			},
+		},
+	})
+	grc := &k8s.GenericResourceCache{}
+	grc.Add(gr)
+
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
+	if err != nil {
+		t.Fatalf("failed to get provider: %s", err)
+	}
+
+	// update is pushed and the repository watcher reloads the changed manifests
+	update := func(digest string) int {
+		event := &types.Event{Repository: types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.3", Digest: digest}}
+		updated, err := provider.processEvent(context.Background(), event)
+		if err != nil {
+			t.Fatalf("got error while processing event: %s", err)
+		}
+		if len(updated) > 0 {
+			img := gr.GetImages()[0]
+			gr.UpdateContainer(0, gitrepo.ReplacedImage(img, fp.replaced[img]))
+		}
+		gr.SetSpecAnnotations(fp.specAnnotations["deployment/dep-1"])
+		return len(updated)
+	}
+
+	if updated := update(digestA); updated != 1 {
+		t.Fatalf("expected resource to be updated, got: %d", updated)
+	}
+	if img := gr.GetImages()[0]; img != "gcr.io/v2-namespace/hello-world:1.2.3@"+digestA {
+		t.Errorf("expected tag to be pinned to the new digest in the manifests, got: %s", img)
+	}
+	if digest := gr.GetSpecAnnotations()[types.BowResolvedDigestAnnotation]; digest != digestA {
+		t.Errorf("expected resolved digest to be written to the manifests, got: '%s'", digest)
+	}
+
+	if updated := update(digestA); updated != 0 {
+		t.Errorf("expected unchanged digest to be skipped, got: %d updated", updated)
+	}
+	if updated := update(digestB); updated != 1 {
+		t.Errorf("expected changed digest to be updated, got: %d", updated)
+	}
+	if img := gr.GetImages()[0]; img != "gcr.io/v2-namespace/hello-world:1.2.3@"+digestB {
+		t.Errorf("expected tag to be pinned to the changed digest, got: %s", img)
+	}
+}
+
+func TestImageHashWrittenToManifests(t *testing.T) {
+	defer func(enabled bool) { ImageHashRollout = enabled }(ImageHashRollout)
+	ImageHashRollout = true
+
+	fp := &fakeRepo{}
+	grc := &k8s.GenericResourceCache{}
+	grc.Add(MustParseGR(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "dep-1",
+			Namespace: "xxxx",
+			Labels:    map[string]string{types.BowPolicyLabel: "all"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Image: "gcr.io/v2-namespace/hello-world:1.0.0"},
+					},
+				},
+			},
+		},
+	}))
+
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
+	if err != nil {
+		t.Fatalf("failed to get provider: %s", err)
+	}
+
+	event := &types.Event{Repository: types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.0.1", Digest: "sha256:0123456789abcdef"}}
+	if _, err := provider.processEvent(context.Background(), event); err != nil {
+		t.Fatalf("got error while processing event: %s", err)
+	}
+	if hash := fp.specAnnotations["deployment/dep-1"][types.BowImageHashAnnotation]; hash != "0123456789ab" {
+		t.Errorf("expected image hash to be written to the manifests, got: '%s'", hash)
+	}
+}
+
+func TestUpdateTimeWrittenToManifests(t *testing.T) {
+	defer func(key string, mode UpdateTimeMode) {
+		UpdateTimeAnnotation = key
+		UpdateTimeAnnotationMode = mode
+	}(UpdateTimeAnnotation, UpdateTimeAnnotationMode)
+
+	process := func(key string, mode UpdateTimeMode) *fakeRepo {
+		UpdateTimeAnnotation = key
+		UpdateTimeAnnotationMode = mode
+
+		fp := &fakeRepo{}
+		grc := &k8s.GenericResourceCache{}
+		grc.Add(MustParseGR(&apps_v1.Deployment{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "dep-1",
+				Namespace: "xxxx",
+				Labels:    map[string]string{types.BowPolicyLabel: "all"},
+			},
+			Spec: apps_v1.DeploymentSpec{
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{
+							{Image: "gcr.io/v2-namespace/hello-world:1.0.0"},
+						},
+					},
+				},
+			},
+		}))
+
+		provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
+		if err != nil {
+			t.Fatalf("failed to get provider: %s", err)
+		}
+		event := &types.Event{Repository: types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.0.1"}}
+		if _, err := provider.processEvent(context.Background(), event); err != nil {
+			t.Fatalf("got error while processing event: %s", err)
+		}
+		return fp
+	}
+
+	fp := process("example.com/updated", UpdateTimeAlways)
+	annotations := fp.specAnnotations["deployment/dep-1"]
+	if annotations["example.com/updated"] == "" {
+		t.Errorf("expected update time to be written to the manifests under the configured key, got: %v", annotations)
+	}
+	if _, ok := annotations[types.BowUpdateTimeAnnotation]; ok {
+		t.Errorf("expected default update time annotation not to be written, got: %v", annotations)
+	}
+
+	fp = process(types.BowUpdateTimeAnnotation, UpdateTimeNever)
+	if _, ok := fp.specAnnotations["deployment/dep-1"][types.BowUpdateTimeAnnotation]; ok {
+		t.Errorf("expected update time not to be written, got: %v", fp.specAnnotations["deployment/dep-1"])
+	}
+}
+
+func TestReleaseNotesWrittenToManifests(t *testing.T) {
+	releaseNotes := "https://github.com/alwinius/bow/releases/tag/1.2.0-with-a-long-changelog-anchor"
+
+	fp := &fakeRepo{}
+	grc := &k8s.GenericResourceCache{}
+	grc.Add(MustParseGR(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "dep-1",
+			Namespace:   "xxxx",
+			Labels:      map[string]string{types.BowPolicyLabel: "minor"},
+			Annotations: map[string]string{types.BowReleaseNotesURL: releaseNotes},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Image: "gcr.io/v2-namespace/hello-world:1.1.1"},
+					},
+				},
+			},
+		},
+	}))
+
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
+	if err != nil {
+		t.Fatalf("failed to get provider: %s", err)
+	}
+
+	event := &types.Event{Repository: types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.0"}}
+	if _, err := provider.processEvent(context.Background(), event); err != nil {
+		t.Fatalf("got error while processing event: %s", err)
+	}
+
+	if got := fp.specAnnotations["deployment/dep-1"][types.BowReleaseNotesAnnotation]; got != releaseNotes[:63] {
+		t.Errorf("expected release notes truncated to 63 characters to be written to the manifests, got: '%s'", got)
+	}
+	if got := fp.configMaps["xxxx/bow-release-notes-deployment-dep-1"][ReleaseNotesConfigMapKey]; got != releaseNotes {
+		t.Errorf("expected full release notes in the config map, got: '%s'", got)
+	}
+}
+
+func TestImagePullPolicyWrittenToManifests(t *testing.T) {
+	fp := &fakeRepo{}
+	grc := &k8s.GenericResourceCache{}
+	grc.Add(MustParseGR(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "dep-1",
+			Namespace:   "xxxx",
+			Labels:      map[string]string{types.BowPolicyLabel: "all"},
+			Annotations: map[string]string{types.BowImagePullPolicyAnnotation: "Always"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Image: "gcr.io/v2-namespace/hello-world:1.0.0"},
+					},
+				},
+			},
+		},
+	}))
+
+	provider, err := NewProvider(&Opts{Sender: &fakeSender{}, ApprovalManager: approver(), Cache: grc, Repo: fp})
+	if err != nil {
+		t.Fatalf("failed to get provider: %s", err)
+	}
+
+	event := &types.Event{Repository: types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.0.1"}}
+	if _, err := provider.processEvent(context.Background(), event); err != nil {
+		t.Fatalf("got error while processing event: %s", err)
+	}
+	if policy := fp.pullPolicies["deployment/dep-1/gcr.io/v2-namespace/hello-world:1.0.1"]; policy != "Always" {
+		t.Errorf("expected image pull policy of the updated image to be written to the manifests, got: '%s' (%v)", policy, fp.pullPolicies)
+	}
+}
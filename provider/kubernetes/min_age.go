@@ -0,0 +1,92 @@
+package kubernetes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/image"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// checkMinAge - plans of resources with bow/min-age annotation are only ready once
+// the new image is old enough, next is the time when the first deferred plan
+// becomes ready. Plans are skipped when image age can't be determined
+func (p *Provider) checkMinAge(plans []*UpdatePlan, repo *types.Repository, now time.Time) (ready []*UpdatePlan, next time.Time) {
+	var (
+		created    time.Time
+		createdErr error
+		fetched    bool
+	)
+
+	for _, plan := range plans {
+		spec, ok := plan.Resource.GetAnnotations()[types.BowMinAgeAnnotation]
+		if !ok || spec == "" {
+			ready = append(ready, plan)
+			continue
+		}
+
+		minAge, err := time.ParseDuration(spec)
+		if err != nil || minAge < 0 {
+			log.WithFields(log.Fields{
+				"min_age":   spec,
+				"name":      plan.Resource.Name,
+				"namespace": plan.Resource.Namespace,
+				"kind":      plan.Resource.Kind(),
+			}).Error("provider.kubernetes: invalid minimum image age, skipping update")
+			continue
+		}
+
+		if !fetched {
+			created, createdErr = p.imageCreated(repo, plan.Resource)
+			fetched = true
+		}
+		if createdErr != nil {
+			log.WithFields(log.Fields{
+				"error":     createdErr,
+				"image":     repo.String(),
+				"name":      plan.Resource.Name,
+				"namespace": plan.Resource.Namespace,
+			}).Error("provider.kubernetes: failed to get image creation time, skipping update")
+			continue
+		}
+
+		oldEnough := created.Add(minAge)
+		if !oldEnough.After(now) {
+			ready = append(ready, plan)
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"name":      plan.Resource.Name,
+			"namespace": plan.Resource.Namespace,
+			"kind":      plan.Resource.Kind(),
+			"image":     repo.String(),
+			"created":   created,
+			"min_age":   minAge,
+		}).Info("provider.kubernetes: image is too new, deferring update")
+
+		if next.IsZero() || oldEnough.Before(next) {
+			next = oldEnough
+		}
+	}
+
+	return ready, next
+}
+
+// imageCreated - creation time of the event image, resource image pull secret is
+// passed to credentials helpers
+func (p *Provider) imageCreated(repo *types.Repository, resource *k8s.GenericResource) (time.Time, error) {
+	if p.registryClient == nil {
+		return time.Time{}, fmt.Errorf("image creation time is not available")
+	}
+
+	ref, err := image.Parse(repo.String())
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return p.registryClient.Created(p.registryOpts(ref, resource))
+}
@@ -0,0 +1,107 @@
+package kubernetes
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/registry"
+	"github.com/alwinius/bow/types"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeRegistryClient struct {
+	created time.Time
+	digest  string
+	tags    []string
+	err     error
+	calls   int
+}
+
+func (g *fakeRegistryClient) Get(opts registry.Opts) (*registry.Repository, error) {
+	g.calls++
+	if g.err != nil {
+		return nil, g.err
+	}
+	return &registry.Repository{Tags: g.tags}, nil
+}
+
+func (g *fakeRegistryClient) Created(opts registry.Opts) (time.Time, error) {
+	g.calls++
+	return g.created, g.err
+}
+
+func (g *fakeRegistryClient) Digest(opts registry.Opts) (string, error) {
+	g.calls++
+	return g.digest, g.err
+}
+
+func minAgePlan(name, minAge string) *UpdatePlan {
+	annotations := map[string]string{}
+	if minAge != "" {
+		annotations[types.BowMinAgeAnnotation] = minAge
+	}
+	gr, err := k8s.NewGenericResource(&apps_v1.Deployment{
+		TypeMeta: meta_v1.TypeMeta{
+			Kind: "Deployment",
+		},
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        name,
+			Namespace:   "xxxx",
+			Annotations: annotations,
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &UpdatePlan{Resource: gr, CurrentVersion: "1.1.1", NewVersion: "1.1.2"}
+}
+
+func TestCheckMinAge(t *testing.T) {
+	now := time.Date(2024, 1, 12, 12, 0, 0, 0, time.UTC)
+	repo := &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.2"}
+
+	plans := []*UpdatePlan{
+		minAgePlan("no-min-age", ""),
+		minAgePlan("10m", "10m"),
+		minAgePlan("30m", "30m"),
+		minAgePlan("1h", "1h"),
+		minAgePlan("invalid", "half an hour"),
+	}
+
+	getter := &fakeRegistryClient{created: now.Add(-20 * time.Minute)}
+	p := &Provider{registryClient: getter}
+
+	ready, next := p.checkMinAge(plans, repo, now)
+	if len(ready) != 2 {
+		t.Fatalf("expected 2 plans to be ready, got: %d", len(ready))
+	}
+	if ready[0].Resource.Name != "no-min-age" || ready[1].Resource.Name != "10m" {
+		t.Errorf("unexpected ready plans: %s, %s", ready[0].Resource.Name, ready[1].Resource.Name)
+	}
+	// 30m plan is the first one to become ready
+	if !next.Equal(now.Add(10 * time.Minute)) {
+		t.Errorf("unexpected next check: %s", next)
+	}
+	if getter.calls != 1 {
+		t.Errorf("expected image creation time to be fetched once, got: %d", getter.calls)
+	}
+}
+
+func TestCheckMinAgeUnknownCreationTime(t *testing.T) {
+	now := time.Date(2024, 1, 12, 12, 0, 0, 0, time.UTC)
+	repo := &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.2"}
+
+	p := &Provider{registryClient: &fakeRegistryClient{err: errors.New("not found")}}
+
+	ready, next := p.checkMinAge([]*UpdatePlan{minAgePlan("no-min-age", ""), minAgePlan("30m", "30m")}, repo, now)
+	if len(ready) != 1 || ready[0].Resource.Name != "no-min-age" {
+		t.Errorf("expected only plan without minimum age to be ready, got: %d", len(ready))
+	}
+	if !next.IsZero() {
+		t.Errorf("expected no deferred check, got: %s", next)
+	}
+}
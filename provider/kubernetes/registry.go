@@ -0,0 +1,74 @@
+package kubernetes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alwinius/bow/extension/credentialshelper"
+	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/registry"
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/image"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RegistryClient - registry queries used by resources that set minimum
+// image age, skip unchanged digests or limit version deltas
+type RegistryClient interface {
+	Get(opts registry.Opts) (*registry.Repository, error)
+	Created(opts registry.Opts) (time.Time, error)
+	Digest(opts registry.Opts) (string, error)
+}
+
+// registryOpts - registry options for the image, credentials are resolved from
+// resource image pull secrets or credentials helpers
+func (p *Provider) registryOpts(ref *image.Reference, resource *k8s.GenericResource) registry.Opts {
+	ti := &types.TrackedImage{
+		Image:               ref,
+		Namespace:           resource.Namespace,
+		Secrets:             imagePullSecrets(resource),
+		UseWorkloadIdentity: p.useWorkloadIdentity,
+	}
+	p.resolveCredentials(ti)
+	creds := credentialshelper.GetCredentials(ti)
+
+	return registry.Opts{
+		Registry: ref.Scheme() + "://" + ref.Registry(),
+		Name:     ref.ShortName(),
+		Tag:      ref.Tag(),
+		Username: creds.Username,
+		Password: creds.Password,
+	}
+}
+
+// withResolvedDigest - copy of the repository with digest of its tag, events from
+// webhooks usually don't carry digests. Repository is returned unchanged if
+// digest can't be resolved
+func (p *Provider) withResolvedDigest(repo *types.Repository, resource *k8s.GenericResource) *types.Repository {
+	digest, err := p.resolveDigest(repo, resource)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+			"image": repo.String(),
+		}).Warn("provider.kubernetes: failed to resolve image digest")
+		return repo
+	}
+
+	resolved := *repo
+	resolved.Digest = digest
+	return &resolved
+}
+
+func (p *Provider) resolveDigest(repo *types.Repository, resource *k8s.GenericResource) (string, error) {
+	if p.registryClient == nil {
+		return "", fmt.Errorf("image digest is not available")
+	}
+
+	ref, err := image.Parse(repo.String())
+	if err != nil {
+		return "", err
+	}
+
+	return p.registryClient.Digest(p.registryOpts(ref, resource))
+}
@@ -0,0 +1,80 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/types"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateUpdatePlansResolvesDigest(t *testing.T) {
+	gr, err := k8s.NewGenericResource(&apps_v1.Deployment{
+		TypeMeta: meta_v1.TypeMeta{
+			Kind: "Deployment",
+		},
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "dep-1",
+			Namespace:   "xxxx",
+			Labels:      map[string]string{types.BowPolicyLabel: "force"},
+			Annotations: map[string]string{types.BowSkipUnchangedDigestAnnotation: "true"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Annotations: map[string]string{types.BowResolvedDigestAnnotation: "sha256:aaa"},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "app",
+							Image: "gcr.io/v2-namespace/hello-world:master",
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create resource: %s", err)
+	}
+
+	grc := &k8s.GenericResourceCache{}
+	grc.Add(gr)
+
+	client := &fakeRegistryClient{digest: "sha256:aaa"}
+	p := &Provider{cache: grc, registryClient: client}
+
+	// webhook events don't carry digests
+	repo := &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "master"}
+
+	plans, err := p.createUpdatePlans(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(plans) != 0 {
+		t.Errorf("expected no plans for unchanged digest, got: %d", len(plans))
+	}
+	if client.calls != 1 {
+		t.Errorf("expected digest to be resolved once, got: %d", client.calls)
+	}
+	if repo.Digest != "" {
+		t.Errorf("event repository shouldn't be modified")
+	}
+
+	client.digest = "sha256:bbb"
+	plans, err = p.createUpdatePlans(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(plans) != 1 {
+		t.Fatalf("expected 1 plan for new digest, got: %d", len(plans))
+	}
+	if plans[0].Resource.GetSpecAnnotations()[types.BowResolvedDigestAnnotation] != "sha256:bbb" {
+		t.Errorf("expected resolved digest to be recorded")
+	}
+}
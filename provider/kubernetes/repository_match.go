@@ -0,0 +1,79 @@
+package kubernetes
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/image"
+
+	"github.com/ryanuber/go-glob"
+)
+
+// repositoryMatchRegexPrefix - bow/repository-match value is a regular expression
+// matching the whole repository instead of comma separated globs
+const repositoryMatchRegexPrefix = "regex:"
+
+// repositoryMatcher - returns function matching container repositories (registry
+// host and name) against the event repository and the new tag. Containers of the
+// same repository always match, with bow/repository-match annotation the resource
+// also accepts containers and events of other repositories matching its patterns,
+// ie: */team/app for the image mirrored under several registry hosts. Event
+// repository is always taken literally
+func repositoryMatcher(repo *types.Repository, resource *k8s.GenericResource) (match func(repository string) bool, tag string, err error) {
+	eventRepoRef, err := image.Parse(repo.String())
+	if err != nil {
+		return nil, "", err
+	}
+	eventRepository := eventRepoRef.Repository()
+
+	pattern, err := repositoryMatchPattern(resource.GetAnnotations()[types.BowRepositoryMatchAnnotation])
+	if err != nil {
+		return nil, "", err
+	}
+
+	if pattern == nil || !pattern(eventRepository) {
+		return func(repository string) bool {
+			return repository == eventRepository
+		}, eventRepoRef.Tag(), nil
+	}
+
+	return func(repository string) bool {
+		return repository == eventRepository || pattern(repository)
+	}, eventRepoRef.Tag(), nil
+}
+
+// repositoryMatchPattern - parses bow/repository-match annotation, comma separated
+// globs or a regular expression with regex: prefix, nil when annotation isn't set
+func repositoryMatchPattern(value string) (func(repository string) bool, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(value, repositoryMatchRegexPrefix) {
+		expr := strings.TrimPrefix(value, repositoryMatchRegexPrefix)
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pattern '%s': %s", types.BowRepositoryMatchAnnotation, expr, err)
+		}
+		return re.MatchString, nil
+	}
+
+	var globs []string
+	for _, g := range strings.Split(value, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			globs = append(globs, g)
+		}
+	}
+	return func(repository string) bool {
+		for _, g := range globs {
+			if glob.Glob(g, repository) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
@@ -0,0 +1,67 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/types"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckForUpdateRepositoryMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		match      string
+		repository string
+		image      string
+		wantUpdate bool
+	}{
+		{"same repository", "", "gcr.io/team/app", "gcr.io/team/app:1.1.0", true},
+		{"other host", "", "gcr.io/team/app", "quay.io/team/app:1.1.0", false},
+		{"event repository is not a pattern", "", "*/team/app", "quay.io/team/app:1.1.0", false},
+		{"glob, mirrored host", "*/team/app", "gcr.io/team/app", "quay.io/team/app:1.1.0", true},
+		{"glob, other name", "*/team/app", "gcr.io/team/app", "quay.io/team/other:1.1.0", false},
+		{"glob, event outside pattern", "*/team/app", "gcr.io/team/other", "quay.io/team/app:1.1.0", false},
+		{"glob list", "gcr.io/team/app, quay.io/team/app", "gcr.io/team/app", "quay.io/team/app:1.1.0", true},
+		{"regex, listed host", `regex:(gcr|quay)\.io/team/app`, "gcr.io/team/app", "quay.io/team/app:1.1.0", true},
+		{"regex, other host", `regex:(gcr|quay)\.io/team/app`, "gcr.io/team/app", "mirror.io/team/app:1.1.0", false},
+		{"invalid regex", `regex:(gcr`, "gcr.io/team/app", "quay.io/team/app:1.1.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, err := k8s.NewGenericResource(&apps_v1.Deployment{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:        "dep-1",
+					Namespace:   "xxxx",
+					Annotations: map[string]string{types.BowRepositoryMatchAnnotation: tt.match},
+				},
+				Spec: apps_v1.DeploymentSpec{
+					Template: core_v1.PodTemplateSpec{
+						Spec: core_v1.PodSpec{
+							Containers: []core_v1.Container{{Name: "app", Image: tt.image}},
+						},
+					},
+				},
+			})
+			if err != nil {
+				t.Fatalf("failed to create resource: %s", err)
+			}
+
+			plan, shouldUpdate, _ := checkForUpdate(context.Background(), mustGetPolicy("all", nil), &types.Repository{
+				Name: tt.repository,
+				Tag:  "1.2.0",
+			}, resource)
+			if shouldUpdate != tt.wantUpdate {
+				t.Fatalf("checkForUpdate() should update = %v, want %v", shouldUpdate, tt.wantUpdate)
+			}
+			if tt.wantUpdate && (len(plan.Images) != 1 || plan.Images[0] != tt.image || plan.NewVersion != "1.2.0") {
+				t.Errorf("unexpected plan images: %v, new version: %s", plan.Images, plan.NewVersion)
+			}
+		})
+	}
+}
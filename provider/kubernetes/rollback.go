@@ -0,0 +1,193 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alwinius/bow/types"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	core_v1 "k8s.io/api/core/v1"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// rollbackCheckInterval - how often updated deployments are checked
+var rollbackCheckInterval = 15 * time.Second
+
+type rolloutState int
+
+const (
+	// new images are not running yet, ie: manifests weren't synced from git
+	rolloutNotSynced rolloutState = iota
+	rolloutProgressing
+	rolloutComplete
+	rolloutFailed
+)
+
+// deploymentRolloutState - checks whether deployment running any of the new images
+// became available, reason is set for failed rollouts
+func deploymentRolloutState(d *apps_v1.Deployment, rollbacks map[string]string) (state rolloutState, reason string) {
+	running := false
+	for _, c := range d.Spec.Template.Spec.Containers {
+		if _, ok := rollbacks[c.Image]; ok {
+			running = true
+		}
+	}
+	if !running {
+		return rolloutNotSynced, ""
+	}
+
+	if d.Status.ObservedGeneration < d.Generation {
+		return rolloutProgressing, ""
+	}
+
+	for _, cond := range d.Status.Conditions {
+		if cond.Status != core_v1.ConditionFalse {
+			continue
+		}
+		if cond.Type == apps_v1.DeploymentAvailable || cond.Type == apps_v1.DeploymentProgressing {
+			return rolloutFailed, fmt.Sprintf("%s: %s", cond.Reason, cond.Message)
+		}
+	}
+
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	if d.Status.UpdatedReplicas >= replicas && d.Status.AvailableReplicas >= replicas && d.Status.Replicas == d.Status.UpdatedReplicas {
+		return rolloutComplete, ""
+	}
+
+	return rolloutProgressing, ""
+}
+
+// watchRollback - reverts the update when updated deployment doesn't become available
+// within the window, rollbacks map new images to their previous tags. Only Deployments
+// are checked as they report availability conditions
+func (p *Provider) watchRollback(plan *UpdatePlan, rollbacks map[string]string, window time.Duration) {
+	resource := plan.Resource
+	if _, ok := resource.GetResource().(*apps_v1.Deployment); !ok {
+		log.WithFields(log.Fields{
+			"name":      resource.Name,
+			"namespace": resource.Namespace,
+			"kind":      resource.Kind(),
+		}).Warn("provider.kubernetes: rollback on failure is only supported for deployments")
+		return
+	}
+	if p.deployments == nil {
+		log.WithFields(log.Fields{
+			"name":      resource.Name,
+			"namespace": resource.Namespace,
+		}).Warn("provider.kubernetes: kubernetes API is not available, rollback on failure is disabled")
+		return
+	}
+
+	go func() {
+		failed, reason := p.waitForRollout(plan, rollbacks, window)
+		if failed {
+			p.rollback(plan, rollbacks, reason)
+		}
+	}()
+}
+
+// waitForRollout - polls deployment status until it becomes available, fails
+// or the window passes
+func (p *Provider) waitForRollout(plan *UpdatePlan, rollbacks map[string]string, window time.Duration) (failed bool, reason string) {
+	resource := plan.Resource
+	deadline := time.Now().Add(window)
+	synced := false
+
+	for {
+		d, err := p.deployments.GetDeployment(resource.Namespace, resource.Name)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":     err,
+				"name":      resource.Name,
+				"namespace": resource.Namespace,
+			}).Warn("provider.kubernetes: failed to get deployment status")
+		} else {
+			state, reason := deploymentRolloutState(d, rollbacks)
+			switch state {
+			case rolloutComplete:
+				return false, ""
+			case rolloutFailed:
+				return true, reason
+			case rolloutProgressing:
+				synced = true
+			}
+		}
+
+		if !time.Now().Before(deadline) {
+			break
+		}
+
+		select {
+		case <-p.stop:
+			return false, ""
+		case <-time.After(rollbackCheckInterval):
+		}
+	}
+
+	if !synced {
+		// nothing to revert in the cluster, new images were never deployed
+		log.WithFields(log.Fields{
+			"name":      resource.Name,
+			"namespace": resource.Namespace,
+			"window":    window,
+		}).Warn("provider.kubernetes: updated images were not deployed within rollback window")
+		return false, ""
+	}
+
+	return true, fmt.Sprintf("not available after %s", window)
+}
+
+// rollback - reverts new images to their previous tags
+func (p *Provider) rollback(plan *UpdatePlan, rollbacks map[string]string, reason string) {
+	resource := plan.Resource
+
+	var newImages []string
+	for img, tag := range rollbacks {
+		p.repo.GrepAndReplace(img, tag)
+		err := p.repo.CommitAndPushAll("rolling back " + img + " to " + tag)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":     err,
+				"name":      resource.Name,
+				"namespace": resource.Namespace,
+				"rollback":  fmt.Sprintf("%s->%s", plan.NewVersion, plan.CurrentVersion),
+			}).Error("provider.kubernetes: got error while committing and pushing rollback")
+			return
+		}
+		newImages = append(newImages, img)
+	}
+	sort.Strings(newImages)
+
+	log.WithFields(log.Fields{
+		"name":      resource.Name,
+		"namespace": resource.Namespace,
+		"previous":  plan.NewVersion,
+		"new":       plan.CurrentVersion,
+		"reason":    reason,
+	}).Warn("provider.kubernetes: failed update rolled back")
+
+	p.sender.Send(types.EventNotification{
+		ResourceKind: resource.Kind(),
+		Identifier:   resource.Identifier,
+		Name:         "rollback resource",
+		Message:      fmt.Sprintf("Rolled back %s %s/%s %s->%s (%s), update failed: %s", resource.Kind(), resource.Namespace, resource.Name, plan.NewVersion, plan.CurrentVersion, strings.Join(newImages, ", "), reason),
+		CreatedAt:    time.Now(),
+		Type:         types.NotificationDeploymentUpdate,
+		Level:        types.LevelWarn,
+		Channels:     types.ParseEventNotificationChannels(resource.GetAnnotations()),
+		Metadata: map[string]string{
+			"provider":        p.GetName(),
+			"namespace":       resource.GetNamespace(),
+			"name":            resource.GetName(),
+			"current_version": plan.NewVersion,
+			"new_version":     plan.CurrentVersion,
+		},
+	})
+}
@@ -0,0 +1,173 @@
+package kubernetes
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/internal/k8s"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeDeploymentGetter struct {
+	deployments []*apps_v1.Deployment
+	err         error
+	calls       int
+}
+
+func (g *fakeDeploymentGetter) GetDeployment(namespace, name string) (*apps_v1.Deployment, error) {
+	g.calls++
+	if g.err != nil {
+		return nil, g.err
+	}
+	// last state is returned once all of them were seen
+	idx := g.calls - 1
+	if idx >= len(g.deployments) {
+		idx = len(g.deployments) - 1
+	}
+	return g.deployments[idx], nil
+}
+
+func rolloutDeployment(image string, conditions ...apps_v1.DeploymentCondition) *apps_v1.Deployment {
+	replicas := int32(2)
+	return &apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:       "dep-1",
+			Namespace:  "xxxx",
+			Generation: 2,
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: core_v1.PodTemplateSpec{
+				Spec: core_v1.PodSpec{
+					Containers: []core_v1.Container{{Image: image}},
+				},
+			},
+		},
+		Status: apps_v1.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           3,
+			UpdatedReplicas:    1,
+			AvailableReplicas:  2,
+			Conditions:         conditions,
+		},
+	}
+}
+
+func availableDeployment(image string) *apps_v1.Deployment {
+	d := rolloutDeployment(image, apps_v1.DeploymentCondition{Type: apps_v1.DeploymentAvailable, Status: core_v1.ConditionTrue})
+	d.Status.Replicas = 2
+	d.Status.UpdatedReplicas = 2
+	return d
+}
+
+var unavailable = apps_v1.DeploymentCondition{
+	Type:    apps_v1.DeploymentAvailable,
+	Status:  core_v1.ConditionFalse,
+	Reason:  "MinimumReplicasUnavailable",
+	Message: "Deployment does not have minimum availability.",
+}
+
+func TestDeploymentRolloutState(t *testing.T) {
+	rollbacks := map[string]string{"gcr.io/v2-namespace/hello-world:1.1.2": "1.1.1"}
+
+	stale := rolloutDeployment("gcr.io/v2-namespace/hello-world:1.1.2")
+	stale.Status.ObservedGeneration = 1
+
+	tests := []struct {
+		name       string
+		deployment *apps_v1.Deployment
+		want       rolloutState
+	}{
+		{"old image", rolloutDeployment("gcr.io/v2-namespace/hello-world:1.1.1"), rolloutNotSynced},
+		{"not observed", stale, rolloutProgressing},
+		{"progressing", rolloutDeployment("gcr.io/v2-namespace/hello-world:1.1.2"), rolloutProgressing},
+		{"unavailable", rolloutDeployment("gcr.io/v2-namespace/hello-world:1.1.2", unavailable), rolloutFailed},
+		{"deadline exceeded", rolloutDeployment("gcr.io/v2-namespace/hello-world:1.1.2", apps_v1.DeploymentCondition{
+			Type:   apps_v1.DeploymentProgressing,
+			Status: core_v1.ConditionFalse,
+			Reason: "ProgressDeadlineExceeded",
+		}), rolloutFailed},
+		{"available", availableDeployment("gcr.io/v2-namespace/hello-world:1.1.2"), rolloutComplete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := deploymentRolloutState(tt.deployment, rollbacks)
+			if got != tt.want {
+				t.Errorf("deploymentRolloutState() = %v, want %v", got, tt.want)
+			}
+			if (got == rolloutFailed) != (reason != "") {
+				t.Errorf("unexpected reason: %s", reason)
+			}
+		})
+	}
+}
+
+func TestWaitForRollout(t *testing.T) {
+	defer func(interval time.Duration) { rollbackCheckInterval = interval }(rollbackCheckInterval)
+	rollbackCheckInterval = time.Millisecond
+
+	rollbacks := map[string]string{"gcr.io/v2-namespace/hello-world:1.1.2": "1.1.1"}
+	gr, err := k8s.NewGenericResource(rolloutDeployment("gcr.io/v2-namespace/hello-world:1.1.1"))
+	if err != nil {
+		t.Fatalf("failed to create resource: %s", err)
+	}
+	plan := &UpdatePlan{Resource: gr, CurrentVersion: "1.1.1", NewVersion: "1.1.2"}
+
+	tests := []struct {
+		name       string
+		getter     *fakeDeploymentGetter
+		wantFailed bool
+	}{
+		{
+			name: "becomes available",
+			getter: &fakeDeploymentGetter{deployments: []*apps_v1.Deployment{
+				rolloutDeployment("gcr.io/v2-namespace/hello-world:1.1.1"),
+				rolloutDeployment("gcr.io/v2-namespace/hello-world:1.1.2"),
+				availableDeployment("gcr.io/v2-namespace/hello-world:1.1.2"),
+			}},
+		},
+		{
+			name: "becomes unavailable",
+			getter: &fakeDeploymentGetter{deployments: []*apps_v1.Deployment{
+				rolloutDeployment("gcr.io/v2-namespace/hello-world:1.1.2"),
+				rolloutDeployment("gcr.io/v2-namespace/hello-world:1.1.2", unavailable),
+			}},
+			wantFailed: true,
+		},
+		{
+			name: "still progressing",
+			getter: &fakeDeploymentGetter{deployments: []*apps_v1.Deployment{
+				rolloutDeployment("gcr.io/v2-namespace/hello-world:1.1.2"),
+			}},
+			wantFailed: true,
+		},
+		{
+			name: "never synced",
+			getter: &fakeDeploymentGetter{deployments: []*apps_v1.Deployment{
+				rolloutDeployment("gcr.io/v2-namespace/hello-world:1.1.1"),
+			}},
+		},
+		{
+			name:   "api errors",
+			getter: &fakeDeploymentGetter{err: errors.New("boom")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Provider{deployments: tt.getter, stop: make(chan struct{})}
+			failed, reason := p.waitForRollout(plan, rollbacks, 20*time.Millisecond)
+			if failed != tt.wantFailed {
+				t.Errorf("waitForRollout() failed = %v, want %v (reason: %s)", failed, tt.wantFailed, reason)
+			}
+			if failed && reason == "" {
+				t.Errorf("expected failure reason")
+			}
+		})
+	}
+}
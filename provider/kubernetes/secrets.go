@@ -0,0 +1,52 @@
+package kubernetes
+
+import (
+	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SecretResolver - resolves image pull secrets of tracked resources
+// into registry credentials
+type SecretResolver interface {
+	Resolve(namespace, name string, image *types.TrackedImage) (*types.Credentials, error)
+}
+
+// imagePullSecrets - secret from bow/imagePullSecret label or annotation
+// followed by image pull secrets of the pod spec
+func imagePullSecrets(gr *k8s.GenericResource) []string {
+	var secrets []string
+	if specified := getImagePullSecretFromMeta(gr.GetLabels(), gr.GetAnnotations()); specified != "" {
+		secrets = append(secrets, specified)
+	}
+	for _, secret := range gr.GetImagePullSecrets() {
+		if secret != "" && !contains(secrets, secret) {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+// resolveCredentials - sets credentials from the first image pull secret that
+// has them for the image registry, polling falls back to credentials helpers otherwise
+func (p *Provider) resolveCredentials(image *types.TrackedImage) {
+	if p.secretResolver == nil || image.WorkloadIdentity() {
+		return
+	}
+
+	for _, name := range image.Secrets {
+		creds, err := p.secretResolver.Resolve(image.Namespace, name, image)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":     err,
+				"secret":    name,
+				"namespace": image.Namespace,
+				"image":     image.Image.Repository(),
+			}).Debug("provider.kubernetes: image pull secret has no credentials for image")
+			continue
+		}
+		image.Credentials = creds
+		return
+	}
+}
@@ -0,0 +1,122 @@
+package kubernetes
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/types"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeSecretResolver - credentials by namespace/secret/registry
+type fakeSecretResolver struct {
+	credentials map[string]*types.Credentials
+	resolved    []string
+}
+
+func (r *fakeSecretResolver) Resolve(namespace, name string, image *types.TrackedImage) (*types.Credentials, error) {
+	r.resolved = append(r.resolved, namespace+"/"+name)
+	creds, ok := r.credentials[namespace+"/"+name+"/"+image.Image.Registry()]
+	if !ok {
+		return nil, fmt.Errorf("secret has no credentials for registry %s", image.Image.Registry())
+	}
+	return creds, nil
+}
+
+func TestTrackedImagesPodSpecImagePullSecrets(t *testing.T) {
+	gr, err := k8s.NewGenericResource(&apps_v1.Deployment{
+		TypeMeta: meta_v1.TypeMeta{
+			Kind: "Deployment",
+		},
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "dep-1",
+			Namespace:   "xxxx",
+			Labels:      map[string]string{types.BowPolicyLabel: "all"},
+			Annotations: map[string]string{types.BowTriggerLabel: "poll"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					ImagePullSecrets: []v1.LocalObjectReference{
+						{Name: "docker-hub"},
+						{Name: "quay"},
+					},
+					Containers: []v1.Container{
+						{
+							Name:  "app",
+							Image: "quay.io/bow/app:1.1.0",
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create resource: %s", err)
+	}
+
+	grc := &k8s.GenericResourceCache{}
+	grc.Add(gr)
+
+	resolver := &fakeSecretResolver{
+		credentials: map[string]*types.Credentials{
+			"xxxx/quay/quay.io": &types.Credentials{Username: "user-1", Password: "secret"},
+		},
+	}
+
+	p, err := NewProvider(&Opts{Cache: grc, SecretResolver: resolver})
+	if err != nil {
+		t.Fatalf("failed to create provider: %s", err)
+	}
+
+	tracked, err := p.TrackedImages()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(tracked) != 1 {
+		t.Fatalf("expected 1 tracked image, got: %d", len(tracked))
+	}
+
+	if !reflect.DeepEqual(tracked[0].Secrets, []string{"docker-hub", "quay"}) {
+		t.Errorf("unexpected secrets: %v", tracked[0].Secrets)
+	}
+	if tracked[0].Namespace != "xxxx" {
+		t.Errorf("unexpected namespace: %s", tracked[0].Namespace)
+	}
+	if tracked[0].Credentials == nil || tracked[0].Credentials.Username != "user-1" {
+		t.Errorf("expected credentials from the second pull secret, got: %v", tracked[0].Credentials)
+	}
+	if !reflect.DeepEqual(resolver.resolved, []string{"xxxx/docker-hub", "xxxx/quay"}) {
+		t.Errorf("unexpected resolved secrets: %v", resolver.resolved)
+	}
+}
+
+func TestImagePullSecretsAnnotationFirst(t *testing.T) {
+	gr, err := k8s.NewGenericResource(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "dep-1",
+			Namespace:   "xxxx",
+			Annotations: map[string]string{types.BowImagePullSecretAnnotation: "quay"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					ImagePullSecrets: []v1.LocalObjectReference{{Name: "docker-hub"}, {Name: "quay"}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create resource: %s", err)
+	}
+
+	secrets := imagePullSecrets(gr)
+	if !reflect.DeepEqual(secrets, []string{"quay", "docker-hub"}) {
+		t.Errorf("unexpected secrets: %v", secrets)
+	}
+}
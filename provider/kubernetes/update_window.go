@@ -0,0 +1,45 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/pending"
+	"github.com/alwinius/bow/util/trace"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// checkUpdateWindows - splits plans into the ones that can be applied now and
+// the ones that have to wait for the update window of their policy. Returned
+// time is when the earliest deferred window opens, zero if nothing was deferred
+func checkUpdateWindows(plans []*UpdatePlan, now time.Time) (ready []*UpdatePlan, next time.Time) {
+	var windows pending.Windows
+	for _, plan := range plans {
+		opens := windows.Defer(plan.Window, now)
+		if opens.IsZero() {
+			ready = append(ready, plan)
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"name":          plan.Resource.Name,
+			"namespace":     plan.Resource.Namespace,
+			"kind":          plan.Resource.Kind(),
+			"update_window": plan.Window.String(),
+			"opens":         opens,
+		}).Info("provider.kubernetes: outside of update window, deferring update")
+	}
+
+	return ready, windows.Next()
+}
+
+// requeue - submits deferred event back to the event loop once its window opens,
+// replayed event gets a new trace ID and skips deduplication
+func (p *Provider) requeue(event *types.Event) {
+	select {
+	case p.events <- &queuedEvent{ctx: trace.NewContext(context.Background()), event: event, replay: true}:
+	case <-p.stop:
+	}
+}
@@ -0,0 +1,178 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/pending"
+	"github.com/alwinius/bow/util/timeutil"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func windowPlan(name, spec string) *UpdatePlan {
+	gr, err := k8s.NewGenericResource(&apps_v1.Deployment{
+		TypeMeta: meta_v1.TypeMeta{
+			Kind: "Deployment",
+		},
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      name,
+			Namespace: "xxxx",
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	plan := &UpdatePlan{Resource: gr, CurrentVersion: "1.1.1", NewVersion: "1.1.2"}
+	if spec != "" {
+		plan.Window, err = timeutil.ParseUpdateWindow(spec)
+		if err != nil {
+			panic(err)
+		}
+	}
+	return plan
+}
+
+func TestCheckUpdateWindows(t *testing.T) {
+	defer func() { timeutil.Now = time.Now }()
+
+	plans := []*UpdatePlan{
+		windowPlan("no-window", ""),
+		// weekdays, 02:00-03:59 UTC
+		windowPlan("weekdays", "* 2-3 * * 1-5"),
+	}
+
+	t.Run("inside window", func(t *testing.T) {
+		// Friday 02:30 UTC
+		timeutil.Now = func() time.Time {
+			return time.Date(2024, 1, 12, 2, 30, 0, 0, time.UTC)
+		}
+
+		ready, next := checkUpdateWindows(plans, timeutil.Now())
+		if len(ready) != 2 {
+			t.Fatalf("expected 2 plans to be ready, got: %d", len(ready))
+		}
+		if ready[1].Resource.Name != "weekdays" {
+			t.Errorf("unexpected ready plan: %s", ready[1].Resource.Name)
+		}
+		if !next.IsZero() {
+			t.Errorf("expected no deferred plans, got next window: %s", next)
+		}
+	})
+
+	t.Run("outside window", func(t *testing.T) {
+		// Friday 12:00 UTC, next window opens on Monday
+		timeutil.Now = func() time.Time {
+			return time.Date(2024, 1, 12, 12, 0, 0, 0, time.UTC)
+		}
+
+		ready, next := checkUpdateWindows(plans, timeutil.Now())
+		if len(ready) != 1 || ready[0].Resource.Name != "no-window" {
+			t.Fatalf("expected only plan without window to be ready, got: %v", ready)
+		}
+		expected := time.Date(2024, 1, 15, 2, 0, 0, 0, time.UTC)
+		if !next.Equal(expected) {
+			t.Errorf("expected next window at %s, got: %s", expected, next)
+		}
+	})
+}
+
+func TestDeferEvent(t *testing.T) {
+	p := &Provider{
+		events: make(chan *queuedEvent, 10),
+		stop:   make(chan struct{}),
+	}
+	p.deferred = pending.New(p.requeue)
+	defer p.Stop()
+
+	event := &types.Event{Repository: types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.2", Digest: "sha256:aaa"}}
+	newer := &types.Event{Repository: types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.2", Digest: "sha256:bbb"}}
+	until := timeutil.Now().Add(50 * time.Millisecond)
+
+	p.deferred.Add(event, until)
+	// same image and tag is queued only once, with the latest digest
+	p.deferred.Add(newer, until)
+
+	select {
+	case got := <-p.events:
+		if got.event != newer {
+			t.Errorf("unexpected event re-queued: %v", got.event)
+		}
+		if !got.replay {
+			t.Errorf("expected re-queued event to skip deduplication")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("event was not re-queued")
+	}
+
+	select {
+	case got := <-p.events:
+		t.Errorf("event re-queued twice: %v", got.event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestCreateUpdatePlansUpdateWindow(t *testing.T) {
+	defer func() { timeutil.Now = time.Now }()
+
+	gr := MustParseGR(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "dep-1",
+			Namespace: "xxxx",
+			Annotations: map[string]string{
+				types.BowPolicyLabel:            "minor",
+				types.BowUpdateWindowAnnotation: "* 2-5 * * 6,0",
+			},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Image: "gcr.io/v2-namespace/hello-world:1.1.1",
+						},
+					},
+				},
+			},
+		},
+	})
+
+	grc := &k8s.GenericResourceCache{}
+	grc.Add(gr)
+	p := &Provider{cache: grc}
+
+	repo := &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.0"}
+
+	// Monday 12:00 UTC
+	timeutil.Now = func() time.Time { return time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC) }
+
+	plans, err := p.createUpdatePlans(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(plans) != 1 {
+		t.Fatalf("expected plan outside of update window, got: %d", len(plans))
+	}
+
+	ready, next := checkUpdateWindows(plans, timeutil.Now())
+	if len(ready) != 0 {
+		t.Errorf("expected plan to be deferred, got: %v", ready)
+	}
+	if expected := time.Date(2024, 1, 20, 2, 0, 0, 0, time.UTC); !next.Equal(expected) {
+		t.Errorf("expected event to be deferred until %s, got: %s", expected, next)
+	}
+
+	// major update is refused by the policy itself, nothing to wait for
+	plans, err = p.createUpdatePlans(context.Background(), &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "2.0.0"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(plans) != 0 {
+		t.Errorf("expected no plans for update refused by policy, got: %d", len(plans))
+	}
+}
@@ -1,43 +1,82 @@
 package kubernetes
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/alwinius/bow/internal/k8s"
 	"github.com/alwinius/bow/internal/policy"
 	"github.com/alwinius/bow/types"
 	"github.com/alwinius/bow/util/image"
+	"github.com/alwinius/bow/util/trace"
+
+	core_v1 "k8s.io/api/core/v1"
 
 	log "github.com/sirupsen/logrus"
 )
 
-func checkForUpdate(plc policy.Policy, repo *types.Repository, resource *k8s.GenericResource) (updatePlan *UpdatePlan, shouldUpdateDeployment bool, err error) {
+func checkForUpdate(ctx context.Context, plc policy.Policy, repo *types.Repository, resource *k8s.GenericResource) (updatePlan *UpdatePlan, shouldUpdateDeployment bool, err error) {
 	updatePlan = &UpdatePlan{}
 
-	eventRepoRef, err := image.Parse(repo.String())
+	matchRepository, newTag, err := repositoryMatcher(repo, resource)
 	if err != nil {
 		return
 	}
 
-	log.WithFields(log.Fields{
+	trace.Log(ctx).WithFields(log.Fields{
 		"name":      resource.Name,
 		"namespace": resource.Namespace,
 		"kind":      resource.Kind(),
 		"policy":    plc.Name(),
 	}).Debug("provider.kubernetes.checkVersionedDeployment: bow policy found, checking resource...")
 	shouldUpdateDeployment = false
-	for idx, c := range resource.Containers() {
-		containerImageRef, err := image.Parse(c.Image)
+	ignored := types.ParseIgnoredContainers(resource.GetAnnotations())
+	skipUnchanged := types.ParseSkipUnchangedDigest(resource.GetAnnotations())
+	digestMatch := policy.MatchDigest(plc)
+	pullPolicy, pullPolicyErr := types.ParseImagePullPolicy(resource.GetAnnotations())
+	if pullPolicyErr != nil {
+		trace.Log(ctx).WithFields(log.Fields{
+			"error":     pullPolicyErr,
+			"name":      resource.Name,
+			"namespace": resource.Namespace,
+			"kind":      resource.Kind(),
+		}).Warn("provider.kubernetes: ignoring image pull policy annotation")
+	}
+	kustomized := types.ParseKustomizeImages(resource.GetAnnotations())
+	delta, deltaErr := types.ParseMaxVersionDelta(resource.GetAnnotations())
+	if deltaErr != nil {
+		trace.Log(ctx).WithFields(log.Fields{
+			"error":     deltaErr,
+			"name":      resource.Name,
+			"namespace": resource.Namespace,
+			"kind":      resource.Kind(),
+		}).Warn("provider.kubernetes: ignoring max version delta annotation")
+	}
+	for _, c := range resourceContainers(resource) {
+		if ignored[c.Name] {
+			trace.Log(ctx).WithFields(log.Fields{
+				"name":      resource.Name,
+				"namespace": resource.Namespace,
+				"kind":      resource.Kind(),
+				"container": c.Name,
+			}).Debug("provider.kubernetes: container is ignored, skipping")
+			continue
+		}
+
+		img := kustomizedImage(kustomized, c.Image)
+		containerImage, currentDigest := image.SplitDigest(img)
+		containerImageRef, err := image.Parse(containerImage)
 		if err != nil {
-			log.WithFields(log.Fields{
+			trace.Log(ctx).WithFields(log.Fields{
 				"error":      err,
-				"image_name": c.Image,
+				"image_name": img,
 			}).Error("provider.kubernetes: failed to parse image name")
 			continue
 		}
 
-		log.WithFields(log.Fields{
+		trace.Log(ctx).WithFields(log.Fields{
 			"name":              resource.Name,
 			"namespace":         resource.Namespace,
 			"kind":              resource.Kind(),
@@ -45,20 +84,44 @@ func checkForUpdate(plc policy.Policy, repo *types.Repository, resource *k8s.Gen
 			"target_image_name": repo.Name,
 			"target_tag":        repo.Tag,
 			"policy":            plc.Name(),
-			"image":             c.Image,
+			"image":             img,
 		}).Debug("provider.kubernetes: checking image")
 
-		if containerImageRef.Repository() != eventRepoRef.Repository() {
-			log.WithFields(log.Fields{
+		if !matchRepository(containerImageRef.Repository()) {
+			trace.Log(ctx).WithFields(log.Fields{
 				"parsed_image_name": containerImageRef.Remote(),
 				"target_image_name": repo.Name,
 			}).Debug("provider.kubernetes: images do not match, ignoring")
 			continue
 		}
 
-		shouldUpdateContainer, err := plc.ShouldUpdate(containerImageRef.Tag(), eventRepoRef.Tag())
+		if repo.Platform != "" {
+			platform := types.ParseContainerPlatform(resource.GetAnnotations(), c.Name)
+			if platform != "" && platform != repo.Platform {
+				trace.Log(ctx).WithFields(log.Fields{
+					"name":      resource.Name,
+					"namespace": resource.Namespace,
+					"container": c.Name,
+					"platform":  platform,
+					"target":    repo.Platform,
+				}).Debug("provider.kubernetes: container uses a different platform, ignoring")
+				continue
+			}
+			if repo.Digest != "" && repo.Digest == currentDigest {
+				trace.Log(ctx).WithFields(log.Fields{
+					"name":      resource.Name,
+					"namespace": resource.Namespace,
+					"container": c.Name,
+					"platform":  repo.Platform,
+					"digest":    currentDigest,
+				}).Debug("provider.kubernetes: platform digest did not change, ignoring")
+				continue
+			}
+		}
+
+		shouldUpdateContainer, err := plc.ShouldUpdate(containerImageRef.Tag(), newTag)
 		if err != nil {
-			log.WithFields(log.Fields{
+			trace.Log(ctx).WithFields(log.Fields{
 				"error":             err,
 				"parsed_image_name": containerImageRef.Remote(),
 				"target_image_name": repo.Name,
@@ -71,28 +134,267 @@ func checkForUpdate(plc policy.Policy, repo *types.Repository, resource *k8s.Gen
 			continue
 		}
 
-		// updating spec template annotations
-		setUpdateTime(resource)
+		if exceedsVersionDelta(delta, containerImageRef.Tag(), newTag) {
+			trace.Log(ctx).WithFields(log.Fields{
+				"name":        resource.Name,
+				"namespace":   resource.Namespace,
+				"kind":        resource.Kind(),
+				"current_tag": containerImageRef.Tag(),
+				"new_tag":     newTag,
+			}).Debug("provider.kubernetes: new tag exceeds max version delta, ignoring")
+			continue
+		}
 
-		// updating image
+		// digest of the previous update was written to the manifests
+		// and is read back by the repository watcher
+		if skipUnchanged && repo.Digest != "" && repo.Digest == resource.GetSpecAnnotations()[types.BowResolvedDigestAnnotation] {
+			trace.Log(ctx).WithFields(log.Fields{
+				"name":      resource.Name,
+				"namespace": resource.Namespace,
+				"kind":      resource.Kind(),
+				"container": c.Name,
+				"digest":    repo.Digest,
+			}).Debug("provider.kubernetes: resolved digest did not change, ignoring")
+			continue
+		}
+
+		// pinned digest of the image and the resolved digest annotation are
+		// both written to the manifests by the previous update
+		if digestMatch && (repo.Digest == "" || repo.Digest == currentDigest || repo.Digest == resource.GetSpecAnnotations()[types.BowResolvedDigestAnnotation]) {
+			trace.Log(ctx).WithFields(log.Fields{
+				"name":      resource.Name,
+				"namespace": resource.Namespace,
+				"kind":      resource.Kind(),
+				"container": c.Name,
+				"digest":    repo.Digest,
+			}).Debug("provider.kubernetes: digest is unknown or did not change, ignoring")
+			continue
+		}
+
+		newImage := fmt.Sprintf("%s:%s", containerImageRef.Repository(), repo.Tag)
 		if containerImageRef.Registry() == image.DefaultRegistryHostname {
-			resource.UpdateContainer(idx, fmt.Sprintf("%s:%s", containerImageRef.ShortName(), repo.Tag))
+			newImage = fmt.Sprintf("%s:%s", containerImageRef.ShortName(), repo.Tag)
+		}
+
+		// updating spec template annotations, digest based hash only changes
+		// with the image so reconciles don't churn GitOps diffs
+		if ImageHashRollout && repo.Digest != "" {
+			setImageHash(resource, repo.Digest)
 		} else {
-			resource.UpdateContainer(idx, fmt.Sprintf("%s:%s", containerImageRef.Repository(), repo.Tag))
+			setUpdateTime(resource, newImage != c.Image)
 		}
+		setResolvedDigest(resource, repo.Digest)
+
+		// updating image
+		c.update(resource, newImage, core_v1.PullPolicy(pullPolicy))
 
 		shouldUpdateDeployment = true
 
-		updatePlan.CurrentVersion = containerImageRef.Tag()
+		// first matching container determines the current version
+		if len(updatePlan.Images) == 0 {
+			updatePlan.CurrentVersion = containerImageRef.Tag()
+		}
+		if !contains(updatePlan.Images, img) {
+			updatePlan.Images = append(updatePlan.Images, img)
+		}
 		updatePlan.NewVersion = repo.Tag
 		updatePlan.Resource = resource
+		if releaseNotes := types.ParseReleaseNotesURL(resource.GetAnnotations()); releaseNotes != "" {
+			updatePlan.ReleaseNotes = []string{releaseNotes}
+		}
+		if repo.Platform != "" {
+			updatePlan.Platform = repo.Platform
+			updatePlan.Digest = repo.Digest
+		}
+		if digestMatch {
+			// same tag is pinned to the new digest so the change reaches manifests
+			updatePlan.Digest = repo.Digest
+		}
 	}
 
 	return updatePlan, shouldUpdateDeployment, nil
 }
 
-func setUpdateTime(resource *k8s.GenericResource) {
+// resourceContainer - container or init container checked for updates, init
+// containers often run migrations with the same image as the main container
+type resourceContainer struct {
+	core_v1.Container
+	index int
+	init  bool
+}
+
+// resourceContainers - containers followed by init containers of the resource
+func resourceContainers(resource *k8s.GenericResource) []resourceContainer {
+	var containers []resourceContainer
+	for idx, c := range resource.Containers() {
+		containers = append(containers, resourceContainer{Container: c, index: idx})
+	}
+	for idx, c := range resource.InitContainers() {
+		containers = append(containers, resourceContainer{Container: c, index: idx, init: true})
+	}
+	return containers
+}
+
+// update - sets new image of the container, pull policy is only set when not empty
+func (c resourceContainer) update(resource *k8s.GenericResource, image string, pullPolicy core_v1.PullPolicy) {
+	if c.init {
+		resource.UpdateInitContainer(c.index, image)
+		if pullPolicy != "" {
+			resource.SetInitContainerImagePullPolicy(c.index, pullPolicy)
+		}
+		return
+	}
+	resource.UpdateContainer(c.index, image)
+	if pullPolicy != "" {
+		resource.SetImagePullPolicy(c.index, pullPolicy)
+	}
+}
+
+// releaseNotesAnnotationLimit - length of annotation values, longer release
+// notes are truncated and kept in full in the release notes config map
+const releaseNotesAnnotationLimit = 63
+
+// ReleaseNotesConfigMapKey - key of the full release notes in the release
+// notes config map of the resource
+const ReleaseNotesConfigMapKey = "release-notes"
+
+// releaseNotesConfigMap - name of the config map with full release notes of the
+// resource, ie: deployment/default/app -> bow-release-notes-deployment-app in the
+// default namespace
+func releaseNotesConfigMap(resource *k8s.GenericResource) string {
+	return "bow-release-notes-" + resource.Kind() + "-" + resource.Name
+}
+
+// setReleaseNotes - stores combined release notes of the applied update in
+// spec template annotations, truncated to the annotation value limit
+func setReleaseNotes(resource *k8s.GenericResource, releaseNotes []string) {
+	if len(releaseNotes) == 0 {
+		return
+	}
+	notes := []rune(strings.Join(releaseNotes, ", "))
+	if len(notes) > releaseNotesAnnotationLimit {
+		notes = notes[:releaseNotesAnnotationLimit]
+	}
+	specAnnotations := resource.GetSpecAnnotations()
+	specAnnotations[types.BowReleaseNotesAnnotation] = string(notes)
+	resource.SetSpecAnnotations(specAnnotations)
+}
+
+// UpdateTimeMode - when the update time annotation is set on updated resources
+type UpdateTimeMode string
+
+// Available update time modes
+const (
+	// UpdateTimeAlways - annotation is set on every update
+	UpdateTimeAlways UpdateTimeMode = "always"
+	// UpdateTimeSameImage - annotation is only set when the image string doesn't
+	// change, ie: force policy updating the same tag, other updates roll out anyway
+	UpdateTimeSameImage UpdateTimeMode = "same-image"
+	// UpdateTimeNever - annotation is never set
+	UpdateTimeNever UpdateTimeMode = "never"
+)
+
+// ParseUpdateTimeMode - parses update time mode, empty string defaults to always
+func ParseUpdateTimeMode(mode string) (UpdateTimeMode, error) {
+	switch UpdateTimeMode(mode) {
+	case "", UpdateTimeAlways:
+		return UpdateTimeAlways, nil
+	case UpdateTimeSameImage, UpdateTimeNever:
+		return UpdateTimeMode(mode), nil
+	}
+	return "", fmt.Errorf("invalid update time mode '%s', expected one of: %s, %s, %s", mode, UpdateTimeAlways, UpdateTimeSameImage, UpdateTimeNever)
+}
+
+// UpdateTimeAnnotation - spec template annotation holding the update time, it forces
+// rollouts when the image string stays the same. GitOps tools can be told to ignore
+// a different key
+var UpdateTimeAnnotation = types.BowUpdateTimeAnnotation
+
+// UpdateTimeAnnotationMode - when UpdateTimeAnnotation is set
+var UpdateTimeAnnotationMode = UpdateTimeAlways
+
+// setUpdateTime - sets update time annotation according to UpdateTimeAnnotationMode,
+// imageChanged tells whether the update changes the container image string
+func setUpdateTime(resource *k8s.GenericResource, imageChanged bool) {
+	switch UpdateTimeAnnotationMode {
+	case UpdateTimeNever:
+		return
+	case UpdateTimeSameImage:
+		if imageChanged {
+			return
+		}
+	}
+
 	specAnnotations := resource.GetSpecAnnotations()
-	specAnnotations[types.BowUpdateTimeAnnotation] = time.Now().String()
+	specAnnotations[UpdateTimeAnnotation] = time.Now().String()
 	resource.SetSpecAnnotations(specAnnotations)
 }
+
+// ImageHashRollout - use short digest of the new image in bow/image-hash instead of
+// update time to trigger rollouts, update time is still set when digest is unknown
+var ImageHashRollout = false
+
+// imageHashLength - length of the short digest in bow/image-hash
+const imageHashLength = 12
+
+// setImageHash - sets short digest of the new image, ie: sha256:abcdef... -> abcdef...
+func setImageHash(resource *k8s.GenericResource, digest string) {
+	hash := digest
+	if idx := strings.Index(hash, ":"); idx >= 0 {
+		hash = hash[idx+1:]
+	}
+	if len(hash) > imageHashLength {
+		hash = hash[:imageHashLength]
+	}
+
+	specAnnotations := resource.GetSpecAnnotations()
+	specAnnotations[types.BowImageHashAnnotation] = hash
+	resource.SetSpecAnnotations(specAnnotations)
+}
+
+// setResolvedDigest - records digest of the new image, stale digest is removed
+// when event didn't carry one
+func setResolvedDigest(resource *k8s.GenericResource, digest string) {
+	specAnnotations := resource.GetSpecAnnotations()
+	if digest == "" {
+		delete(specAnnotations, types.BowResolvedDigestAnnotation)
+	} else {
+		specAnnotations[types.BowResolvedDigestAnnotation] = digest
+	}
+	resource.SetSpecAnnotations(specAnnotations)
+}
+
+// persistedSpecAnnotations - spec annotations bow sets on updated resources that have
+// to be written to the manifests, empty value removes the annotation
+func persistedSpecAnnotations(resource *k8s.GenericResource) map[string]string {
+	specAnnotations := resource.GetSpecAnnotations()
+	persisted := map[string]string{
+		// stale digest is removed when the event didn't carry one
+		types.BowResolvedDigestAnnotation: specAnnotations[types.BowResolvedDigestAnnotation],
+	}
+	for _, key := range []string{UpdateTimeAnnotation, types.BowImageHashAnnotation, types.BowReleaseNotesAnnotation} {
+		if value, ok := specAnnotations[key]; ok {
+			persisted[key] = value
+		}
+	}
+	return persisted
+}
+
+// kustomizedImage - image set by kustomize for the container image, container
+// image is returned as is when kustomize doesn't manage it
+func kustomizedImage(kustomized map[string]string, img string) string {
+	name, _ := image.SplitTag(img)
+	if k, ok := kustomized[name]; ok {
+		return k
+	}
+	return img
+}
+
+func contains(images []string, img string) bool {
+	for _, i := range images {
+		if i == img {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	"context"
 	"reflect"
 	"testing"
 	"time"
@@ -23,6 +24,14 @@ func mustParseGlob(str string) policy.Policy {
 	return p
 }
 
+func mustGetPolicy(name string, options *policy.Options) policy.Policy {
+	p, err := policy.GetPolicy(name, options)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
 func TestProvider_checkForUpdate(t *testing.T) {
 
 	timeutil.Now = func() time.Time {
@@ -104,6 +113,7 @@ func TestProvider_checkForUpdate(t *testing.T) {
 				}),
 				NewVersion:     "latest",
 				CurrentVersion: "latest",
+				Images:         []string{"gcr.io/v2-namespace/hello-world"},
 			},
 			wantShouldUpdateDeployment: true,
 			wantErr:                    false,
@@ -240,6 +250,7 @@ func TestProvider_checkForUpdate(t *testing.T) {
 				}),
 				NewVersion:     "0.2.0",
 				CurrentVersion: "latest",
+				Images:         []string{"karolisr/bow:latest"},
 			},
 			wantShouldUpdateDeployment: true,
 			wantErr:                    false,
@@ -307,6 +318,7 @@ func TestProvider_checkForUpdate(t *testing.T) {
 				}),
 				NewVersion:     "master",
 				CurrentVersion: "master",
+				Images:         []string{"karolisr/bow:master"},
 			},
 			wantShouldUpdateDeployment: true,
 			wantErr:                    false,
@@ -377,6 +389,7 @@ func TestProvider_checkForUpdate(t *testing.T) {
 				}),
 				NewVersion:     "latest-staging",
 				CurrentVersion: "latest-staging",
+				Images:         []string{"karolisr/bow:latest-staging"},
 			},
 			wantShouldUpdateDeployment: true,
 			wantErr:                    false,
@@ -448,6 +461,7 @@ func TestProvider_checkForUpdate(t *testing.T) {
 				}),
 				NewVersion:     "latest-staging",
 				CurrentVersion: "latest-staging",
+				Images:         []string{"eu.gcr.io/karolisr/bow:latest-staging"},
 			},
 			wantShouldUpdateDeployment: true,
 			wantErr:                    false,
@@ -553,6 +567,7 @@ func TestProvider_checkForUpdate(t *testing.T) {
 				}),
 				NewVersion:     "latest-staging",
 				CurrentVersion: "latest-staging",
+				Images:         []string{"eu.gcr.io/karolisr/bow:latest-staging"},
 			},
 			wantShouldUpdateDeployment: true,
 			wantErr:                    false,
@@ -623,6 +638,7 @@ func TestProvider_checkForUpdate(t *testing.T) {
 				}),
 				NewVersion:     "release-2",
 				CurrentVersion: "release-1",
+				Images:         []string{"eu.gcr.io/karolisr/bow:release-1"},
 			},
 			wantShouldUpdateDeployment: true,
 			wantErr:                    false,
@@ -630,7 +646,7 @@ func TestProvider_checkForUpdate(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotUpdatePlan, gotShouldUpdateDeployment, err := checkForUpdate(tt.args.policy, tt.args.repo, tt.args.resource)
+			gotUpdatePlan, gotShouldUpdateDeployment, err := checkForUpdate(context.Background(), tt.args.policy, tt.args.repo, tt.args.resource)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Provider.checkUnversionedDeployment() error = %#v, wantErr %#v", err, tt.wantErr)
 				return
@@ -732,6 +748,7 @@ func TestProvider_checkForUpdateSemver(t *testing.T) {
 				}),
 				NewVersion:     "1.1.2",
 				CurrentVersion: "1.1.1",
+				Images:         []string{"gcr.io/v2-namespace/hello-world:1.1.1"},
 			},
 			wantShouldUpdateDeployment: true,
 			wantErr:                    false,
@@ -913,6 +930,7 @@ func TestProvider_checkForUpdateSemver(t *testing.T) {
 				}),
 				NewVersion:     "1.1.2",
 				CurrentVersion: "1.1.1",
+				Images:         []string{"gcr.io/v2-namespace/hello-world:1.1.1"},
 			},
 			wantShouldUpdateDeployment: true,
 			wantErr:                    false,
@@ -984,6 +1002,7 @@ func TestProvider_checkForUpdateSemver(t *testing.T) {
 				}),
 				NewVersion:     "1.1.2",
 				CurrentVersion: "latest",
+				Images:         []string{"gcr.io/v2-namespace/hello-world:latest"},
 			},
 			wantShouldUpdateDeployment: true,
 			wantErr:                    false,
@@ -1061,6 +1080,7 @@ func TestProvider_checkForUpdateSemver(t *testing.T) {
 				}),
 				NewVersion:     "1.1.2",
 				CurrentVersion: "1.1.2",
+				Images:         []string{"gcr.io/v2-namespace/hello-world:1.1.2"},
 			},
 			wantShouldUpdateDeployment: true,
 			wantErr:                    false,
@@ -1111,11 +1131,173 @@ func TestProvider_checkForUpdateSemver(t *testing.T) {
 			wantShouldUpdateDeployment: false,
 			wantErr:                    false,
 		},
+		{
+			name: "private registry with port",
+			args: args{
+				policy: policy.NewSemverPolicy(policy.SemverPolicyTypeAll),
+				repo:   &types.Repository{Name: "registry.corp:5000/team/app", Tag: "1.2.4"},
+				resource: MustParseGR(&apps_v1.Deployment{
+					meta_v1.TypeMeta{},
+					meta_v1.ObjectMeta{
+						Name:        "dep-1",
+						Namespace:   "xxxx",
+						Annotations: map[string]string{},
+						Labels:      map[string]string{types.BowPolicyLabel: "all"},
+					},
+					apps_v1.DeploymentSpec{
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									v1.Container{
+										Image: "registry.corp:5000/team/app:1.2.3",
+									},
+								},
+							},
+						},
+					},
+					apps_v1.DeploymentStatus{},
+				}),
+			},
+			wantUpdatePlan: &UpdatePlan{
+				Resource: MustParseGR(&apps_v1.Deployment{
+					meta_v1.TypeMeta{},
+					meta_v1.ObjectMeta{
+						Name:        "dep-1",
+						Namespace:   "xxxx",
+						Annotations: map[string]string{},
+						Labels:      map[string]string{types.BowPolicyLabel: "all"},
+					},
+					apps_v1.DeploymentSpec{
+						Template: v1.PodTemplateSpec{
+							ObjectMeta: meta_v1.ObjectMeta{
+								Annotations: map[string]string{},
+							},
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									v1.Container{
+										Image: "registry.corp:5000/team/app:1.2.4",
+									},
+								},
+							},
+						},
+					},
+					apps_v1.DeploymentStatus{},
+				}),
+				NewVersion:     "1.2.4",
+				CurrentVersion: "1.2.3",
+				Images:         []string{"registry.corp:5000/team/app:1.2.3"},
+			},
+			wantShouldUpdateDeployment: true,
+			wantErr:                    false,
+		},
+		{
+			name: "don't force downgrade - no downgrade",
+			args: args{
+				policy: mustGetPolicy("force", &policy.Options{NoDowngrade: true}),
+				repo:   &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.1"},
+				resource: MustParseGR(&apps_v1.Deployment{
+					meta_v1.TypeMeta{},
+					meta_v1.ObjectMeta{
+						Name:        "dep-1",
+						Namespace:   "xxxx",
+						Annotations: map[string]string{},
+						Labels: map[string]string{
+							types.BowPolicyLabel:           "force",
+							types.BowForceNoDowngradeLabel: "true",
+						},
+					},
+					apps_v1.DeploymentSpec{
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									v1.Container{
+										Image: "gcr.io/v2-namespace/hello-world:1.1.2",
+									},
+								},
+							},
+						},
+					},
+					apps_v1.DeploymentStatus{},
+				}),
+			},
+			wantUpdatePlan: &UpdatePlan{
+				Resource:       nil,
+				NewVersion:     "",
+				CurrentVersion: "",
+			},
+			wantShouldUpdateDeployment: false,
+			wantErr:                    false,
+		},
+		{
+			name: "force update from latest - no downgrade",
+			args: args{
+				policy: mustGetPolicy("force", &policy.Options{NoDowngrade: true}),
+				repo:   &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.1"},
+				resource: MustParseGR(&apps_v1.Deployment{
+					meta_v1.TypeMeta{},
+					meta_v1.ObjectMeta{
+						Name:        "dep-1",
+						Namespace:   "xxxx",
+						Annotations: map[string]string{},
+						Labels: map[string]string{
+							types.BowPolicyLabel:           "force",
+							types.BowForceNoDowngradeLabel: "true",
+						},
+					},
+					apps_v1.DeploymentSpec{
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									v1.Container{
+										Image: "gcr.io/v2-namespace/hello-world:latest",
+									},
+								},
+							},
+						},
+					},
+					apps_v1.DeploymentStatus{},
+				}),
+			},
+			wantUpdatePlan: &UpdatePlan{
+				Resource: MustParseGR(&apps_v1.Deployment{
+					meta_v1.TypeMeta{},
+					meta_v1.ObjectMeta{
+						Name:        "dep-1",
+						Namespace:   "xxxx",
+						Annotations: map[string]string{},
+						Labels: map[string]string{
+							types.BowPolicyLabel:           "force",
+							types.BowForceNoDowngradeLabel: "true",
+						},
+					},
+					apps_v1.DeploymentSpec{
+						Template: v1.PodTemplateSpec{
+							ObjectMeta: meta_v1.ObjectMeta{
+								Annotations: map[string]string{},
+							},
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									v1.Container{
+										Image: "gcr.io/v2-namespace/hello-world:1.1.1",
+									},
+								},
+							},
+						},
+					},
+					apps_v1.DeploymentStatus{},
+				}),
+				NewVersion:     "1.1.1",
+				CurrentVersion: "latest",
+				Images:         []string{"gcr.io/v2-namespace/hello-world:latest"},
+			},
+			wantShouldUpdateDeployment: true,
+			wantErr:                    false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotUpdatePlan, gotShouldUpdateDeployment, err := checkForUpdate(tt.args.policy, tt.args.repo, tt.args.resource)
+			gotUpdatePlan, gotShouldUpdateDeployment, err := checkForUpdate(context.Background(), tt.args.policy, tt.args.repo, tt.args.resource)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Provider.checkVersionedDeployment() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1141,3 +1323,932 @@ func TestProvider_checkForUpdateSemver(t *testing.T) {
 		})
 	}
 }
+
+func TestProvider_checkForUpdateIgnoredContainers(t *testing.T) {
+	deployment := func(ignored string) *k8s.GenericResource {
+		return MustParseGR(&apps_v1.Deployment{
+			TypeMeta: meta_v1.TypeMeta{},
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:        "dep-1",
+				Namespace:   "xxxx",
+				Annotations: map[string]string{types.BowIgnoreContainersAnnotation: ignored},
+				Labels:      map[string]string{types.BowPolicyLabel: "all"},
+			},
+			Spec: apps_v1.DeploymentSpec{
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{
+							{
+								Name:  "app",
+								Image: "gcr.io/v2-namespace/hello-world:1.1.1",
+							},
+							{
+								Name:  "istio-proxy",
+								Image: "gcr.io/v2-namespace/hello-world:1.0.0",
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+
+	repo := &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.2"}
+
+	plan, shouldUpdate, err := checkForUpdate(context.Background(), mustGetPolicy("all", nil), repo, deployment("istio-proxy, cloudsql-proxy"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !shouldUpdate {
+		t.Fatalf("expected app container to be updated")
+	}
+	// ignored sidecar would have been the last matching container
+	if plan.CurrentVersion != "1.1.1" || plan.NewVersion != "1.1.2" {
+		t.Errorf("unexpected plan versions: %s->%s", plan.CurrentVersion, plan.NewVersion)
+	}
+
+	_, shouldUpdate, err = checkForUpdate(context.Background(), mustGetPolicy("all", nil), repo, deployment("app,istio-proxy"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if shouldUpdate {
+		t.Errorf("expected no update when all matching containers are ignored")
+	}
+}
+
+func TestProvider_checkForUpdateManifestList(t *testing.T) {
+	resource := MustParseGR(&apps_v1.Deployment{
+		TypeMeta: meta_v1.TypeMeta{},
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "dep-1",
+			Namespace:   "xxxx",
+			Annotations: map[string]string{types.BowPlatformAnnotation: "app=linux/amd64, app-arm=linux/arm64"},
+			Labels:      map[string]string{types.BowPolicyLabel: "force"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "app",
+							Image: "gcr.io/v2-namespace/hello-world:1.1.1@sha256:aaa",
+						},
+						{
+							Name:  "app-arm",
+							Image: "gcr.io/v2-namespace/hello-world:1.1.1@sha256:bbb",
+						},
+					},
+				},
+			},
+		},
+	})
+
+	plc := mustGetPolicy("force", &policy.Options{MatchTag: true})
+
+	// only linux/amd64 digest changed
+	plan, shouldUpdate, err := checkForUpdate(context.Background(), plc, &types.Repository{
+		Name:     "gcr.io/v2-namespace/hello-world",
+		Tag:      "1.1.1",
+		Digest:   "sha256:ccc",
+		Platform: "linux/amd64",
+	}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !shouldUpdate {
+		t.Fatalf("expected linux/amd64 container to be updated")
+	}
+	if plan.Platform != "linux/amd64" || plan.Digest != "sha256:ccc" {
+		t.Errorf("unexpected plan platform and digest: %s %s", plan.Platform, plan.Digest)
+	}
+	if plan.CurrentVersion != "1.1.1" || plan.NewVersion != "1.1.1" {
+		t.Errorf("unexpected plan versions: %s->%s", plan.CurrentVersion, plan.NewVersion)
+	}
+
+	// linux/arm64 entry of the same manifest list is unchanged
+	_, shouldUpdate, err = checkForUpdate(context.Background(), plc, &types.Repository{
+		Name:     "gcr.io/v2-namespace/hello-world",
+		Tag:      "1.1.1",
+		Digest:   "sha256:bbb",
+		Platform: "linux/arm64",
+	}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if shouldUpdate {
+		t.Errorf("expected no update for unchanged linux/arm64 digest")
+	}
+}
+
+func TestProvider_checkForUpdateExcludedContainers(t *testing.T) {
+	resource := MustParseGR(&apps_v1.Deployment{
+		TypeMeta: meta_v1.TypeMeta{},
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "dep-1",
+			Namespace:   "xxxx",
+			Annotations: map[string]string{types.BowExcludeContainersAnnotation: "debug"},
+			Labels:      map[string]string{types.BowPolicyLabel: "all"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "app",
+							Image: "gcr.io/v2-namespace/hello-world:1.1.1",
+						},
+						{
+							Name:  "debug",
+							Image: "gcr.io/v2-namespace/hello-world:1.0.0",
+						},
+					},
+				},
+			},
+		},
+	})
+
+	plan, shouldUpdate, err := checkForUpdate(context.Background(), mustGetPolicy("all", nil), &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.2"}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !shouldUpdate {
+		t.Fatalf("expected app container to be updated")
+	}
+	if plan.CurrentVersion != "1.1.1" || plan.NewVersion != "1.1.2" {
+		t.Errorf("unexpected plan versions: %s->%s", plan.CurrentVersion, plan.NewVersion)
+	}
+
+	containers := plan.Resource.Containers()
+	if containers[1].Image != "gcr.io/v2-namespace/hello-world:1.0.0" {
+		t.Errorf("excluded container image changed: %s", containers[1].Image)
+	}
+}
+
+func TestProvider_checkForUpdatePreRelease(t *testing.T) {
+	resource := func(tag string) *k8s.GenericResource {
+		return MustParseGR(&apps_v1.Deployment{
+			TypeMeta: meta_v1.TypeMeta{},
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:        "dep-1",
+				Namespace:   "xxxx",
+				Annotations: map[string]string{},
+				Labels:      map[string]string{types.BowPolicyLabel: "prerelease"},
+			},
+			Spec: apps_v1.DeploymentSpec{
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{
+							{
+								Image: "gcr.io/v2-namespace/hello-prerelease:" + tag,
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+
+	tests := []struct {
+		name       string
+		currentTag string
+		newTag     string
+		want       bool
+	}{
+		{name: "staging pre-release", currentTag: "v1.1.1", newTag: "v1.1.2-staging", want: true},
+		{name: "pre-release to stable promotion", currentTag: "v1.1.2-staging", newTag: "v1.1.2", want: true},
+		{name: "stable to pre-release demotion", currentTag: "v1.1.2", newTag: "v1.1.2-staging", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, shouldUpdate, err := checkForUpdate(context.Background(),
+				mustGetPolicy("prerelease", nil),
+				&types.Repository{Name: "gcr.io/v2-namespace/hello-prerelease", Tag: tt.newTag},
+				resource(tt.currentTag),
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if shouldUpdate != tt.want {
+				t.Fatalf("checkForUpdate(context.Background(), ) shouldUpdate = %v, want %v", shouldUpdate, tt.want)
+			}
+			if shouldUpdate && (plan.CurrentVersion != tt.currentTag || plan.NewVersion != tt.newTag) {
+				t.Errorf("unexpected plan versions: %s->%s", plan.CurrentVersion, plan.NewVersion)
+			}
+		})
+	}
+}
+
+func TestProvider_setReleaseNotesAfterSemverBump(t *testing.T) {
+	resource := MustParseGR(&apps_v1.Deployment{
+		TypeMeta: meta_v1.TypeMeta{},
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "dep-1",
+			Namespace:   "xxxx",
+			Annotations: map[string]string{types.BowReleaseNotesURL: "https://github.com/alwinius/bow/releases"},
+			Labels:      map[string]string{types.BowPolicyLabel: "minor"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Image: "gcr.io/v2-namespace/hello-world:1.1.1",
+						},
+					},
+				},
+			},
+		},
+	})
+
+	plan, shouldUpdate, err := checkForUpdate(context.Background(),
+		mustGetPolicy("minor", nil),
+		&types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.2.0"},
+		resource,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !shouldUpdate {
+		t.Fatalf("expected semver bump to be applied")
+	}
+
+	setReleaseNotes(plan.Resource, plan.ReleaseNotes)
+
+	got := plan.Resource.GetSpecAnnotations()[types.BowReleaseNotesAnnotation]
+	if got != "https://github.com/alwinius/bow/releases" {
+		t.Errorf("unexpected release notes annotation: %s", got)
+	}
+}
+
+func TestProvider_checkForUpdateResolvedDigest(t *testing.T) {
+	resource := MustParseGR(&apps_v1.Deployment{
+		TypeMeta: meta_v1.TypeMeta{},
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "dep-1",
+			Namespace: "xxxx",
+			Labels:    map[string]string{types.BowPolicyLabel: "force"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "app",
+							Image: "gcr.io/v2-namespace/hello-world:latest",
+						},
+					},
+				},
+			},
+		},
+	})
+
+	plc := mustGetPolicy("force", &policy.Options{MatchTag: true})
+
+	_, shouldUpdate, err := checkForUpdate(context.Background(), plc, &types.Repository{
+		Name:   "gcr.io/v2-namespace/hello-world",
+		Tag:    "latest",
+		Digest: "sha256:ccc",
+	}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !shouldUpdate {
+		t.Fatalf("expected resource to be updated")
+	}
+
+	ann := resource.GetSpecAnnotations()
+	if ann[types.BowUpdateTimeAnnotation] == "" {
+		t.Errorf("missing update time annotation")
+	}
+	if ann[types.BowResolvedDigestAnnotation] != "sha256:ccc" {
+		t.Errorf("unexpected resolved digest: %s", ann[types.BowResolvedDigestAnnotation])
+	}
+
+	// digest is unknown, stale one is removed
+	_, shouldUpdate, err = checkForUpdate(context.Background(), plc, &types.Repository{
+		Name: "gcr.io/v2-namespace/hello-world",
+		Tag:  "latest",
+	}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !shouldUpdate {
+		t.Fatalf("expected resource to be updated")
+	}
+	if _, ok := resource.GetSpecAnnotations()[types.BowResolvedDigestAnnotation]; ok {
+		t.Errorf("expected stale resolved digest to be removed")
+	}
+}
+
+func TestProvider_checkForUpdateSkipUnchangedDigest(t *testing.T) {
+	resource := MustParseGR(&apps_v1.Deployment{
+		TypeMeta: meta_v1.TypeMeta{},
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "dep-1",
+			Namespace:   "xxxx",
+			Labels:      map[string]string{types.BowPolicyLabel: "force"},
+			Annotations: map[string]string{types.BowSkipUnchangedDigestAnnotation: "true"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Annotations: map[string]string{types.BowResolvedDigestAnnotation: "sha256:aaa"},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "app",
+							Image: "gcr.io/v2-namespace/hello-world:master",
+						},
+					},
+				},
+			},
+		},
+	})
+
+	plc := mustGetPolicy("force", &policy.Options{MatchTag: true})
+
+	_, shouldUpdate, err := checkForUpdate(context.Background(), plc, &types.Repository{
+		Name:   "gcr.io/v2-namespace/hello-world",
+		Tag:    "master",
+		Digest: "sha256:aaa",
+	}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if shouldUpdate {
+		t.Errorf("expected no update for unchanged digest")
+	}
+
+	_, shouldUpdate, err = checkForUpdate(context.Background(), plc, &types.Repository{
+		Name:   "gcr.io/v2-namespace/hello-world",
+		Tag:    "master",
+		Digest: "sha256:bbb",
+	}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !shouldUpdate {
+		t.Errorf("expected update for new digest")
+	}
+	if resource.GetSpecAnnotations()[types.BowResolvedDigestAnnotation] != "sha256:bbb" {
+		t.Errorf("expected new digest to be recorded")
+	}
+}
+
+func TestProvider_checkForUpdateMatchDigest(t *testing.T) {
+	resource := MustParseGR(&apps_v1.Deployment{
+		TypeMeta: meta_v1.TypeMeta{},
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "dep-1",
+			Namespace: "xxxx",
+			Labels:    map[string]string{types.BowPolicyLabel: "force", types.BowForceDigestMatchLabel: "true"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "app",
+							Image: "gcr.io/v2-namespace/hello-world:latest@sha256:aaa",
+						},
+					},
+				},
+			},
+		},
+	})
+
+	plc := mustGetPolicy("force", &policy.Options{MatchDigest: true})
+
+	for _, digest := range []string{"", "sha256:aaa"} {
+		_, shouldUpdate, err := checkForUpdate(context.Background(), plc, &types.Repository{
+			Name:   "gcr.io/v2-namespace/hello-world",
+			Tag:    "latest",
+			Digest: digest,
+		}, resource)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if shouldUpdate {
+			t.Errorf("expected no update for digest %q", digest)
+		}
+	}
+
+	plan, shouldUpdate, err := checkForUpdate(context.Background(), plc, &types.Repository{
+		Name:   "gcr.io/v2-namespace/hello-world",
+		Tag:    "latest",
+		Digest: "sha256:bbb",
+	}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !shouldUpdate {
+		t.Fatalf("expected update for new digest of the same tag")
+	}
+	if plan.Digest != "sha256:bbb" {
+		t.Errorf("expected new digest to be pinned, got: %s", plan.Digest)
+	}
+	if plan.CurrentVersion != "latest" || plan.NewVersion != "latest" {
+		t.Errorf("unexpected versions: %s->%s", plan.CurrentVersion, plan.NewVersion)
+	}
+
+	// other tags are never updated
+	_, shouldUpdate, err = checkForUpdate(context.Background(), plc, &types.Repository{
+		Name:   "gcr.io/v2-namespace/hello-world",
+		Tag:    "master",
+		Digest: "sha256:ccc",
+	}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if shouldUpdate {
+		t.Errorf("expected other tag to be ignored")
+	}
+}
+
+func TestProvider_checkForUpdateDigestPolicy(t *testing.T) {
+	resource := MustParseGR(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "dep-1",
+			Namespace:   "xxxx",
+			Annotations: map[string]string{types.BowPolicyLabel: "digest"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Annotations: map[string]string{types.BowResolvedDigestAnnotation: "sha256:aaa"},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "app",
+							Image: "gcr.io/v2-namespace/hello-world:1.2.3",
+						},
+					},
+				},
+			},
+		},
+	})
+
+	plc := mustGetPolicy("digest", &policy.Options{})
+
+	// semver says the same version, digest is unknown or unchanged
+	for _, digest := range []string{"", "sha256:aaa"} {
+		_, shouldUpdate, err := checkForUpdate(context.Background(), plc, &types.Repository{
+			Name:   "gcr.io/v2-namespace/hello-world",
+			Tag:    "1.2.3",
+			Digest: digest,
+		}, resource)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if shouldUpdate {
+			t.Errorf("expected no update for digest %q", digest)
+		}
+	}
+
+	// higher versions are left to semver policies
+	_, shouldUpdate, err := checkForUpdate(context.Background(), plc, &types.Repository{
+		Name:   "gcr.io/v2-namespace/hello-world",
+		Tag:    "1.2.4",
+		Digest: "sha256:ccc",
+	}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if shouldUpdate {
+		t.Errorf("expected other tag to be ignored")
+	}
+
+	// rebuilt tag
+	plan, shouldUpdate, err := checkForUpdate(context.Background(), plc, &types.Repository{
+		Name:   "gcr.io/v2-namespace/hello-world",
+		Tag:    "1.2.3",
+		Digest: "sha256:bbb",
+	}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !shouldUpdate {
+		t.Fatalf("expected update for rebuilt tag")
+	}
+	if plan.CurrentVersion != "1.2.3" || plan.NewVersion != "1.2.3" {
+		t.Errorf("unexpected versions: %s->%s", plan.CurrentVersion, plan.NewVersion)
+	}
+	if resource.GetSpecAnnotations()[types.BowResolvedDigestAnnotation] != "sha256:bbb" {
+		t.Errorf("expected new digest to be recorded")
+	}
+}
+
+func TestProvider_checkForUpdateImagePullPolicy(t *testing.T) {
+	deployment := func(pullPolicy string) *k8s.GenericResource {
+		return MustParseGR(&apps_v1.Deployment{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:        "dep-1",
+				Namespace:   "xxxx",
+				Annotations: map[string]string{types.BowPolicyLabel: "minor", types.BowImagePullPolicyAnnotation: pullPolicy},
+			},
+			Spec: apps_v1.DeploymentSpec{
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{
+							{
+								Name:            "app",
+								Image:           "gcr.io/v2-namespace/hello-world:1.1.1",
+								ImagePullPolicy: v1.PullIfNotPresent,
+							},
+							{
+								Name:            "sidecar",
+								Image:           "gcr.io/v2-namespace/sidecar:1.0.0",
+								ImagePullPolicy: v1.PullIfNotPresent,
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+
+	tests := []struct {
+		name       string
+		pullPolicy string
+		want       v1.PullPolicy
+	}{
+		{name: "always", pullPolicy: "Always", want: v1.PullAlways},
+		{name: "never", pullPolicy: "Never", want: v1.PullNever},
+		{name: "unset keeps policy", pullPolicy: "", want: v1.PullIfNotPresent},
+		{name: "invalid keeps policy", pullPolicy: "sometimes", want: v1.PullIfNotPresent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, shouldUpdate, err := checkForUpdate(context.Background(), mustGetPolicy("minor", nil), &types.Repository{
+				Name: "gcr.io/v2-namespace/hello-world",
+				Tag:  "1.1.2",
+			}, deployment(tt.pullPolicy))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !shouldUpdate {
+				t.Fatalf("expected update")
+			}
+			containers := plan.Resource.Containers()
+			if containers[0].ImagePullPolicy != tt.want {
+				t.Errorf("unexpected image pull policy: %s, want: %s", containers[0].ImagePullPolicy, tt.want)
+			}
+			if containers[1].ImagePullPolicy != v1.PullIfNotPresent {
+				t.Errorf("not updated container pull policy changed: %s", containers[1].ImagePullPolicy)
+			}
+		})
+	}
+}
+
+func TestProvider_checkForUpdateInitContainers(t *testing.T) {
+	resource := MustParseGR(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "dep-1",
+			Namespace:   "xxxx",
+			Annotations: map[string]string{types.BowPolicyLabel: "minor", types.BowIgnoreContainersAnnotation: "ignored"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{
+						{
+							Name:  "migrations",
+							Image: "gcr.io/v2-namespace/hello-world:1.1.1",
+						},
+						{
+							Name:  "ignored",
+							Image: "gcr.io/v2-namespace/hello-world:1.1.1",
+						},
+						{
+							Name:  "wait-for-db",
+							Image: "gcr.io/v2-namespace/busybox:1.0.0",
+						},
+					},
+					Containers: []v1.Container{
+						{
+							Name:  "app",
+							Image: "gcr.io/v2-namespace/hello-world:1.1.1",
+						},
+					},
+				},
+			},
+		},
+	})
+
+	plan, shouldUpdate, err := checkForUpdate(context.Background(), mustGetPolicy("minor", nil), &types.Repository{
+		Name: "gcr.io/v2-namespace/hello-world",
+		Tag:  "1.1.2",
+	}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !shouldUpdate {
+		t.Fatalf("expected update")
+	}
+	if !reflect.DeepEqual(plan.Images, []string{"gcr.io/v2-namespace/hello-world:1.1.1"}) {
+		t.Errorf("unexpected plan images: %v", plan.Images)
+	}
+
+	initContainers := plan.Resource.InitContainers()
+	if initContainers[0].Image != "gcr.io/v2-namespace/hello-world:1.1.2" {
+		t.Errorf("expected migrations init container to be updated, got: %s", initContainers[0].Image)
+	}
+	if initContainers[1].Image != "gcr.io/v2-namespace/hello-world:1.1.1" {
+		t.Errorf("expected ignored init container to stay unchanged, got: %s", initContainers[1].Image)
+	}
+	if initContainers[2].Image != "gcr.io/v2-namespace/busybox:1.0.0" {
+		t.Errorf("expected other init container image to stay unchanged, got: %s", initContainers[2].Image)
+	}
+
+	// image only used by an init container
+	plan, shouldUpdate, err = checkForUpdate(context.Background(), mustGetPolicy("minor", nil), &types.Repository{
+		Name: "gcr.io/v2-namespace/busybox",
+		Tag:  "1.1.0",
+	}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !shouldUpdate {
+		t.Fatalf("expected init container image update")
+	}
+	if plan.CurrentVersion != "1.0.0" || plan.NewVersion != "1.1.0" {
+		t.Errorf("unexpected versions: %s->%s", plan.CurrentVersion, plan.NewVersion)
+	}
+	if !reflect.DeepEqual(plan.Images, []string{"gcr.io/v2-namespace/busybox:1.0.0"}) {
+		t.Errorf("unexpected plan images: %v", plan.Images)
+	}
+}
+
+func TestProvider_checkForUpdateRollout(t *testing.T) {
+	resource := MustParseGR(&k8s.Rollout{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "canary",
+			Namespace: "xxxx",
+			Labels:    map[string]string{types.BowPolicyLabel: "minor"},
+		},
+		Spec: k8s.RolloutSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "app",
+							Image: "gcr.io/v2-namespace/hello-world:1.1.1",
+						},
+					},
+				},
+			},
+		},
+	})
+
+	plan, shouldUpdate, err := checkForUpdate(context.Background(), mustGetPolicy("minor", nil), &types.Repository{
+		Name: "gcr.io/v2-namespace/hello-world",
+		Tag:  "1.2.0",
+	}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !shouldUpdate {
+		t.Fatalf("expected rollout to be updated")
+	}
+	if plan.CurrentVersion != "1.1.1" || plan.NewVersion != "1.2.0" {
+		t.Errorf("unexpected plan versions: %s->%s", plan.CurrentVersion, plan.NewVersion)
+	}
+	if plan.Resource.Identifier != "rollout/xxxx/canary" {
+		t.Errorf("unexpected identifier: %s", plan.Resource.Identifier)
+	}
+	if images := plan.Resource.GetImages(); len(images) != 1 || images[0] != "gcr.io/v2-namespace/hello-world:1.2.0" {
+		t.Errorf("unexpected images of the updated rollout: %v", images)
+	}
+	if _, ok := resource.GetSpecAnnotations()[types.BowUpdateTimeAnnotation]; !ok {
+		t.Errorf("missing update time annotation")
+	}
+}
+
+func TestProvider_checkForUpdateSameRepositoryContainers(t *testing.T) {
+	resource := MustParseGR(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "dep-1",
+			Namespace: "xxxx",
+			Labels:    map[string]string{types.BowPolicyLabel: "minor"},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "app",
+							Image: "gcr.io/v2-namespace/hello-world:1.1.1",
+						},
+						{
+							Name:  "debug",
+							Image: "gcr.io/v2-namespace/hello-world:1.1.0",
+						},
+						{
+							Name:  "app-copy",
+							Image: "gcr.io/v2-namespace/hello-world:1.1.1",
+						},
+						{
+							Name:  "other",
+							Image: "gcr.io/v2-namespace/goodbye-world:1.1.0",
+						},
+					},
+				},
+			},
+		},
+	})
+
+	plan, shouldUpdate, err := checkForUpdate(context.Background(), mustGetPolicy("minor", nil), &types.Repository{
+		Name: "gcr.io/v2-namespace/hello-world",
+		Tag:  "1.2.0",
+	}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !shouldUpdate {
+		t.Fatalf("expected deployment to be updated")
+	}
+
+	// single plan updating both tags of the repository
+	want := []string{"gcr.io/v2-namespace/hello-world:1.1.1", "gcr.io/v2-namespace/hello-world:1.1.0"}
+	if !reflect.DeepEqual(plan.Images, want) {
+		t.Errorf("unexpected plan images: %v", plan.Images)
+	}
+	if plan.CurrentVersion != "1.1.1" || plan.NewVersion != "1.2.0" {
+		t.Errorf("unexpected plan versions: %s->%s", plan.CurrentVersion, plan.NewVersion)
+	}
+}
+
+func TestProvider_checkForUpdateKustomizeImage(t *testing.T) {
+	resource := MustParseGR(&apps_v1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "dep-1",
+			Namespace: "xxxx",
+			Labels:    map[string]string{types.BowPolicyLabel: "minor"},
+			Annotations: map[string]string{
+				types.BowKustomizeImageAnnotation: "app=gcr.io/v2-namespace/hello-world:1.1.1, broken",
+			},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "app",
+							Image: "app",
+						},
+					},
+				},
+			},
+		},
+	})
+
+	plan, shouldUpdate, err := checkForUpdate(context.Background(), mustGetPolicy("minor", nil), &types.Repository{
+		Name: "gcr.io/v2-namespace/hello-world",
+		Tag:  "1.2.0",
+	}, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !shouldUpdate {
+		t.Fatalf("expected kustomize image to be updated")
+	}
+	if plan.CurrentVersion != "1.1.1" || plan.NewVersion != "1.2.0" {
+		t.Errorf("unexpected plan versions: %s->%s", plan.CurrentVersion, plan.NewVersion)
+	}
+	// kustomize image is rewritten instead of the container image
+	if !reflect.DeepEqual(plan.Images, []string{"gcr.io/v2-namespace/hello-world:1.1.1"}) {
+		t.Errorf("unexpected plan images: %v", plan.Images)
+	}
+}
+
+func TestCheckForUpdateUpdateTimeAnnotation(t *testing.T) {
+	defer func(key string, mode UpdateTimeMode) {
+		UpdateTimeAnnotation, UpdateTimeAnnotationMode = key, mode
+	}(UpdateTimeAnnotation, UpdateTimeAnnotationMode)
+	UpdateTimeAnnotation = "gitops.example.com/ignored-update-time"
+
+	newResource := func(img string) *k8s.GenericResource {
+		gr, err := k8s.NewGenericResource(&apps_v1.Deployment{
+			ObjectMeta: meta_v1.ObjectMeta{Name: "dep-1", Namespace: "xxxx"},
+			Spec: apps_v1.DeploymentSpec{
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{{Name: "app", Image: img}},
+					},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to create resource: %s", err)
+		}
+		return gr
+	}
+
+	tests := []struct {
+		name     string
+		mode     UpdateTimeMode
+		policy   policy.Policy
+		image    string
+		newTag   string
+		wantTime bool
+	}{
+		{"always, new tag", UpdateTimeAlways, mustGetPolicy("minor", nil), "gcr.io/v2-namespace/hello-world:1.1.1", "1.2.0", true},
+		{"same image, new tag", UpdateTimeSameImage, mustGetPolicy("minor", nil), "gcr.io/v2-namespace/hello-world:1.1.1", "1.2.0", false},
+		{"same image, same tag", UpdateTimeSameImage, policy.NewForcePolicy(true), "gcr.io/v2-namespace/hello-world:latest", "latest", true},
+		{"never", UpdateTimeNever, policy.NewForcePolicy(true), "gcr.io/v2-namespace/hello-world:latest", "latest", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			UpdateTimeAnnotationMode = tt.mode
+			resource := newResource(tt.image)
+
+			_, shouldUpdate, err := checkForUpdate(context.Background(), tt.policy, &types.Repository{
+				Name: "gcr.io/v2-namespace/hello-world",
+				Tag:  tt.newTag,
+			}, resource)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !shouldUpdate {
+				t.Fatalf("expected resource to be updated")
+			}
+
+			ann := resource.GetSpecAnnotations()
+			if _, ok := ann[UpdateTimeAnnotation]; ok != tt.wantTime {
+				t.Errorf("expected update time annotation: %v, got annotations: %v", tt.wantTime, ann)
+			}
+			if _, ok := ann[types.BowUpdateTimeAnnotation]; ok {
+				t.Errorf("default update time annotation shouldn't be set")
+			}
+		})
+	}
+}
+
+func TestParseUpdateTimeMode(t *testing.T) {
+	for mode, want := range map[string]UpdateTimeMode{"": UpdateTimeAlways, "always": UpdateTimeAlways, "same-image": UpdateTimeSameImage, "never": UpdateTimeNever} {
+		got, err := ParseUpdateTimeMode(mode)
+		if err != nil || got != want {
+			t.Errorf("ParseUpdateTimeMode(%q) = %s, %v, want %s", mode, got, err, want)
+		}
+	}
+	if _, err := ParseUpdateTimeMode("sometimes"); err == nil {
+		t.Errorf("expected error for invalid mode")
+	}
+}
+
+func TestCheckForUpdateImageHashRollout(t *testing.T) {
+	defer func(enabled bool) { ImageHashRollout = enabled }(ImageHashRollout)
+	ImageHashRollout = true
+
+	tests := []struct {
+		name     string
+		digest   string
+		wantHash string
+		wantTime bool
+	}{
+		{"digest", "sha256:0123456789abcdef0123456789abcdef", "0123456789ab", false},
+		{"unknown digest", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, err := k8s.NewGenericResource(&apps_v1.Deployment{
+				ObjectMeta: meta_v1.ObjectMeta{Name: "dep-1", Namespace: "xxxx"},
+				Spec: apps_v1.DeploymentSpec{
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{{Name: "app", Image: "gcr.io/v2-namespace/hello-world:latest"}},
+						},
+					},
+				},
+			})
+			if err != nil {
+				t.Fatalf("failed to create resource: %s", err)
+			}
+
+			_, shouldUpdate, err := checkForUpdate(context.Background(), policy.NewForcePolicy(true), &types.Repository{
+				Name:   "gcr.io/v2-namespace/hello-world",
+				Tag:    "latest",
+				Digest: tt.digest,
+			}, resource)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !shouldUpdate {
+				t.Fatalf("expected resource to be updated")
+			}
+
+			ann := resource.GetSpecAnnotations()
+			if ann[types.BowImageHashAnnotation] != tt.wantHash {
+				t.Errorf("expected image hash %q, got %q", tt.wantHash, ann[types.BowImageHashAnnotation])
+			}
+			if _, ok := ann[types.BowUpdateTimeAnnotation]; ok != tt.wantTime {
+				t.Errorf("expected update time annotation: %v, got annotations: %v", tt.wantTime, ann)
+			}
+		})
+	}
+}
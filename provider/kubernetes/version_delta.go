@@ -0,0 +1,148 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/internal/policy"
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/image"
+	"github.com/alwinius/bow/util/trace"
+	"github.com/alwinius/bow/util/version"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// VersionDeltaStepDelay - how long after an intermediate update limited by
+// bow/max-version-delta the event is replayed to step up further
+var VersionDeltaStepDelay = 5 * time.Minute
+
+// exceedsVersionDelta - whether update from current to new tag is larger than the delta,
+// tags that aren't semantic versions can't be compared and never exceed it
+func exceedsVersionDelta(delta *types.VersionDelta, current, new string) bool {
+	if delta == nil {
+		return false
+	}
+	currentVersion, err := version.GetVersion(current)
+	if err != nil {
+		return false
+	}
+	newVersion, err := version.GetVersion(new)
+	if err != nil {
+		return false
+	}
+	return !delta.Allows(currentVersion, newVersion)
+}
+
+// withVersionDeltaStep - copy of the repository with the highest tag that the policy
+// accepts within bow/max-version-delta of the resource, repository is returned unchanged
+// when the event tag is within the delta or no such tag can be found
+func (p *Provider) withVersionDeltaStep(ctx context.Context, plc policy.Policy, repo *types.Repository, resource *k8s.GenericResource) *types.Repository {
+	delta, err := types.ParseMaxVersionDelta(resource.GetAnnotations())
+	if err != nil || delta == nil {
+		return repo
+	}
+
+	current := currentTag(repo, resource)
+	if current == "" || !exceedsVersionDelta(delta, current, repo.Tag) {
+		return repo
+	}
+
+	tags, err := p.repositoryTags(repo, resource)
+	if err != nil {
+		trace.Log(ctx).WithFields(log.Fields{
+			"error": err,
+			"image": repo.String(),
+		}).Warn("provider.kubernetes: failed to get repository tags, update exceeding max version delta is skipped")
+		return repo
+	}
+
+	var step string
+	higher := policy.NewSemverPolicy(policy.SemverPolicyTypeAll)
+	for _, tag := range tags {
+		if exceedsVersionDelta(delta, current, tag) {
+			continue
+		}
+		if ok, err := plc.ShouldUpdate(current, tag); err != nil || !ok {
+			continue
+		}
+		if step != "" {
+			if ok, err := higher.ShouldUpdate(step, tag); err != nil || !ok {
+				continue
+			}
+		}
+		step = tag
+	}
+	if step == "" {
+		return repo
+	}
+
+	trace.Log(ctx).WithFields(log.Fields{
+		"name":        resource.Name,
+		"namespace":   resource.Namespace,
+		"kind":        resource.Kind(),
+		"current_tag": current,
+		"new_tag":     repo.Tag,
+		"step":        step,
+	}).Info("provider.kubernetes: new version exceeds max version delta, stepping up")
+
+	stepped := *repo
+	stepped.Tag = step
+	stepped.Digest = "" // digest belongs to the event tag
+	return &stepped
+}
+
+// currentTag - tag of the first container running the repository, ignored
+// containers are skipped
+func currentTag(repo *types.Repository, resource *k8s.GenericResource) string {
+	matchRepository, _, err := repositoryMatcher(repo, resource)
+	if err != nil {
+		return ""
+	}
+	ignored := types.ParseIgnoredContainers(resource.GetAnnotations())
+	kustomized := types.ParseKustomizeImages(resource.GetAnnotations())
+	for _, c := range resourceContainers(resource) {
+		if ignored[c.Name] {
+			continue
+		}
+		containerImage, _ := image.SplitDigest(kustomizedImage(kustomized, c.Image))
+		ref, err := image.Parse(containerImage)
+		if err != nil {
+			continue
+		}
+		if matchRepository(ref.Repository()) {
+			return ref.Tag()
+		}
+	}
+	return ""
+}
+
+// repositoryTags - available tags of the event repository
+func (p *Provider) repositoryTags(repo *types.Repository, resource *k8s.GenericResource) ([]string, error) {
+	if p.registryClient == nil {
+		return nil, fmt.Errorf("repository tags are not available")
+	}
+
+	ref, err := image.Parse(repo.String())
+	if err != nil {
+		return nil, err
+	}
+
+	repository, err := p.registryClient.Get(p.registryOpts(ref, resource))
+	if err != nil {
+		return nil, err
+	}
+	return repository.Tags, nil
+}
+
+// stepped - whether any of the plans stops at an intermediate version
+func stepped(plans []*UpdatePlan) bool {
+	for _, plan := range plans {
+		if plan.TargetVersion != "" && plan.TargetVersion != plan.NewVersion {
+			return true
+		}
+	}
+	return false
+}
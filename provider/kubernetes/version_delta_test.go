@@ -0,0 +1,96 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alwinius/bow/internal/k8s"
+	"github.com/alwinius/bow/types"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func versionDeltaResource(t *testing.T, tag, delta string) *k8s.GenericResource {
+	gr, err := k8s.NewGenericResource(&apps_v1.Deployment{
+		TypeMeta: meta_v1.TypeMeta{
+			Kind: "Deployment",
+		},
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "dep-1",
+			Namespace:   "xxxx",
+			Labels:      map[string]string{types.BowPolicyLabel: "major"},
+			Annotations: map[string]string{types.BowMaxVersionDeltaAnnotation: delta},
+		},
+		Spec: apps_v1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "app",
+							Image: "gcr.io/v2-namespace/hello-world:" + tag,
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create resource: %s", err)
+	}
+	return gr
+}
+
+func TestCreateUpdatePlansMaxVersionDelta(t *testing.T) {
+	tags := []string{"1.0.0", "1.4.0", "1.6.0", "2.0.0", "2.3.0", "2.3.1-rc1", "3.0.0", "latest"}
+	repo := &types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "3.0.0", Digest: "sha256:aaa"}
+
+	tests := []struct {
+		name        string
+		tag         string
+		delta       string
+		client      *fakeRegistryClient
+		wantVersion string
+	}{
+		{name: "within delta", tag: "2.3.0", delta: "major=1", client: &fakeRegistryClient{tags: tags}, wantVersion: "3.0.0"},
+		{name: "major step", tag: "1.0.0", delta: "major=1", client: &fakeRegistryClient{tags: tags}, wantVersion: "2.3.0"},
+		{name: "major and minor step", tag: "1.0.0", delta: "major=0,minor=5", client: &fakeRegistryClient{tags: tags}, wantVersion: "1.4.0"},
+		{name: "no tag within delta", tag: "1.6.0", delta: "major=0,minor=0", client: &fakeRegistryClient{tags: tags}},
+		{name: "tags not available", tag: "1.0.0", delta: "major=1", client: &fakeRegistryClient{err: errors.New("boom")}},
+		{name: "invalid delta", tag: "1.0.0", delta: "major", client: &fakeRegistryClient{tags: tags}, wantVersion: "3.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grc := &k8s.GenericResourceCache{}
+			grc.Add(versionDeltaResource(t, tt.tag, tt.delta))
+			p := &Provider{cache: grc, registryClient: tt.client}
+
+			plans, err := p.createUpdatePlans(context.Background(), repo)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tt.wantVersion == "" {
+				if len(plans) != 0 {
+					t.Errorf("expected no plans, got: %v", plans)
+				}
+				return
+			}
+			if len(plans) != 1 {
+				t.Fatalf("expected 1 plan, got: %d", len(plans))
+			}
+			plan := plans[0]
+			if plan.NewVersion != tt.wantVersion || plan.TargetVersion != "3.0.0" {
+				t.Errorf("unexpected versions: new %s, target %s, want %s", plan.NewVersion, plan.TargetVersion, tt.wantVersion)
+			}
+			if stepped(plans) != (tt.wantVersion != "3.0.0") {
+				t.Errorf("unexpected step, new version %s", plan.NewVersion)
+			}
+			if tt.wantVersion != "3.0.0" && plan.Digest != "" {
+				t.Errorf("digest of the event tag shouldn't be pinned to the step: %s", plan.Digest)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/alwinius/bow/approvals"
 	"github.com/alwinius/bow/types"
@@ -17,6 +18,12 @@ type Provider interface {
 	Stop()
 }
 
+// ReadinessChecker - optionally implemented by providers that aren't ready
+// to process events right after they are created
+type ReadinessChecker interface {
+	Ready() error
+}
+
 // Providers - available providers
 type Providers interface {
 	Submit(event types.Event) error
@@ -121,6 +128,21 @@ func (p *DefaultProviders) List() []string {
 	return list
 }
 
+// Ready - checks whether all providers are ready, providers that don't
+// implement ReadinessChecker are always ready
+func (p *DefaultProviders) Ready() error {
+	for name, provider := range p.providers {
+		rc, ok := provider.(ReadinessChecker)
+		if !ok {
+			continue
+		}
+		if err := rc.Ready(); err != nil {
+			return fmt.Errorf("provider %s is not ready: %s", name, err)
+		}
+	}
+	return nil
+}
+
 // Stop - stop all providers
 func (p *DefaultProviders) Stop() {
 	for _, provider := range p.providers {
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alwinius/bow/extension/notification"
+	"github.com/alwinius/bow/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultStartupSummaryRepositories - how many tracked repositories are listed
+// in the startup notification
+const DefaultStartupSummaryRepositories = 10
+
+// startup summary waits for providers to become ready, tracked images
+// aren't known before that
+var (
+	startupReadyInterval = time.Second
+	startupReadyTimeout  = 5 * time.Minute
+)
+
+// NotifyStartup - waits until providers are ready and sends a single notification
+// with the count of tracked images per provider and first maxRepositories repositories,
+// so operators can confirm the configuration was picked up after a restart
+func (p *DefaultProviders) NotifyStartup(sender notification.Sender, maxRepositories int) {
+	deadline := time.Now().Add(startupReadyTimeout)
+	for {
+		err := p.Ready()
+		if err == nil {
+			break
+		}
+		if !time.Now().Before(deadline) {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Warn("provider.defaultProviders: providers are not ready, sending startup summary anyway")
+			break
+		}
+		select {
+		case <-p.stopCh:
+			return
+		case <-time.After(startupReadyInterval):
+		}
+	}
+
+	sender.Send(p.startupSummary(maxRepositories))
+}
+
+// startupSummary - tracked images per provider and first maxRepositories
+// repositories sorted by name
+func (p *DefaultProviders) startupSummary(maxRepositories int) types.EventNotification {
+	names := p.List()
+	sort.Strings(names)
+
+	metadata := make(map[string]string)
+	var counts []string
+	seen := make(map[string]bool)
+	var repositories []string
+	total := 0
+
+	for _, name := range names {
+		images, err := p.providers[name].TrackedImages()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":    err,
+				"provider": name,
+			}).Error("provider.defaultProviders: failed to get tracked images for startup summary")
+			counts = append(counts, fmt.Sprintf("%s: unknown", name))
+			continue
+		}
+
+		total += len(images)
+		counts = append(counts, fmt.Sprintf("%s: %d", name, len(images)))
+		metadata[name] = strconv.Itoa(len(images))
+
+		for _, img := range images {
+			if img.Image == nil {
+				continue
+			}
+			repository := img.Image.Repository()
+			if !seen[repository] {
+				seen[repository] = true
+				repositories = append(repositories, repository)
+			}
+		}
+	}
+	sort.Strings(repositories)
+
+	msg := fmt.Sprintf("Bow started, tracking %d images (%s)", total, strings.Join(counts, ", "))
+	if len(repositories) > 0 && maxRepositories > 0 {
+		listed := repositories
+		if len(listed) > maxRepositories {
+			listed = listed[:maxRepositories]
+		}
+		msg += ": " + strings.Join(listed, ", ")
+		if more := len(repositories) - len(listed); more > 0 {
+			msg += fmt.Sprintf(" and %d more", more)
+		}
+	}
+
+	return types.EventNotification{
+		Name:      "bow started",
+		Message:   msg,
+		CreatedAt: time.Now(),
+		Type:      types.NotificationSystemEvent,
+		Level:     types.LevelInfo,
+		Metadata:  metadata,
+	}
+}
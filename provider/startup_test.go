@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/extension/notification"
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/image"
+)
+
+type fakeProvider struct {
+	name   string
+	images []*types.TrackedImage
+	ready  error
+}
+
+func (p *fakeProvider) Submit(event types.Event) error                { return nil }
+func (p *fakeProvider) TrackedImages() ([]*types.TrackedImage, error) { return p.images, nil }
+func (p *fakeProvider) GetName() string                               { return p.name }
+func (p *fakeProvider) Stop()                                         {}
+func (p *fakeProvider) Ready() error                                  { return p.ready }
+
+type fakeSender struct {
+	sent []types.EventNotification
+}
+
+func (s *fakeSender) Configure(cfg *notification.Config) (bool, error) { return true, nil }
+
+func (s *fakeSender) Send(event types.EventNotification) error {
+	s.sent = append(s.sent, event)
+	return nil
+}
+
+func tracked(t *testing.T, images ...string) []*types.TrackedImage {
+	var tracked []*types.TrackedImage
+	for _, img := range images {
+		ref, err := image.Parse(img)
+		if err != nil {
+			t.Fatalf("failed to parse image: %s", err)
+		}
+		tracked = append(tracked, &types.TrackedImage{Image: ref})
+	}
+	return tracked
+}
+
+func TestNotifyStartup(t *testing.T) {
+	defer func(interval, timeout time.Duration) {
+		startupReadyInterval, startupReadyTimeout = interval, timeout
+	}(startupReadyInterval, startupReadyTimeout)
+	startupReadyInterval = time.Millisecond
+	startupReadyTimeout = 50 * time.Millisecond
+
+	p := &DefaultProviders{
+		providers: map[string]Provider{
+			"kubernetes": &fakeProvider{name: "kubernetes", images: tracked(t, "gcr.io/team/b:1.0.0", "gcr.io/team/a:1.0.0", "gcr.io/team/a:1.1.0")},
+			"helm":       &fakeProvider{name: "helm", images: tracked(t, "quay.io/team/c:1.0.0"), ready: errors.New("releases not listed")},
+		},
+		stopCh: make(chan struct{}),
+	}
+
+	sender := &fakeSender{}
+	p.NotifyStartup(sender, 2)
+
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected 1 notification, got: %d", len(sender.sent))
+	}
+	event := sender.sent[0]
+	if event.Level != types.LevelInfo {
+		t.Errorf("unexpected level: %s", event.Level)
+	}
+	want := "Bow started, tracking 4 images (helm: 1, kubernetes: 3): gcr.io/team/a, gcr.io/team/b and 1 more"
+	if event.Message != want {
+		t.Errorf("unexpected message: %s", event.Message)
+	}
+	if event.Metadata["kubernetes"] != "3" || event.Metadata["helm"] != "1" {
+		t.Errorf("unexpected metadata: %v", event.Metadata)
+	}
+
+	summary := p.startupSummary(10)
+	if !strings.HasSuffix(summary.Message, "gcr.io/team/a, gcr.io/team/b, quay.io/team/c") {
+		t.Errorf("unexpected message: %s", summary.Message)
+	}
+}
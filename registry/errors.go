@@ -0,0 +1,40 @@
+package registry
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/rusenask/docker-registry-client/registry"
+)
+
+// error classes of failed registry requests
+const (
+	ErrorClassAuth     = "auth"
+	ErrorClassNetwork  = "network"
+	ErrorClassNotFound = "notfound"
+	ErrorClassOther    = "other"
+)
+
+// ErrorClass - classifies registry client errors so failing credentials can be told
+// apart from unreachable registries and missing repositories or tags
+func ErrorClass(err error) string {
+	var statusErr *registry.HttpStatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.Response.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return ErrorClassAuth
+		case http.StatusNotFound:
+			return ErrorClassNotFound
+		}
+		return ErrorClassOther
+	}
+
+	// failed requests are wrapped in url.Error which is a net.Error as well
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ErrorClassNetwork
+	}
+
+	return ErrorClassOther
+}
@@ -0,0 +1,53 @@
+package registry
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorClass(t *testing.T) {
+	statusServer := func(status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+	}
+
+	tests := []struct {
+		name   string
+		status int
+		want   string
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, want: ErrorClassAuth},
+		{name: "forbidden", status: http.StatusForbidden, want: ErrorClassAuth},
+		{name: "not found", status: http.StatusNotFound, want: ErrorClassNotFound},
+		{name: "server error", status: http.StatusInternalServerError, want: ErrorClassOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := statusServer(tt.status)
+			defer ts.Close()
+
+			_, err := New().Digest(Opts{Registry: ts.URL, Name: "bow/app", Tag: "1.0.0"})
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+			if got := ErrorClass(err); got != tt.want {
+				t.Errorf("ErrorClass(%s) = %s, want %s", err, got, tt.want)
+			}
+		})
+	}
+
+	// nothing listens on the closed server
+	ts := statusServer(http.StatusOK)
+	ts.Close()
+	_, err := New().Get(Opts{Registry: ts.URL, Name: "bow/app"})
+	if got := ErrorClass(err); got != ErrorClassNetwork {
+		t.Errorf("ErrorClass(%s) = %s, want %s", err, got, ErrorClassNetwork)
+	}
+
+	if got := ErrorClass(errors.New("boom")); got != ErrorClassOther {
+		t.Errorf("unexpected class of plain error: %s", got)
+	}
+}
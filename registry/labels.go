@@ -0,0 +1,109 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rusenask/docker-registry-client/registry"
+)
+
+// manifest media types that reference image config
+const (
+	mediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+)
+
+// ErrNoImageConfig - manifest doesn't reference image config, ie: schema 1
+// manifests or manifest lists
+var ErrNoImageConfig = errors.New("manifest has no image config")
+
+// ErrNoCreationTime - image config doesn't have creation time
+var ErrNoCreationTime = errors.New("image config has no creation time")
+
+type imageManifest struct {
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+}
+
+type imageConfig struct {
+	Created time.Time `json:"created"`
+	Config  struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"config"`
+}
+
+// Labels - get labels from the image config, ie: org.opencontainers.image.description
+func (c *DefaultClient) Labels(opts Opts) (map[string]string, error) {
+	cfg, err := c.imageConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Config.Labels, nil
+}
+
+// Created - get image creation time from the image config
+func (c *DefaultClient) Created(opts Opts) (time.Time, error) {
+	cfg, err := c.imageConfig(opts)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if cfg.Created.IsZero() {
+		return time.Time{}, ErrNoCreationTime
+	}
+	return cfg.Created, nil
+}
+
+func (c *DefaultClient) imageConfig(opts Opts) (*imageConfig, error) {
+	if opts.Tag == "" {
+		return nil, ErrTagNotSupplied
+	}
+
+	hub, err := c.getRegistryClient(opts.Registry, opts.Username, opts.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest imageManifest
+	err = getJSON(hub, fmt.Sprintf("/v2/%s/manifests/%s", opts.Name, opts.Tag), &manifest, mediaTypeDockerManifest, mediaTypeOCIManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.Config.Digest == "" {
+		return nil, ErrNoImageConfig
+	}
+
+	var cfg imageConfig
+	err = getJSON(hub, fmt.Sprintf("/v2/%s/blobs/%s", opts.Name, manifest.Config.Digest), &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func getJSON(hub *registry.Registry, path string, target interface{}, accept ...string) error {
+	url := hub.URL + path
+	hub.Logf("registry.json.get url=%s", url)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+
+	resp, err := hub.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
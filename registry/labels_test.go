@@ -0,0 +1,64 @@
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newImageConfigServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/v2/karolisr/webhook-demo/manifests/0.0.11":
+			if req.Header.Get("Accept") == "" {
+				resp.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			resp.Header().Set("Content-Type", mediaTypeDockerManifest)
+			resp.Write([]byte(`{"schemaVersion": 2, "config": {"mediaType": "application/vnd.docker.container.image.v1+json", "digest": "sha256:cfg"}}`))
+		case "/v2/karolisr/webhook-demo/blobs/sha256:cfg":
+			resp.Write([]byte(`{"architecture": "amd64", "created": "2019-03-01T12:00:00Z", "config": {"Labels": {"org.opencontainers.image.description": "fixes things"}}}`))
+		default:
+			resp.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestLabels(t *testing.T) {
+	ts := newImageConfigServer()
+	defer ts.Close()
+
+	client := New()
+	labels, err := client.Labels(Opts{
+		Registry: ts.URL,
+		Name:     "karolisr/webhook-demo",
+		Tag:      "0.0.11",
+	})
+	if err != nil {
+		t.Fatalf("failed to get labels: %s", err)
+	}
+
+	if labels["org.opencontainers.image.description"] != "fixes things" {
+		t.Errorf("unexpected labels: %v", labels)
+	}
+}
+
+func TestCreated(t *testing.T) {
+	ts := newImageConfigServer()
+	defer ts.Close()
+
+	client := New()
+	created, err := client.Created(Opts{
+		Registry: ts.URL,
+		Name:     "karolisr/webhook-demo",
+		Tag:      "0.0.11",
+	})
+	if err != nil {
+		t.Fatalf("failed to get creation time: %s", err)
+	}
+
+	if !created.Equal(time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected creation time: %s", created)
+	}
+}
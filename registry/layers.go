@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// ErrLayerNotFound - artifact manifest has no layer with the requested media type
+var ErrLayerNotFound = errors.New("manifest has no layer with requested media type")
+
+type artifactManifest struct {
+	Layers []struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+	} `json:"layers"`
+}
+
+// Layer - downloads first layer with the media type from an OCI artifact,
+// ie: chart archive of a helm chart pushed to the registry
+func (c *DefaultClient) Layer(opts Opts, mediaType string) ([]byte, error) {
+	if opts.Tag == "" {
+		return nil, ErrTagNotSupplied
+	}
+
+	hub, err := c.getRegistryClient(opts.Registry, opts.Username, opts.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest artifactManifest
+	err = getJSON(hub, fmt.Sprintf("/v2/%s/manifests/%s", opts.Name, opts.Tag), &manifest, mediaTypeOCIManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != mediaType {
+			continue
+		}
+
+		url := hub.URL + fmt.Sprintf("/v2/%s/blobs/%s", opts.Name, layer.Digest)
+		hub.Logf("registry.blob.get url=%s", url)
+
+		resp, err := hub.Client.Get(url)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, url)
+		}
+		return ioutil.ReadAll(resp.Body)
+	}
+
+	return nil, ErrLayerNotFound
+}
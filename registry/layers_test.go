@@ -0,0 +1,46 @@
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLayer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/v2/charts/wd/manifests/1.2.3":
+			resp.Header().Set("Content-Type", mediaTypeOCIManifest)
+			resp.Write([]byte(`{"schemaVersion": 2, "config": {"mediaType": "application/vnd.cncf.helm.config.v1+json", "digest": "sha256:cfg"},
+				"layers": [{"mediaType": "application/vnd.cncf.helm.chart.provenance.v1.prov", "digest": "sha256:prov"},
+				{"mediaType": "application/vnd.cncf.helm.chart.content.v1.tar+gzip", "digest": "sha256:chart"}]}`))
+		case "/v2/charts/wd/blobs/sha256:chart":
+			resp.Write([]byte("chart archive"))
+		default:
+			resp.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := New()
+	layer, err := client.Layer(Opts{
+		Registry: ts.URL,
+		Name:     "charts/wd",
+		Tag:      "1.2.3",
+	}, "application/vnd.cncf.helm.chart.content.v1.tar+gzip")
+	if err != nil {
+		t.Fatalf("failed to get layer: %s", err)
+	}
+	if string(layer) != "chart archive" {
+		t.Errorf("unexpected layer: %s", layer)
+	}
+
+	_, err = client.Layer(Opts{
+		Registry: ts.URL,
+		Name:     "charts/wd",
+		Tag:      "1.2.3",
+	}, "application/vnd.oci.image.layer.v1.tar")
+	if err != ErrLayerNotFound {
+		t.Errorf("expected ErrLayerNotFound, got: %v", err)
+	}
+}
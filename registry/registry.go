@@ -40,7 +40,7 @@ func New() *DefaultClient {
 	}
 	return &DefaultClient{
 		mu:         &sync.Mutex{},
-		registries: make(map[uint32]*registry.Registry),
+		registries: make(map[uint32]*cachedRegistry),
 		insecure:   insecure,
 	}
 }
@@ -49,10 +49,17 @@ func New() *DefaultClient {
 type DefaultClient struct {
 	// a map of registries to reuse for polling
 	mu         *sync.Mutex
-	registries map[uint32]*registry.Registry
+	registries map[uint32]*cachedRegistry
 	insecure   bool
 }
 
+// cachedRegistry - registry client together with the password it was created with,
+// short lived passwords (access tokens) replace the client once they get refreshed
+type cachedRegistry struct {
+	password string
+	registry *registry.Registry
+}
+
 // Opts - registry client opts. If username & password are not supplied
 // it will try to authenticate as anonymous
 type Opts struct {
@@ -77,10 +84,10 @@ func (c *DefaultClient) getRegistryClient(registryAddress, username, password st
 
 	var r *registry.Registry
 
-	h := hash(registryAddress + username + password)
-	r, ok := c.registries[h]
-	if ok {
-		return r, nil
+	h := hash(registryAddress + username)
+	cached, ok := c.registries[h]
+	if ok && cached.password == password {
+		return cached.registry, nil
 	}
 
 	url := strings.TrimSuffix(registryAddress, "/")
@@ -92,7 +99,7 @@ func (c *DefaultClient) getRegistryClient(registryAddress, username, password st
 
 	r.Logf = LogFormatter
 
-	c.registries[h] = r
+	c.registries[h] = &cachedRegistry{password: password, registry: r}
 
 	return r, nil
 }
@@ -313,3 +313,24 @@ func TestGetDockerHubManyTags(t *testing.T) {
 	}
 	fmt.Println(tags)
 }
+
+func TestRegistryClientReplacedOnPasswordChange(t *testing.T) {
+	client := New()
+
+	first, err := client.getRegistryClient("https://gcr.io", "oauth2accesstoken", "ya29.first")
+	if err != nil {
+		t.Fatalf("failed to get client: %s", err)
+	}
+	same, _ := client.getRegistryClient("https://gcr.io", "oauth2accesstoken", "ya29.first")
+	if first != same {
+		t.Errorf("expected client to be reused")
+	}
+
+	refreshed, _ := client.getRegistryClient("https://gcr.io", "oauth2accesstoken", "ya29.second")
+	if refreshed == first {
+		t.Errorf("expected new client after password change")
+	}
+	if len(client.registries) != 1 {
+		t.Errorf("expected refreshed client to replace old one, got %d clients", len(client.registries))
+	}
+}
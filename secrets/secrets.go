@@ -65,10 +65,11 @@ func (g *DefaultGetter) Get(image *types.TrackedImage) (*types.Credentials, erro
 }
 
 func (g *DefaultGetter) lookupDefaultDockerConfig(image *types.TrackedImage) (*types.Credentials, bool) {
-	return credentialsFromConfig(image, g.defaultDockerConfig)
+	return CredentialsFromConfig(image, g.defaultDockerConfig)
 }
 
-func credentialsFromConfig(image *types.TrackedImage, cfg DockerCfg) (*types.Credentials, bool) {
+// CredentialsFromConfig - looks up credentials for image registry in docker config
+func CredentialsFromConfig(image *types.TrackedImage, cfg DockerCfg) (*types.Credentials, bool) {
 	credentials := &types.Credentials{}
 	found := false
 
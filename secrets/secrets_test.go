@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/alwinius/bow/provider/helm"
 	"github.com/alwinius/bow/types"
 	"github.com/alwinius/bow/util/image"
 	testutil "github.com/alwinius/bow/util/testing"
@@ -344,7 +343,7 @@ func TestGetDirectHelmSecret(t *testing.T) {
 		Image:     imgRef,
 		Namespace: "default",
 		Secrets:   []string{"myregistrysecret"},
-		Provider:  helm.ProviderName,
+		Provider:  "helm",
 	}
 
 	creds, err := getter.Get(trackedImage)
@@ -55,6 +55,7 @@ func (j *WatchRepositoryTagsJob) Run() {
 	})
 
 	if err != nil {
+		recordPollError(j.details.trackedImage.Image.Registry(), err)
 		log.WithFields(log.Fields{
 			"error":        err,
 			"registry_url": reg,
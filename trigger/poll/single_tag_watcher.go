@@ -41,6 +41,7 @@ func (j *WatchTagJob) Run() {
 	registriesScannedCounter.With(prometheus.Labels{"registry": j.details.trackedImage.Image.Registry(), "image": j.details.trackedImage.Image.Repository()}).Inc()
 
 	if err != nil {
+		recordPollError(j.details.trackedImage.Image.Registry(), err)
 		log.WithFields(log.Fields{
 			"error": err,
 			"image": j.details.trackedImage.Image.String(),
@@ -7,10 +7,12 @@ import (
 	"sync"
 
 	"github.com/alwinius/bow/extension/credentialshelper"
+	"github.com/alwinius/bow/internal/policy"
 	"github.com/alwinius/bow/provider"
 	"github.com/alwinius/bow/registry"
 	"github.com/alwinius/bow/types"
 	"github.com/alwinius/bow/util/image"
+	"github.com/alwinius/bow/util/timeutil"
 	"github.com/alwinius/bow/util/version"
 	"github.com/rusenask/cron"
 
@@ -27,6 +29,14 @@ var registriesScannedCounter = prometheus.NewCounterVec(
 	[]string{"registry", "image"},
 )
 
+var registryPollErrorsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "registry_poll_errors_total",
+		Help: "How many registry requests of the poll trigger failed, partitioned by registry and error class (auth, network, notfound, other).",
+	},
+	[]string{"registry", "class"},
+)
+
 var pollTriggerTrackedImages = prometheus.NewGauge(
 	prometheus.GaugeOpts{
 		Name: "poll_trigger_tracked_images",
@@ -36,6 +46,7 @@ var pollTriggerTrackedImages = prometheus.NewGauge(
 
 func init() {
 	prometheus.MustRegister(registriesScannedCounter)
+	prometheus.MustRegister(registryPollErrorsCounter)
 	prometheus.MustRegister(pollTriggerTrackedImages)
 }
 
@@ -50,6 +61,7 @@ type watchDetails struct {
 	digest       string // image digest
 	latest       string // latest tag
 	schedule     string
+	job          cron.Job
 
 	mu sync.RWMutex
 }
@@ -127,7 +139,7 @@ func (w *RepositoryWatcher) Watch(images ...*types.TrackedImage) error {
 	var errs []string
 	tracked := map[string]bool{}
 
-	for _, image := range images {
+	for _, image := range shareCredentials(images) {
 		if image.Trigger != types.TriggerTypePoll {
 			continue
 		}
@@ -153,6 +165,39 @@ func (w *RepositoryWatcher) Watch(images ...*types.TrackedImage) error {
 	return nil
 }
 
+// shareCredentials - images with the same identifier share a single watch job
+// which polls with the last image it was given. Credentials resolved from an image
+// pull secret of one of them are set on the rest so the job doesn't lose them when
+// another release or resource tracks the same image without a secret
+func shareCredentials(images []*types.TrackedImage) []*types.TrackedImage {
+	credentials := make(map[string]*types.Credentials)
+	for _, image := range images {
+		if image.Credentials == nil {
+			continue
+		}
+		key := getImageIdentifier(image.Image)
+		if _, ok := credentials[key]; !ok {
+			credentials[key] = image.Credentials
+		}
+	}
+	if len(credentials) == 0 {
+		return images
+	}
+
+	shared := make([]*types.TrackedImage, 0, len(images))
+	for _, image := range images {
+		creds, ok := credentials[getImageIdentifier(image.Image)]
+		if ok && image.Credentials == nil && !image.WorkloadIdentity() {
+			// copying, tracked images belong to providers
+			withCreds := *image
+			withCreds.Credentials = creds
+			image = &withCreds
+		}
+		shared = append(shared, image)
+	}
+	return shared
+}
+
 func (w *RepositoryWatcher) unwatch(tracked map[string]bool) {
 	for key, details := range w.watched {
 		if !tracked[key] {
@@ -173,7 +218,7 @@ func (w *RepositoryWatcher) watch(image *types.TrackedImage) (string, error) {
 		return "", fmt.Errorf("cron schedule cannot be empty")
 	}
 
-	_, err := cron.Parse(image.PollSchedule)
+	_, err := timeutil.ParseSchedule(image.PollSchedule)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"error":    err,
@@ -202,7 +247,7 @@ func (w *RepositoryWatcher) watch(image *types.TrackedImage) (string, error) {
 
 	// checking schedule
 	if details.schedule != image.PollSchedule {
-		err := w.cron.UpdateJob(key, image.PollSchedule)
+		err := w.updateJob(key, details, image.PollSchedule)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"error": err,
@@ -221,7 +266,30 @@ func (w *RepositoryWatcher) watch(image *types.TrackedImage) (string, error) {
 	return key, nil
 }
 
-func (w *RepositoryWatcher) addJob(ti *types.TrackedImage, schedule string) error {
+// updateJob - cron can only update jobs from its own spec format, so to support
+// timezones the job is removed and scheduled again
+func (w *RepositoryWatcher) updateJob(key string, details *watchDetails, spec string) error {
+	schedule, err := timeutil.ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+
+	w.cron.DeleteJob(key)
+	w.cron.Schedule(key, schedule, details.job)
+
+	details.mu.Lock()
+	details.schedule = spec
+	details.mu.Unlock()
+
+	return nil
+}
+
+func (w *RepositoryWatcher) addJob(ti *types.TrackedImage, spec string) error {
+	schedule, err := timeutil.ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+
 	// getting initial digest
 	reg := ti.Image.Scheme() + "://" + ti.Image.Registry()
 
@@ -235,6 +303,7 @@ func (w *RepositoryWatcher) addJob(ti *types.TrackedImage, schedule string) erro
 		Password: creds.Password,
 	})
 	if err != nil {
+		recordPollError(ti.Image.Registry(), err)
 		log.WithFields(log.Fields{
 			"error":    err,
 			"image":    ti.Image.String(),
@@ -249,44 +318,60 @@ func (w *RepositoryWatcher) addJob(ti *types.TrackedImage, schedule string) erro
 		trackedImage: ti,
 		digest:       digest, // current image digest
 		latest:       ti.Image.Tag(),
-		schedule:     schedule,
+		schedule:     spec,
 	}
 
 	// adding job to internal map
 	w.watched[key] = details
 
 	// checking tag type, for versioned (semver) tags we setup a watch all tags job
-	// and for non-semver types we create a single tag watcher which
-	// checks digest
+	// and for non-semver types or policies comparing digests of the same tag we
+	// create a single tag watcher which checks digest
 	_, err = version.GetVersion(ti.Image.Tag())
-	if err != nil {
+	if err != nil || matchDigest(ti.Policy) {
 		// adding new job
 		job := NewWatchTagJob(w.providers, w.registryClient, details)
+		details.job = job
 		log.WithFields(log.Fields{
 			"job_name": key,
 			"image":    ti.Image.String(),
 			"digest":   digest,
-			"schedule": schedule,
+			"schedule": spec,
 		}).Info("trigger.poll.RepositoryWatcher: new watch tag digest job added")
 
 		// running it now
 		job.Run()
 
-		return w.cron.AddJob(key, schedule, job)
+		w.cron.Schedule(key, schedule, job)
+		return nil
 	}
 
 	// adding new job
 	job := NewWatchRepositoryTagsJob(w.providers, w.registryClient, details)
+	details.job = job
 	log.WithFields(log.Fields{
 		"job_name": key,
 		"image":    ti.Image.String(),
 		"digest":   digest,
-		"schedule": schedule,
+		"schedule": spec,
 	}).Info("trigger.poll.RepositoryWatcher: new watch repository tags job added")
 
 	// running it now
 	job.Run()
 
-	return w.cron.AddJob(key, schedule, job)
+	w.cron.Schedule(key, schedule, job)
+	return nil
+
+}
+
+// matchDigest - whether tracked image policy only updates when digest of
+// the same tag changes
+func matchDigest(plc types.Policy) bool {
+	p, ok := plc.(policy.Policy)
+	return ok && policy.MatchDigest(p)
+}
 
+// recordPollError - counts failed registry request by registry host and error class
+func recordPollError(registryHost string, err error) {
+	registryPollErrorsCounter.With(prometheus.Labels{"registry": registryHost, "class": registry.ErrorClass(err)}).Inc()
 }
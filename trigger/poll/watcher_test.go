@@ -511,3 +511,89 @@ func TestUnwatchAfterNotTrackedAnymore(t *testing.T) {
 		t.Errorf("expected to find watching 3 entries, found: %d", len(watcher.watched))
 	}
 }
+
+func TestWatchSharedImagePullSecretCredentials(t *testing.T) {
+	fp := &fakeProvider{}
+	mem := memory.NewMemoryCache()
+	am := approvals.New(mem)
+	providers := provider.New([]provider.Provider{fp}, am)
+
+	frc := &fakeRegistryClient{
+		digestToReturn: "sha256:0604af35299dd37ff23937d115d103532948b568a9dd8197d14c256a8ab8b0bb",
+	}
+
+	watcher := NewRepositoryWatcher(providers, frc)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watcher.Start(ctx)
+
+	// the same private image tracked by two releases, only one of them
+	// has an image pull secret
+	withSecret := mustParse("quay.io/bow/app:latest", "@every 10m")
+	withSecret.Secrets = []string{"registry-creds"}
+	withSecret.Credentials = &types.Credentials{Username: "user-1", Password: "secret"}
+	withoutSecret := mustParse("quay.io/bow/app:latest", "@every 10m")
+
+	err := watcher.Watch(withSecret, withoutSecret)
+	if err != nil {
+		t.Fatalf("failed to watch images: %s", err)
+	}
+
+	details, ok := watcher.watched["quay.io/bow/app:latest"]
+	if !ok {
+		t.Fatalf("image watcher not found")
+	}
+
+	details.job.Run()
+
+	if frc.opts.Username != "user-1" || frc.opts.Password != "secret" {
+		t.Errorf("expected image pull secret credentials to be used, got: %s/%s", frc.opts.Username, frc.opts.Password)
+	}
+	if withoutSecret.Credentials != nil {
+		t.Errorf("expected provider's tracked image to stay unchanged")
+	}
+}
+
+func TestWatchDigestPolicySemverTag(t *testing.T) {
+	fp := &fakeProvider{}
+	mem := memory.NewMemoryCache()
+	am := approvals.New(mem)
+	providers := provider.New([]provider.Provider{fp}, am)
+
+	frc := &fakeRegistryClient{
+		digestToReturn: "sha256:0604af35299dd37ff23937d115d103532948b568a9dd8197d14c256a8ab8b0bb",
+	}
+
+	watcher := NewRepositoryWatcher(providers, frc)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watcher.Start(ctx)
+
+	digestImage := mustParse("quay.io/bow/app:1.2.3", "@every 10m")
+	digestImage.Policy = policy.NewDigestPolicy()
+	semverImage := mustParse("quay.io/bow/other:1.2.3", "@every 10m")
+	semverImage.Policy = policy.NewSemverPolicy(policy.SemverPolicyTypeMinor)
+
+	err := watcher.Watch(digestImage, semverImage)
+	if err != nil {
+		t.Fatalf("failed to watch images: %s", err)
+	}
+
+	if _, ok := watcher.watched["quay.io/bow/app:1.2.3"].job.(*WatchTagJob); !ok {
+		t.Errorf("expected digest policy to watch tag digest, got: %T", watcher.watched["quay.io/bow/app:1.2.3"].job)
+	}
+	if _, ok := watcher.watched["quay.io/bow/other:1.2.3"].job.(*WatchRepositoryTagsJob); !ok {
+		t.Errorf("expected semver policy to watch repository tags, got: %T", watcher.watched["quay.io/bow/other:1.2.3"].job)
+	}
+
+	// rebuilt tag
+	frc.digestToReturn = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	watcher.watched["quay.io/bow/app:1.2.3"].job.Run()
+
+	if len(fp.submitted) != 1 {
+		t.Fatalf("expected 1 event, got: %d", len(fp.submitted))
+	}
+	if fp.submitted[0].Repository.Tag != "1.2.3" || fp.submitted[0].Repository.Digest != frc.digestToReturn {
+		t.Errorf("unexpected event repository: %+v", fp.submitted[0].Repository)
+	}
+}
@@ -0,0 +1,115 @@
+package sqs
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+const apiVersion = "2012-11-05"
+
+// message - received SQS message
+type message struct {
+	MessageID     string `xml:"MessageId"`
+	ReceiptHandle string `xml:"ReceiptHandle"`
+	Body          string `xml:"Body"`
+}
+
+type receiveMessageResponse struct {
+	Messages []message `xml:"ReceiveMessageResult>Message"`
+}
+
+type errorResponse struct {
+	Code    string `xml:"Error>Code"`
+	Message string `xml:"Error>Message"`
+}
+
+// queueClient - minimal SQS query API client, requests are signed with
+// credentials from the default AWS chain
+type queueClient struct {
+	queueURL string
+	region   string
+
+	signer *v4.Signer
+	client *http.Client
+}
+
+func newQueueClient(queueURL, region string, creds *credentials.Credentials) *queueClient {
+	return &queueClient{
+		queueURL: queueURL,
+		region:   region,
+		signer:   v4.NewSigner(creds),
+		// long polling waits up to 20 seconds for messages
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// receive - long polls the queue for messages
+func (c *queueClient) receive(wait time.Duration) ([]message, error) {
+	var resp receiveMessageResponse
+	err := c.do(url.Values{
+		"Action":              {"ReceiveMessage"},
+		"MaxNumberOfMessages": {"10"},
+		"WaitTimeSeconds":     {strconv.Itoa(int(wait.Seconds()))},
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Messages, nil
+}
+
+// delete - removes processed message from the queue
+func (c *queueClient) delete(receiptHandle string) error {
+	return c.do(url.Values{
+		"Action":        {"DeleteMessage"},
+		"ReceiptHandle": {receiptHandle},
+	}, nil)
+}
+
+func (c *queueClient) do(params url.Values, target interface{}) error {
+	params.Set("Version", apiVersion)
+	body := []byte(params.Encode())
+
+	req, err := http.NewRequest("POST", c.queueURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	_, err = c.signer.Sign(req, bytes.NewReader(body), "sqs", c.region, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to sign request: %s", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr errorResponse
+		if xml.Unmarshal(respBody, &apiErr) == nil && apiErr.Code != "" {
+			return fmt.Errorf("%s: %s", apiErr.Code, apiErr.Message)
+		}
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	if target == nil {
+		return nil
+	}
+	return xml.Unmarshal(respBody, target)
+}
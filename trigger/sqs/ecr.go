@@ -0,0 +1,73 @@
+package sqs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alwinius/bow/types"
+)
+
+// ecrEvent - ECR image action event, delivered by EventBridge rules
+// either directly or through SNS topics
+type ecrEvent struct {
+	DetailType string `json:"detail-type"`
+	Source     string `json:"source"`
+	Account    string `json:"account"`
+	Region     string `json:"region"`
+	Detail     struct {
+		Result         string `json:"result"`
+		RepositoryName string `json:"repository-name"`
+		ImageDigest    string `json:"image-digest"`
+		ActionType     string `json:"action-type"`
+		ImageTag       string `json:"image-tag"`
+	} `json:"detail"`
+}
+
+// snsNotification - SNS envelope of messages delivered without raw message delivery
+type snsNotification struct {
+	Type    string `json:"Type"`
+	Message string `json:"Message"`
+}
+
+// parseMessage - parses ECR push event from the message body, nil event is
+// returned for other ECR actions and failed pushes
+func parseMessage(body string) (*types.Event, error) {
+	var notification snsNotification
+	err := json.Unmarshal([]byte(body), &notification)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode message: %s", err)
+	}
+	if notification.Type == "Notification" && notification.Message != "" {
+		body = notification.Message
+	}
+
+	var event ecrEvent
+	err = json.Unmarshal([]byte(body), &event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ECR event: %s", err)
+	}
+
+	if event.DetailType != "ECR Image Action" {
+		return nil, fmt.Errorf("unexpected event type '%s'", event.DetailType)
+	}
+
+	// we only care about successful pushes of tagged images
+	if event.Detail.ActionType != "PUSH" || event.Detail.Result != "SUCCESS" || event.Detail.ImageTag == "" {
+		return nil, nil
+	}
+
+	if event.Account == "" || event.Region == "" || event.Detail.RepositoryName == "" {
+		return nil, fmt.Errorf("ECR event is missing account, region or repository name")
+	}
+
+	return &types.Event{
+		Repository: types.Repository{
+			Name:   fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/%s", event.Account, event.Region, event.Detail.RepositoryName),
+			Tag:    event.Detail.ImageTag,
+			Digest: event.Detail.ImageDigest,
+		},
+		CreatedAt:   time.Now(),
+		TriggerName: "ecr-sqs",
+	}, nil
+}
@@ -0,0 +1,157 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/alwinius/bow/provider"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// queueHostRegexp - region is taken from queue URLs such as
+// https://sqs.us-east-1.amazonaws.com/123456789012/bow
+var queueHostRegexp = regexp.MustCompile(`^sqs\.([a-z0-9-]+)\.amazonaws\.com`)
+
+// how long receive requests wait for messages and how long the subscriber
+// backs off after failed requests
+const (
+	receiveWait  = 20 * time.Second
+	errorBackoff = 5 * time.Second
+)
+
+// queue - SQS queue operations, used for testing
+type queue interface {
+	receive(wait time.Duration) ([]message, error)
+	delete(receiptHandle string) error
+}
+
+// Subscriber - receives ECR image push events from an SQS queue
+type Subscriber struct {
+	providers provider.Providers
+	queueURL  string
+	queue     queue
+}
+
+// Opts - subscriber options
+type Opts struct {
+	QueueURL string
+	// Region - optional, defaults to the queue URL region or AWS_REGION
+	Region    string
+	Providers provider.Providers
+}
+
+// New - create new SQS subscriber, credentials are taken from AWS_* environment
+// variables, shared config or instance metadata (IAM roles)
+func New(opts *Opts) (*Subscriber, error) {
+	if opts.QueueURL == "" {
+		return nil, fmt.Errorf("queue URL cannot be empty")
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %s", err)
+	}
+
+	region := opts.Region
+	if region == "" {
+		region = queueRegion(opts.QueueURL)
+	}
+	if region == "" {
+		region = aws.StringValue(sess.Config.Region)
+	}
+	if region == "" {
+		return nil, fmt.Errorf("failed to determine region of queue %s, set AWS_REGION", opts.QueueURL)
+	}
+
+	return &Subscriber{
+		providers: opts.Providers,
+		queueURL:  opts.QueueURL,
+		queue:     newQueueClient(opts.QueueURL, region, sess.Config.Credentials),
+	}, nil
+}
+
+func queueRegion(queueURL string) string {
+	u, err := url.Parse(queueURL)
+	if err != nil {
+		return ""
+	}
+	matches := queueHostRegexp.FindStringSubmatch(u.Host)
+	if len(matches) != 2 {
+		return ""
+	}
+	return matches[1]
+}
+
+// Start - receives messages until context is cancelled
+func (s *Subscriber) Start(ctx context.Context) error {
+	log.WithFields(log.Fields{
+		"queue": s.queueURL,
+	}).Info("trigger.sqs: receiving ECR events...")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		messages, err := s.queue.receive(receiveWait)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+				"queue": s.queueURL,
+			}).Error("trigger.sqs: failed to receive messages")
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(errorBackoff):
+			}
+			continue
+		}
+
+		for _, msg := range messages {
+			s.handle(msg)
+		}
+	}
+}
+
+// handle - submits ECR push event to providers, messages are deleted even if they
+// can't be parsed so they aren't received again
+func (s *Subscriber) handle(msg message) {
+	defer func() {
+		err := s.queue.delete(msg.ReceiptHandle)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":      err,
+				"message_id": msg.MessageID,
+			}).Error("trigger.sqs: failed to delete message")
+		}
+	}()
+
+	event, err := parseMessage(msg.Body)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":      err,
+			"message_id": msg.MessageID,
+		}).Warn("trigger.sqs: failed to parse message")
+		return
+	}
+	if event == nil {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"image": event.Repository.Name,
+		"tag":   event.Repository.Tag,
+	}).Debug("trigger.sqs: got ECR push event")
+
+	s.providers.Submit(*event)
+}
@@ -0,0 +1,209 @@
+package sqs
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+
+	"github.com/alwinius/bow/types"
+)
+
+const ecrPushEvent = `{
+  "version": "0",
+  "id": "13cde686-328b-6117-af20-0e5566167482",
+  "detail-type": "ECR Image Action",
+  "source": "aws.ecr",
+  "account": "123456789012",
+  "time": "2019-11-16T01:54:34Z",
+  "region": "us-west-2",
+  "resources": [],
+  "detail": {
+    "result": "SUCCESS",
+    "repository-name": "my-repository-name",
+    "image-digest": "sha256:7f5b2640fe6fb4f46592dfd3410c4a79dac4f89e4782432e0378abcd1234",
+    "action-type": "PUSH",
+    "image-tag": "1.2.3"
+  }
+}`
+
+type fakeProviders struct {
+	submitted []types.Event
+}
+
+func (p *fakeProviders) Submit(event types.Event) error {
+	p.submitted = append(p.submitted, event)
+	return nil
+}
+
+func (p *fakeProviders) TrackedImages() ([]*types.TrackedImage, error) { return nil, nil }
+func (p *fakeProviders) List() []string                                { return nil }
+func (p *fakeProviders) Stop()                                         {}
+
+type fakeQueue struct {
+	messages []message
+	deleted  []string
+}
+
+func (q *fakeQueue) receive(wait time.Duration) ([]message, error) {
+	messages := q.messages
+	q.messages = nil
+	return messages, nil
+}
+
+func (q *fakeQueue) delete(receiptHandle string) error {
+	q.deleted = append(q.deleted, receiptHandle)
+	return nil
+}
+
+func TestParseMessage(t *testing.T) {
+	snsBody, err := json.Marshal(map[string]string{
+		"Type":      "Notification",
+		"MessageId": "abc",
+		"Message":   ecrPushEvent,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal SNS notification: %s", err)
+	}
+
+	for name, body := range map[string]string{
+		"eventbridge": ecrPushEvent,
+		"sns":         string(snsBody),
+	} {
+		t.Run(name, func(t *testing.T) {
+			event, err := parseMessage(body)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if event == nil {
+				t.Fatalf("expected event")
+			}
+			if event.Repository.Name != "123456789012.dkr.ecr.us-west-2.amazonaws.com/my-repository-name" {
+				t.Errorf("unexpected name: %s", event.Repository.Name)
+			}
+			if event.Repository.Tag != "1.2.3" {
+				t.Errorf("unexpected tag: %s", event.Repository.Tag)
+			}
+			if event.Repository.Digest != "sha256:7f5b2640fe6fb4f46592dfd3410c4a79dac4f89e4782432e0378abcd1234" {
+				t.Errorf("unexpected digest: %s", event.Repository.Digest)
+			}
+		})
+	}
+}
+
+func TestParseMessageIgnored(t *testing.T) {
+	for name, body := range map[string]string{
+		"delete":      strings.Replace(ecrPushEvent, `"PUSH"`, `"DELETE"`, 1),
+		"failed push": strings.Replace(ecrPushEvent, `"SUCCESS"`, `"FAILURE"`, 1),
+		"untagged":    strings.Replace(ecrPushEvent, `"1.2.3"`, `""`, 1),
+	} {
+		t.Run(name, func(t *testing.T) {
+			event, err := parseMessage(body)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if event != nil {
+				t.Errorf("expected event to be ignored, got: %s", event.Repository.String())
+			}
+		})
+	}
+
+	_, err := parseMessage(`{"detail-type": "EC2 Instance State-change Notification"}`)
+	if err == nil {
+		t.Errorf("expected error for unknown event type")
+	}
+}
+
+func TestSubscriberStart(t *testing.T) {
+	providers := &fakeProviders{}
+	queue := &fakeQueue{messages: []message{
+		{MessageID: "1", ReceiptHandle: "handle-1", Body: ecrPushEvent},
+		{MessageID: "2", ReceiptHandle: "handle-2", Body: "not json"},
+	}}
+	s := &Subscriber{providers: providers, queue: queue}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Start(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for len(providers.submitted) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if len(providers.submitted) != 1 {
+		t.Fatalf("expected 1 event, got: %d", len(providers.submitted))
+	}
+	// invalid messages are removed as well
+	if len(queue.deleted) != 2 {
+		t.Errorf("expected 2 messages to be deleted, got: %d", len(queue.deleted))
+	}
+}
+
+func TestQueueClient(t *testing.T) {
+	var actions []string
+	ts := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if !strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			resp.WriteHeader(http.StatusForbidden)
+			resp.Write([]byte(`<ErrorResponse><Error><Code>InvalidClientTokenId</Code><Message>denied</Message></Error></ErrorResponse>`))
+			return
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		values, _ := url.ParseQuery(string(body))
+		actions = append(actions, values.Get("Action"))
+
+		switch values.Get("Action") {
+		case "ReceiveMessage":
+			resp.Write([]byte(`<ReceiveMessageResponse><ReceiveMessageResult><Message><MessageId>1</MessageId><ReceiptHandle>handle-1</ReceiptHandle><Body>hello</Body></Message></ReceiveMessageResult></ReceiveMessageResponse>`))
+		case "DeleteMessage":
+			if values.Get("ReceiptHandle") != "handle-1" {
+				resp.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			resp.Write([]byte(`<DeleteMessageResponse></DeleteMessageResponse>`))
+		}
+	}))
+	defer ts.Close()
+
+	client := newQueueClient(ts.URL+"/123456789012/bow", "us-west-2", credentials.NewStaticCredentials("AKID", "SECRET", ""))
+
+	messages, err := client.receive(time.Second)
+	if err != nil {
+		t.Fatalf("failed to receive: %s", err)
+	}
+	if len(messages) != 1 || messages[0].Body != "hello" || messages[0].ReceiptHandle != "handle-1" {
+		t.Fatalf("unexpected messages: %+v", messages)
+	}
+
+	err = client.delete("handle-1")
+	if err != nil {
+		t.Fatalf("failed to delete: %s", err)
+	}
+
+	unauthorized := newQueueClient(ts.URL+"/123456789012/bow", "us-west-2", credentials.NewStaticCredentials("OTHER", "SECRET", ""))
+	_, err = unauthorized.receive(time.Second)
+	if err == nil || !strings.Contains(err.Error(), "InvalidClientTokenId") {
+		t.Errorf("expected API error, got: %v", err)
+	}
+}
+
+func TestQueueRegion(t *testing.T) {
+	if region := queueRegion("https://sqs.eu-west-1.amazonaws.com/123456789012/bow"); region != "eu-west-1" {
+		t.Errorf("unexpected region: %s", region)
+	}
+	if region := queueRegion("http://localhost:4566/000000000000/bow"); region != "" {
+		t.Errorf("unexpected region: %s", region)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -52,6 +54,10 @@ type Approval struct {
 	// IDs for audit
 	Voters JSONB `json:"voters" gorm:"type:json"`
 
+	// AllowedVoters - optional, only votes of these voters count
+	// towards VotesRequired, other votes are recorded for audit
+	AllowedVoters StringList `json:"allowedVoters" gorm:"type:json"`
+
 	// Explicitly rejected approval
 	// can be set directly by user
 	// so even if deadline is not reached approval
@@ -61,22 +67,32 @@ type Approval struct {
 	// Deadline for this request
 	Deadline time.Time `json:"deadline"`
 
+	// Reminded is set once approvers were reminded
+	// about the approaching deadline
+	Reminded bool `json:"reminded"`
+
 	// When this approval was created
 	CreatedAt time.Time `json:"createdAt"`
 	// WHen this approval was updated
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// GetVoters - distinct voters, sorted
 func (a *Approval) GetVoters() []string {
-	// meta := make(map[string]string)
 	var voters []string
 	for key := range a.Voters {
 		voters = append(voters, key)
-
 	}
+	sort.Strings(voters)
 	return voters
 }
 
+// HasVoter - checks whether voter has already voted
+func (a *Approval) HasVoter(voter string) bool {
+	_, ok := a.Voters[voter]
+	return ok
+}
+
 func (a *Approval) AddVoter(voter string) {
 	if a.Voters == nil {
 		a.Voters = make(map[string]interface{})
@@ -84,6 +100,42 @@ func (a *Approval) AddVoter(voter string) {
 	a.Voters[voter] = time.Now()
 }
 
+// CanVote - whether vote of the voter counts, any voter can
+// vote when allowed voters aren't set
+func (a *Approval) CanVote(voter string) bool {
+	if len(a.AllowedVoters) == 0 {
+		return true
+	}
+	for _, allowed := range a.AllowedVoters {
+		if allowed == voter {
+			return true
+		}
+	}
+	return false
+}
+
+// CountedVotes - number of distinct voters whose votes count
+func (a *Approval) CountedVotes() int {
+	counted := 0
+	for voter := range a.Voters {
+		if a.CanVote(voter) {
+			counted++
+		}
+	}
+	return counted
+}
+
+// PendingVoters - allowed voters that haven't voted yet
+func (a *Approval) PendingVoters() []string {
+	var pending []string
+	for _, voter := range a.AllowedVoters {
+		if !a.HasVoter(voter) {
+			pending = append(pending, voter)
+		}
+	}
+	return pending
+}
+
 // ApprovalStatus - approval status type used in approvals
 // to determine whether it was rejected/approved or still pending
 type ApprovalStatus int
@@ -133,6 +185,46 @@ func (a *Approval) Delta() string {
 	return fmt.Sprintf("%s -> %s", a.CurrentVersion, a.NewVersion)
 }
 
+// StringList is stored as a JSON array
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		l = StringList{}
+	}
+	return json.Marshal(l)
+}
+
+func (l *StringList) Scan(src interface{}) error {
+	var source []byte
+	switch s := src.(type) {
+	case []byte:
+		source = s
+	case string:
+		source = []byte(s)
+	case nil:
+		*l = nil
+		return nil
+	default:
+		return errors.New("type assertion .([]byte) failed.")
+	}
+
+	return json.Unmarshal(source, l)
+}
+
+// ParseApprovalVoters - parses comma separated voters from the
+// approval voters annotation
+func ParseApprovalVoters(annotations map[string]string) []string {
+	var voters []string
+	for _, voter := range strings.Split(annotations[BowApprovalVotersAnnotation], ",") {
+		voter = strings.TrimSpace(voter)
+		if voter != "" {
+			voters = append(voters, voter)
+		}
+	}
+	return voters
+}
+
 // JSONB is stored as a JSON blob
 type JSONB map[string]interface{}
 
@@ -163,3 +255,13 @@ func (b *JSONB) Scan(src interface{}) error {
 
 	return nil
 }
+
+// ReminderDue - checks whether pending approval has less than half of its
+// approval deadline left and approvers weren't reminded yet
+func (a *Approval) ReminderDue(now time.Time) bool {
+	if a.Reminded || a.Status() != ApprovalStatusPending || a.Deadline.IsZero() {
+		return false
+	}
+	remaining := a.Deadline.Sub(now)
+	return remaining > 0 && remaining <= a.Deadline.Sub(a.CreatedAt)/2
+}
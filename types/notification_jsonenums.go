@@ -18,6 +18,8 @@ var (
 		"NotificationSystemEvent":         NotificationSystemEvent,
 		"NotificationUpdateApproved":      NotificationUpdateApproved,
 		"NotificationUpdateRejected":      NotificationUpdateRejected,
+		"NotificationApprovalReminder":    NotificationApprovalReminder,
+		"NotificationApprovalRequested":   NotificationApprovalRequested,
 	}
 
 	_NotificationValueToName = map[Notification]string{
@@ -30,6 +32,8 @@ var (
 		NotificationSystemEvent:         "NotificationSystemEvent",
 		NotificationUpdateApproved:      "NotificationUpdateApproved",
 		NotificationUpdateRejected:      "NotificationUpdateRejected",
+		NotificationApprovalReminder:    "NotificationApprovalReminder",
+		NotificationApprovalRequested:   "NotificationApprovalRequested",
 	}
 )
 
@@ -46,6 +50,8 @@ func init() {
 			interface{}(NotificationSystemEvent).(fmt.Stringer).String():         NotificationSystemEvent,
 			interface{}(NotificationUpdateApproved).(fmt.Stringer).String():      NotificationUpdateApproved,
 			interface{}(NotificationUpdateRejected).(fmt.Stringer).String():      NotificationUpdateRejected,
+			interface{}(NotificationApprovalReminder).(fmt.Stringer).String():    NotificationApprovalReminder,
+			interface{}(NotificationApprovalRequested).(fmt.Stringer).String():   NotificationApprovalRequested,
 		}
 	}
 }
@@ -25,6 +25,19 @@ type TrackedImage struct {
 	// combined semver tags
 	Tags   []string `json:"tags"`
 	Policy Policy   `json:"policy"`
+	// Credentials - registry credentials resolved by the provider, ie: from
+	// image pull secrets, take precedence over credentials helpers
+	Credentials *Credentials `json:"-"`
+	// UseWorkloadIdentity - credentials of Google registries are taken from the
+	// GKE Workload Identity service account instead of image pull secrets or
+	// static credentials, see WorkloadIdentity
+	UseWorkloadIdentity bool `json:"useWorkloadIdentity"`
+}
+
+// WorkloadIdentity - whether registry credentials come from GKE Workload Identity,
+// only Google registries accept them so other registries keep using pull secrets
+func (i TrackedImage) WorkloadIdentity() bool {
+	return i.UseWorkloadIdentity && i.Image != nil && image.IsGoogleRegistry(i.Image.Registry())
 }
 
 type Policy interface {
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -32,6 +33,23 @@ const BowTriggerLabel = "bow/trigger"
 const BowForceTagMatchLegacyLabel = "bow/match-tag"
 const BowForceTagMatchLabel = "bow/matchTag"
 
+// BowForceDigestMatchLabel - label that makes force policy compare digests instead of
+// tag strings, mutable tags such as latest are only updated when the registry digest
+// differs from the one recorded in bow/resolved-digest during the previous update
+const BowForceDigestMatchLabel = "bow/matchDigest"
+
+// BowForceNoDowngradeLabel - label that prevents force policy from replacing a semver
+// tag with a lower semver tag, for example when receiving stale events
+const BowForceNoDowngradeLabel = "bow/noDowngrade"
+
+// BowSemverBuildMetaLabel - label that makes all and patch semver policies treat
+// higher numeric build metadata as an update, ie: 1.4.2+build.57 -> 1.4.2+build.58
+const BowSemverBuildMetaLabel = "bow/includeBuildMeta"
+
+// BowPreReleaseChannelLabel - label that makes semver policies track only pre-releases
+// of the channel, ie: "staging" updates to 1.1.2-staging but ignores 1.1.2 and 1.1.2-alpha
+const BowPreReleaseChannelLabel = "bow/preReleaseChannel"
+
 // BowPollScheduleAnnotation - optional variable to setup custom schedule for polling, defaults to @every 10m
 const BowPollScheduleAnnotation = "bow/pollSchedule"
 
@@ -45,21 +63,106 @@ const BowDigestAnnotation = "bow/digest"
 // default notification channel(-s) per deployment/chart
 const BowNotificationChanAnnotation = "bow/notify"
 
+// BowNotificationChannelsAnnotation - comma separated notification channels of
+// the resource, ie: slack-ops,pagerduty. Takes precedence over BowNotificationChanAnnotation
+const BowNotificationChannelsAnnotation = "bow/notification-channels"
+
 // BowMinimumApprovalsLabel - min approvals
 const BowMinimumApprovalsLabel = "bow/approvals"
 
 // bowUpdateTimeAnnotation - update time
 const BowUpdateTimeAnnotation = "bow/update-time"
 
+// BowResolvedDigestAnnotation - digest the updated tag resolved to, set next to
+// update time so rollouts of floating tags such as latest can be audited
+const BowResolvedDigestAnnotation = "bow/resolved-digest"
+
+// BowImageHashAnnotation - short digest of the new image, replaces update time as
+// the rollout trigger when image hash rollouts are enabled
+const BowImageHashAnnotation = "bow/image-hash"
+
+// BowMinAgeAnnotation - minimum age of the new image, ie: "30m". Updates to images
+// pushed more recently are deferred until they are old enough
+const BowMinAgeAnnotation = "bow/min-age"
+
+// BowUpdateWindowAnnotation - cron range expression restricting updates to
+// maintenance windows, ie: "* 2-3 * * 1-5" for weekdays 02:00-04:00 UTC. Policy
+// is wrapped so updates outside of the window are deferred until it opens
+const BowUpdateWindowAnnotation = "bow/update-window"
+
+// BowIgnoreContainersAnnotation - comma separated container names that should
+// never be updated, ie: sidecars such as istio-proxy
+const BowIgnoreContainersAnnotation = "bow/ignore-containers"
+
+// BowExcludeContainersAnnotation - comma separated container names that are frozen,
+// same as BowIgnoreContainersAnnotation, both annotations can be combined
+const BowExcludeContainersAnnotation = "bow/exclude-containers"
+
+// BowPlatformAnnotation - platform (os/arch) of the images used by the resource, either
+// for all containers "linux/amd64" or per container "app=linux/amd64,agent=linux/arm64"
+const BowPlatformAnnotation = "bow/platform"
+
 // BowApprovalDeadlineLabel - approval deadline
 const BowApprovalDeadlineLabel = "bow/approvalDeadline"
 
+// BowApprovalVotersAnnotation - optional comma separated voters, only their
+// votes count towards required approvals, ie: alice,bob,carol
+const BowApprovalVotersAnnotation = "bow/approval-voters"
+
 // BowApprovalDeadlineDefault - default deadline in hours
 const BowApprovalDeadlineDefault = 24
 
 // BowReleasePage - optional release notes URL passed on with notification
 const BowReleaseNotesURL = "bow/releaseNotes"
 
+// BowReleaseNotesAnnotation - pod template annotation with release notes
+// of the last applied update
+const BowReleaseNotesAnnotation = "bow/release-notes"
+
+// BowNotifyOnNoUpdateAnnotation - set to "false" to suppress update notifications when
+// the version doesn't change, ie: force policy updating the same tag on every poll
+const BowNotifyOnNoUpdateAnnotation = "bow/notify-on-no-update"
+
+// BowSkipUnchangedDigestAnnotation - set to "true" to skip updates when the new image
+// resolves to the digest recorded in bow/resolved-digest, ie: frequently built master tags
+const BowSkipUnchangedDigestAnnotation = "bow/skip-unchanged-digest"
+
+// BowRollbackOnFailureAnnotation - set to "true" or to a window such as "10m" to revert
+// updated Deployments that don't become available within the window
+const BowRollbackOnFailureAnnotation = "bow/rollback-on-failure"
+
+// DefaultRollbackWindow - how long updated Deployments have to become available
+// when bow/rollback-on-failure is set to "true"
+const DefaultRollbackWindow = 5 * time.Minute
+
+// BowKustomizeImageAnnotation - images set by kustomize, comma separated entries in the
+// "kustomize edit set image" syntax "<name>=<newImage>:<tag>", ie: "app=gcr.io/team/app:1.0.0".
+// Containers running image "app" are tracked and updated as gcr.io/team/app, the entry and
+// kustomization images are rewritten instead of the container image
+const BowKustomizeImageAnnotation = "bow/kustomize-image"
+
+// BowRepositoryMatchAnnotation - repository patterns of the resource, comma separated
+// globs (ie: "*/team/app") or a regular expression with "regex:" prefix matching the
+// whole repository. Events for any repository matching the pattern update containers
+// whose repository matches it too, ie: the same image mirrored under several registry hosts
+const BowRepositoryMatchAnnotation = "bow/repository-match"
+
+// BowArgoCDAppAnnotation - ArgoCD application ("name" or "namespace/name") that is
+// force synced once bow pushed the update, so ArgoCD doesn't wait for its next poll
+const BowArgoCDAppAnnotation = "bow/argocd-app"
+
+// BowImagePullPolicyAnnotation - imagePullPolicy ("Always", "Never" or "IfNotPresent")
+// set on updated containers, ie: Always for mutable tags
+const BowImagePullPolicyAnnotation = "bow/image-pull-policy"
+
+// BowMaxVersionDeltaAnnotation - largest version jump of a single update, ie: "major=1,minor=5".
+// Updates exceeding it step up to the highest version within the delta instead
+const BowMaxVersionDeltaAnnotation = "bow/max-version-delta"
+
+// FluxImagePolicyAnnotation - Flux style image policy, ie: "semver:^1.2", read in Flux
+// compatibility mode for resources without bow policy
+const FluxImagePolicyAnnotation = "app.kubernetes.io/image-policy"
+
 // Repository - represents main docker repository fields that
 // bow cares about
 type Repository struct {
@@ -67,7 +170,10 @@ type Repository struct {
 	Name   string `json:"name"`
 	Tag    string `json:"tag"`
 	Digest string `json:"digest"` // optional digest field
-	OldTag string
+	// optional os/arch of the manifest list entry, ie: linux/amd64,
+	// digest then belongs to this platform only
+	Platform string `json:"platform,omitempty"`
+	OldTag   string
 }
 
 // String gives you [host/]team/repo[:tag] identifier
@@ -197,17 +303,183 @@ func ParseEventNotificationChannels(annotations map[string]string) []string {
 	if annotations == nil {
 		return channels
 	}
-	chanStr, ok := annotations[BowNotificationChanAnnotation]
-	if ok {
-		chans := strings.Split(chanStr, ",")
-		for _, c := range chans {
-			channels = append(channels, strings.TrimSpace(c))
+	for _, key := range []string{BowNotificationChannelsAnnotation, BowNotificationChanAnnotation} {
+		chanStr, ok := annotations[key]
+		if !ok {
+			continue
 		}
+		for _, c := range strings.Split(chanStr, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				channels = append(channels, c)
+			}
+		}
+		return channels
 	}
 
 	return channels
 }
 
+// ParseIgnoredContainers - parses resource annotations to get names of the
+// containers that should be skipped during updates
+func ParseIgnoredContainers(annotations map[string]string) map[string]bool {
+	ignored := make(map[string]bool)
+	for _, key := range []string{BowIgnoreContainersAnnotation, BowExcludeContainersAnnotation} {
+		for _, name := range strings.Split(annotations[key], ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				ignored[name] = true
+			}
+		}
+	}
+
+	return ignored
+}
+
+// ParseKustomizeImages - parses resource annotations to get images set by kustomize,
+// map[name]newImage:tag. Entries without a name or a tag are skipped
+func ParseKustomizeImages(annotations map[string]string) map[string]string {
+	images := make(map[string]string)
+	for _, entry := range strings.Split(annotations[BowKustomizeImageAnnotation], ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name, img := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		idx := strings.LastIndex(img, ":")
+		if name == "" || idx < 0 || strings.Contains(img[idx+1:], "/") {
+			continue
+		}
+		images[name] = img
+	}
+	return images
+}
+
+// ParseContainerPlatform - parses resource annotations to get the platform (os/arch)
+// of a container, empty if platform is not set
+func ParseContainerPlatform(annotations map[string]string, container string) string {
+	var platform string
+	for _, entry := range strings.Split(annotations[BowPlatformAnnotation], ",") {
+		entry = strings.TrimSpace(entry)
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) == 1 {
+			// applies to all containers unless overridden
+			platform = entry
+			continue
+		}
+		if strings.TrimSpace(parts[0]) == container {
+			return strings.TrimSpace(parts[1])
+		}
+	}
+
+	return platform
+}
+
+// ParseNotifyOnNoUpdate - parses resource annotations to check whether update notifications
+// should be sent when the version doesn't change, defaults to true
+func ParseNotifyOnNoUpdate(annotations map[string]string) bool {
+	notify, err := strconv.ParseBool(strings.TrimSpace(annotations[BowNotifyOnNoUpdateAnnotation]))
+	if err != nil {
+		return true
+	}
+	return notify
+}
+
+// ParseSkipUnchangedDigest - parses resource annotations to check whether updates
+// to already deployed digests should be skipped, defaults to false
+func ParseSkipUnchangedDigest(annotations map[string]string) bool {
+	skip, err := strconv.ParseBool(strings.TrimSpace(annotations[BowSkipUnchangedDigestAnnotation]))
+	if err != nil {
+		return false
+	}
+	return skip
+}
+
+// ParseRollbackOnFailure - parses resource annotations to get the window in which updated
+// resource has to become available, ok is false when rollbacks are disabled
+func ParseRollbackOnFailure(annotations map[string]string) (window time.Duration, ok bool) {
+	spec := strings.TrimSpace(annotations[BowRollbackOnFailureAnnotation])
+	if spec == "" {
+		return 0, false
+	}
+	if enabled, err := strconv.ParseBool(spec); err == nil {
+		return DefaultRollbackWindow, enabled
+	}
+	window, err := time.ParseDuration(spec)
+	if err != nil || window <= 0 {
+		return 0, false
+	}
+	return window, true
+}
+
+// ParseArgoCDApp - parses resource annotations to get the ArgoCD application
+// to sync after updates, empty when it isn't set
+func ParseArgoCDApp(annotations map[string]string) string {
+	return strings.TrimSpace(annotations[BowArgoCDAppAnnotation])
+}
+
+// ParseImagePullPolicy - parses resource annotations to get the image pull policy of
+// updated containers, empty when it isn't set
+func ParseImagePullPolicy(annotations map[string]string) (string, error) {
+	policy := strings.TrimSpace(annotations[BowImagePullPolicyAnnotation])
+	switch policy {
+	case "", "Always", "Never", "IfNotPresent":
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid image pull policy '%s', expected Always, Never or IfNotPresent", policy)
+}
+
+// VersionDelta - largest allowed increase of each version component, minor is
+// only compared within the same major and patch within the same minor version.
+// Negative values are unlimited
+type VersionDelta struct {
+	Major int64
+	Minor int64
+	Patch int64
+}
+
+// Allows - whether update from current to new version stays within the delta
+func (d *VersionDelta) Allows(current, new *Version) bool {
+	switch {
+	case new.Major != current.Major:
+		return d.Major < 0 || new.Major-current.Major <= d.Major
+	case new.Minor != current.Minor:
+		return d.Minor < 0 || new.Minor-current.Minor <= d.Minor
+	}
+	return d.Patch < 0 || new.Patch-current.Patch <= d.Patch
+}
+
+// ParseMaxVersionDelta - parses resource annotations to get the max version delta,
+// nil when it isn't set. Components that aren't listed are unlimited
+func ParseMaxVersionDelta(annotations map[string]string) (*VersionDelta, error) {
+	spec := strings.TrimSpace(annotations[BowMaxVersionDeltaAnnotation])
+	if spec == "" {
+		return nil, nil
+	}
+
+	delta := &VersionDelta{Major: -1, Minor: -1, Patch: -1}
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid max version delta '%s', expected ie: major=1,minor=5", spec)
+		}
+		value, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("invalid max version delta '%s', %s must be a non-negative number", spec, strings.TrimSpace(parts[0]))
+		}
+		switch strings.TrimSpace(parts[0]) {
+		case "major":
+			delta.Major = value
+		case "minor":
+			delta.Minor = value
+		case "patch":
+			delta.Patch = value
+		default:
+			return nil, fmt.Errorf("invalid max version delta '%s', expected major, minor or patch", spec)
+		}
+	}
+	return delta, nil
+}
+
 func ParseReleaseNotesURL(annotations map[string]string) string {
 	if annotations == nil {
 		return ""
@@ -216,6 +488,35 @@ func ParseReleaseNotesURL(annotations map[string]string) string {
 	return annotations[BowReleaseNotesURL]
 }
 
+// ReleaseNotesMetadata - notification metadata key with JSON encoded list of
+// release notes, helm updates can combine release notes of several images
+const ReleaseNotesMetadata = "release_notes"
+
+// EncodeReleaseNotes - release notes as notification metadata value, empty when
+// there are none
+func EncodeReleaseNotes(releaseNotes []string) string {
+	if len(releaseNotes) == 0 {
+		return ""
+	}
+	bts, err := json.Marshal(releaseNotes)
+	if err != nil {
+		return ""
+	}
+	return string(bts)
+}
+
+// DecodeReleaseNotes - release notes from notification metadata value
+func DecodeReleaseNotes(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var releaseNotes []string
+	if err := json.Unmarshal([]byte(value), &releaseNotes); err != nil {
+		return nil
+	}
+	return releaseNotes
+}
+
 // Notification - notification types used by notifier
 type Notification int
 
@@ -236,6 +537,9 @@ const (
 
 	NotificationUpdateApproved
 	NotificationUpdateRejected
+
+	NotificationApprovalReminder
+	NotificationApprovalRequested
 )
 
 func (n Notification) String() string {
@@ -258,6 +562,10 @@ func (n Notification) String() string {
 		return "update approved"
 	case NotificationUpdateRejected:
 		return "update rejected "
+	case NotificationApprovalReminder:
+		return "approval reminder"
+	case NotificationApprovalRequested:
+		return "approval requested"
 	default:
 		return "unknown"
 	}
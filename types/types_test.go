@@ -95,19 +95,29 @@ func TestParseEventNotificationChannels(t *testing.T) {
 		},
 		{
 			name: "one chan",
-			args: args{map[string]string{bowNotificationChanAnnotation: "verychan"}},
+			args: args{map[string]string{BowNotificationChanAnnotation: "verychan"}},
 			want: []string{"verychan"},
 		},
 		{
 			name: "two chans with space",
-			args: args{map[string]string{bowNotificationChanAnnotation: "verychan, corp"}},
+			args: args{map[string]string{BowNotificationChanAnnotation: "verychan, corp"}},
 			want: []string{"verychan", "corp"},
 		},
 		{
 			name: "two chans no space",
-			args: args{map[string]string{bowNotificationChanAnnotation: "verychan,corp"}},
+			args: args{map[string]string{BowNotificationChanAnnotation: "verychan,corp"}},
 			want: []string{"verychan", "corp"},
 		},
+		{
+			name: "notification channels",
+			args: args{map[string]string{BowNotificationChannelsAnnotation: "slack-ops, pagerduty,"}},
+			want: []string{"slack-ops", "pagerduty"},
+		},
+		{
+			name: "notification channels override notify",
+			args: args{map[string]string{BowNotificationChannelsAnnotation: "slack-ops", BowNotificationChanAnnotation: "verychan"}},
+			want: []string{"slack-ops"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -143,7 +153,7 @@ func TestParseReleaseNotesURL(t *testing.T) {
 			name: "link",
 			args: args{
 				annotations: map[string]string{
-					bowReleaseNotesURL: "http://link",
+					BowReleaseNotesURL: "http://link",
 				},
 			},
 			want: "http://link",
@@ -157,3 +167,136 @@ func TestParseReleaseNotesURL(t *testing.T) {
 		})
 	}
 }
+
+func TestParseNotifyOnNoUpdate(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{
+			name: "nil map",
+			want: true,
+		},
+		{
+			name:        "disabled",
+			annotations: map[string]string{BowNotifyOnNoUpdateAnnotation: "false"},
+			want:        false,
+		},
+		{
+			name:        "enabled",
+			annotations: map[string]string{BowNotifyOnNoUpdateAnnotation: "true"},
+			want:        true,
+		},
+		{
+			name:        "invalid value keeps default",
+			annotations: map[string]string{BowNotifyOnNoUpdateAnnotation: "nope"},
+			want:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseNotifyOnNoUpdate(tt.annotations); got != tt.want {
+				t.Errorf("ParseNotifyOnNoUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImagePullPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+		wantErr     bool
+	}{
+		{name: "nil map"},
+		{name: "always", annotations: map[string]string{BowImagePullPolicyAnnotation: "Always"}, want: "Always"},
+		{name: "if not present", annotations: map[string]string{BowImagePullPolicyAnnotation: " IfNotPresent "}, want: "IfNotPresent"},
+		{name: "never", annotations: map[string]string{BowImagePullPolicyAnnotation: "Never"}, want: "Never"},
+		{name: "invalid", annotations: map[string]string{BowImagePullPolicyAnnotation: "always"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseImagePullPolicy(tt.annotations)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseImagePullPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseImagePullPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMaxVersionDelta(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    *VersionDelta
+		wantErr bool
+	}{
+		{name: "not set"},
+		{name: "major and minor", spec: "major=1,minor=5", want: &VersionDelta{Major: 1, Minor: 5, Patch: -1}},
+		{name: "spaces", spec: " patch = 3 ", want: &VersionDelta{Major: -1, Minor: -1, Patch: 3}},
+		{name: "unknown component", spec: "build=1", wantErr: true},
+		{name: "negative", spec: "major=-1", wantErr: true},
+		{name: "missing value", spec: "major", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMaxVersionDelta(map[string]string{BowMaxVersionDeltaAnnotation: tt.spec})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMaxVersionDelta() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMaxVersionDelta() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionDeltaAllows(t *testing.T) {
+	delta := &VersionDelta{Major: 1, Minor: 5, Patch: -1}
+	tests := []struct {
+		current Version
+		new     Version
+		want    bool
+	}{
+		{Version{Major: 1}, Version{Major: 2, Minor: 3}, true},
+		{Version{Major: 1}, Version{Major: 3}, false},
+		{Version{Major: 1, Minor: 2}, Version{Major: 1, Minor: 7}, true},
+		{Version{Major: 1, Minor: 2}, Version{Major: 1, Minor: 8}, false},
+		{Version{Major: 1, Minor: 2}, Version{Major: 1, Minor: 2, Patch: 40}, true},
+	}
+	for _, tt := range tests {
+		if got := delta.Allows(&tt.current, &tt.new); got != tt.want {
+			t.Errorf("Allows(%d.%d.%d, %d.%d.%d) = %v, want %v", tt.current.Major, tt.current.Minor, tt.current.Patch, tt.new.Major, tt.new.Minor, tt.new.Patch, got, tt.want)
+		}
+	}
+}
+
+func TestParseApprovalVoters(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []string
+	}{
+		{
+			name:        "not set",
+			annotations: map[string]string{},
+		},
+		{
+			name:        "voters",
+			annotations: map[string]string{BowApprovalVotersAnnotation: "alice, bob,,carol "},
+			want:        []string{"alice", "bob", "carol"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseApprovalVoters(tt.annotations); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseApprovalVoters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
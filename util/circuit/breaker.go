@@ -0,0 +1,155 @@
+package circuit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alwinius/bow/util/timeutil"
+)
+
+// State - circuit state
+type State int
+
+// available circuit states
+const (
+	// StateClosed - events are processed
+	StateClosed State = iota
+	// StateOpen - events are dropped until backoff passes
+	StateOpen
+	// StateHalfOpen - backoff passed, next event decides whether
+	// circuit gets closed or opened again
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Opts - breaker configuration
+type Opts struct {
+	// Threshold - consecutive failures after which circuit opens
+	Threshold int
+	// Backoff - how long circuit stays open before the next attempt
+	Backoff time.Duration
+}
+
+// DefaultOpts - used by providers, can be overridden before
+// providers are created
+var DefaultOpts = Opts{
+	Threshold: 5,
+	Backoff:   5 * time.Minute,
+}
+
+type circuit struct {
+	state    State
+	failures int
+	openedAt time.Time
+}
+
+// Breaker - keeps separate circuit for each key (image, release, etc.)
+type Breaker struct {
+	opts Opts
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// New - create new breaker
+func New(opts Opts) *Breaker {
+	if opts.Threshold < 1 {
+		opts.Threshold = 1
+	}
+	return &Breaker{
+		opts:     opts,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// Allow - whether work for the key should be attempted, open circuit
+// becomes half-open once backoff passes
+func (b *Breaker) Allow(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		return true
+	}
+
+	if c.state == StateOpen {
+		if timeutil.Now().Sub(c.openedAt) < b.opts.Backoff {
+			return false
+		}
+		c.state = StateHalfOpen
+	}
+
+	return true
+}
+
+// Success - closes circuit
+func (b *Breaker) Success(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.circuits, key)
+}
+
+// Failure - records failure, returns true when this failure opened the circuit
+// so callers can log it once instead of on every failure
+func (b *Breaker) Failure(key string) (opened bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+
+	c.failures++
+
+	if c.state == StateHalfOpen || (c.state == StateClosed && c.failures >= b.opts.Threshold) {
+		c.state = StateOpen
+		c.openedAt = timeutil.Now()
+		return true
+	}
+
+	return false
+}
+
+// State - current circuit state for the key
+func (b *Breaker) State(key string) State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		return StateClosed
+	}
+
+	// reporting open circuits that are ready for another attempt as half-open
+	if c.state == StateOpen && timeutil.Now().Sub(c.openedAt) >= b.opts.Backoff {
+		return StateHalfOpen
+	}
+
+	return c.state
+}
+
+// Failures - consecutive failures for the key
+func (b *Breaker) Failures(key string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		return 0
+	}
+	return c.failures
+}
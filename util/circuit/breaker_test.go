@@ -0,0 +1,66 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/util/timeutil"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+	timeutil.Now = func() time.Time { return now }
+	defer func() { timeutil.Now = time.Now }()
+
+	b := New(Opts{Threshold: 3, Backoff: time.Minute})
+	key := "index.docker.io/karolisr/webhook-demo"
+
+	for i := 0; i < 2; i++ {
+		if !b.Allow(key) {
+			t.Fatalf("expected closed circuit to allow events")
+		}
+		if b.Failure(key) {
+			t.Fatalf("circuit shouldn't open before threshold")
+		}
+	}
+
+	if !b.Failure(key) {
+		t.Fatalf("expected circuit to open on threshold")
+	}
+	if b.State(key) != StateOpen {
+		t.Errorf("expected open circuit, got: %s", b.State(key))
+	}
+	if b.Allow(key) {
+		t.Errorf("open circuit shouldn't allow events")
+	}
+	if !b.Allow("other") {
+		t.Errorf("circuits should be separate for each key")
+	}
+
+	// backoff passed, next event is a trial
+	now = now.Add(time.Minute)
+	if b.State(key) != StateHalfOpen {
+		t.Errorf("expected half-open circuit, got: %s", b.State(key))
+	}
+	if !b.Allow(key) {
+		t.Fatalf("half-open circuit should allow event")
+	}
+	if !b.Failure(key) {
+		t.Errorf("expected failed trial to open circuit again")
+	}
+	if b.Allow(key) {
+		t.Errorf("reopened circuit shouldn't allow events")
+	}
+
+	now = now.Add(time.Minute)
+	if !b.Allow(key) {
+		t.Fatalf("half-open circuit should allow event")
+	}
+	b.Success(key)
+	if b.State(key) != StateClosed {
+		t.Errorf("expected closed circuit after success, got: %s", b.State(key))
+	}
+	if b.Failures(key) != 0 {
+		t.Errorf("expected failures to be reset, got: %d", b.Failures(key))
+	}
+}
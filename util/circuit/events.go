@@ -0,0 +1,68 @@
+package circuit
+
+import (
+	"context"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/deadletter"
+	"github.com/alwinius/bow/util/image"
+	"github.com/alwinius/bow/util/trace"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// MetaKey - tracked image metadata key with circuit state
+const MetaKey = "circuit"
+
+// Key - events for the same image share a circuit regardless
+// of how the image name was written
+func Key(name string) string {
+	ref, err := image.Parse(name)
+	if err != nil {
+		return name
+	}
+	return ref.Repository()
+}
+
+// HandleEvent - processes provider event unless circuit for the image is open, errors
+// are logged until the circuit opens and then suppressed for the backoff period. Event
+// is added to the dead letter queue of the provider when its circuit opens
+func (b *Breaker) HandleEvent(ctx context.Context, provider string, event *types.Event, deadLetters *deadletter.Queue, process func(ctx context.Context, event *types.Event) error) {
+	key := Key(event.Repository.Name)
+	if !b.Allow(key) {
+		trace.Log(ctx).WithFields(log.Fields{
+			"image": event.Repository.Name,
+			"tag":   event.Repository.Tag,
+		}).Debug("provider." + provider + ": circuit open, skipping event")
+		return
+	}
+
+	err := process(ctx, event)
+	if err == nil {
+		b.Success(key)
+		return
+	}
+
+	if !b.Failure(key) {
+		trace.Log(ctx).WithFields(log.Fields{
+			"error": err,
+			"image": event.Repository.Name,
+			"tag":   event.Repository.Tag,
+		}).Error("provider." + provider + ": failed to process event")
+		return
+	}
+
+	trace.Log(ctx).WithFields(log.Fields{
+		"error":    err,
+		"image":    event.Repository.Name,
+		"tag":      event.Repository.Tag,
+		"failures": b.Failures(key),
+	}).Error("provider." + provider + ": failed to process event, circuit opened, suppressing events for this image")
+
+	deadLetters.Add(provider, *event, err, b.Failures(key))
+}
+
+// Retry - closes circuit of the retried event so it isn't skipped
+func (b *Breaker) Retry(event *types.Event) {
+	b.Success(Key(event.Repository.Name))
+}
@@ -0,0 +1,49 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/deadletter"
+)
+
+func TestKey(t *testing.T) {
+	if Key("karolisr/webhook-demo") != Key("index.docker.io/karolisr/webhook-demo:0.0.11") {
+		t.Errorf("expected same image to share a circuit")
+	}
+	if Key("!invalid!") != "!invalid!" {
+		t.Errorf("expected invalid name to be used as is")
+	}
+}
+
+func TestHandleEvent(t *testing.T) {
+	b := New(Opts{Threshold: 2, Backoff: time.Hour})
+	dlq := deadletter.New(10)
+
+	calls := 0
+	process := func(ctx context.Context, event *types.Event) error {
+		calls++
+		return errors.New("registry unavailable")
+	}
+
+	event := &types.Event{Repository: types.Repository{Name: "karolisr/webhook-demo", Tag: "0.0.11"}}
+	for i := 0; i < 5; i++ {
+		b.HandleEvent(context.Background(), "kubernetes", event, dlq, process)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected event to be processed until circuit opens, got %d calls", calls)
+	}
+	entries := dlq.List()
+	if len(entries) != 1 || entries[0].Provider != "kubernetes" || entries[0].Retries != 2 {
+		t.Fatalf("expected dead letter once circuit opened, got: %v", entries)
+	}
+
+	b.Retry(event)
+	if state := b.State(Key(event.Repository.Name)); state != StateClosed {
+		t.Errorf("expected retried event to close circuit, got: %s", state)
+	}
+}
@@ -0,0 +1,148 @@
+package deadletter
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/timeutil"
+)
+
+// DefaultSize - how many failed events are kept, oldest entries
+// are dropped once the queue is full
+const DefaultSize = 100
+
+// errors
+var (
+	ErrNotFound          = errors.New("dead letter not found")
+	ErrProviderNotActive = errors.New("provider of the dead letter is not active")
+)
+
+// Entry - event that kept failing until circuit for its image opened
+type Entry struct {
+	ID       string      `json:"id"`
+	Provider string      `json:"provider"`
+	Event    types.Event `json:"event"`
+	// Error - last processing error
+	Error string `json:"error"`
+	// Retries - consecutive failed attempts
+	Retries   int       `json:"retries"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Queue - keeps failed events so operators can inspect them and retry once the
+// underlying issue is resolved. Events are deduplicated by provider, repository
+// and tag, repeated failures update the existing entry
+type Queue struct {
+	size int
+
+	mu       sync.Mutex
+	entries  []*Entry
+	retriers map[string]func(event *types.Event)
+}
+
+// New - creates new queue keeping up to size entries
+func New(size int) *Queue {
+	if size < 1 {
+		size = DefaultSize
+	}
+	return &Queue{
+		size:     size,
+		retriers: make(map[string]func(event *types.Event)),
+	}
+}
+
+// Register - sets function requeueing retried events of the provider
+func (q *Queue) Register(provider string, retry func(event *types.Event)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.retriers[provider] = retry
+}
+
+// Add - adds failed event, queue can be nil in which case failed
+// events are dropped
+func (q *Queue) Add(provider string, event types.Event, err error, retries int) *Entry {
+	if q == nil {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := timeutil.Now()
+	for _, e := range q.entries {
+		if e.Provider == provider && e.Event.Repository.String() == event.Repository.String() {
+			e.Event = event
+			e.Error = err.Error()
+			e.Retries = retries
+			e.UpdatedAt = now
+			return e
+		}
+	}
+
+	e := &Entry{
+		ID:        uuid.New().String(),
+		Provider:  provider,
+		Event:     event,
+		Error:     err.Error(),
+		Retries:   retries,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	q.entries = append(q.entries, e)
+	if len(q.entries) > q.size {
+		q.entries = q.entries[len(q.entries)-q.size:]
+	}
+
+	return e
+}
+
+// List - failed events, oldest first
+func (q *Queue) List() []Entry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries := make([]Entry, 0, len(q.entries))
+	for _, e := range q.entries {
+		entries = append(entries, *e)
+	}
+	return entries
+}
+
+// Retry - removes entry from the queue and requeues its event
+// to the provider that failed to process it
+func (q *Queue) Retry(id string) (*Entry, error) {
+	q.mu.Lock()
+
+	idx := -1
+	for i, e := range q.entries {
+		if e.ID == id {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		q.mu.Unlock()
+		return nil, ErrNotFound
+	}
+
+	e := q.entries[idx]
+	retry, ok := q.retriers[e.Provider]
+	if !ok {
+		q.mu.Unlock()
+		return nil, fmt.Errorf("%s: %s", ErrProviderNotActive, e.Provider)
+	}
+	q.entries = append(q.entries[:idx], q.entries[idx+1:]...)
+	q.mu.Unlock()
+
+	event := e.Event
+	retry(&event)
+
+	return e, nil
+}
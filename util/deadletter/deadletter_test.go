@@ -0,0 +1,91 @@
+package deadletter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alwinius/bow/types"
+)
+
+func event(name, tag string) types.Event {
+	return types.Event{Repository: types.Repository{Name: name, Tag: tag}}
+}
+
+func TestAddDeduplicates(t *testing.T) {
+	q := New(10)
+
+	first := q.Add("kubernetes", event("foo/bar", "1.0.0"), errors.New("first"), 5)
+	second := q.Add("kubernetes", event("foo/bar", "1.0.0"), errors.New("second"), 6)
+	q.Add("helm", event("foo/bar", "1.0.0"), errors.New("helm"), 5)
+	q.Add("kubernetes", event("foo/bar", "1.1.0"), errors.New("other tag"), 5)
+
+	if first.ID != second.ID {
+		t.Errorf("expected repeated failure to update existing entry")
+	}
+
+	entries := q.List()
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got: %d", len(entries))
+	}
+	if entries[0].Error != "second" || entries[0].Retries != 6 {
+		t.Errorf("unexpected entry: %+v", entries[0])
+	}
+}
+
+func TestAddDropsOldest(t *testing.T) {
+	q := New(2)
+
+	q.Add("kubernetes", event("foo/a", "1.0.0"), errors.New("boom"), 5)
+	q.Add("kubernetes", event("foo/b", "1.0.0"), errors.New("boom"), 5)
+	q.Add("kubernetes", event("foo/c", "1.0.0"), errors.New("boom"), 5)
+
+	entries := q.List()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got: %d", len(entries))
+	}
+	if entries[0].Event.Repository.Name != "foo/b" {
+		t.Errorf("expected oldest entry to be dropped, got: %s", entries[0].Event.Repository.Name)
+	}
+}
+
+func TestAddNilQueue(t *testing.T) {
+	var q *Queue
+	if e := q.Add("kubernetes", event("foo/bar", "1.0.0"), errors.New("boom"), 5); e != nil {
+		t.Errorf("expected nil entry")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	q := New(10)
+
+	var retried []*types.Event
+	q.Register("kubernetes", func(event *types.Event) {
+		retried = append(retried, event)
+	})
+
+	e := q.Add("kubernetes", event("foo/bar", "1.0.0"), errors.New("boom"), 5)
+	orphan := q.Add("helm", event("foo/bar", "1.0.0"), errors.New("boom"), 5)
+
+	_, err := q.Retry("missing")
+	if err != ErrNotFound {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+
+	_, err = q.Retry(orphan.ID)
+	if err == nil {
+		t.Errorf("expected error for provider without retry function")
+	}
+
+	_, err = q.Retry(e.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(retried) != 1 || retried[0].Repository.Tag != "1.0.0" {
+		t.Fatalf("expected event to be requeued, got: %v", retried)
+	}
+
+	entries := q.List()
+	if len(entries) != 1 || entries[0].ID != orphan.ID {
+		t.Errorf("expected retried entry to be removed, got: %+v", entries)
+	}
+}
@@ -0,0 +1,59 @@
+package dedup
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alwinius/bow/types"
+)
+
+// Window - remembers recently seen keys, ie: repository and tag of events. Keys
+// seen again before the window passes are duplicates, ie: several webhooks
+// fired for the same push
+type Window struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// New - creates new deduplication window, zero window disables deduplication
+func New(window time.Duration) *Window {
+	return &Window{
+		window: window,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// Key - deduplication key of the event repository, [host/]name[:tag][@digest]. Same
+// tag pushed again with another digest is a new image and not a duplicate
+func Key(repo *types.Repository) string {
+	if repo.Digest == "" {
+		return repo.String()
+	}
+	return repo.String() + "@" + repo.Digest
+}
+
+// Seen - records the key, returns true if it was already seen within the window.
+// Duplicates don't extend the window so repeated events are processed again
+// once it passes
+func (w *Window) Seen(key string, now time.Time) bool {
+	if w == nil || w.window <= 0 {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for k, at := range w.seen {
+		if now.Sub(at) >= w.window {
+			delete(w.seen, k)
+		}
+	}
+
+	if _, ok := w.seen[key]; ok {
+		return true
+	}
+	w.seen[key] = now
+	return false
+}
@@ -0,0 +1,51 @@
+package dedup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/types"
+)
+
+func TestWindow(t *testing.T) {
+	now := time.Date(2024, 1, 12, 12, 0, 0, 0, time.UTC)
+	w := New(5 * time.Second)
+
+	if w.Seen("hello-world:1.1.2", now) {
+		t.Errorf("first event shouldn't be a duplicate")
+	}
+	if !w.Seen("hello-world:1.1.2", now.Add(time.Second)) {
+		t.Errorf("expected same key within the window to be a duplicate")
+	}
+	if w.Seen("hello-world:1.1.3", now.Add(time.Second)) {
+		t.Errorf("other tag shouldn't be a duplicate")
+	}
+	if w.Seen("hello-world:1.1.2", now.Add(5*time.Second)) {
+		t.Errorf("expected key to be processed again once the window passed")
+	}
+	if len(w.seen) != 2 {
+		t.Errorf("expected expired keys to be removed, got: %v", w.seen)
+	}
+}
+
+func TestWindowDisabled(t *testing.T) {
+	now := time.Date(2024, 1, 12, 12, 0, 0, 0, time.UTC)
+
+	for _, w := range []*Window{nil, New(0)} {
+		w.Seen("hello-world:1.1.2", now)
+		if w.Seen("hello-world:1.1.2", now) {
+			t.Errorf("expected deduplication to be disabled")
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	pushed := Key(&types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.2", Digest: "sha256:aaa"})
+	repushed := Key(&types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.2", Digest: "sha256:bbb"})
+	if pushed == repushed {
+		t.Errorf("expected same tag with another digest to have a different key, got: %s", pushed)
+	}
+	if key := Key(&types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.2"}); key != "gcr.io/v2-namespace/hello-world:1.1.2" {
+		t.Errorf("unexpected key without digest: %s", key)
+	}
+}
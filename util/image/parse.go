@@ -72,6 +72,34 @@ func clean(url string) (cleaned string, scheme string) {
 	return s, scheme
 }
 
+// SplitTag - splits image into name and tag without normalizing it, registry
+// ports are kept in the name: registry.corp:5000/team/app:1.2.3 returns
+// registry.corp:5000/team/app and 1.2.3. Tag is empty if image has no tag.
+func SplitTag(image string) (name, tag string) {
+	// digests are not tags
+	if idx := strings.Index(image, "@"); idx >= 0 {
+		image = image[:idx]
+	}
+
+	idx := strings.LastIndex(image, ":")
+	if idx < 0 || strings.Contains(image[idx+1:], "/") {
+		// colon belongs to registry port
+		return image, ""
+	}
+
+	return image[:idx], image[idx+1:]
+}
+
+// SplitDigest splits image into name and digest, ie: "repo:1.0@sha256:abc"
+// becomes "repo:1.0" and "sha256:abc". Digest is empty when image is not pinned
+func SplitDigest(image string) (name, digest string) {
+	idx := strings.Index(image, "@")
+	if idx < 0 {
+		return image, ""
+	}
+	return image[:idx], image[idx+1:]
+}
+
 // Parse returns a Reference from analyzing the given remote identifier.
 func Parse(remote string) (*Reference, error) {
 
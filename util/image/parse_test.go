@@ -128,6 +128,81 @@ func TestParseRepo(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "registry.corp:5000/team/app:1.2.3 (registry port)",
+			args: args{remote: "registry.corp:5000/team/app:1.2.3"},
+			want: &Repository{
+				Name:       "team/app:1.2.3",
+				Repository: "registry.corp:5000/team/app",
+				Remote:     "registry.corp:5000/team/app:1.2.3",
+				Registry:   "registry.corp:5000",
+				ShortName:  "team/app",
+				Tag:        "1.2.3",
+				Scheme:     "https",
+			},
+			wantErr: false,
+		},
+		{
+			name: "registry.corp:5000/team/app (registry port, no tag)",
+			args: args{remote: "registry.corp:5000/team/app"},
+			want: &Repository{
+				Name:       "team/app:latest",
+				Repository: "registry.corp:5000/team/app",
+				Remote:     "registry.corp:5000/team/app:latest",
+				Registry:   "registry.corp:5000",
+				ShortName:  "team/app",
+				Tag:        "latest",
+				Scheme:     "https",
+			},
+			wantErr: false,
+		},
+		{
+			name: "public.ecr.aws/nginx/nginx:1.19 (ECR public)",
+			args: args{remote: "public.ecr.aws/nginx/nginx:1.19"},
+			want: &Repository{
+				Name:       "nginx/nginx:1.19",
+				Repository: "public.ecr.aws/nginx/nginx",
+				Remote:     "public.ecr.aws/nginx/nginx:1.19",
+				Registry:   ECRPublicRegistryHostname,
+				ShortName:  "nginx/nginx",
+				Tag:        "1.19",
+				Scheme:     "https",
+			},
+			wantErr: false,
+		},
+		{
+			name: "public.ecr.aws/eks-distro/kubernetes/pause:v1.18.9-eks-1-18-1 (ECR public, nested)",
+			args: args{remote: "public.ecr.aws/eks-distro/kubernetes/pause:v1.18.9-eks-1-18-1"},
+			want: &Repository{
+				Name:       "eks-distro/kubernetes/pause:v1.18.9-eks-1-18-1",
+				Repository: "public.ecr.aws/eks-distro/kubernetes/pause",
+				Remote:     "public.ecr.aws/eks-distro/kubernetes/pause:v1.18.9-eks-1-18-1",
+				Registry:   ECRPublicRegistryHostname,
+				ShortName:  "eks-distro/kubernetes/pause",
+				Tag:        "v1.18.9-eks-1-18-1",
+				Scheme:     "https",
+			},
+			wantErr: false,
+		},
+		{
+			name: "public.ecr.aws/bitnami/redis (ECR public, no tag)",
+			args: args{remote: "public.ecr.aws/bitnami/redis"},
+			want: &Repository{
+				Name:       "bitnami/redis:latest",
+				Repository: "public.ecr.aws/bitnami/redis",
+				Remote:     "public.ecr.aws/bitnami/redis:latest",
+				Registry:   ECRPublicRegistryHostname,
+				ShortName:  "bitnami/redis",
+				Tag:        "latest",
+				Scheme:     "https",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "public.ecr.aws/nginx:1.19 (ECR public, no alias)",
+			args:    args{remote: "public.ecr.aws/nginx:1.19"},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -142,3 +217,43 @@ func TestParseRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitTag(t *testing.T) {
+	tests := []struct {
+		image    string
+		wantName string
+		wantTag  string
+	}{
+		{image: "foo/bar:1.1", wantName: "foo/bar", wantTag: "1.1"},
+		{image: "foo/bar", wantName: "foo/bar", wantTag: ""},
+		{image: "registry.corp:5000/team/app:1.2.3", wantName: "registry.corp:5000/team/app", wantTag: "1.2.3"},
+		{image: "registry.corp:5000/team/app", wantName: "registry.corp:5000/team/app", wantTag: ""},
+		{image: "registry.corp:5000/team/app:1.2.3@sha256:4e8f55c9b8b1bd8ff0b0e4b4c2c0e4e3", wantName: "registry.corp:5000/team/app", wantTag: "1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			name, tag := SplitTag(tt.image)
+			if name != tt.wantName {
+				t.Errorf("SplitTag() name = %s, want %s", name, tt.wantName)
+			}
+			if tag != tt.wantTag {
+				t.Errorf("SplitTag() tag = %s, want %s", tag, tt.wantTag)
+			}
+		})
+	}
+}
+
+func TestSplitDigest(t *testing.T) {
+	name, digest := SplitDigest("registry.corp:5000/team/app:1.2.3@sha256:4e8f55c9b8b1bd8ff0b0e4b4c2c0e4e3")
+	if name != "registry.corp:5000/team/app:1.2.3" {
+		t.Errorf("SplitDigest() name = %s", name)
+	}
+	if digest != "sha256:4e8f55c9b8b1bd8ff0b0e4b4c2c0e4e3" {
+		t.Errorf("SplitDigest() digest = %s", digest)
+	}
+
+	name, digest = SplitDigest("foo/bar:1.1")
+	if name != "foo/bar:1.1" || digest != "" {
+		t.Errorf("SplitDigest() = %s, %s", name, digest)
+	}
+}
@@ -23,8 +23,26 @@ const (
 
 	// DefaultRepoPrefix is the prefix used for default repositories in default host
 	DefaultRepoPrefix = "library/"
+
+	// ECRPublicRegistryHostname is the hostname of AWS ECR public gallery, repositories
+	// there are always prefixed with registry alias, ie: public.ecr.aws/<alias>/<repo>
+	ECRPublicRegistryHostname = "public.ecr.aws"
 )
 
+// IsECRPublic - whether registry is AWS ECR public gallery, its images
+// are pulled anonymously
+func IsECRPublic(registry string) bool {
+	return strings.EqualFold(registry, ECRPublicRegistryHostname)
+}
+
+// IsGoogleRegistry - whether registry is Google Container Registry (gcr.io, *.gcr.io)
+// or Artifact Registry (*-docker.pkg.dev)
+func IsGoogleRegistry(registry string) bool {
+	return registry == "gcr.io" ||
+		strings.HasSuffix(registry, ".gcr.io") ||
+		strings.HasSuffix(registry, "-docker.pkg.dev")
+}
+
 // Repository is an object created from Named interface
 type Repository struct {
 	Name       string // Name returns the image's name. (ie: debian[:8.2])
@@ -200,6 +218,9 @@ func normalize(name string) (string, error) {
 	if strings.ToLower(remoteName) != remoteName {
 		return "", errors.New("invalid reference format: repository name must be lowercase")
 	}
+	if IsECRPublic(host) && !strings.ContainsRune(remoteName, '/') {
+		return "", fmt.Errorf("invalid reference format: ECR public repository %s has no registry alias, expected %s/<alias>/<repository>", name, ECRPublicRegistryHostname)
+	}
 	if host == DefaultRegistryHostname {
 		if strings.HasPrefix(remoteName, DefaultRepoPrefix) {
 			return strings.TrimPrefix(remoteName, DefaultRepoPrefix), nil
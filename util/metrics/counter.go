@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	countersMu sync.Mutex
+	counters   []*Counter
+)
+
+// Counter - prometheus counter with a single label that also keeps its values
+// in process, so they can be served as JSON without a prometheus scraper
+type Counter struct {
+	*prometheus.CounterVec
+
+	name  string
+	label string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewCounter - create new counter partitioned by label, counter still has to
+// be registered with prometheus
+func NewCounter(opts prometheus.CounterOpts, label string) *Counter {
+	c := &Counter{
+		CounterVec: prometheus.NewCounterVec(opts, []string{label}),
+		name:       opts.Name,
+		label:      label,
+		values:     make(map[string]float64),
+	}
+
+	countersMu.Lock()
+	counters = append(counters, c)
+	countersMu.Unlock()
+
+	return c
+}
+
+// Inc - increments counter for the label value, ie: namespace/name
+func (c *Counter) Inc(value string) {
+	c.CounterVec.With(prometheus.Labels{c.label: value}).Inc()
+
+	c.mu.Lock()
+	c.values[value]++
+	c.mu.Unlock()
+}
+
+// Values - copy of current counter values keyed by label value
+func (c *Counter) Values() map[string]float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	values := make(map[string]float64, len(c.values))
+	for k, v := range c.values {
+		values[k] = v
+	}
+	return values
+}
+
+// Snapshot - values of all counters keyed by counter name
+func Snapshot() map[string]map[string]float64 {
+	countersMu.Lock()
+	defer countersMu.Unlock()
+
+	snapshot := make(map[string]map[string]float64, len(counters))
+	for _, c := range counters {
+		snapshot[c.name] = c.Values()
+	}
+	return snapshot
+}
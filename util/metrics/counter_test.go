@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestCounter(t *testing.T) {
+	c := NewCounter(prometheus.CounterOpts{Name: "test_updates_total"}, "chart")
+
+	c.Inc("default/wd")
+	c.Inc("default/wd")
+	c.Inc("kube-system/other")
+
+	values := Snapshot()["test_updates_total"]
+	if values["default/wd"] != 2 {
+		t.Errorf("expected 2 updates, got: %v", values["default/wd"])
+	}
+	if values["kube-system/other"] != 1 {
+		t.Errorf("expected 1 update, got: %v", values["kube-system/other"])
+	}
+
+	// prometheus counter is kept in sync
+	var m dto.Metric
+	err := c.CounterVec.With(prometheus.Labels{"chart": "default/wd"}).Write(&m)
+	if err != nil {
+		t.Fatalf("failed to write metric: %s", err)
+	}
+	if m.GetCounter().GetValue() != 2 {
+		t.Errorf("expected prometheus counter 2, got: %v", m.GetCounter().GetValue())
+	}
+
+	// snapshot is a copy
+	values["default/wd"] = 10
+	if c.Values()["default/wd"] != 2 {
+		t.Errorf("snapshot shouldn't modify counter")
+	}
+}
@@ -0,0 +1,107 @@
+package pending
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/timeutil"
+)
+
+// Events - buffer for events that couldn't be applied yet (ie: update or maintenance
+// window is closed), events are replayed once their time comes. Events are deduplicated
+// by repository and tag, the latest event is kept so replay uses the newest digest
+type Events struct {
+	replay func(event *types.Event)
+
+	mu      sync.Mutex
+	pending map[string]*entry
+}
+
+type entry struct {
+	event *types.Event
+	until time.Time
+	timer *time.Timer
+}
+
+// New - creates new buffer, replay is called from a separate goroutine
+func New(replay func(event *types.Event)) *Events {
+	return &Events{
+		replay:  replay,
+		pending: make(map[string]*entry),
+	}
+}
+
+// Key - deduplication key for the repository, [host/]name[:tag]
+func Key(repo *types.Repository) string {
+	return repo.String()
+}
+
+// Add - buffers event until given time, if the same repository and tag is already
+// pending the event replaces it and the earlier replay time is kept
+func (e *Events) Add(event *types.Event, until time.Time) {
+	key := Key(&event.Repository)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	existing, ok := e.pending[key]
+	if ok {
+		existing.event = event
+		if !until.Before(existing.until) {
+			return
+		}
+		existing.timer.Stop()
+	}
+
+	en := &entry{event: event, until: until}
+	en.timer = time.AfterFunc(until.Sub(timeutil.Now()), func() {
+		e.fire(key, en)
+	})
+	e.pending[key] = en
+}
+
+func (e *Events) fire(key string, en *entry) {
+	e.mu.Lock()
+	if e.pending[key] != en {
+		// replaced or stopped in the meantime
+		e.mu.Unlock()
+		return
+	}
+	delete(e.pending, key)
+	event := en.event
+	e.mu.Unlock()
+
+	e.replay(event)
+}
+
+// Get - returns pending event for the repository and when it will be replayed
+func (e *Events) Get(repo *types.Repository) (event *types.Event, until time.Time, ok bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	en, ok := e.pending[Key(repo)]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	return en.event, en.until, true
+}
+
+// Len - number of pending events
+func (e *Events) Len() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return len(e.pending)
+}
+
+// Stop - drops all pending events
+func (e *Events) Stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for key, en := range e.pending {
+		en.timer.Stop()
+		delete(e.pending, key)
+	}
+}
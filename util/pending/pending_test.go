@@ -0,0 +1,95 @@
+package pending
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/timeutil"
+)
+
+func TestEvents(t *testing.T) {
+	replayed := make(chan *types.Event, 10)
+	events := New(func(event *types.Event) {
+		replayed <- event
+	})
+	defer events.Stop()
+
+	first := &types.Event{Repository: types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.2", Digest: "sha256:aaa"}}
+	second := &types.Event{Repository: types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.2", Digest: "sha256:bbb"}}
+	other := &types.Event{Repository: types.Repository{Name: "gcr.io/v2-namespace/hello-world", Tag: "1.1.3"}}
+
+	until := timeutil.Now().Add(50 * time.Millisecond)
+	events.Add(first, until)
+	// same repository and tag, newer event replaces the pending one but keeps replay time
+	events.Add(second, until.Add(time.Hour))
+	events.Add(other, until)
+
+	if events.Len() != 2 {
+		t.Fatalf("expected 2 pending events, got: %d", events.Len())
+	}
+	pendingEvent, pendingUntil, ok := events.Get(&first.Repository)
+	if !ok || pendingEvent != second || !pendingUntil.Equal(until) {
+		t.Errorf("unexpected pending event: %v, until: %s", pendingEvent, pendingUntil)
+	}
+
+	got := map[*types.Event]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-replayed:
+			got[event] = true
+		case <-time.After(2 * time.Second):
+			t.Fatalf("events were not replayed")
+		}
+	}
+	if !got[second] || !got[other] {
+		t.Errorf("expected latest events to be replayed, got: %v", got)
+	}
+
+	select {
+	case event := <-replayed:
+		t.Errorf("event replayed twice: %v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if events.Len() != 0 {
+		t.Errorf("expected no pending events after replay, got: %d", events.Len())
+	}
+}
+
+func TestEventsEarlierReplay(t *testing.T) {
+	replayed := make(chan *types.Event, 10)
+	events := New(func(event *types.Event) {
+		replayed <- event
+	})
+	defer events.Stop()
+
+	event := &types.Event{Repository: types.Repository{Name: "karolisr/webhook-demo", Tag: "0.0.11"}}
+	events.Add(event, timeutil.Now().Add(time.Hour))
+	events.Add(event, timeutil.Now().Add(50*time.Millisecond))
+
+	select {
+	case got := <-replayed:
+		if got != event {
+			t.Errorf("unexpected event replayed: %v", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected event to be replayed at the earlier time")
+	}
+}
+
+func TestEventsStop(t *testing.T) {
+	replayed := make(chan *types.Event, 10)
+	events := New(func(event *types.Event) {
+		replayed <- event
+	})
+
+	events.Add(&types.Event{Repository: types.Repository{Name: "karolisr/webhook-demo", Tag: "0.0.11"}}, timeutil.Now().Add(50*time.Millisecond))
+	events.Stop()
+
+	select {
+	case event := <-replayed:
+		t.Errorf("stopped buffer replayed event: %v", event)
+	case <-time.After(150 * time.Millisecond):
+	}
+}
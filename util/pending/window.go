@@ -0,0 +1,33 @@
+package pending
+
+import (
+	"time"
+
+	"github.com/alwinius/bow/util/timeutil"
+)
+
+// Windows - checks updates against their update windows and remembers when the
+// earliest window of the deferred updates opens, zero value is ready to use
+type Windows struct {
+	next time.Time
+}
+
+// Defer - returns when the window opens if the update has to wait for it, zero
+// time if it can be applied now. Nil window allows updates any time
+func (w *Windows) Defer(window *timeutil.UpdateWindow, now time.Time) (opens time.Time) {
+	if window == nil || window.Contains(now) {
+		return time.Time{}
+	}
+
+	opens = window.Next(now)
+	if w.next.IsZero() || opens.Before(w.next) {
+		w.next = opens
+	}
+	return opens
+}
+
+// Next - when the earliest window of the deferred updates opens, zero
+// if nothing was deferred
+func (w *Windows) Next() time.Time {
+	return w.next
+}
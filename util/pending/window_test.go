@@ -0,0 +1,47 @@
+package pending
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alwinius/bow/util/timeutil"
+)
+
+func TestWindows(t *testing.T) {
+	// Friday 12:00 UTC
+	now := time.Date(2024, 1, 12, 12, 0, 0, 0, time.UTC)
+
+	weekdays, err := timeutil.ParseUpdateWindow("* 2-3 * * 1-5")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	noon, err := timeutil.ParseUpdateWindow("* 12 * * *")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	weekend, err := timeutil.ParseUpdateWindow("* 2-3 * * 6")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var windows Windows
+	if opens := windows.Defer(nil, now); !opens.IsZero() {
+		t.Errorf("expected update without window to be ready, got: %s", opens)
+	}
+	if opens := windows.Defer(noon, now); !opens.IsZero() {
+		t.Errorf("expected update inside window to be ready, got: %s", opens)
+	}
+	if !windows.Next().IsZero() {
+		t.Errorf("expected nothing to be deferred, got: %s", windows.Next())
+	}
+
+	if opens := windows.Defer(weekdays, now); !opens.Equal(time.Date(2024, 1, 15, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected weekday window opening: %s", opens)
+	}
+	if opens := windows.Defer(weekend, now); !opens.Equal(time.Date(2024, 1, 13, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected weekend window opening: %s", opens)
+	}
+	if expected := time.Date(2024, 1, 13, 2, 0, 0, 0, time.UTC); !windows.Next().Equal(expected) {
+		t.Errorf("expected earliest window at %s, got: %s", expected, windows.Next())
+	}
+}
@@ -51,3 +51,20 @@ func (s *Stopper) Stop() {
 	close(s.stop)
 	s.wg.Wait()
 }
+
+// WaitTimeout waits for the wait group, returns false if the timeout
+// passed before all goroutines finished
+func WaitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -0,0 +1,70 @@
+package timeutil
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rusenask/cron"
+)
+
+// schedule timezone prefixes, ie: CRON_TZ=Europe/Berlin 0 9 * * 1-5
+var scheduleTZPrefixes = []string{"CRON_TZ=", "TZ="}
+
+// locationSchedule - evaluates wrapped schedule in a specific timezone
+type locationSchedule struct {
+	schedule cron.Schedule
+	location *time.Location
+}
+
+// Next - returns next activation time, evaluated in the schedule's timezone
+func (s *locationSchedule) Next(t time.Time) time.Time {
+	return s.schedule.Next(t.In(s.location))
+}
+
+// ParseSchedule - parses poll schedule. Accepts descriptors such as "@every 2m",
+// standard 5 field cron expressions, 6 field expressions with seconds and an optional
+// timezone prefix: "CRON_TZ=Europe/Berlin 0 9 * * 1-5"
+func ParseSchedule(spec string) (cron.Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("schedule cannot be empty")
+	}
+
+	var location *time.Location
+	for _, prefix := range scheduleTZPrefixes {
+		if !strings.HasPrefix(spec, prefix) {
+			continue
+		}
+		fields := strings.SplitN(spec, " ", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("missing cron expression after timezone in schedule '%s'", spec)
+		}
+		loc, err := time.LoadLocation(strings.TrimPrefix(fields[0], prefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone in schedule '%s': %s", spec, err)
+		}
+		location = loc
+		spec = strings.TrimSpace(fields[1])
+		break
+	}
+
+	var (
+		schedule cron.Schedule
+		err      error
+	)
+	if !strings.HasPrefix(spec, "@") && len(strings.Fields(spec)) == 5 {
+		schedule, err = cron.ParseStandard(spec)
+	} else {
+		schedule, err = cron.Parse(spec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule '%s': %s", spec, err)
+	}
+
+	if location != nil {
+		return &locationSchedule{schedule: schedule, location: location}, nil
+	}
+
+	return schedule, nil
+}
@@ -0,0 +1,72 @@
+package timeutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("failed to load location: %s", err)
+	}
+
+	// Friday, 2024-01-12 10:00 UTC (11:00 in Berlin)
+	now := time.Date(2024, 1, 12, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		spec    string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			spec: "@every 2m",
+			want: now.Add(2 * time.Minute),
+		},
+		{
+			// standard 5 field cron, next weekday at 09:00 UTC is Monday
+			spec: "0 9 * * 1-5",
+			want: time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			spec: "CRON_TZ=Europe/Berlin 0 9 * * 1-5",
+			want: time.Date(2024, 1, 15, 9, 0, 0, 0, berlin),
+		},
+		{
+			spec: "TZ=Europe/Berlin 30 0 12 * * *",
+			want: time.Date(2024, 1, 12, 12, 0, 30, 0, berlin),
+		},
+		{
+			spec:    "CRON_TZ=Nowhere/Nothing 0 9 * * 1-5",
+			wantErr: true,
+		},
+		{
+			spec:    "CRON_TZ=Europe/Berlin",
+			wantErr: true,
+		},
+		{
+			spec:    "0 25 * * *",
+			wantErr: true,
+		},
+		{
+			spec:    "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := schedule.Next(now)
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseSchedule().Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
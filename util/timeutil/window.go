@@ -0,0 +1,53 @@
+package timeutil
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rusenask/cron"
+)
+
+// UpdateWindow - time window in which updates are allowed. Window is described
+// by a cron expression where every matching minute is inside the window, ie:
+// "* 2-3 * * 1-5" allows updates on weekdays between 02:00 and 04:00 UTC.
+// Timezone prefixes are supported the same way as in poll schedules:
+// "CRON_TZ=Europe/Berlin * 2-3 * * 1-5"
+type UpdateWindow struct {
+	spec     string
+	schedule cron.Schedule
+}
+
+// ParseUpdateWindow - parses update window cron range expression
+func ParseUpdateWindow(spec string) (*UpdateWindow, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@") {
+		return nil, fmt.Errorf("invalid update window '%s': descriptors are not supported, use a cron range expression", spec)
+	}
+
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid update window: %s", err)
+	}
+
+	return &UpdateWindow{spec: spec, schedule: schedule}, nil
+}
+
+// Contains - checks whether given time is inside the window
+func (w *UpdateWindow) Contains(t time.Time) bool {
+	minute := t.Truncate(time.Minute)
+	return w.schedule.Next(minute.Add(-time.Second)).Equal(minute)
+}
+
+// Next - returns the time when the window opens next, given time is
+// returned if it's already inside the window
+func (w *UpdateWindow) Next(t time.Time) time.Time {
+	if w.Contains(t) {
+		return t
+	}
+	return w.schedule.Next(t)
+}
+
+func (w *UpdateWindow) String() string {
+	return w.spec
+}
@@ -0,0 +1,80 @@
+package timeutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateWindow(t *testing.T) {
+	// weekdays, 02:00-03:59 UTC
+	window, err := ParseUpdateWindow("* 2-3 * * 1-5")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		contains bool
+		next     time.Time
+	}{
+		{
+			name:     "inside window",
+			now:      time.Date(2024, 1, 12, 2, 30, 15, 0, time.UTC),
+			contains: true,
+			next:     time.Date(2024, 1, 12, 2, 30, 15, 0, time.UTC),
+		},
+		{
+			name:     "last minute of the window",
+			now:      time.Date(2024, 1, 12, 3, 59, 59, 0, time.UTC),
+			contains: true,
+			next:     time.Date(2024, 1, 12, 3, 59, 59, 0, time.UTC),
+		},
+		{
+			name:     "after window, next one is on monday",
+			now:      time.Date(2024, 1, 12, 4, 0, 0, 0, time.UTC),
+			contains: false,
+			next:     time.Date(2024, 1, 15, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "before window",
+			now:      time.Date(2024, 1, 11, 1, 15, 0, 0, time.UTC),
+			contains: false,
+			next:     time.Date(2024, 1, 11, 2, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := window.Contains(tt.now); got != tt.contains {
+				t.Errorf("Contains() = %v, want %v", got, tt.contains)
+			}
+			if got := window.Next(tt.now); !got.Equal(tt.next) {
+				t.Errorf("Next() = %v, want %v", got, tt.next)
+			}
+		})
+	}
+}
+
+func TestUpdateWindowTimezone(t *testing.T) {
+	window, err := ParseUpdateWindow("CRON_TZ=Europe/Berlin * 2-3 * * *")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// 01:30 UTC is 02:30 in Berlin, 03:30 UTC is already 04:30
+	if !window.Contains(time.Date(2024, 1, 12, 1, 30, 0, 0, time.UTC)) {
+		t.Errorf("expected time to be inside the window")
+	}
+	if window.Contains(time.Date(2024, 1, 12, 3, 30, 0, 0, time.UTC)) {
+		t.Errorf("expected time to be outside the window")
+	}
+}
+
+func TestParseUpdateWindowInvalid(t *testing.T) {
+	for _, spec := range []string{"", "@every 1h", "* 25 * * *"} {
+		if _, err := ParseUpdateWindow(spec); err == nil {
+			t.Errorf("expected error for update window '%s'", spec)
+		}
+	}
+}
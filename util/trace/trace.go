@@ -0,0 +1,41 @@
+package trace
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Field - log field holding the trace ID
+const Field = "traceID"
+
+type contextKey struct{}
+
+// NewContext - context with a new trace ID, used when events are received so log
+// lines of a single update can be correlated across processing steps
+func NewContext(ctx context.Context) context.Context {
+	return WithID(ctx, uuid.New().String())
+}
+
+// WithID - context with the trace ID
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// ID - trace ID of the context, empty when context has none
+func ID(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Log - log entry with the trace ID of the context, fields are added
+// as usual: trace.Log(ctx).WithFields(log.Fields{...})
+func Log(ctx context.Context) *log.Entry {
+	id := ID(ctx)
+	if id == "" {
+		return log.NewEntry(log.StandardLogger())
+	}
+	return log.WithField(Field, id)
+}
@@ -0,0 +1,24 @@
+package trace
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContext(t *testing.T) {
+	ctx := NewContext(context.Background())
+	id := ID(ctx)
+	if id == "" {
+		t.Fatalf("expected trace ID")
+	}
+	if other := ID(NewContext(context.Background())); other == id {
+		t.Errorf("expected unique trace IDs")
+	}
+
+	if got := Log(ctx).Data[Field]; got != id {
+		t.Errorf("expected log entry with trace ID %s, got: %v", id, got)
+	}
+	if _, ok := Log(context.Background()).Data[Field]; ok {
+		t.Errorf("expected no trace ID field without trace")
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/Masterminds/semver"
 	"github.com/alwinius/bow/types"
+	"github.com/alwinius/bow/util/image"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -56,9 +57,9 @@ func GetVersion(version string) (*types.Version, error) {
 
 // GetVersionFromImageName - get version from image name
 func GetVersionFromImageName(name string) (*types.Version, error) {
-	parts := strings.Split(name, ":")
-	if len(parts) > 1 {
-		return GetVersion(parts[1])
+	_, tag := image.SplitTag(name)
+	if tag != "" {
+		return GetVersion(tag)
 	}
 
 	return nil, ErrVersionTagMissing
@@ -66,14 +67,14 @@ func GetVersionFromImageName(name string) (*types.Version, error) {
 
 // GetImageNameAndVersion - get name and version
 func GetImageNameAndVersion(name string) (string, *types.Version, error) {
-	parts := strings.Split(name, ":")
-	if len(parts) > 0 {
-		v, err := GetVersion(parts[1])
+	imageName, tag := image.SplitTag(name)
+	if tag != "" {
+		v, err := GetVersion(tag)
 		if err != nil {
 			return "", nil, err
 		}
 
-		return parts[0], v, nil
+		return imageName, v, nil
 	}
 
 	return "", nil, ErrVersionTagMissing
@@ -45,6 +45,17 @@ func TestGetVersionFromImageName(t *testing.T) {
 			want:    MustParse("0.1.14"),
 			wantErr: false,
 		},
+		{
+			name:    "registry with port",
+			args:    args{name: "registry.corp:5000/team/app:1.2.3"},
+			want:    MustParse("1.2.3"),
+			wantErr: false,
+		},
+		{
+			name:    "registry with port, no tag",
+			args:    args{name: "registry.corp:5000/team/app"},
+			wantErr: true,
+		},
 		{
 			name:    "non semver, missing minor and patch",
 			args:    args{name: "index.docker.io/application:42"},